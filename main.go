@@ -7,17 +7,57 @@ import (
 	"os"
 	"slices"
 	"strings"
+	"text/tabwriter"
 
 	"github.com/joho/godotenv"
 	"github.com/mark3labs/mcp-go/server"
+	"github.com/nguyenvanduocit/dev-kit/services"
 	"github.com/nguyenvanduocit/dev-kit/tools"
 )
 
 func main() {
 	envFile := flag.String("env", ".env", "Path to environment file")
 	protocol := flag.String("protocol", "stdio", "Protocol to use (stdio, sse)")
+	recordDir := flag.String("record", "", "Record upstream HTTP traffic into cassette files in this directory")
+	replayDir := flag.String("replay", "", "Replay upstream HTTP traffic from cassette files in this directory")
+	backend := flag.String("backend", "live", "Backend to use (live, fake)")
+	fixture := flag.String("fixture", "", "Seed file for the fake backend (defaults to the built-in demo data)")
+	extensionsFile := flag.String("extensions", "", "Path to a JSON file describing extension tool groups")
+	listGroups := flag.Bool("list-groups", false, "Print the available tool groups and exit")
 	flag.Parse()
 
+	if *recordDir != "" && *replayDir != "" {
+		log.Fatal("-record and -replay cannot be used together")
+	}
+
+	switch *backend {
+	case "live":
+		if *fixture != "" {
+			log.Fatal("-fixture requires -backend fake")
+		}
+	case "fake":
+		if *recordDir != "" || *replayDir != "" {
+			log.Fatal("-backend fake cannot be combined with -record or -replay")
+		}
+		if err := services.EnableFakeBackend(*fixture); err != nil {
+			log.Fatal(err)
+		}
+	default:
+		log.Fatalf("Invalid backend %q, expected live or fake", *backend)
+	}
+
+	if *recordDir != "" {
+		if err := services.EnableRecording(*recordDir); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if *replayDir != "" {
+		if err := services.EnableReplay(*replayDir); err != nil {
+			log.Fatal(err)
+		}
+	}
+
 	if *envFile != "" {
 		if err := godotenv.Load(*envFile); err != nil {
 			fmt.Printf("Warning: Error loading env file %s: %v\n", *envFile, err)
@@ -32,35 +72,43 @@ func main() {
 		server.WithResourceCapabilities(true, true),
 	)
 
-	enableTools := strings.Split(os.Getenv("ENABLE_TOOLS"), ",")
-	allToolsEnabled := len(enableTools) == 1 && enableTools[0] == ""
-
-	isEnabled := func(toolName string) bool {
-		return allToolsEnabled || slices.Contains(enableTools, toolName)
-	}
-
-	if isEnabled("confluence") {
-		tools.RegisterConfluenceTool(mcpServer)
+	if *extensionsFile != "" {
+		if err := tools.LoadExtensions(*extensionsFile); err != nil {
+			log.Fatal(err)
+		}
 	}
 
-	if isEnabled("jira") {
-		tools.RegisterJiraTool(mcpServer)
+	if *listGroups {
+		printGroups()
+		return
 	}
 
-	if isEnabled("gitlab") {
-		tools.RegisterGitLabTool(mcpServer)
-	}
+	enableTools := strings.Split(os.Getenv("ENABLE_TOOLS"), ",")
+	allToolsEnabled := len(enableTools) == 1 && enableTools[0] == ""
 
-	if isEnabled("github") {
-		tools.RegisterGitHubTool(mcpServer)
+	isEnabled := func(toolName string) bool {
+		return allToolsEnabled || slices.Contains(enableTools, toolName)
 	}
 
-	if isEnabled("script") {
-		tools.RegisterScriptTool(mcpServer)
+	groups := tools.Groups()
+	for _, name := range enableTools {
+		known := slices.ContainsFunc(groups, func(group tools.ToolGroup) bool { return group.Name == name })
+		if name != "" && !known {
+			log.Printf("Warning: ENABLE_TOOLS contains unknown tool group %q", name)
+		}
 	}
 
-	if isEnabled("codereview") {
-		tools.RegisterCodeReviewTool(mcpServer)
+	for _, group := range groups {
+		if !isEnabled(group.Name) {
+			continue
+		}
+		if missing := group.MissingEnv(); len(missing) > 0 {
+			log.Printf("Warning: skipping tool group %s, missing %s", group.Name, strings.Join(missing, ", "))
+			continue
+		}
+		if err := group.Register(mcpServer); err != nil {
+			log.Printf("Warning: skipping tool group %s: %v", group.Name, err)
+		}
 	}
 
 	if *protocol == "stdio" {
@@ -80,3 +128,17 @@ func main() {
 		}
 	}
 }
+
+// printGroups writes the registered tool groups, their access and required configuration to stdout
+func printGroups() {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "GROUP\tACCESS\tREQUIRES\tDESCRIPTION")
+	for _, group := range tools.Groups() {
+		requires := strings.Join(group.Requires(), ",")
+		if requires == "" {
+			requires = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", group.Name, group.Access, requires, group.Description)
+	}
+	w.Flush()
+}
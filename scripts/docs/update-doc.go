@@ -211,8 +211,9 @@ func main() {
 
         // Extract tool information if in tools directory
         if strings.HasPrefix(path, "tools/") {
-            fileName := filepath.Base(path)
-            tools := extractToolInfo(node, fileName)
+            // jira_comment.go and friends belong to the jira group
+            group, _, _ := strings.Cut(strings.TrimSuffix(filepath.Base(path), ".go"), "_")
+            tools := extractToolInfo(node, group+".go")
             allTools = append(allTools, tools...)
         }
 
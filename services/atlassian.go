@@ -2,66 +2,249 @@ package services
 
 import (
 	"log"
+	"net/http"
+	"net/url"
 	"os"
+	"strings"
 	"sync"
 
 	"github.com/ctreminiom/go-atlassian/confluence"
 	"github.com/ctreminiom/go-atlassian/jira/agile"
+	"github.com/ctreminiom/go-atlassian/jira/sm"
 	jira "github.com/ctreminiom/go-atlassian/jira/v2"
+	"github.com/ctreminiom/go-atlassian/service/common"
 	"github.com/pkg/errors"
 )
 
-func loadAtlassianCredentials() (host, mail, token string) {
-	host = os.Getenv("ATLASSIAN_HOST")
-	mail = os.Getenv("ATLASSIAN_EMAIL")
-	token = os.Getenv("ATLASSIAN_TOKEN")
+// Authentication modes for Jira and Confluence, selected with ATLASSIAN_AUTH_MODE
+const (
+	// AtlassianAuthBasic is Atlassian Cloud basic auth with an account email and API token
+	AtlassianAuthBasic = "basic"
+	// AtlassianAuthPAT is Jira and Confluence Data Center or Server with a personal access token
+	AtlassianAuthPAT = "pat"
+	// AtlassianAuthOAuth is Atlassian Cloud with an OAuth 2.0 (3LO) access token
+	AtlassianAuthOAuth = "oauth"
+)
+
+// AtlassianAuthMode returns the configured authentication mode, basic when it is not set
+func AtlassianAuthMode() string {
+	// Authentication mode: basic (Cloud email + API token), pat (Data Center personal access token) or oauth (Cloud OAuth 2.0)
+	mode := strings.ToLower(strings.TrimSpace(os.Getenv("ATLASSIAN_AUTH_MODE")))
+	if mode == "" {
+		return AtlassianAuthBasic
+	}
+	return mode
+}
+
+// AtlassianDataCenter reports whether Jira and Confluence are self-hosted Data Center or Server
+// instances, which identify users by username and lack some of the Cloud REST endpoints
+func AtlassianDataCenter() bool {
+	return AtlassianAuthMode() == AtlassianAuthPAT
+}
+
+// JiraBrowseURL returns the address of an issue in the Jira web UI
+func JiraBrowseURL(issueKey string) string {
+	return loadAtlassianConfig().siteURL + "/browse/" + issueKey
+}
+
+// AtlassianRequiredEnv lists the environment variables the configured authentication mode needs
+func AtlassianRequiredEnv() []string {
+	switch AtlassianAuthMode() {
+	case AtlassianAuthPAT:
+		return []string{"ATLASSIAN_HOST", "ATLASSIAN_TOKEN"}
+	case AtlassianAuthOAuth:
+		if os.Getenv("ATLASSIAN_OAUTH_REFRESH_TOKEN") != "" {
+			return []string{"ATLASSIAN_HOST", "ATLASSIAN_OAUTH_CLIENT_ID", "ATLASSIAN_OAUTH_CLIENT_SECRET"}
+		}
+		return []string{"ATLASSIAN_HOST", "ATLASSIAN_TOKEN"}
+	default:
+		return []string{"ATLASSIAN_HOST", "ATLASSIAN_EMAIL", "ATLASSIAN_TOKEN"}
+	}
+}
+
+// atlassianConfig is what the Jira, Agile, Service Management and Confluence clients need to reach their instance
+type atlassianConfig struct {
+	mode string
+	// siteURL is the Jira site users open in a browser
+	siteURL string
+	// jiraHost and confluenceHost are the REST API base URLs, which for OAuth go through api.atlassian.com
+	jiraHost       string
+	confluenceHost string
+	mail           string
+	token          string
+	// oauth supplies fresh access tokens when a refresh token is configured
+	oauth *atlassianOAuth
+}
+
+var loadAtlassianConfig = sync.OnceValue(func() atlassianConfig {
+	config := atlassianConfig{
+		mode:     AtlassianAuthMode(),
+		jiraHost: strings.TrimSuffix(os.Getenv("ATLASSIAN_HOST"), "/"),
+		// Confluence URL when it is not served from ATLASSIAN_HOST, as with separate Data Center instances
+		confluenceHost: strings.TrimSuffix(os.Getenv("ATLASSIAN_CONFLUENCE_HOST"), "/"),
+		mail:           os.Getenv("ATLASSIAN_EMAIL"),
+		token:          os.Getenv("ATLASSIAN_TOKEN"),
+	}
+	if config.confluenceHost == "" {
+		config.confluenceHost = config.jiraHost
+	}
+	config.siteURL = config.jiraHost
+
+	switch config.mode {
+	case AtlassianAuthBasic, AtlassianAuthPAT, AtlassianAuthOAuth:
+	default:
+		log.Fatalf("ATLASSIAN_AUTH_MODE must be one of %s, %s or %s, got %q", AtlassianAuthBasic, AtlassianAuthPAT, AtlassianAuthOAuth, config.mode)
+	}
+
+	// Cassettes and the fake backend need no credentials and serve Cloud paths, so offline modes only need placeholders
+	if IsOffline() {
+		if config.jiraHost == "" {
+			config.jiraHost = "https://offline.atlassian.invalid"
+			config.confluenceHost = config.jiraHost
+			config.siteURL = config.jiraHost
+		}
+		if config.mail == "" {
+			config.mail = "offline@example.com"
+		}
+		if config.token == "" {
+			config.token = "offline"
+		}
+		return config
+	}
+
+	var missing []string
+	for _, name := range AtlassianRequiredEnv() {
+		if os.Getenv(name) == "" {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		log.Fatalf("%s are required for ATLASSIAN_AUTH_MODE=%s, please set it in MCP Config", strings.Join(missing, ", "), config.mode)
+	}
+
+	if config.mode == AtlassianAuthOAuth {
+		if os.Getenv("ATLASSIAN_OAUTH_REFRESH_TOKEN") != "" {
+			config.oauth = newAtlassianOAuth()
+		} else {
+			config.oauth = &atlassianOAuth{accessToken: config.token}
+		}
+
+		cloudID, err := config.oauth.cloudID(config.jiraHost)
+		if err != nil {
+			log.Fatal(errors.WithMessage(err, "failed to find the Atlassian cloud ID"))
+		}
+		config.jiraHost = "https://api.atlassian.com/ex/jira/" + cloudID
+		config.confluenceHost = "https://api.atlassian.com/ex/confluence/" + cloudID
+	}
 
-	if host == "" || mail == "" || token == "" {
-		log.Fatal("ATLASSIAN_HOST, ATLASSIAN_EMAIL, ATLASSIAN_TOKEN are required, please set it in MCP Config")
+	return config
+})
+
+// httpClient returns the HTTP client of the Jira or Confluence clients, which carries OAuth tokens
+// and maps Confluence Cloud paths to Data Center ones
+func (config atlassianConfig) httpClient(forConfluence bool) *http.Client {
+	client := DefaultHttpClient()
+	if IsOffline() {
+		return client
+	}
+
+	transport := client.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	if config.mode == AtlassianAuthPAT && forConfluence {
+		// go-atlassian builds Confluence Cloud paths under /wiki, which Data Center serves from its context path
+		site, err := url.Parse(config.confluenceHost)
+		if err != nil {
+			log.Fatal(errors.WithMessage(err, "failed to parse the Confluence host"))
+		}
+		transport = &confluenceDataCenterTransport{next: transport, prefix: site.Path + "/wiki/"}
+	}
+	if config.oauth != nil {
+		transport = &atlassianOAuthTransport{next: transport, oauth: config.oauth}
 	}
 
-	return host, mail, token
+	return &http.Client{Transport: transport, Timeout: client.Timeout}
+}
+
+// authenticate sets up the credentials of the configured mode; OAuth tokens are added by the transport instead
+func (config atlassianConfig) authenticate(auth common.Authentication) {
+	switch config.mode {
+	case AtlassianAuthPAT:
+		auth.SetBearerToken(config.token)
+	case AtlassianAuthOAuth:
+		if config.oauth == nil {
+			auth.SetBearerToken(config.token)
+		}
+	default:
+		auth.SetBasicAuth(config.mail, config.token)
+	}
+}
+
+// confluenceDataCenterTransport strips the /wiki prefix of Confluence Cloud REST paths
+type confluenceDataCenterTransport struct {
+	next   http.RoundTripper
+	prefix string
+}
+
+func (t *confluenceDataCenterTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if strings.HasPrefix(req.URL.Path, t.prefix) {
+		req = req.Clone(req.Context())
+		req.URL.Path = strings.TrimSuffix(t.prefix, "wiki/") + strings.TrimPrefix(req.URL.Path, t.prefix)
+		req.URL.RawPath = ""
+	}
+	return t.next.RoundTrip(req)
 }
 
 var ConfluenceClient = sync.OnceValue[*confluence.Client](func() *confluence.Client {
-	host, mail, token := loadAtlassianCredentials()
+	config := loadAtlassianConfig()
 
-	instance, err := confluence.New(nil, host)
+	instance, err := confluence.New(config.httpClient(true), config.confluenceHost)
 	if err != nil {
 		log.Fatal(errors.WithMessage(err, "failed to create confluence client"))
 	}
 
-	instance.Auth.SetBasicAuth(mail, token)
+	config.authenticate(instance.Auth)
 
 	return instance
 })
 
 var JiraClient = sync.OnceValue[*jira.Client](func() *jira.Client {
-	host, mail, token := loadAtlassianCredentials()
-
-	if host == "" || mail == "" || token == "" {
-		log.Fatal("ATLASSIAN_HOST, ATLASSIAN_EMAIL, ATLASSIAN_TOKEN are required")
-	}
+	config := loadAtlassianConfig()
 
-	instance, err := jira.New(nil, host)
+	instance, err := jira.New(config.httpClient(false), config.jiraHost)
 	if err != nil {
 		log.Fatal(errors.WithMessage(err, "failed to create jira client"))
 	}
 
-	instance.Auth.SetBasicAuth(mail, token)
+	config.authenticate(instance.Auth)
 
 	return instance
 })
 
 var AgileClient = sync.OnceValue[*agile.Client](func() *agile.Client {
-	host, mail, token := loadAtlassianCredentials()
+	config := loadAtlassianConfig()
 
-	instance, err := agile.New(nil, host)
+	instance, err := agile.New(config.httpClient(false), config.jiraHost)
 	if err != nil {
 		log.Fatal(errors.WithMessage(err, "failed to create agile client"))
 	}
 
-	instance.Auth.SetBasicAuth(mail, token)
+	config.authenticate(instance.Auth)
+
+	return instance
+})
+
+var ServiceManagementClient = sync.OnceValue[*sm.Client](func() *sm.Client {
+	config := loadAtlassianConfig()
+
+	instance, err := sm.New(config.httpClient(false), config.jiraHost)
+	if err != nil {
+		log.Fatal(errors.WithMessage(err, "failed to create service management client"))
+	}
+
+	config.authenticate(instance.Auth)
 
 	return instance
 })
@@ -0,0 +1,182 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	atlassianTokenURL     = "https://auth.atlassian.com/oauth/token"
+	atlassianResourcesURL = "https://api.atlassian.com/oauth/token/accessible-resources"
+)
+
+// atlassianOAuth hands out OAuth 2.0 (3LO) access tokens for Atlassian Cloud. With a refresh token it
+// renews the access token shortly before it expires; otherwise the configured token is used as is.
+type atlassianOAuth struct {
+	mu           sync.Mutex
+	clientID     string
+	clientSecret string
+	refreshToken string
+	// tokenFile keeps the latest refresh token, which Atlassian rotates on every refresh
+	tokenFile   string
+	accessToken string
+	expiry      time.Time
+}
+
+func newAtlassianOAuth() *atlassianOAuth {
+	oauth := &atlassianOAuth{
+		clientID:     os.Getenv("ATLASSIAN_OAUTH_CLIENT_ID"),
+		clientSecret: os.Getenv("ATLASSIAN_OAUTH_CLIENT_SECRET"),
+		refreshToken: os.Getenv("ATLASSIAN_OAUTH_REFRESH_TOKEN"),
+		// File that stores rotated OAuth refresh tokens across restarts
+		tokenFile: os.Getenv("ATLASSIAN_OAUTH_TOKEN_FILE"),
+	}
+
+	// A refresh token saved by an earlier run supersedes the configured one, which has been rotated away
+	if oauth.tokenFile != "" {
+		if saved, err := os.ReadFile(oauth.tokenFile); err == nil && strings.TrimSpace(string(saved)) != "" {
+			oauth.refreshToken = strings.TrimSpace(string(saved))
+		}
+	}
+
+	return oauth
+}
+
+// token returns a valid access token, refreshing it when it is about to expire
+func (o *atlassianOAuth) token(ctx context.Context) (string, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.refreshToken == "" || (o.accessToken != "" && time.Now().Add(time.Minute).Before(o.expiry)) {
+		return o.accessToken, nil
+	}
+
+	payload, err := json.Marshal(map[string]string{
+		"grant_type":    "refresh_token",
+		"client_id":     o.clientID,
+		"client_secret": o.clientSecret,
+		"refresh_token": o.refreshToken,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	var result struct {
+		AccessToken  string `json:"access_token"`
+		RefreshToken string `json:"refresh_token"`
+		ExpiresIn    int    `json:"expires_in"`
+	}
+	if err := atlassianOAuthCall(ctx, http.MethodPost, atlassianTokenURL, "", payload, &result); err != nil {
+		return "", fmt.Errorf("failed to refresh the OAuth access token: %v", err)
+	}
+
+	o.accessToken = result.AccessToken
+	o.expiry = time.Now().Add(time.Duration(result.ExpiresIn) * time.Second)
+	if result.RefreshToken != "" && result.RefreshToken != o.refreshToken {
+		o.refreshToken = result.RefreshToken
+		if o.tokenFile != "" {
+			if err := os.WriteFile(o.tokenFile, []byte(o.refreshToken+"\n"), 0600); err != nil {
+				return "", fmt.Errorf("failed to save the rotated OAuth refresh token to %s: %v", o.tokenFile, err)
+			}
+		}
+	}
+
+	return o.accessToken, nil
+}
+
+// cloudID returns ATLASSIAN_CLOUD_ID, or looks up the cloud ID of the site at host among the
+// sites the token was granted for
+func (o *atlassianOAuth) cloudID(host string) (string, error) {
+	// Cloud ID of the Atlassian site, looked up from ATLASSIAN_HOST when empty
+	if cloudID := os.Getenv("ATLASSIAN_CLOUD_ID"); cloudID != "" {
+		return cloudID, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second*3)
+	defer cancel()
+
+	token, err := o.token(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	var sites []struct {
+		ID   string `json:"id"`
+		URL  string `json:"url"`
+		Name string `json:"name"`
+	}
+	if err := atlassianOAuthCall(ctx, http.MethodGet, atlassianResourcesURL, token, nil, &sites); err != nil {
+		return "", fmt.Errorf("failed to list the sites of the OAuth token: %v", err)
+	}
+
+	var urls []string
+	for _, site := range sites {
+		if strings.EqualFold(strings.TrimSuffix(site.URL, "/"), strings.TrimSuffix(host, "/")) {
+			return site.ID, nil
+		}
+		urls = append(urls, site.URL)
+	}
+	return "", fmt.Errorf("the OAuth token was not granted access to %s, only to: %s", host, strings.Join(urls, ", "))
+}
+
+// atlassianOAuthClient goes through PROXY_URL like DefaultHttpClient but never through the recorder
+var atlassianOAuthClient = sync.OnceValue(func() *http.Client {
+	return &http.Client{Transport: proxyTransport(), Timeout: 4 * time.Second * 3}
+})
+
+// atlassianOAuthCall talks to the Atlassian authorization endpoints. It bypasses DefaultHttpClient so
+// client secrets and tokens never end up in recorded cassettes.
+func atlassianOAuthCall(ctx context.Context, method, endpoint, token string, payload []byte, out interface{}) error {
+	request, err := http.NewRequestWithContext(ctx, method, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Accept", "application/json")
+	if payload != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		request.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	response, err := atlassianOAuthClient().Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return err
+	}
+	if response.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("%s (endpoint: %s)", strings.TrimSpace(string(body)), endpoint)
+	}
+
+	return json.Unmarshal(body, out)
+}
+
+// atlassianOAuthTransport adds the current OAuth access token to every request
+type atlassianOAuthTransport struct {
+	next  http.RoundTripper
+	oauth *atlassianOAuth
+}
+
+func (t *atlassianOAuthTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.oauth.token(req.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+token)
+	return t.next.RoundTrip(req)
+}
@@ -0,0 +1,296 @@
+package services
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// sensitiveHeaders are never written to a cassette
+var sensitiveHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"Private-Token",
+	"Job-Token",
+	"Cookie",
+	"Set-Cookie",
+	"X-Atlassian-Token",
+}
+
+var (
+	recordDir string
+	replayDir string
+	replay    *replayTransport
+)
+
+// EnableRecording captures every upstream HTTP exchange into cassette files under dir.
+// It must be called before any client is created.
+func EnableRecording(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create record directory: %v", err)
+	}
+	recordDir = dir
+	return nil
+}
+
+// EnableReplay serves upstream HTTP responses from cassette files under dir instead of the network.
+// The cassettes are loaded and checked right away, so a broken one fails at startup rather than
+// on the first tool call. It must be called before any client is created.
+func EnableReplay(dir string) error {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return fmt.Errorf("failed to open replay directory: %v", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("replay path %s is not a directory", dir)
+	}
+
+	transport, err := newReplayTransport(dir)
+	if err != nil {
+		return fmt.Errorf("failed to load cassettes: %v", err)
+	}
+	replayDir, replay = dir, transport
+	return nil
+}
+
+type cassetteMessage struct {
+	Method       string      `json:"method,omitempty"`
+	URL          string      `json:"url,omitempty"`
+	Status       int         `json:"status,omitempty"`
+	Headers      http.Header `json:"headers,omitempty"`
+	Body         string      `json:"body,omitempty"`
+	BodyEncoding string      `json:"body_encoding,omitempty"`
+}
+
+type cassette struct {
+	Key        string          `json:"key"`
+	RecordedAt time.Time       `json:"recorded_at"`
+	Request    cassetteMessage `json:"request"`
+	Response   cassetteMessage `json:"response"`
+}
+
+func encodeBody(body []byte) (string, string) {
+	if utf8.Valid(body) {
+		return string(body), ""
+	}
+	return base64.StdEncoding.EncodeToString(body), "base64"
+}
+
+func decodeBody(body, encoding string) ([]byte, error) {
+	if encoding == "base64" {
+		return base64.StdEncoding.DecodeString(body)
+	}
+	return []byte(body), nil
+}
+
+func stripHeaders(header http.Header) http.Header {
+	clean := header.Clone()
+	for _, name := range sensitiveHeaders {
+		clean.Del(name)
+	}
+	return clean
+}
+
+// cassetteKey identifies a request independently of the host, so cassettes
+// recorded against one instance can be replayed with placeholder credentials
+func cassetteKey(method, requestURI string, body []byte) string {
+	sum := sha256.Sum256(body)
+	return fmt.Sprintf("%s %s %s", method, requestURI, hex.EncodeToString(sum[:8]))
+}
+
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	req.Body.Close()
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+var unsafeFileChars = regexp.MustCompile(`[^a-zA-Z0-9]+`)
+
+// recordingTransport forwards requests to the network and writes each exchange to disk
+type recordingTransport struct {
+	next http.RoundTripper
+	dir  string
+
+	mu  sync.Mutex
+	seq int
+}
+
+// newRecordingTransport continues numbering after any cassettes already in dir
+func newRecordingTransport(next http.RoundTripper, dir string) *recordingTransport {
+	existing, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	return &recordingTransport{next: next, dir: dir, seq: len(existing)}
+}
+
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqBody, err := readRequestBody(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body: %v", err)
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %v", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	entry := cassette{
+		Key:        cassetteKey(req.Method, req.URL.RequestURI(), reqBody),
+		RecordedAt: time.Now().UTC(),
+		Request: cassetteMessage{
+			Method:  req.Method,
+			URL:     req.URL.String(),
+			Headers: stripHeaders(req.Header),
+		},
+		Response: cassetteMessage{
+			Status:  resp.StatusCode,
+			Headers: stripHeaders(resp.Header),
+		},
+	}
+	entry.Request.Body, entry.Request.BodyEncoding = encodeBody(reqBody)
+	entry.Response.Body, entry.Response.BodyEncoding = encodeBody(respBody)
+
+	if err := t.write(&entry, req); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+func (t *recordingTransport) write(entry *cassette, req *http.Request) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.seq++
+	slug := strings.Trim(unsafeFileChars.ReplaceAllString(req.URL.Host+req.URL.Path, "-"), "-")
+	if len(slug) > 80 {
+		slug = slug[:80]
+	}
+	name := fmt.Sprintf("%06d-%s-%s.json", t.seq, strings.ToLower(req.Method), slug)
+
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode cassette: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(t.dir, name), data, 0o600); err != nil {
+		return fmt.Errorf("failed to write cassette: %v", err)
+	}
+
+	return nil
+}
+
+// replayTransport answers requests from recorded cassettes. Identical requests are
+// served in the order they were recorded; the last recording is repeated once exhausted.
+type replayTransport struct {
+	mu       sync.Mutex
+	entries  map[string][]*cassette
+	position map[string]int
+}
+
+func newReplayTransport(dir string) (*replayTransport, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	t := &replayTransport{
+		entries:  make(map[string][]*cassette),
+		position: make(map[string]int),
+	}
+
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read cassette %s: %v", file, err)
+		}
+
+		var entry cassette
+		if err := json.Unmarshal(data, &entry); err != nil {
+			return nil, fmt.Errorf("failed to parse cassette %s: %v", file, err)
+		}
+		if entry.Key == "" {
+			return nil, fmt.Errorf("cassette %s has no key", file)
+		}
+		if entry.Response.Status < 100 || entry.Response.Status > 999 {
+			return nil, fmt.Errorf("cassette %s has invalid response status %d", file, entry.Response.Status)
+		}
+		if _, err := decodeBody(entry.Response.Body, entry.Response.BodyEncoding); err != nil {
+			return nil, fmt.Errorf("cassette %s has an invalid response body: %v", file, err)
+		}
+
+		t.entries[entry.Key] = append(t.entries[entry.Key], &entry)
+	}
+
+	return t, nil
+}
+
+func (t *replayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqBody, err := readRequestBody(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body: %v", err)
+	}
+
+	key := cassetteKey(req.Method, req.URL.RequestURI(), reqBody)
+
+	t.mu.Lock()
+	entries := t.entries[key]
+	if len(entries) == 0 {
+		t.mu.Unlock()
+		return nil, fmt.Errorf("no recorded response for %s %s", req.Method, req.URL.RequestURI())
+	}
+	index := t.position[key]
+	if index < len(entries)-1 {
+		t.position[key] = index + 1
+	}
+	entry := entries[index]
+	t.mu.Unlock()
+
+	body, err := decodeBody(entry.Response.Body, entry.Response.BodyEncoding)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode recorded body: %v", err)
+	}
+
+	header := entry.Response.Headers.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", entry.Response.Status, http.StatusText(entry.Response.Status)),
+		StatusCode:    entry.Response.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
@@ -0,0 +1,121 @@
+// Package fake implements an in-process, stateful stand-in for the Jira, Confluence,
+// GitHub and GitLab REST endpoints used by dev-kit. It is used by the `-backend fake`
+// mode and lets the tool handlers run end to end without any network access.
+package fake
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Backend serves the fake REST API. All state lives in memory and is seeded from a Fixture.
+type Backend struct {
+	mu  sync.Mutex
+	mux *http.ServeMux
+	now func() time.Time
+
+	jira       *jiraState
+	confluence *confluenceState
+	github     *githubState
+	gitlab     *gitlabState
+}
+
+// New creates a backend seeded with the given fixture
+func New(fixture *Fixture) *Backend {
+	b := &Backend{
+		mux: http.NewServeMux(),
+		now: time.Now,
+	}
+
+	b.jira = newJiraState(&fixture.Jira)
+	b.confluence = newConfluenceState(&fixture.Confluence)
+	b.github = newGitHubState(&fixture.GitHub)
+	b.gitlab = newGitLabState(&fixture.GitLab)
+
+	b.registerJiraRoutes()
+	b.registerConfluenceRoutes()
+	b.registerGitHubRoutes()
+	b.registerGitLabRoutes()
+
+	return b
+}
+
+func (b *Backend) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.mux.ServeHTTP(w, r)
+}
+
+// RoundTrip lets the backend be used directly as an http.Client transport
+func (b *Backend) RoundTrip(req *http.Request) (*http.Response, error) {
+	recorder := httptest.NewRecorder()
+	b.ServeHTTP(recorder, req)
+
+	resp := recorder.Result()
+	resp.Request = req
+	return resp, nil
+}
+
+func (b *Backend) handle(pattern string, handler http.HandlerFunc) {
+	b.mux.HandleFunc(pattern, handler)
+}
+
+func (b *Backend) timestamp() string {
+	return b.now().UTC().Format(jiraTimeFormat)
+}
+
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if value != nil {
+		json.NewEncoder(w).Encode(value)
+	}
+}
+
+func decodeJSON(r *http.Request, value interface{}) error {
+	defer r.Body.Close()
+	return json.NewDecoder(r.Body).Decode(value)
+}
+
+// jiraError mimics the error payload returned by Jira and Confluence
+func jiraError(w http.ResponseWriter, status int, messages ...string) {
+	writeJSON(w, status, map[string]interface{}{
+		"errorMessages": messages,
+		"errors":        map[string]string{},
+	})
+}
+
+// gitError mimics the error payload returned by GitHub and GitLab
+func gitError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"message": message})
+}
+
+func queryInt(r *http.Request, name string, fallback int) int {
+	value, err := strconv.Atoi(r.URL.Query().Get(name))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+func pathInt(r *http.Request, name string) (int, bool) {
+	value, err := strconv.Atoi(r.PathValue(name))
+	return value, err == nil
+}
+
+// paginate returns the window [startAt, startAt+maxResults) of n items
+func paginate(n, startAt, maxResults int) (int, int) {
+	if startAt < 0 || startAt > n {
+		startAt = n
+	}
+	end := n
+	if maxResults > 0 && startAt+maxResults < n {
+		end = startAt + maxResults
+	}
+	return startAt, end
+}
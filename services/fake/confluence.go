@@ -0,0 +1,302 @@
+package fake
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type confluencePage = ConfluencePageFixture
+
+type confluenceState struct {
+	spaces []ConfluenceSpaceFixture
+	pages  []*confluencePage
+	nextID int
+}
+
+func newConfluenceState(fixture *ConfluenceFixture) *confluenceState {
+	s := &confluenceState{spaces: fixture.Spaces, nextID: 90000}
+	for _, seed := range fixture.Pages {
+		page := &seed
+		if page.Version == 0 {
+			page.Version = 1
+		}
+		if id, err := strconv.Atoi(page.ID); err == nil && id > s.nextID {
+			s.nextID = id
+		}
+		s.pages = append(s.pages, page)
+	}
+	return s
+}
+
+func (s *confluenceState) page(id string) *confluencePage {
+	for _, page := range s.pages {
+		if page.ID == id {
+			return page
+		}
+	}
+	return nil
+}
+
+func (s *confluenceState) space(key string) *ConfluenceSpaceFixture {
+	for i := range s.spaces {
+		if strings.EqualFold(s.spaces[i].Key, key) {
+			return &s.spaces[i]
+		}
+	}
+	return nil
+}
+
+func (s *confluenceState) render(page *confluencePage, r *http.Request) map[string]interface{} {
+	result := map[string]interface{}{
+		"id":     page.ID,
+		"type":   "page",
+		"status": "current",
+		"title":  page.Title,
+		"space":  map[string]interface{}{"key": page.Space},
+		"version": map[string]interface{}{
+			"number": page.Version,
+			"when":   page.LastModified,
+		},
+		"body": map[string]interface{}{
+			"storage": map[string]interface{}{
+				"value":          page.Body,
+				"representation": "storage",
+			},
+		},
+		"_links": map[string]interface{}{
+			"self":  fmt.Sprintf("https://%s/wiki/rest/api/content/%s", r.Host, page.ID),
+			"webui": fmt.Sprintf("/spaces/%s/pages/%s", page.Space, page.ID),
+		},
+	}
+	if space := s.space(page.Space); space != nil {
+		result["space"] = map[string]interface{}{"key": space.Key, "name": space.Name}
+	}
+	if parent := s.page(page.ParentID); parent != nil {
+		result["ancestors"] = []map[string]interface{}{{"id": parent.ID, "type": "page", "title": parent.Title}}
+	}
+	return result
+}
+
+var cqlClause = regexp.MustCompile(`(?i)^\s*([a-z]+)\s*(=|~|!=)\s*(?:"([^"]*)"|'([^']*)'|(\S+))\s*$`)
+var cqlAnd = regexp.MustCompile(`(?i)\s+and\s+`)
+
+// matchCQL supports the CQL subset used by assistants: type, space, title, text, id
+// and ancestor clauses combined with AND. Unsupported clauses are reported as errors.
+func (s *confluenceState) matchCQL(cql string, page *confluencePage) (bool, error) {
+	cql = regexp.MustCompile(`(?i)\s+order\s+by\s+.*$`).ReplaceAllString(cql, "")
+	if strings.TrimSpace(cql) == "" {
+		return true, nil
+	}
+
+	for _, clause := range cqlAnd.Split(cql, -1) {
+		m := cqlClause.FindStringSubmatch(clause)
+		if m == nil {
+			return false, fmt.Errorf("could not parse cql clause: %s", strings.TrimSpace(clause))
+		}
+		field, op, value := strings.ToLower(m[1]), m[2], m[3]+m[4]+m[5]
+
+		var actual string
+		switch field {
+		case "type":
+			actual = "page"
+		case "space":
+			actual = page.Space
+		case "title":
+			actual = page.Title
+		case "text", "sitesearch":
+			actual = page.Title + " " + page.Body
+		case "id":
+			actual = page.ID
+		case "ancestor", "parent":
+			actual = page.ParentID
+		default:
+			return false, fmt.Errorf("unsupported cql field: %s", field)
+		}
+
+		var ok bool
+		switch op {
+		case "=":
+			ok = strings.EqualFold(actual, value)
+		case "!=":
+			ok = !strings.EqualFold(actual, value)
+		case "~":
+			ok = strings.Contains(strings.ToLower(actual), strings.ToLower(strings.Trim(value, "*")))
+		}
+		if !ok {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+func (b *Backend) registerConfluenceRoutes() {
+	b.handle("GET /wiki/rest/api/search", b.confluenceSearch)
+	b.handle("GET /wiki/rest/api/content", b.confluenceListContent)
+	b.handle("GET /wiki/rest/api/content/{id}", b.confluenceGetContent)
+	b.handle("POST /wiki/rest/api/content", b.confluenceCreateContent)
+	b.handle("PUT /wiki/rest/api/content/{id}", b.confluenceUpdateContent)
+}
+
+func (b *Backend) confluenceSearch(w http.ResponseWriter, r *http.Request) {
+	cql := r.URL.Query().Get("cql")
+	limit := queryInt(r, "limit", 25)
+
+	results := []map[string]interface{}{}
+	for _, page := range b.confluence.pages {
+		ok, err := b.confluence.matchCQL(cql, page)
+		if err != nil {
+			jiraError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if !ok {
+			continue
+		}
+
+		excerpt := page.Body
+		if len(excerpt) > 200 {
+			excerpt = excerpt[:200]
+		}
+		results = append(results, map[string]interface{}{
+			"content":      b.confluence.render(page, r),
+			"title":        page.Title,
+			"excerpt":      excerpt,
+			"url":          fmt.Sprintf("/spaces/%s/pages/%s", page.Space, page.ID),
+			"entityType":   "content",
+			"lastModified": page.LastModified,
+		})
+	}
+
+	start, end := paginate(len(results), queryInt(r, "start", 0), limit)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"results":   results[start:end],
+		"start":     start,
+		"limit":     limit,
+		"size":      end - start,
+		"totalSize": len(results),
+		"cqlQuery":  cql,
+	})
+}
+
+func (b *Backend) confluenceListContent(w http.ResponseWriter, r *http.Request) {
+	spaceKey := r.URL.Query().Get("spaceKey")
+	title := r.URL.Query().Get("title")
+
+	results := []map[string]interface{}{}
+	for _, page := range b.confluence.pages {
+		if spaceKey != "" && !strings.EqualFold(page.Space, spaceKey) {
+			continue
+		}
+		if title != "" && page.Title != title {
+			continue
+		}
+		results = append(results, b.confluence.render(page, r))
+	}
+
+	start, end := paginate(len(results), queryInt(r, "start", 0), queryInt(r, "limit", 25))
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"results": results[start:end],
+		"start":   start,
+		"limit":   end - start,
+		"size":    end - start,
+	})
+}
+
+func (b *Backend) confluenceGetContent(w http.ResponseWriter, r *http.Request) {
+	page := b.confluence.page(r.PathValue("id"))
+	if page == nil {
+		jiraError(w, http.StatusNotFound, "No content found with id: "+r.PathValue("id"))
+		return
+	}
+	writeJSON(w, http.StatusOK, b.confluence.render(page, r))
+}
+
+type confluenceContentPayload struct {
+	Title string `json:"title"`
+	Space *struct {
+		Key string `json:"key"`
+	} `json:"space"`
+	Ancestors []struct {
+		ID string `json:"id"`
+	} `json:"ancestors"`
+	Body *struct {
+		Storage *struct {
+			Value string `json:"value"`
+		} `json:"storage"`
+	} `json:"body"`
+	Version *struct {
+		Number int `json:"number"`
+	} `json:"version"`
+}
+
+func (b *Backend) confluenceCreateContent(w http.ResponseWriter, r *http.Request) {
+	var payload confluenceContentPayload
+	if err := decodeJSON(r, &payload); err != nil {
+		jiraError(w, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	if payload.Space == nil || b.confluence.space(payload.Space.Key) == nil {
+		jiraError(w, http.StatusBadRequest, "A valid space key is required")
+		return
+	}
+	if payload.Title == "" {
+		jiraError(w, http.StatusBadRequest, "A title is required")
+		return
+	}
+	for _, page := range b.confluence.pages {
+		if strings.EqualFold(page.Space, payload.Space.Key) && page.Title == payload.Title {
+			jiraError(w, http.StatusBadRequest, "A page with this title already exists in this space")
+			return
+		}
+	}
+
+	b.confluence.nextID++
+	page := &confluencePage{
+		ID:           strconv.Itoa(b.confluence.nextID),
+		Space:        payload.Space.Key,
+		Title:        payload.Title,
+		Version:      1,
+		LastModified: b.timestamp(),
+	}
+	if payload.Body != nil && payload.Body.Storage != nil {
+		page.Body = payload.Body.Storage.Value
+	}
+	if len(payload.Ancestors) > 0 {
+		page.ParentID = payload.Ancestors[len(payload.Ancestors)-1].ID
+	}
+	b.confluence.pages = append(b.confluence.pages, page)
+
+	writeJSON(w, http.StatusOK, b.confluence.render(page, r))
+}
+
+func (b *Backend) confluenceUpdateContent(w http.ResponseWriter, r *http.Request) {
+	page := b.confluence.page(r.PathValue("id"))
+	if page == nil {
+		jiraError(w, http.StatusNotFound, "No content found with id: "+r.PathValue("id"))
+		return
+	}
+
+	var payload confluenceContentPayload
+	if err := decodeJSON(r, &payload); err != nil {
+		jiraError(w, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	if payload.Version == nil || payload.Version.Number != page.Version+1 {
+		jiraError(w, http.StatusConflict, fmt.Sprintf("Version must be incremented on update. Current version is: %d", page.Version))
+		return
+	}
+
+	page.Version = payload.Version.Number
+	page.LastModified = b.timestamp()
+	if payload.Title != "" {
+		page.Title = payload.Title
+	}
+	if payload.Body != nil && payload.Body.Storage != nil {
+		page.Body = payload.Body.Storage.Value
+	}
+
+	writeJSON(w, http.StatusOK, b.confluence.render(page, r))
+}
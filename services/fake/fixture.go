@@ -0,0 +1,471 @@
+package fake
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+//go:embed fixture.json
+var defaultFixture []byte
+
+// Fixture is the seed data of the fake backend
+type Fixture struct {
+	Jira       JiraFixture       `json:"jira"`
+	Confluence ConfluenceFixture `json:"confluence"`
+	GitHub     GitHubFixture     `json:"github"`
+	GitLab     GitLabFixture     `json:"gitlab"`
+}
+
+type JiraFixture struct {
+	CurrentUser string               `json:"current_user"`
+	Users       []JiraUserFixture    `json:"users"`
+	Projects    []JiraProjectFixture `json:"projects"`
+	Statuses    []JiraStatusFixture  `json:"statuses"`
+	// Priorities are listed from the highest to the lowest, the Jira defaults when empty
+	Priorities  []JiraPriorityFixture   `json:"priorities"`
+	Transitions []JiraTransitionFixture `json:"transitions"`
+	Issues      []JiraIssueFixture      `json:"issues"`
+	Boards      []JiraBoardFixture      `json:"boards"`
+	Sprints     []JiraSprintFixture     `json:"sprints"`
+	Fields      []JiraFieldFixture      `json:"fields"`
+	LinkTypes   []JiraLinkTypeFixture   `json:"link_types"`
+	Links       []JiraLinkFixture       `json:"links"`
+	Versions    []JiraVersionFixture    `json:"versions"`
+	Filters     []JiraFilterFixture     `json:"filters"`
+	Dashboards  []JiraDashboardFixture  `json:"dashboards"`
+	Components  []JiraComponentFixture  `json:"components"`
+	// ServiceDesks turn projects into Jira Service Management service desks
+	ServiceDesks []JiraServiceDeskFixture `json:"service_desks"`
+}
+
+type JiraLinkTypeFixture struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Inward  string `json:"inward"`
+	Outward string `json:"outward"`
+}
+
+// JiraLinkFixture links two issues so that From <outward> To, e.g. KP-5 blocks KP-6
+type JiraLinkFixture struct {
+	Type string `json:"type"`
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// JiraVersionFixture is a project version; issues name theirs in FixVersions
+type JiraVersionFixture struct {
+	ID          string `json:"id"`
+	Project     string `json:"project"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	StartDate   string `json:"start_date"`
+	ReleaseDate string `json:"release_date"`
+	Released    bool   `json:"released"`
+	Archived    bool   `json:"archived"`
+}
+
+// JiraFilterFixture is a saved filter. Shares are "project:KEY", "group:NAME" or "authenticated";
+// a filter without shares is private to its owner. Favourite is from the current user's point of view.
+type JiraFilterFixture struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Owner       string   `json:"owner"`
+	JQL         string   `json:"jql"`
+	Favourite   bool     `json:"favourite"`
+	Shares      []string `json:"shares"`
+}
+
+// JiraDashboardFixture is a dashboard, shared like a filter
+type JiraDashboardFixture struct {
+	ID        string   `json:"id"`
+	Name      string   `json:"name"`
+	Owner     string   `json:"owner"`
+	Favourite bool     `json:"favourite"`
+	Shares    []string `json:"shares"`
+}
+
+// JiraServiceDeskFixture is the service desk of a project, with its portal request types,
+// agent queues and SLA goals
+type JiraServiceDeskFixture struct {
+	ID           string                   `json:"id"`
+	Project      string                   `json:"project"`
+	RequestTypes []JiraRequestTypeFixture `json:"request_types"`
+	Queues       []JiraQueueFixture       `json:"queues"`
+	SLAs         []JiraSLAFixture         `json:"slas"`
+}
+
+// JiraRequestTypeFixture is a request type. Fields are the IDs of the fields on its portal
+// form and Required those that customers must fill in.
+type JiraRequestTypeFixture struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	HelpText    string   `json:"help_text"`
+	IssueType   string   `json:"issue_type"`
+	Fields      []string `json:"fields"`
+	Required    []string `json:"required"`
+}
+
+type JiraQueueFixture struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	JQL  string `json:"jql"`
+}
+
+// JiraSLAFixture is an SLA goal counted in calendar time from the creation of a request. It
+// stops at the first public comment by someone other than the reporter when Stop is response,
+// or when the request reaches a done status when Stop is resolution.
+type JiraSLAFixture struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Goal string `json:"goal"` // written as Jira does, e.g. 4h
+	Stop string `json:"stop"`
+}
+
+type JiraUserFixture struct {
+	AccountID   string `json:"account_id"`
+	DisplayName string `json:"display_name"`
+	Email       string `json:"email"`
+}
+
+type JiraProjectFixture struct {
+	ID         string   `json:"id"`
+	Key        string   `json:"key"`
+	Name       string   `json:"name"`
+	Lead       string   `json:"lead"`
+	IssueTypes []string `json:"issue_types"`
+	// Assignable lists the users who can be assigned issues, everyone when empty
+	Assignable      []string `json:"assignable"`
+	IssueTypeScheme string   `json:"issue_type_scheme"`
+	WorkflowScheme  string   `json:"workflow_scheme"`
+	// DefaultWorkflow applies to the issue types Workflows does not map to a workflow of their own
+	DefaultWorkflow string            `json:"default_workflow"`
+	Workflows       map[string]string `json:"workflows"`
+}
+
+// JiraComponentFixture is a project component. AssigneeType is PROJECT_DEFAULT, COMPONENT_LEAD,
+// PROJECT_LEAD or UNASSIGNED, as in Jira.
+type JiraComponentFixture struct {
+	ID           string `json:"id"`
+	Project      string `json:"project"`
+	Name         string `json:"name"`
+	Description  string `json:"description"`
+	Lead         string `json:"lead"`
+	AssigneeType string `json:"assignee_type"`
+}
+
+type JiraPriorityFixture struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type JiraStatusFixture struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Category string `json:"category"` // new, indeterminate or done
+}
+
+type JiraTransitionFixture struct {
+	ID       string   `json:"id"`
+	Name     string   `json:"name"`
+	From     []string `json:"from"` // status names, empty means any
+	To       string   `json:"to"`
+	Fields   []string `json:"fields"`   // IDs of the fields on the transition screen
+	Required []string `json:"required"` // IDs of the screen fields that must be set
+}
+
+// JiraFieldFixture is a custom field. The system fields are built in.
+type JiraFieldFixture struct {
+	ID            string   `json:"id"`
+	Name          string   `json:"name"`
+	Type          string   `json:"type"`  // schema type, e.g. number, option or array
+	Items         string   `json:"items"` // item type of array fields
+	Custom        string   `json:"custom"`
+	Required      bool     `json:"required"`
+	AllowedValues []string `json:"allowed_values"`
+}
+
+type JiraCommentFixture struct {
+	Author  string `json:"author"`
+	Body    string `json:"body"`
+	Created string `json:"created"`
+	// Internal comments of service desk requests are only shown to agents
+	Internal bool `json:"internal"`
+}
+
+type JiraIssueFixture struct {
+	Key         string               `json:"key"`
+	Type        string               `json:"type"`
+	Summary     string               `json:"summary"`
+	Description string               `json:"description"`
+	Status      string               `json:"status"`
+	Priority    string               `json:"priority"`
+	Assignee    string               `json:"assignee"`
+	Reporter    string               `json:"reporter"`
+	Labels      []string             `json:"labels"`
+	Parent      string               `json:"parent"`
+	FixVersions []string             `json:"fix_versions"`
+	Components  []string             `json:"components"`
+	Created     string               `json:"created"`
+	Updated     string               `json:"updated"`
+	Comments    []JiraCommentFixture `json:"comments"`
+	// Watchers are added to the reporter and assignee, who watch their issues as in Jira
+	Watchers []string `json:"watchers"`
+	// Fields holds other field values by field ID, e.g. story points
+	Fields map[string]interface{} `json:"fields"`
+	// History seeds the changelog, oldest first
+	History     []JiraHistoryFixture    `json:"history"`
+	Worklogs    []JiraWorklogFixture    `json:"worklogs"`
+	Attachments []JiraAttachmentFixture `json:"attachments"`
+	// RequestType is the ID of the service desk request type the issue was raised with
+	RequestType string `json:"request_type"`
+}
+
+// JiraAttachmentFixture is a file attached to an issue. Binary content is written in
+// base64 with Encoding set to base64, as cassettes do.
+type JiraAttachmentFixture struct {
+	Filename string `json:"filename"`
+	MimeType string `json:"mime_type"`
+	Author   string `json:"author"`
+	Created  string `json:"created"`
+	Content  string `json:"content"`
+	Encoding string `json:"encoding"`
+}
+
+// JiraWorklogFixture is time logged on an issue, with TimeSpent written as Jira does, e.g. 1h 30m
+type JiraWorklogFixture struct {
+	Author    string `json:"author"`
+	Started   string `json:"started"`
+	TimeSpent string `json:"time_spent"`
+	Comment   string `json:"comment"`
+}
+
+// JiraHistoryFixture is a change of one field. Sprint changes name the sprints, separated by
+// commas, and custom fields are referred to by ID.
+type JiraHistoryFixture struct {
+	Created string `json:"created"`
+	Author  string `json:"author"`
+	Field   string `json:"field"`
+	From    string `json:"from"`
+	To      string `json:"to"`
+}
+
+type JiraBoardFixture struct {
+	ID      int    `json:"id"`
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	Project string `json:"project"`
+	// Columns defaults to one column per status
+	Columns []JiraColumnFixture `json:"columns"`
+	// Estimation is the ID of the estimation field, empty to estimate by issue count
+	Estimation string `json:"estimation"`
+}
+
+type JiraColumnFixture struct {
+	Name     string   `json:"name"`
+	Statuses []string `json:"statuses"` // status names
+}
+
+type JiraSprintFixture struct {
+	ID        int      `json:"id"`
+	BoardID   int      `json:"board_id"`
+	Name      string   `json:"name"`
+	State     string   `json:"state"`
+	Goal      string   `json:"goal"`
+	StartDate string   `json:"start_date"`
+	EndDate   string   `json:"end_date"`
+	Completed string   `json:"complete_date"`
+	Issues    []string `json:"issues"`
+}
+
+type ConfluenceFixture struct {
+	Spaces []ConfluenceSpaceFixture `json:"spaces"`
+	Pages  []ConfluencePageFixture  `json:"pages"`
+}
+
+type ConfluenceSpaceFixture struct {
+	Key  string `json:"key"`
+	Name string `json:"name"`
+}
+
+type ConfluencePageFixture struct {
+	ID           string `json:"id"`
+	Space        string `json:"space"`
+	Title        string `json:"title"`
+	Body         string `json:"body"` // storage format (XHTML)
+	ParentID     string `json:"parent_id"`
+	Version      int    `json:"version"`
+	LastModified string `json:"last_modified"`
+}
+
+type GitHubFixture struct {
+	Repos    []GitHubRepoFixture    `json:"repos"`
+	Pulls    []GitHubPullFixture    `json:"pulls"`
+	Issues   []GitHubIssueFixture   `json:"issues"`
+	Comments []GitHubCommentFixture `json:"comments"`
+}
+
+type GitHubRepoFixture struct {
+	Owner         string            `json:"owner"`
+	Name          string            `json:"name"`
+	Description   string            `json:"description"`
+	Private       bool              `json:"private"`
+	Language      string            `json:"language"`
+	DefaultBranch string            `json:"default_branch"`
+	Stars         int               `json:"stars"`
+	Forks         int               `json:"forks"`
+	Created       string            `json:"created"`
+	Updated       string            `json:"updated"`
+	Files         map[string]string `json:"files"`
+}
+
+type GitHubPullFixture struct {
+	Repo    string `json:"repo"` // owner/name
+	Number  int    `json:"number"`
+	Title   string `json:"title"`
+	Body    string `json:"body"`
+	State   string `json:"state"`
+	User    string `json:"user"`
+	Head    string `json:"head"`
+	Base    string `json:"base"`
+	Created string `json:"created"`
+	Merged  string `json:"merged"`
+	Closed  string `json:"closed"`
+}
+
+type GitHubIssueFixture struct {
+	Repo    string   `json:"repo"` // owner/name
+	Number  int      `json:"number"`
+	Title   string   `json:"title"`
+	Body    string   `json:"body"`
+	State   string   `json:"state"`
+	User    string   `json:"user"`
+	Labels  []string `json:"labels"`
+	Created string   `json:"created"`
+	Closed  string   `json:"closed"`
+}
+
+type GitHubCommentFixture struct {
+	Repo    string `json:"repo"` // owner/name
+	Number  int    `json:"number"`
+	User    string `json:"user"`
+	Body    string `json:"body"`
+	Created string `json:"created"`
+}
+
+type GitLabFixture struct {
+	Groups        []GitLabGroupFixture        `json:"groups"`
+	Projects      []GitLabProjectFixture      `json:"projects"`
+	MergeRequests []GitLabMergeRequestFixture `json:"merge_requests"`
+	Notes         []GitLabNoteFixture         `json:"notes"`
+	Pipelines     []GitLabPipelineFixture     `json:"pipelines"`
+	Commits       []GitLabCommitFixture       `json:"commits"`
+	Events        []GitLabEventFixture        `json:"events"`
+}
+
+type GitLabGroupFixture struct {
+	ID      string                `json:"id"`
+	Members []GitLabMemberFixture `json:"members"`
+}
+
+type GitLabMemberFixture struct {
+	ID          int    `json:"id"`
+	Username    string `json:"username"`
+	Name        string `json:"name"`
+	AccessLevel int    `json:"access_level"`
+}
+
+type GitLabProjectFixture struct {
+	ID            int               `json:"id"`
+	Group         string            `json:"group"`
+	Path          string            `json:"path"` // path with namespace
+	Name          string            `json:"name"`
+	Description   string            `json:"description"`
+	DefaultBranch string            `json:"default_branch"`
+	Branches      []string          `json:"branches"`
+	Tags          []string          `json:"tags"`
+	Files         map[string]string `json:"files"`
+	LastActivity  string            `json:"last_activity"`
+}
+
+type GitLabDiffFixture struct {
+	OldPath     string `json:"old_path"`
+	NewPath     string `json:"new_path"`
+	Diff        string `json:"diff"`
+	NewFile     bool   `json:"new_file"`
+	RenamedFile bool   `json:"renamed_file"`
+	DeletedFile bool   `json:"deleted_file"`
+}
+
+type GitLabMergeRequestFixture struct {
+	Project      string              `json:"project"`
+	IID          int                 `json:"iid"`
+	Title        string              `json:"title"`
+	Description  string              `json:"description"`
+	State        string              `json:"state"`
+	Author       string              `json:"author"`
+	SourceBranch string              `json:"source_branch"`
+	TargetBranch string              `json:"target_branch"`
+	Created      string              `json:"created"`
+	Diffs        []GitLabDiffFixture `json:"diffs"`
+}
+
+type GitLabNoteFixture struct {
+	Project string `json:"project"`
+	MRIID   int    `json:"mr_iid"`
+	Author  string `json:"author"`
+	Body    string `json:"body"`
+	Created string `json:"created"`
+}
+
+type GitLabPipelineFixture struct {
+	Project string `json:"project"`
+	ID      int    `json:"id"`
+	Status  string `json:"status"`
+	Ref     string `json:"ref"`
+	SHA     string `json:"sha"`
+	Created string `json:"created"`
+}
+
+type GitLabCommitFixture struct {
+	Project string              `json:"project"`
+	SHA     string              `json:"sha"`
+	Title   string              `json:"title"`
+	Author  string              `json:"author"`
+	Ref     string              `json:"ref"`
+	Date    string              `json:"date"`
+	Parents []string            `json:"parents"`
+	Diffs   []GitLabDiffFixture `json:"diffs"`
+}
+
+type GitLabEventFixture struct {
+	Username   string `json:"username"`
+	ProjectID  int    `json:"project_id"`
+	Action     string `json:"action"`
+	TargetType string `json:"target_type"`
+	TargetIID  int    `json:"target_iid"`
+	Created    string `json:"created"`
+}
+
+// LoadFixture reads a fixture file; an empty path loads the built-in demo fixture
+func LoadFixture(path string) (*Fixture, error) {
+	data := defaultFixture
+	if path != "" {
+		var err error
+		data, err = os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read fixture: %v", err)
+		}
+	}
+
+	var fixture Fixture
+	if err := json.Unmarshal(data, &fixture); err != nil {
+		return nil, fmt.Errorf("failed to parse fixture: %v", err)
+	}
+
+	return &fixture, nil
+}
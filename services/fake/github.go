@@ -0,0 +1,486 @@
+package fake
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+type githubState struct {
+	repos    []*GitHubRepoFixture
+	pulls    []*GitHubPullFixture
+	issues   []*GitHubIssueFixture
+	comments []*GitHubCommentFixture
+	nextID   int64
+}
+
+func newGitHubState(fixture *GitHubFixture) *githubState {
+	s := &githubState{nextID: 1000}
+	for i := range fixture.Repos {
+		s.repos = append(s.repos, &fixture.Repos[i])
+	}
+	for i := range fixture.Pulls {
+		s.pulls = append(s.pulls, &fixture.Pulls[i])
+	}
+	for i := range fixture.Issues {
+		s.issues = append(s.issues, &fixture.Issues[i])
+	}
+	for i := range fixture.Comments {
+		s.comments = append(s.comments, &fixture.Comments[i])
+	}
+	return s
+}
+
+func repoName(r *http.Request) string {
+	return r.PathValue("owner") + "/" + r.PathValue("repo")
+}
+
+func (s *githubState) repo(fullName string) *GitHubRepoFixture {
+	for _, repo := range s.repos {
+		if strings.EqualFold(repo.Owner+"/"+repo.Name, fullName) {
+			return repo
+		}
+	}
+	return nil
+}
+
+func (s *githubState) pull(fullName string, number int) *GitHubPullFixture {
+	for _, pull := range s.pulls {
+		if strings.EqualFold(pull.Repo, fullName) && pull.Number == number {
+			return pull
+		}
+	}
+	return nil
+}
+
+func (s *githubState) issue(fullName string, number int) *GitHubIssueFixture {
+	for _, issue := range s.issues {
+		if strings.EqualFold(issue.Repo, fullName) && issue.Number == number {
+			return issue
+		}
+	}
+	return nil
+}
+
+// nextNumber returns the next number shared by issues and pull requests of a repository
+func (s *githubState) nextNumber(fullName string) int {
+	number := 0
+	for _, pull := range s.pulls {
+		if strings.EqualFold(pull.Repo, fullName) && pull.Number > number {
+			number = pull.Number
+		}
+	}
+	for _, issue := range s.issues {
+		if strings.EqualFold(issue.Repo, fullName) && issue.Number > number {
+			number = issue.Number
+		}
+	}
+	return number + 1
+}
+
+func githubUser(login string) map[string]interface{} {
+	return map[string]interface{}{"login": login, "type": "User"}
+}
+
+func optionalTime(value string) interface{} {
+	if value == "" {
+		return nil
+	}
+	return value
+}
+
+func githubRepoJSON(repo *GitHubRepoFixture) map[string]interface{} {
+	fullName := repo.Owner + "/" + repo.Name
+	return map[string]interface{}{
+		"name":              repo.Name,
+		"full_name":         fullName,
+		"owner":             githubUser(repo.Owner),
+		"private":           repo.Private,
+		"description":       repo.Description,
+		"html_url":          "https://github.com/" + fullName,
+		"clone_url":         "https://github.com/" + fullName + ".git",
+		"default_branch":    repo.DefaultBranch,
+		"language":          repo.Language,
+		"stargazers_count":  repo.Stars,
+		"forks_count":       repo.Forks,
+		"open_issues_count": 0,
+		"created_at":        optionalTime(repo.Created),
+		"updated_at":        optionalTime(repo.Updated),
+	}
+}
+
+func githubPullJSON(pull *GitHubPullFixture) map[string]interface{} {
+	return map[string]interface{}{
+		"number":     pull.Number,
+		"title":      pull.Title,
+		"body":       pull.Body,
+		"state":      pull.State,
+		"user":       githubUser(pull.User),
+		"html_url":   fmt.Sprintf("https://github.com/%s/pull/%d", pull.Repo, pull.Number),
+		"created_at": optionalTime(pull.Created),
+		"merged_at":  optionalTime(pull.Merged),
+		"closed_at":  optionalTime(pull.Closed),
+		"merged":     pull.Merged != "",
+		"head":       map[string]interface{}{"ref": pull.Head},
+		"base":       map[string]interface{}{"ref": pull.Base},
+	}
+}
+
+func githubIssueJSON(issue *GitHubIssueFixture) map[string]interface{} {
+	labels := []map[string]interface{}{}
+	for _, label := range issue.Labels {
+		labels = append(labels, map[string]interface{}{"name": label})
+	}
+	return map[string]interface{}{
+		"number":     issue.Number,
+		"title":      issue.Title,
+		"body":       issue.Body,
+		"state":      issue.State,
+		"user":       githubUser(issue.User),
+		"labels":     labels,
+		"html_url":   fmt.Sprintf("https://github.com/%s/issues/%d", issue.Repo, issue.Number),
+		"created_at": optionalTime(issue.Created),
+		"closed_at":  optionalTime(issue.Closed),
+	}
+}
+
+func stateMatches(filter, state string) bool {
+	return filter == "" || filter == "all" || strings.EqualFold(filter, state)
+}
+
+func (b *Backend) registerGitHubRoutes() {
+	b.handle("GET /users/{owner}/repos", b.githubListRepos)
+	b.handle("GET /orgs/{owner}/repos", b.githubListRepos)
+	b.handle("GET /repos/{owner}/{repo}", b.githubGetRepo)
+	b.handle("GET /repos/{owner}/{repo}/contents/{path...}", b.githubGetContents)
+	b.handle("GET /repos/{owner}/{repo}/pulls", b.githubListPulls)
+	b.handle("POST /repos/{owner}/{repo}/pulls", b.githubCreatePull)
+	b.handle("GET /repos/{owner}/{repo}/pulls/{number}", b.githubGetPull)
+	b.handle("PATCH /repos/{owner}/{repo}/pulls/{number}", b.githubEditPull)
+	b.handle("POST /repos/{owner}/{repo}/pulls/{number}/reviews", b.githubCreateReview)
+	b.handle("GET /repos/{owner}/{repo}/issues", b.githubListIssues)
+	b.handle("GET /repos/{owner}/{repo}/issues/{number}", b.githubGetIssue)
+	b.handle("PATCH /repos/{owner}/{repo}/issues/{number}", b.githubEditIssue)
+	b.handle("GET /repos/{owner}/{repo}/issues/{number}/comments", b.githubListComments)
+	b.handle("POST /repos/{owner}/{repo}/issues/{number}/comments", b.githubCreateComment)
+}
+
+func (b *Backend) githubListRepos(w http.ResponseWriter, r *http.Request) {
+	owner := r.PathValue("owner")
+	repoType := r.URL.Query().Get("type")
+
+	repos := []map[string]interface{}{}
+	for _, repo := range b.github.repos {
+		if !strings.EqualFold(repo.Owner, owner) {
+			continue
+		}
+		if (repoType == "public" && repo.Private) || (repoType == "private" && !repo.Private) {
+			continue
+		}
+		repos = append(repos, githubRepoJSON(repo))
+	}
+
+	writeJSON(w, http.StatusOK, repos)
+}
+
+func (b *Backend) githubGetRepo(w http.ResponseWriter, r *http.Request) {
+	repo := b.github.repo(repoName(r))
+	if repo == nil {
+		gitError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+	writeJSON(w, http.StatusOK, githubRepoJSON(repo))
+}
+
+func (b *Backend) githubGetContents(w http.ResponseWriter, r *http.Request) {
+	repo := b.github.repo(repoName(r))
+	if repo == nil {
+		gitError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+
+	path := r.PathValue("path")
+	content, ok := repo.Files[path]
+	if !ok {
+		gitError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"type":     "file",
+		"encoding": "base64",
+		"name":     path[strings.LastIndex(path, "/")+1:],
+		"path":     path,
+		"size":     len(content),
+		"content":  base64.StdEncoding.EncodeToString([]byte(content)),
+	})
+}
+
+func (b *Backend) githubListPulls(w http.ResponseWriter, r *http.Request) {
+	name := repoName(r)
+	if b.github.repo(name) == nil {
+		gitError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+
+	state := r.URL.Query().Get("state")
+	if state == "" {
+		state = "open"
+	}
+
+	pulls := []map[string]interface{}{}
+	for _, pull := range b.github.pulls {
+		if strings.EqualFold(pull.Repo, name) && stateMatches(state, pull.State) {
+			pulls = append(pulls, githubPullJSON(pull))
+		}
+	}
+
+	writeJSON(w, http.StatusOK, pulls)
+}
+
+func (b *Backend) githubGetPull(w http.ResponseWriter, r *http.Request) {
+	number, _ := pathInt(r, "number")
+	pull := b.github.pull(repoName(r), number)
+	if pull == nil {
+		gitError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+	writeJSON(w, http.StatusOK, githubPullJSON(pull))
+}
+
+func (b *Backend) githubCreatePull(w http.ResponseWriter, r *http.Request) {
+	name := repoName(r)
+	if b.github.repo(name) == nil {
+		gitError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+
+	var payload struct {
+		Title string `json:"title"`
+		Head  string `json:"head"`
+		Base  string `json:"base"`
+		Body  string `json:"body"`
+	}
+	if err := decodeJSON(r, &payload); err != nil {
+		gitError(w, http.StatusBadRequest, "Problems parsing JSON")
+		return
+	}
+	if payload.Title == "" || payload.Head == "" || payload.Base == "" {
+		gitError(w, http.StatusUnprocessableEntity, "Validation Failed")
+		return
+	}
+
+	pull := &GitHubPullFixture{
+		Repo:    name,
+		Number:  b.github.nextNumber(name),
+		Title:   payload.Title,
+		Body:    payload.Body,
+		State:   "open",
+		User:    r.PathValue("owner"),
+		Head:    payload.Head,
+		Base:    payload.Base,
+		Created: b.now().UTC().Format("2006-01-02T15:04:05Z"),
+	}
+	b.github.pulls = append(b.github.pulls, pull)
+
+	writeJSON(w, http.StatusCreated, githubPullJSON(pull))
+}
+
+func (b *Backend) githubEditPull(w http.ResponseWriter, r *http.Request) {
+	number, _ := pathInt(r, "number")
+	pull := b.github.pull(repoName(r), number)
+	if pull == nil {
+		gitError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+
+	var payload struct {
+		Title *string `json:"title"`
+		Body  *string `json:"body"`
+		State *string `json:"state"`
+	}
+	if err := decodeJSON(r, &payload); err != nil {
+		gitError(w, http.StatusBadRequest, "Problems parsing JSON")
+		return
+	}
+
+	if payload.Title != nil {
+		pull.Title = *payload.Title
+	}
+	if payload.Body != nil {
+		pull.Body = *payload.Body
+	}
+	if payload.State != nil {
+		pull.State = *payload.State
+		if pull.State == "closed" {
+			pull.Closed = b.now().UTC().Format("2006-01-02T15:04:05Z")
+		} else {
+			pull.Closed = ""
+		}
+	}
+
+	writeJSON(w, http.StatusOK, githubPullJSON(pull))
+}
+
+func (b *Backend) githubCreateReview(w http.ResponseWriter, r *http.Request) {
+	number, _ := pathInt(r, "number")
+	if b.github.pull(repoName(r), number) == nil {
+		gitError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+
+	var payload struct {
+		Event string `json:"event"`
+		Body  string `json:"body"`
+	}
+	if err := decodeJSON(r, &payload); err != nil {
+		gitError(w, http.StatusBadRequest, "Problems parsing JSON")
+		return
+	}
+
+	b.github.nextID++
+	state := map[string]string{"APPROVE": "APPROVED", "REQUEST_CHANGES": "CHANGES_REQUESTED", "COMMENT": "COMMENTED"}[payload.Event]
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"id":    b.github.nextID,
+		"state": state,
+		"body":  payload.Body,
+		"user":  githubUser(r.PathValue("owner")),
+	})
+}
+
+func (b *Backend) githubListIssues(w http.ResponseWriter, r *http.Request) {
+	name := repoName(r)
+	if b.github.repo(name) == nil {
+		gitError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+
+	state := r.URL.Query().Get("state")
+	if state == "" {
+		state = "open"
+	}
+
+	issues := []map[string]interface{}{}
+	for _, issue := range b.github.issues {
+		if strings.EqualFold(issue.Repo, name) && stateMatches(state, issue.State) {
+			issues = append(issues, githubIssueJSON(issue))
+		}
+	}
+	// the issues endpoint also returns pull requests
+	for _, pull := range b.github.pulls {
+		if strings.EqualFold(pull.Repo, name) && stateMatches(state, pull.State) {
+			item := githubPullJSON(pull)
+			item["pull_request"] = map[string]interface{}{"html_url": item["html_url"]}
+			issues = append(issues, item)
+		}
+	}
+
+	writeJSON(w, http.StatusOK, issues)
+}
+
+func (b *Backend) githubGetIssue(w http.ResponseWriter, r *http.Request) {
+	number, _ := pathInt(r, "number")
+	issue := b.github.issue(repoName(r), number)
+	if issue == nil {
+		gitError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+	writeJSON(w, http.StatusOK, githubIssueJSON(issue))
+}
+
+func (b *Backend) githubEditIssue(w http.ResponseWriter, r *http.Request) {
+	number, _ := pathInt(r, "number")
+	issue := b.github.issue(repoName(r), number)
+	if issue == nil {
+		gitError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+
+	var payload struct {
+		Title  *string   `json:"title"`
+		Body   *string   `json:"body"`
+		State  *string   `json:"state"`
+		Labels *[]string `json:"labels"`
+	}
+	if err := decodeJSON(r, &payload); err != nil {
+		gitError(w, http.StatusBadRequest, "Problems parsing JSON")
+		return
+	}
+
+	if payload.Title != nil {
+		issue.Title = *payload.Title
+	}
+	if payload.Body != nil {
+		issue.Body = *payload.Body
+	}
+	if payload.Labels != nil {
+		issue.Labels = *payload.Labels
+	}
+	if payload.State != nil {
+		issue.State = *payload.State
+		if issue.State == "closed" {
+			issue.Closed = b.now().UTC().Format("2006-01-02T15:04:05Z")
+		} else {
+			issue.Closed = ""
+		}
+	}
+
+	writeJSON(w, http.StatusOK, githubIssueJSON(issue))
+}
+
+func (b *Backend) githubListComments(w http.ResponseWriter, r *http.Request) {
+	name := repoName(r)
+	number, _ := pathInt(r, "number")
+	if b.github.issue(name, number) == nil && b.github.pull(name, number) == nil {
+		gitError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+
+	comments := []map[string]interface{}{}
+	for _, comment := range b.github.comments {
+		if strings.EqualFold(comment.Repo, name) && comment.Number == number {
+			comments = append(comments, map[string]interface{}{
+				"body":       comment.Body,
+				"user":       githubUser(comment.User),
+				"created_at": optionalTime(comment.Created),
+			})
+		}
+	}
+
+	writeJSON(w, http.StatusOK, comments)
+}
+
+func (b *Backend) githubCreateComment(w http.ResponseWriter, r *http.Request) {
+	name := repoName(r)
+	number, _ := pathInt(r, "number")
+	if b.github.issue(name, number) == nil && b.github.pull(name, number) == nil {
+		gitError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+
+	var payload struct {
+		Body string `json:"body"`
+	}
+	if err := decodeJSON(r, &payload); err != nil {
+		gitError(w, http.StatusBadRequest, "Problems parsing JSON")
+		return
+	}
+
+	comment := &GitHubCommentFixture{
+		Repo:    name,
+		Number:  number,
+		User:    r.PathValue("owner"),
+		Body:    payload.Body,
+		Created: b.now().UTC().Format("2006-01-02T15:04:05Z"),
+	}
+	b.github.comments = append(b.github.comments, comment)
+
+	b.github.nextID++
+	writeJSON(w, http.StatusCreated, map[string]interface{}{
+		"id":         b.github.nextID,
+		"body":       comment.Body,
+		"user":       githubUser(comment.User),
+		"created_at": comment.Created,
+	})
+}
@@ -0,0 +1,491 @@
+package fake
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type gitlabState struct {
+	groups        []GitLabGroupFixture
+	projects      []*GitLabProjectFixture
+	mergeRequests []*GitLabMergeRequestFixture
+	notes         []*GitLabNoteFixture
+	pipelines     []*GitLabPipelineFixture
+	commits       []*GitLabCommitFixture
+	events        []*GitLabEventFixture
+}
+
+func newGitLabState(fixture *GitLabFixture) *gitlabState {
+	s := &gitlabState{groups: fixture.Groups}
+	for i := range fixture.Projects {
+		s.projects = append(s.projects, &fixture.Projects[i])
+	}
+	for i := range fixture.MergeRequests {
+		s.mergeRequests = append(s.mergeRequests, &fixture.MergeRequests[i])
+	}
+	for i := range fixture.Notes {
+		s.notes = append(s.notes, &fixture.Notes[i])
+	}
+	for i := range fixture.Pipelines {
+		s.pipelines = append(s.pipelines, &fixture.Pipelines[i])
+	}
+	for i := range fixture.Commits {
+		s.commits = append(s.commits, &fixture.Commits[i])
+	}
+	for i := range fixture.Events {
+		s.events = append(s.events, &fixture.Events[i])
+	}
+	return s
+}
+
+// project resolves a project by numeric ID or by its path with namespace
+func (s *gitlabState) project(id string) *GitLabProjectFixture {
+	for _, project := range s.projects {
+		if strconv.Itoa(project.ID) == id || strings.EqualFold(project.Path, id) {
+			return project
+		}
+	}
+	return nil
+}
+
+func (s *gitlabState) group(id string) *GitLabGroupFixture {
+	for i := range s.groups {
+		if strings.EqualFold(s.groups[i].ID, id) {
+			return &s.groups[i]
+		}
+	}
+	return nil
+}
+
+func (s *gitlabState) mergeRequest(project string, iid int) *GitLabMergeRequestFixture {
+	for _, mr := range s.mergeRequests {
+		if strings.EqualFold(mr.Project, project) && mr.IID == iid {
+			return mr
+		}
+	}
+	return nil
+}
+
+func (s *gitlabState) commit(project, sha string) *GitLabCommitFixture {
+	for _, commit := range s.commits {
+		if strings.EqualFold(commit.Project, project) && sha != "" && strings.HasPrefix(commit.SHA, sha) {
+			return commit
+		}
+	}
+	return nil
+}
+
+func gitlabUser(username string) map[string]interface{} {
+	return map[string]interface{}{"username": username, "name": username, "state": "active"}
+}
+
+// gitlabTime normalises fixture timestamps, falling back to now so that the
+// client never sees a missing time it dereferences
+func (b *Backend) gitlabTime(value string) string {
+	if value == "" {
+		return b.now().UTC().Format(time.RFC3339)
+	}
+	return value
+}
+
+func gitlabDiffsJSON(diffs []GitLabDiffFixture) []map[string]interface{} {
+	result := []map[string]interface{}{}
+	for _, diff := range diffs {
+		result = append(result, map[string]interface{}{
+			"old_path":     diff.OldPath,
+			"new_path":     diff.NewPath,
+			"diff":         diff.Diff,
+			"new_file":     diff.NewFile,
+			"renamed_file": diff.RenamedFile,
+			"deleted_file": diff.DeletedFile,
+		})
+	}
+	return result
+}
+
+func (b *Backend) gitlabProjectJSON(project *GitLabProjectFixture) map[string]interface{} {
+	return map[string]interface{}{
+		"id":                  project.ID,
+		"name":                project.Name,
+		"path":                project.Path[strings.LastIndex(project.Path, "/")+1:],
+		"path_with_namespace": project.Path,
+		"description":         project.Description,
+		"default_branch":      project.DefaultBranch,
+		"web_url":             "https://gitlab.com/" + project.Path,
+		"last_activity_at":    b.gitlabTime(project.LastActivity),
+	}
+}
+
+func (b *Backend) gitlabMergeRequestJSON(mr *GitLabMergeRequestFixture) map[string]interface{} {
+	return map[string]interface{}{
+		"iid":           mr.IID,
+		"title":         mr.Title,
+		"description":   mr.Description,
+		"state":         mr.State,
+		"author":        gitlabUser(mr.Author),
+		"source_branch": mr.SourceBranch,
+		"target_branch": mr.TargetBranch,
+		"web_url":       fmt.Sprintf("https://gitlab.com/%s/-/merge_requests/%d", mr.Project, mr.IID),
+		"created_at":    b.gitlabTime(mr.Created),
+		"diff_refs":     map[string]interface{}{},
+	}
+}
+
+func (b *Backend) gitlabNoteJSON(id int, note *GitLabNoteFixture) map[string]interface{} {
+	return map[string]interface{}{
+		"id":         id,
+		"body":       note.Body,
+		"author":     gitlabUser(note.Author),
+		"created_at": b.gitlabTime(note.Created),
+		"updated_at": b.gitlabTime(note.Created),
+	}
+}
+
+func (b *Backend) gitlabCommitJSON(commit *GitLabCommitFixture) map[string]interface{} {
+	shortID := commit.SHA
+	if len(shortID) > 8 {
+		shortID = shortID[:8]
+	}
+	return map[string]interface{}{
+		"id":             commit.SHA,
+		"short_id":       shortID,
+		"title":          commit.Title,
+		"message":        commit.Title,
+		"author_name":    commit.Author,
+		"committed_date": b.gitlabTime(commit.Date),
+		"authored_date":  b.gitlabTime(commit.Date),
+		"parent_ids":     commit.Parents,
+		"web_url":        fmt.Sprintf("https://gitlab.com/%s/-/commit/%s", commit.Project, commit.SHA),
+	}
+}
+
+// withProject looks up the {project} path value and reports 404 when it is unknown
+func (b *Backend) withProject(handler func(http.ResponseWriter, *http.Request, *GitLabProjectFixture)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		project := b.gitlab.project(r.PathValue("project"))
+		if project == nil {
+			gitError(w, http.StatusNotFound, "404 Project Not Found")
+			return
+		}
+		handler(w, r, project)
+	}
+}
+
+func (b *Backend) registerGitLabRoutes() {
+	b.handle("GET /api/v4/groups/{group}/projects", b.gitlabListGroupProjects)
+	b.handle("GET /api/v4/groups/{group}/members", b.gitlabListGroupMembers)
+	b.handle("GET /api/v4/users/{user}/events", b.gitlabListUserEvents)
+	b.handle("GET /api/v4/projects/{project}", b.withProject(b.gitlabGetProject))
+	b.handle("GET /api/v4/projects/{project}/repository/branches", b.withProject(b.gitlabListBranches))
+	b.handle("GET /api/v4/projects/{project}/repository/tags", b.withProject(b.gitlabListTags))
+	b.handle("GET /api/v4/projects/{project}/repository/files/{file}/raw", b.withProject(b.gitlabGetRawFile))
+	b.handle("GET /api/v4/projects/{project}/repository/commits", b.withProject(b.gitlabListCommits))
+	b.handle("GET /api/v4/projects/{project}/repository/commits/{sha}", b.withProject(b.gitlabGetCommit))
+	b.handle("GET /api/v4/projects/{project}/repository/commits/{sha}/diff", b.withProject(b.gitlabGetCommitDiff))
+	b.handle("GET /api/v4/projects/{project}/pipelines", b.withProject(b.gitlabListPipelines))
+	b.handle("GET /api/v4/projects/{project}/merge_requests", b.withProject(b.gitlabListMergeRequests))
+	b.handle("POST /api/v4/projects/{project}/merge_requests", b.withProject(b.gitlabCreateMergeRequest))
+	b.handle("GET /api/v4/projects/{project}/merge_requests/{iid}", b.withProject(b.gitlabGetMergeRequest))
+	b.handle("GET /api/v4/projects/{project}/merge_requests/{iid}/diffs", b.withProject(b.gitlabListMergeRequestDiffs))
+	b.handle("GET /api/v4/projects/{project}/merge_requests/{iid}/notes", b.withProject(b.gitlabListNotes))
+	b.handle("POST /api/v4/projects/{project}/merge_requests/{iid}/notes", b.withProject(b.gitlabCreateNote))
+}
+
+func (b *Backend) gitlabListGroupProjects(w http.ResponseWriter, r *http.Request) {
+	group := r.PathValue("group")
+	search := strings.ToLower(r.URL.Query().Get("search"))
+
+	projects := []map[string]interface{}{}
+	for _, project := range b.gitlab.projects {
+		if !strings.EqualFold(project.Group, group) {
+			continue
+		}
+		if search != "" && !strings.Contains(strings.ToLower(project.Name+" "+project.Path), search) {
+			continue
+		}
+		projects = append(projects, b.gitlabProjectJSON(project))
+	}
+
+	writeJSON(w, http.StatusOK, projects)
+}
+
+func (b *Backend) gitlabListGroupMembers(w http.ResponseWriter, r *http.Request) {
+	group := b.gitlab.group(r.PathValue("group"))
+	if group == nil {
+		gitError(w, http.StatusNotFound, "404 Group Not Found")
+		return
+	}
+
+	members := []map[string]interface{}{}
+	for _, member := range group.Members {
+		members = append(members, map[string]interface{}{
+			"id":           member.ID,
+			"username":     member.Username,
+			"name":         member.Name,
+			"state":        "active",
+			"access_level": member.AccessLevel,
+		})
+	}
+
+	writeJSON(w, http.StatusOK, members)
+}
+
+func (b *Backend) gitlabListUserEvents(w http.ResponseWriter, r *http.Request) {
+	username := r.PathValue("user")
+	after := r.URL.Query().Get("after")
+	before := r.URL.Query().Get("before")
+
+	events := []map[string]interface{}{}
+	for _, event := range b.gitlab.events {
+		if !strings.EqualFold(event.Username, username) {
+			continue
+		}
+		created := b.gitlabTime(event.Created)
+		// dates compare lexically because both sides are ISO 8601
+		if (after != "" && created[:10] <= after) || (before != "" && created[:10] >= before) {
+			continue
+		}
+		events = append(events, map[string]interface{}{
+			"project_id":  event.ProjectID,
+			"action_name": event.Action,
+			"target_type": event.TargetType,
+			"target_iid":  event.TargetIID,
+			"created_at":  created,
+			"author":      gitlabUser(event.Username),
+		})
+	}
+
+	writeJSON(w, http.StatusOK, events)
+}
+
+func (b *Backend) gitlabGetProject(w http.ResponseWriter, r *http.Request, project *GitLabProjectFixture) {
+	writeJSON(w, http.StatusOK, b.gitlabProjectJSON(project))
+}
+
+func (b *Backend) gitlabListBranches(w http.ResponseWriter, r *http.Request, project *GitLabProjectFixture) {
+	branches := []map[string]interface{}{}
+	for _, name := range project.Branches {
+		branches = append(branches, map[string]interface{}{"name": name, "default": name == project.DefaultBranch})
+	}
+	writeJSON(w, http.StatusOK, branches)
+}
+
+func (b *Backend) gitlabListTags(w http.ResponseWriter, r *http.Request, project *GitLabProjectFixture) {
+	tags := []map[string]interface{}{}
+	for _, name := range project.Tags {
+		tags = append(tags, map[string]interface{}{"name": name})
+	}
+	writeJSON(w, http.StatusOK, tags)
+}
+
+func (b *Backend) gitlabGetRawFile(w http.ResponseWriter, r *http.Request, project *GitLabProjectFixture) {
+	content, ok := project.Files[r.PathValue("file")]
+	if !ok {
+		gitError(w, http.StatusNotFound, "404 File Not Found")
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(content))
+}
+
+func (b *Backend) gitlabListCommits(w http.ResponseWriter, r *http.Request, project *GitLabProjectFixture) {
+	ref := r.URL.Query().Get("ref_name")
+	since := r.URL.Query().Get("since")
+	until := r.URL.Query().Get("until")
+
+	commits := []map[string]interface{}{}
+	for _, commit := range b.gitlab.commits {
+		if !strings.EqualFold(commit.Project, project.Path) {
+			continue
+		}
+		if ref != "" && commit.Ref != "" && commit.Ref != ref {
+			continue
+		}
+		date, err := time.Parse(time.RFC3339, b.gitlabTime(commit.Date))
+		if err == nil && !inRange(date, since, until) {
+			continue
+		}
+		commits = append(commits, b.gitlabCommitJSON(commit))
+	}
+
+	writeJSON(w, http.StatusOK, commits)
+}
+
+func inRange(t time.Time, since, until string) bool {
+	if start, err := time.Parse(time.RFC3339, since); err == nil && t.Before(start) {
+		return false
+	}
+	if end, err := time.Parse(time.RFC3339, until); err == nil && t.After(end) {
+		return false
+	}
+	return true
+}
+
+func (b *Backend) gitlabGetCommit(w http.ResponseWriter, r *http.Request, project *GitLabProjectFixture) {
+	commit := b.gitlab.commit(project.Path, r.PathValue("sha"))
+	if commit == nil {
+		gitError(w, http.StatusNotFound, "404 Commit Not Found")
+		return
+	}
+	writeJSON(w, http.StatusOK, b.gitlabCommitJSON(commit))
+}
+
+func (b *Backend) gitlabGetCommitDiff(w http.ResponseWriter, r *http.Request, project *GitLabProjectFixture) {
+	commit := b.gitlab.commit(project.Path, r.PathValue("sha"))
+	if commit == nil {
+		gitError(w, http.StatusNotFound, "404 Commit Not Found")
+		return
+	}
+	writeJSON(w, http.StatusOK, gitlabDiffsJSON(commit.Diffs))
+}
+
+func (b *Backend) gitlabListPipelines(w http.ResponseWriter, r *http.Request, project *GitLabProjectFixture) {
+	status := r.URL.Query().Get("status")
+
+	pipelines := []map[string]interface{}{}
+	for _, pipeline := range b.gitlab.pipelines {
+		if !strings.EqualFold(pipeline.Project, project.Path) {
+			continue
+		}
+		if status != "" && pipeline.Status != status {
+			continue
+		}
+		pipelines = append(pipelines, map[string]interface{}{
+			"id":         pipeline.ID,
+			"project_id": project.ID,
+			"status":     pipeline.Status,
+			"ref":        pipeline.Ref,
+			"sha":        pipeline.SHA,
+			"created_at": b.gitlabTime(pipeline.Created),
+			"web_url":    fmt.Sprintf("https://gitlab.com/%s/-/pipelines/%d", project.Path, pipeline.ID),
+		})
+	}
+
+	writeJSON(w, http.StatusOK, pipelines)
+}
+
+func (b *Backend) gitlabListMergeRequests(w http.ResponseWriter, r *http.Request, project *GitLabProjectFixture) {
+	state := r.URL.Query().Get("state")
+
+	mrs := []map[string]interface{}{}
+	for _, mr := range b.gitlab.mergeRequests {
+		if strings.EqualFold(mr.Project, project.Path) && stateMatches(state, mr.State) {
+			mrs = append(mrs, b.gitlabMergeRequestJSON(mr))
+		}
+	}
+
+	writeJSON(w, http.StatusOK, mrs)
+}
+
+func (b *Backend) gitlabGetMergeRequest(w http.ResponseWriter, r *http.Request, project *GitLabProjectFixture) {
+	iid, _ := pathInt(r, "iid")
+	mr := b.gitlab.mergeRequest(project.Path, iid)
+	if mr == nil {
+		gitError(w, http.StatusNotFound, "404 Not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, b.gitlabMergeRequestJSON(mr))
+}
+
+func (b *Backend) gitlabListMergeRequestDiffs(w http.ResponseWriter, r *http.Request, project *GitLabProjectFixture) {
+	iid, _ := pathInt(r, "iid")
+	mr := b.gitlab.mergeRequest(project.Path, iid)
+	if mr == nil {
+		gitError(w, http.StatusNotFound, "404 Not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, gitlabDiffsJSON(mr.Diffs))
+}
+
+func (b *Backend) gitlabCreateMergeRequest(w http.ResponseWriter, r *http.Request, project *GitLabProjectFixture) {
+	var payload struct {
+		Title        string `json:"title"`
+		Description  string `json:"description"`
+		SourceBranch string `json:"source_branch"`
+		TargetBranch string `json:"target_branch"`
+	}
+	if err := decodeJSON(r, &payload); err != nil {
+		gitError(w, http.StatusBadRequest, "400 Bad request - "+err.Error())
+		return
+	}
+	if payload.Title == "" || payload.SourceBranch == "" || payload.TargetBranch == "" {
+		gitError(w, http.StatusBadRequest, "400 Bad request - title, source_branch and target_branch are required")
+		return
+	}
+
+	iid := 0
+	for _, mr := range b.gitlab.mergeRequests {
+		if strings.EqualFold(mr.Project, project.Path) && mr.IID > iid {
+			iid = mr.IID
+		}
+	}
+
+	mr := &GitLabMergeRequestFixture{
+		Project:      project.Path,
+		IID:          iid + 1,
+		Title:        payload.Title,
+		Description:  payload.Description,
+		State:        "opened",
+		Author:       "fake-user",
+		SourceBranch: payload.SourceBranch,
+		TargetBranch: payload.TargetBranch,
+		Created:      b.gitlabTime(""),
+	}
+	b.gitlab.mergeRequests = append(b.gitlab.mergeRequests, mr)
+
+	writeJSON(w, http.StatusCreated, b.gitlabMergeRequestJSON(mr))
+}
+
+func (b *Backend) gitlabListNotes(w http.ResponseWriter, r *http.Request, project *GitLabProjectFixture) {
+	iid, _ := pathInt(r, "iid")
+	if b.gitlab.mergeRequest(project.Path, iid) == nil {
+		gitError(w, http.StatusNotFound, "404 Not found")
+		return
+	}
+
+	notes := []map[string]interface{}{}
+	for i, note := range b.gitlab.notes {
+		if strings.EqualFold(note.Project, project.Path) && note.MRIID == iid {
+			notes = append(notes, b.gitlabNoteJSON(i+1, note))
+		}
+	}
+	// the tools ask for newest first
+	if r.URL.Query().Get("sort") == "desc" {
+		for i, j := 0, len(notes)-1; i < j; i, j = i+1, j-1 {
+			notes[i], notes[j] = notes[j], notes[i]
+		}
+	}
+
+	writeJSON(w, http.StatusOK, notes)
+}
+
+func (b *Backend) gitlabCreateNote(w http.ResponseWriter, r *http.Request, project *GitLabProjectFixture) {
+	iid, _ := pathInt(r, "iid")
+	if b.gitlab.mergeRequest(project.Path, iid) == nil {
+		gitError(w, http.StatusNotFound, "404 Not found")
+		return
+	}
+
+	var payload struct {
+		Body string `json:"body"`
+	}
+	if err := decodeJSON(r, &payload); err != nil || payload.Body == "" {
+		gitError(w, http.StatusBadRequest, "400 Bad request - body is missing")
+		return
+	}
+
+	note := &GitLabNoteFixture{
+		Project: project.Path,
+		MRIID:   iid,
+		Author:  "fake-user",
+		Body:    payload.Body,
+		Created: b.gitlabTime(""),
+	}
+	b.gitlab.notes = append(b.gitlab.notes, note)
+
+	writeJSON(w, http.StatusCreated, b.gitlabNoteJSON(len(b.gitlab.notes), note))
+}
@@ -0,0 +1,3854 @@
+package fake
+
+import (
+	"encoding/base64"
+	"fmt"
+	"hash/fnv"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const jiraTimeFormat = "2006-01-02T15:04:05.000-0700"
+
+type jiraIssue struct {
+	ID        string
+	Key       string
+	Fields    map[string]interface{}
+	Comments  []map[string]interface{}
+	Changelog []map[string]interface{}
+	Worklogs  []map[string]interface{}
+	Watchers  []string
+}
+
+type jiraAttachment struct {
+	ID       string
+	IssueKey string
+	Filename string
+	MimeType string
+	Author   string
+	Created  string
+	Content  []byte
+}
+
+// jiraLink links two issues so that From <outward> To
+type jiraLink struct {
+	ID   string
+	Type *JiraLinkTypeFixture
+	From string
+	To   string
+}
+
+type jiraState struct {
+	currentUser string
+	users       []JiraUserFixture
+	projects    []JiraProjectFixture
+	statuses    []JiraStatusFixture
+	transitions []JiraTransitionFixture
+	boards      []JiraBoardFixture
+	sprints     []*JiraSprintFixture
+	fields      []JiraFieldFixture
+	priorities  []JiraPriorityFixture
+	linkTypes   []JiraLinkTypeFixture
+	versions    []*JiraVersionFixture
+	components  []*JiraComponentFixture
+	filters     []*JiraFilterFixture
+	dashboards  []JiraDashboardFixture
+	desks       []JiraServiceDeskFixture
+
+	issues   []*jiraIssue
+	byKey    map[string]*jiraIssue
+	sprintOf map[string]int
+	// requestTypeOf maps the keys of service desk requests to their request type ID
+	requestTypeOf map[string]string
+	links         []*jiraLink
+	files         []*jiraAttachment
+	nextID        int
+	nextKey       map[string]int
+	nextOther     int
+}
+
+func newJiraState(fixture *JiraFixture) *jiraState {
+	s := &jiraState{
+		currentUser:   fixture.CurrentUser,
+		users:         fixture.Users,
+		projects:      fixture.Projects,
+		statuses:      fixture.Statuses,
+		transitions:   fixture.Transitions,
+		boards:        fixture.Boards,
+		fields:        fixture.Fields,
+		priorities:    fixture.Priorities,
+		linkTypes:     fixture.LinkTypes,
+		dashboards:    fixture.Dashboards,
+		desks:         fixture.ServiceDesks,
+		byKey:         make(map[string]*jiraIssue),
+		sprintOf:      make(map[string]int),
+		requestTypeOf: make(map[string]string),
+		nextID:        10000,
+		nextKey:       make(map[string]int),
+		nextOther:     100,
+	}
+
+	if len(s.priorities) == 0 {
+		s.priorities = jiraDefaultPriorities
+	}
+
+	for i := range fixture.Versions {
+		version := fixture.Versions[i]
+		s.versions = append(s.versions, &version)
+	}
+
+	for i := range fixture.Components {
+		component := fixture.Components[i]
+		s.components = append(s.components, &component)
+	}
+
+	for i := range fixture.Filters {
+		filter := fixture.Filters[i]
+		s.filters = append(s.filters, &filter)
+	}
+
+	for i := range fixture.Sprints {
+		sprint := fixture.Sprints[i]
+		s.sprints = append(s.sprints, &sprint)
+		for _, key := range sprint.Issues {
+			s.sprintOf[key] = sprint.ID
+		}
+	}
+
+	for _, seed := range fixture.Issues {
+		s.addIssue(seed)
+	}
+
+	for _, link := range fixture.Links {
+		if linkType := s.linkType(link.Type); linkType != nil {
+			s.link(linkType, link.From, link.To)
+		}
+	}
+
+	return s
+}
+
+func (s *jiraState) addIssue(seed JiraIssueFixture) *jiraIssue {
+	s.nextID++
+	projectKey, number, _ := strings.Cut(seed.Key, "-")
+	if n, err := strconv.Atoi(number); err == nil && n > s.nextKey[projectKey] {
+		s.nextKey[projectKey] = n
+	}
+
+	issue := &jiraIssue{
+		ID:  strconv.Itoa(s.nextID),
+		Key: seed.Key,
+		Fields: map[string]interface{}{
+			"summary":     seed.Summary,
+			"description": seed.Description,
+			"created":     seed.Created,
+			"updated":     seed.Updated,
+			"labels":      append([]string{}, seed.Labels...),
+		},
+	}
+
+	if project := s.project(projectKey); project != nil {
+		issue.Fields["project"] = s.projectRef(project)
+	}
+	issue.Fields["issuetype"] = s.issueTypeRef(seed.Type)
+
+	status := seed.Status
+	if status == "" && len(s.statuses) > 0 {
+		status = s.statuses[0].Name
+	}
+	if st := s.status(status); st != nil {
+		issue.Fields["status"] = s.statusRef(st)
+	}
+	if seed.Priority != "" {
+		issue.Fields["priority"] = s.priorityRef(seed.Priority)
+	}
+	if user := s.user(seed.Assignee); user != nil {
+		issue.Fields["assignee"] = userRef(user)
+	}
+	if user := s.user(seed.Reporter); user != nil {
+		issue.Fields["reporter"] = userRef(user)
+	}
+	if seed.Parent != "" {
+		issue.Fields["parent"] = map[string]interface{}{"key": seed.Parent}
+	}
+	var fixVersions []interface{}
+	for _, name := range seed.FixVersions {
+		if version := s.version(projectKey, name); version != nil {
+			fixVersions = append(fixVersions, versionRef(version))
+		}
+	}
+	issue.Fields["fixVersions"] = fixVersions
+	components := []interface{}{}
+	for _, name := range seed.Components {
+		if component := s.component(projectKey, name); component != nil {
+			components = append(components, componentRef(component))
+		}
+	}
+	issue.Fields["components"] = components
+
+	for id, value := range seed.Fields {
+		issue.Fields[id] = value
+	}
+
+	for _, watcher := range append([]string{seed.Reporter, seed.Assignee}, seed.Watchers...) {
+		if user := s.user(watcher); user != nil {
+			s.watch(issue, user)
+		}
+	}
+
+	for _, seed := range seed.Comments {
+		comment := s.addComment(issue, seed.Author, seed.Body, seed.Created)
+		if s.serviceDeskOf(projectKey) != nil {
+			comment["jsdPublic"] = !seed.Internal
+		}
+	}
+	if seed.RequestType != "" {
+		s.requestTypeOf[issue.Key] = seed.RequestType
+	}
+
+	for _, change := range seed.History {
+		item := map[string]interface{}{"field": change.Field, "fieldtype": "jira", "fromString": change.From, "toString": change.To}
+		if change.Field == "Sprint" {
+			item["fieldtype"] = "custom"
+			item["from"] = s.sprintIDs(change.From)
+			item["to"] = s.sprintIDs(change.To)
+		} else if field := s.customField(change.Field); field != nil {
+			item["field"] = field.Name
+			item["fieldtype"] = "custom"
+			item["fieldId"] = field.ID
+		}
+		s.recordItem(issue, change.Created, change.Author, item)
+	}
+
+	for _, worklog := range seed.Worklogs {
+		if seconds, ok := jiraWorkSeconds(worklog.TimeSpent); ok {
+			s.addWorklog(issue, worklog.Author, worklog.Started, worklog.TimeSpent, seconds, worklog.Comment, worklog.Started)
+		}
+	}
+
+	for _, attachment := range seed.Attachments {
+		content := []byte(attachment.Content)
+		if attachment.Encoding == "base64" {
+			decoded, err := base64.StdEncoding.DecodeString(attachment.Content)
+			if err != nil {
+				continue
+			}
+			content = decoded
+		}
+		s.addAttachment(issue, attachment.Author, attachment.Filename, attachment.MimeType, content, attachment.Created)
+	}
+
+	s.issues = append(s.issues, issue)
+	s.byKey[issue.Key] = issue
+	return issue
+}
+
+func (s *jiraState) addComment(issue *jiraIssue, author, body, created string) map[string]interface{} {
+	s.nextOther++
+	comment := map[string]interface{}{
+		"id":      strconv.Itoa(s.nextOther),
+		"body":    body,
+		"created": created,
+		"updated": created,
+	}
+	if user := s.user(author); user != nil {
+		comment["author"] = userRef(user)
+		comment["updateAuthor"] = userRef(user)
+	}
+	issue.Comments = append(issue.Comments, comment)
+	return comment
+}
+
+func (s *jiraState) linkType(nameOrID string) *JiraLinkTypeFixture {
+	for i := range s.linkTypes {
+		if strings.EqualFold(s.linkTypes[i].Name, nameOrID) || s.linkTypes[i].ID == nameOrID {
+			return &s.linkTypes[i]
+		}
+	}
+	return nil
+}
+
+func linkTypeJSON(linkType *JiraLinkTypeFixture) map[string]interface{} {
+	return map[string]interface{}{
+		"id":      linkType.ID,
+		"name":    linkType.Name,
+		"inward":  linkType.Inward,
+		"outward": linkType.Outward,
+	}
+}
+
+func (s *jiraState) link(linkType *JiraLinkTypeFixture, from, to string) *jiraLink {
+	s.nextOther++
+	link := &jiraLink{ID: strconv.Itoa(s.nextOther), Type: linkType, From: from, To: to}
+	s.links = append(s.links, link)
+	return link
+}
+
+// linkedIssue is the short form of an issue that links, subtasks and parents refer to
+func linkedIssue(issue *jiraIssue) map[string]interface{} {
+	return map[string]interface{}{
+		"id":  issue.ID,
+		"key": issue.Key,
+		"fields": map[string]interface{}{
+			"summary":   issue.Fields["summary"],
+			"status":    issue.Fields["status"],
+			"issuetype": issue.Fields["issuetype"],
+			"priority":  issue.Fields["priority"],
+		},
+	}
+}
+
+// issueLinks renders the links of an issue as seen from that issue
+func (s *jiraState) issueLinks(issue *jiraIssue) []map[string]interface{} {
+	links := []map[string]interface{}{}
+	for _, link := range s.links {
+		entry := map[string]interface{}{"id": link.ID, "type": linkTypeJSON(link.Type)}
+		switch issue.Key {
+		case link.From:
+			if other := s.issue(link.To); other != nil {
+				entry["outwardIssue"] = linkedIssue(other)
+				links = append(links, entry)
+			}
+		case link.To:
+			if other := s.issue(link.From); other != nil {
+				entry["inwardIssue"] = linkedIssue(other)
+				links = append(links, entry)
+			}
+		}
+	}
+	return links
+}
+
+func (s *jiraState) addWorklog(issue *jiraIssue, author, started, timeSpent string, seconds int, comment, created string) map[string]interface{} {
+	s.nextOther++
+	worklog := map[string]interface{}{
+		"id":               strconv.Itoa(s.nextOther),
+		"issueId":          issue.ID,
+		"started":          started,
+		"timeSpent":        timeSpent,
+		"timeSpentSeconds": seconds,
+		"comment":          comment,
+		"created":          created,
+		"updated":          created,
+	}
+	if user := s.user(author); user != nil {
+		worklog["author"] = userRef(user)
+		worklog["updateAuthor"] = userRef(user)
+	}
+	issue.Worklogs = append(issue.Worklogs, worklog)
+	return worklog
+}
+
+func (s *jiraState) addAttachment(issue *jiraIssue, author, filename, mimeType string, content []byte, created string) *jiraAttachment {
+	s.nextOther++
+	attachment := &jiraAttachment{
+		ID:       strconv.Itoa(s.nextOther),
+		IssueKey: issue.Key,
+		Filename: filename,
+		MimeType: mimeType,
+		Author:   author,
+		Created:  created,
+		Content:  content,
+	}
+	s.files = append(s.files, attachment)
+	return attachment
+}
+
+func (s *jiraState) attachment(id string) *jiraAttachment {
+	for _, attachment := range s.files {
+		if attachment.ID == id {
+			return attachment
+		}
+	}
+	return nil
+}
+
+func (s *jiraState) attachmentJSON(attachment *jiraAttachment, r *http.Request) map[string]interface{} {
+	result := map[string]interface{}{
+		"id":       attachment.ID,
+		"self":     fmt.Sprintf("https://%s/rest/api/2/attachment/%s", r.Host, attachment.ID),
+		"filename": attachment.Filename,
+		"mimeType": attachment.MimeType,
+		"size":     len(attachment.Content),
+		"created":  attachment.Created,
+		"content":  fmt.Sprintf("https://%s/rest/api/2/attachment/content/%s", r.Host, attachment.ID),
+	}
+	if user := s.user(attachment.Author); user != nil {
+		result["author"] = userRef(user)
+	}
+	return result
+}
+
+// jiraWorkSeconds parses a duration such as 1w 2d 3h 30m, counting 8 hour days and 5 day weeks as Jira does by default
+func jiraWorkSeconds(text string) (int, bool) {
+	units := map[byte]float64{'w': 5 * 8 * 3600, 'd': 8 * 3600, 'h': 3600, 'm': 60}
+	seconds := 0.0
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return 0, false
+	}
+	for _, field := range fields {
+		unit, ok := units[field[len(field)-1]]
+		if !ok {
+			return 0, false
+		}
+		amount, err := strconv.ParseFloat(field[:len(field)-1], 64)
+		if err != nil || amount < 0 {
+			return 0, false
+		}
+		seconds += amount * unit
+	}
+	return int(seconds), seconds > 0
+}
+
+func (s *jiraState) project(keyOrID string) *JiraProjectFixture {
+	for i := range s.projects {
+		if strings.EqualFold(s.projects[i].Key, keyOrID) || s.projects[i].ID == keyOrID {
+			return &s.projects[i]
+		}
+	}
+	return nil
+}
+
+func (s *jiraState) projectRef(project *JiraProjectFixture) map[string]interface{} {
+	return map[string]interface{}{"id": project.ID, "key": project.Key, "name": project.Name}
+}
+
+func (s *jiraState) issueTypeRef(name string) map[string]interface{} {
+	// IDs must be distinct per name, as create metadata is looked up by issue type ID
+	id := fnv.New32a()
+	id.Write([]byte(strings.ToLower(name)))
+	return map[string]interface{}{
+		"id":      strconv.Itoa(int(id.Sum32()%90000) + 10000),
+		"name":    name,
+		"subtask": strings.EqualFold(name, "Sub-task") || strings.EqualFold(name, "Subtask"),
+	}
+}
+
+func (s *jiraState) status(nameOrID string) *JiraStatusFixture {
+	for i := range s.statuses {
+		if strings.EqualFold(s.statuses[i].Name, nameOrID) || s.statuses[i].ID == nameOrID {
+			return &s.statuses[i]
+		}
+	}
+	return nil
+}
+
+func (s *jiraState) statusRef(status *JiraStatusFixture) map[string]interface{} {
+	categoryNames := map[string]string{"new": "To Do", "indeterminate": "In Progress", "done": "Done"}
+	categoryIDs := map[string]int{"new": 2, "indeterminate": 4, "done": 3}
+	return map[string]interface{}{
+		"id":   status.ID,
+		"name": status.Name,
+		"statusCategory": map[string]interface{}{
+			"id":   categoryIDs[status.Category],
+			"key":  status.Category,
+			"name": categoryNames[status.Category],
+		},
+	}
+}
+
+func (s *jiraState) version(projectKey, nameOrID string) *JiraVersionFixture {
+	for _, version := range s.versions {
+		if version.ID == nameOrID || (strings.EqualFold(version.Project, projectKey) && version.Name == nameOrID) {
+			return version
+		}
+	}
+	return nil
+}
+
+func (s *jiraState) component(projectKey, nameOrID string) *JiraComponentFixture {
+	for _, component := range s.components {
+		if component.ID == nameOrID || (strings.EqualFold(component.Project, projectKey) && strings.EqualFold(component.Name, nameOrID)) {
+			return component
+		}
+	}
+	return nil
+}
+
+func componentRef(component *JiraComponentFixture) map[string]interface{} {
+	return map[string]interface{}{"id": component.ID, "name": component.Name}
+}
+
+func versionRef(version *JiraVersionFixture) map[string]interface{} {
+	return map[string]interface{}{"id": version.ID, "name": version.Name, "released": version.Released, "archived": version.Archived}
+}
+
+// versionJSON renders a version; it is overdue when unreleased past its release date
+func (s *jiraState) versionJSON(version *JiraVersionFixture, r *http.Request, today string) map[string]interface{} {
+	result := map[string]interface{}{
+		"self":        fmt.Sprintf("https://%s/rest/api/2/version/%s", r.Host, version.ID),
+		"id":          version.ID,
+		"name":        version.Name,
+		"description": version.Description,
+		"archived":    version.Archived,
+		"released":    version.Released,
+	}
+	if project := s.project(version.Project); project != nil {
+		result["projectId"], _ = strconv.Atoi(project.ID)
+	}
+	if version.StartDate != "" {
+		result["startDate"] = version.StartDate
+	}
+	if version.ReleaseDate != "" {
+		result["releaseDate"] = version.ReleaseDate
+		result["overdue"] = !version.Released && version.ReleaseDate < today
+	}
+	return result
+}
+
+// issueVersions returns the IDs of the fix versions of an issue
+func issueVersions(issue *jiraIssue) []string {
+	var ids []string
+	versions, _ := issue.Fields["fixVersions"].([]interface{})
+	for _, version := range versions {
+		if ref, ok := version.(map[string]interface{}); ok {
+			ids = append(ids, fmt.Sprint(ref["id"]))
+		}
+	}
+	return ids
+}
+
+func (s *jiraState) user(query string) *JiraUserFixture {
+	if query == "" {
+		return nil
+	}
+	for i := range s.users {
+		u := &s.users[i]
+		if u.AccountID == query || u.username() == query || strings.EqualFold(u.DisplayName, query) || strings.EqualFold(u.Email, query) {
+			return u
+		}
+	}
+	return nil
+}
+
+// matchUsers returns the users whose name or email contains query, or everyone for an empty query
+func (s *jiraState) matchUsers(query string) []*JiraUserFixture {
+	query = strings.ToLower(strings.TrimSpace(query))
+	var users []*JiraUserFixture
+	for i := range s.users {
+		u := &s.users[i]
+		if query == "" || u.AccountID == query || u.username() == query || strings.Contains(strings.ToLower(u.DisplayName), query) || strings.Contains(strings.ToLower(u.Email), query) {
+			users = append(users, u)
+		}
+	}
+	return users
+}
+
+func (s *jiraState) assignable(project *JiraProjectFixture, user *JiraUserFixture) bool {
+	if project == nil || len(project.Assignable) == 0 {
+		return true
+	}
+	for _, query := range project.Assignable {
+		if s.user(query) == user {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *jiraState) watch(issue *jiraIssue, user *JiraUserFixture) {
+	for _, id := range issue.Watchers {
+		if id == user.AccountID {
+			return
+		}
+	}
+	issue.Watchers = append(issue.Watchers, user.AccountID)
+}
+
+// username is the Data Center style login of a user, taken from their email
+func (u *JiraUserFixture) username() string {
+	name, _, _ := strings.Cut(u.Email, "@")
+	return name
+}
+
+func userRef(user *JiraUserFixture) map[string]interface{} {
+	return map[string]interface{}{
+		"accountId":    user.AccountID,
+		"name":         user.username(),
+		"displayName":  user.DisplayName,
+		"emailAddress": user.Email,
+		"active":       true,
+	}
+}
+
+func (s *jiraState) sprint(id int) *JiraSprintFixture {
+	for _, sprint := range s.sprints {
+		if sprint.ID == id {
+			return sprint
+		}
+	}
+	return nil
+}
+
+func (s *jiraState) board(id int) *JiraBoardFixture {
+	for i := range s.boards {
+		if s.boards[i].ID == id {
+			return &s.boards[i]
+		}
+	}
+	return nil
+}
+
+func (s *jiraState) issue(keyOrID string) *jiraIssue {
+	if issue, ok := s.byKey[strings.ToUpper(keyOrID)]; ok {
+		return issue
+	}
+	for _, issue := range s.issues {
+		if issue.ID == keyOrID {
+			return issue
+		}
+	}
+	return nil
+}
+
+func nestedString(fields map[string]interface{}, name, key string) string {
+	if value, ok := fields[name].(map[string]interface{}); ok {
+		if str, ok := value[key].(string); ok {
+			return str
+		}
+	}
+	return ""
+}
+
+// fieldValues returns the comparable values of an issue field for JQL evaluation
+func (s *jiraState) fieldValues(issue *jiraIssue, field string) []string {
+	values := func(items ...string) []string {
+		var result []string
+		for _, item := range items {
+			if item != "" {
+				result = append(result, item)
+			}
+		}
+		return result
+	}
+
+	f := issue.Fields
+	switch field {
+	case "project":
+		return values(nestedString(f, "project", "key"), nestedString(f, "project", "name"), nestedString(f, "project", "id"))
+	case "key", "issuekey":
+		return values(issue.Key)
+	case "id":
+		return values(issue.ID)
+	case "summary":
+		return values(fmt.Sprint(f["summary"]))
+	case "description":
+		if description, ok := f["description"].(string); ok {
+			return values(description)
+		}
+		return nil
+	case "text":
+		description, _ := f["description"].(string)
+		return values(fmt.Sprint(f["summary"]), description)
+	case "status":
+		return values(nestedString(f, "status", "name"), nestedString(f, "status", "id"))
+	case "statuscategory":
+		if status, ok := f["status"].(map[string]interface{}); ok {
+			if category, ok := status["statusCategory"].(map[string]interface{}); ok {
+				return values(fmt.Sprint(category["key"]), fmt.Sprint(category["name"]))
+			}
+		}
+		return nil
+	case "issuetype", "type":
+		return values(nestedString(f, "issuetype", "name"))
+	case "assignee", "reporter":
+		return values(nestedString(f, field, "accountId"), nestedString(f, field, "displayName"), nestedString(f, field, "emailAddress"))
+	case "priority":
+		return values(nestedString(f, "priority", "name"))
+	case "resolution":
+		return values(nestedString(f, "resolution", "name"))
+	case "labels":
+		var labels []string
+		switch typed := f["labels"].(type) {
+		case []string:
+			labels = typed
+		case []interface{}:
+			for _, label := range typed {
+				labels = append(labels, fmt.Sprint(label))
+			}
+		}
+		return values(labels...)
+	case "parent":
+		return values(nestedString(f, "parent", "key"))
+	case "component":
+		var components []string
+		items, _ := f["components"].([]interface{})
+		for _, item := range items {
+			if ref, ok := item.(map[string]interface{}); ok {
+				components = append(components, fmt.Sprint(ref["id"]), fmt.Sprint(ref["name"]))
+			}
+		}
+		return values(components...)
+	case "fixversion":
+		var versions []string
+		for _, id := range issueVersions(issue) {
+			if version := s.version("", id); version != nil {
+				versions = append(versions, version.ID, version.Name)
+			}
+		}
+		return values(versions...)
+	case "sprint":
+		if sprint := s.sprint(s.sprintOf[issue.Key]); sprint != nil {
+			return values(strconv.Itoa(sprint.ID), sprint.Name)
+		}
+		return nil
+	case "created", "updated":
+		if value, ok := f[field].(string); ok {
+			return values(value)
+		}
+		return nil
+	case "worklogdate":
+		var dates []string
+		for _, worklog := range issue.Worklogs {
+			if started := fmt.Sprint(worklog["started"]); len(started) >= len("2006-01-02") {
+				dates = append(dates, started[:len("2006-01-02")])
+			}
+		}
+		return values(dates...)
+	}
+	return nil
+}
+
+// render returns the JSON representation of an issue as Jira would return it
+func (s *jiraState) render(issue *jiraIssue, r *http.Request) map[string]interface{} {
+	fields := make(map[string]interface{}, len(issue.Fields)+2)
+	for name, value := range issue.Fields {
+		fields[name] = value
+	}
+
+	if parentKey := nestedString(issue.Fields, "parent", "key"); parentKey != "" {
+		if parent := s.issue(parentKey); parent != nil {
+			fields["parent"] = map[string]interface{}{
+				"id":  parent.ID,
+				"key": parent.Key,
+				"fields": map[string]interface{}{
+					"summary": parent.Fields["summary"],
+					"status":  parent.Fields["status"],
+				},
+			}
+		}
+	}
+
+	var subtasks []map[string]interface{}
+	for _, child := range s.issues {
+		if nestedString(child.Fields, "parent", "key") == issue.Key {
+			if issueType, ok := child.Fields["issuetype"].(map[string]interface{}); ok && issueType["subtask"] == true {
+				subtasks = append(subtasks, map[string]interface{}{
+					"id":  child.ID,
+					"key": child.Key,
+					"fields": map[string]interface{}{
+						"summary":   child.Fields["summary"],
+						"status":    child.Fields["status"],
+						"issuetype": child.Fields["issuetype"],
+					},
+				})
+			}
+		}
+	}
+	if len(subtasks) > 0 {
+		fields["subtasks"] = subtasks
+	}
+	fields["issuelinks"] = s.issueLinks(issue)
+
+	attachments := []map[string]interface{}{}
+	for _, attachment := range s.files {
+		if attachment.IssueKey == issue.Key {
+			attachments = append(attachments, s.attachmentJSON(attachment, r))
+		}
+	}
+	fields["attachment"] = attachments
+
+	fields["comment"] = map[string]interface{}{
+		"comments":   issue.Comments,
+		"maxResults": len(issue.Comments),
+		"total":      len(issue.Comments),
+		"startAt":    0,
+	}
+
+	if sprint := s.sprint(s.sprintOf[issue.Key]); sprint != nil {
+		fields["sprint"] = sprintJSON(sprint)
+	}
+
+	result := map[string]interface{}{
+		"id":     issue.ID,
+		"key":    issue.Key,
+		"self":   fmt.Sprintf("https://%s/rest/api/2/issue/%s", r.Host, issue.ID),
+		"fields": fields,
+	}
+
+	expand := r.URL.Query().Get("expand")
+	if strings.Contains(expand, "transitions") {
+		result["transitions"] = s.availableTransitions(issue)
+	}
+	if strings.Contains(expand, "renderedFields") {
+		description, _ := issue.Fields["description"].(string)
+		result["renderedFields"] = map[string]interface{}{
+			"description": "<p>" + html.EscapeString(description) + "</p>",
+		}
+	}
+	if strings.Contains(expand, "changelog") {
+		result["changelog"] = map[string]interface{}{
+			"startAt":    0,
+			"maxResults": len(issue.Changelog),
+			"total":      len(issue.Changelog),
+			"histories":  issue.Changelog,
+		}
+	}
+
+	return result
+}
+
+func (s *jiraState) availableTransitions(issue *jiraIssue) []map[string]interface{} {
+	current := nestedString(issue.Fields, "status", "name")
+	transitions := []map[string]interface{}{}
+	for _, transition := range s.transitions {
+		allowed := len(transition.From) == 0
+		for _, from := range transition.From {
+			if strings.EqualFold(from, current) {
+				allowed = true
+			}
+		}
+		if !allowed {
+			continue
+		}
+		item := map[string]interface{}{"id": transition.ID, "name": transition.Name}
+		if to := s.status(transition.To); to != nil {
+			item["to"] = s.statusRef(to)
+		}
+		transitions = append(transitions, item)
+	}
+	return transitions
+}
+
+func (s *jiraState) recordChange(issue *jiraIssue, timestamp, field, from, to string) {
+	s.recordItem(issue, timestamp, s.currentUser, map[string]interface{}{"field": field, "fieldtype": "jira", "fromString": from, "toString": to})
+}
+
+func (s *jiraState) recordItem(issue *jiraIssue, timestamp, author string, item map[string]interface{}) {
+	s.nextOther++
+	entry := map[string]interface{}{
+		"id":      strconv.Itoa(s.nextOther),
+		"created": timestamp,
+		"items":   []map[string]interface{}{item},
+	}
+	if user := s.user(author); user != nil {
+		entry["author"] = userRef(user)
+	}
+	issue.Changelog = append(issue.Changelog, entry)
+}
+
+func (s *jiraState) customField(id string) *JiraFieldFixture {
+	for i := range s.fields {
+		if s.fields[i].ID == id {
+			return &s.fields[i]
+		}
+	}
+	return nil
+}
+
+// sprintIDs turns comma separated sprint names into the IDs a Sprint changelog item holds
+func (s *jiraState) sprintIDs(names string) string {
+	var ids []string
+	for _, name := range strings.Split(names, ",") {
+		for _, sprint := range s.sprints {
+			if sprint.Name == strings.TrimSpace(name) {
+				ids = append(ids, strconv.Itoa(sprint.ID))
+			}
+		}
+	}
+	return strings.Join(ids, ",")
+}
+
+// moveToSprint puts an issue into a sprint, or the backlog for sprint 0, recording the change
+func (s *jiraState) moveToSprint(issue *jiraIssue, id int, timestamp string) {
+	from := s.sprint(s.sprintOf[issue.Key])
+	to := s.sprint(id)
+	if from == to {
+		return
+	}
+
+	item := map[string]interface{}{"field": "Sprint", "fieldtype": "custom", "fromString": "", "toString": "", "from": "", "to": ""}
+	if from != nil {
+		item["fromString"], item["from"] = from.Name, strconv.Itoa(from.ID)
+	}
+	if to != nil {
+		item["toString"], item["to"] = to.Name, strconv.Itoa(to.ID)
+		s.sprintOf[issue.Key] = id
+	} else {
+		delete(s.sprintOf, issue.Key)
+	}
+	s.recordItem(issue, timestamp, s.currentUser, item)
+	issue.Fields["updated"] = timestamp
+}
+
+// applyFields merges a create/update "fields" payload into the stored issue
+func (s *jiraState) applyFields(issue *jiraIssue, fields map[string]interface{}, timestamp string) {
+	for name, value := range fields {
+		switch name {
+		case "assignee", "reporter":
+			if value == nil {
+				delete(issue.Fields, name)
+				continue
+			}
+			ref, _ := value.(map[string]interface{})
+			query, _ := ref["accountId"].(string)
+			if query == "" {
+				query, _ = ref["name"].(string)
+			}
+			if user := s.user(query); user != nil {
+				issue.Fields[name] = userRef(user)
+			}
+		case "priority":
+			ref, _ := value.(map[string]interface{})
+			nameOrID, _ := ref["id"].(string)
+			if nameOrID == "" {
+				nameOrID, _ = ref["name"].(string)
+			}
+			issue.Fields[name] = s.priorityRef(nameOrID)
+		case "issuetype":
+			if ref, ok := value.(map[string]interface{}); ok {
+				if typeName, ok := ref["name"].(string); ok {
+					issue.Fields[name] = s.issueTypeRef(typeName)
+				}
+			}
+		case "parent":
+			if value == nil {
+				delete(issue.Fields, name)
+				continue
+			}
+			issue.Fields[name] = value
+		case "fixVersions":
+			projectKey := nestedString(issue.Fields, "project", "key")
+			items, _ := value.([]interface{})
+			var fixVersions []interface{}
+			for _, item := range items {
+				ref, _ := item.(map[string]interface{})
+				nameOrID, _ := ref["id"].(string)
+				if nameOrID == "" {
+					nameOrID, _ = ref["name"].(string)
+				}
+				if version := s.version(projectKey, nameOrID); version != nil {
+					fixVersions = append(fixVersions, versionRef(version))
+				}
+			}
+			issue.Fields[name] = fixVersions
+		case "components":
+			projectKey := nestedString(issue.Fields, "project", "key")
+			items, _ := value.([]interface{})
+			components := []interface{}{}
+			for _, item := range items {
+				ref, _ := item.(map[string]interface{})
+				nameOrID, _ := ref["id"].(string)
+				if nameOrID == "" {
+					nameOrID, _ = ref["name"].(string)
+				}
+				if component := s.component(projectKey, nameOrID); component != nil {
+					components = append(components, componentRef(component))
+				}
+			}
+			issue.Fields[name] = components
+		case "project":
+			if ref, ok := value.(map[string]interface{}); ok {
+				key, _ := ref["key"].(string)
+				if key == "" {
+					key, _ = ref["id"].(string)
+				}
+				if project := s.project(key); project != nil {
+					issue.Fields[name] = s.projectRef(project)
+				}
+			}
+		default:
+			old := fmt.Sprint(issue.Fields[name])
+			issue.Fields[name] = value
+			if name == "summary" || name == "description" {
+				s.recordChange(issue, timestamp, name, old, fmt.Sprint(value))
+			} else if field := s.customField(name); field != nil {
+				if old == "<nil>" {
+					old = ""
+				}
+				s.recordItem(issue, timestamp, s.currentUser, map[string]interface{}{
+					"field": field.Name, "fieldtype": "custom", "fieldId": field.ID, "fromString": old, "toString": fmt.Sprint(value),
+				})
+			}
+		}
+	}
+	issue.Fields["updated"] = timestamp
+}
+
+// applyOperations handles the "update" part of an edit payload
+func (s *jiraState) applyOperations(issue *jiraIssue, update map[string]interface{}, timestamp string) {
+	for name, rawOps := range update {
+		ops, _ := rawOps.([]interface{})
+		for _, rawOp := range ops {
+			op, _ := rawOp.(map[string]interface{})
+			for verb, value := range op {
+				switch name {
+				case "labels":
+					labels := s.fieldValues(issue, "labels")
+					label := fmt.Sprint(value)
+					switch verb {
+					case "add":
+						labels = append(labels, label)
+					case "remove":
+						filtered := labels[:0]
+						for _, existing := range labels {
+							if existing != label {
+								filtered = append(filtered, existing)
+							}
+						}
+						labels = filtered
+					case "set":
+						labels = nil
+						if list, ok := value.([]interface{}); ok {
+							for _, item := range list {
+								labels = append(labels, fmt.Sprint(item))
+							}
+						}
+					}
+					issue.Fields["labels"] = labels
+				case "comment":
+					if verb == "add" {
+						if payload, ok := value.(map[string]interface{}); ok {
+							comment := s.addComment(issue, s.currentUser, fmt.Sprint(payload["body"]), timestamp)
+							if visibility, ok := payload["visibility"]; ok {
+								comment["visibility"] = visibility
+							}
+						}
+					}
+				default:
+					if verb == "set" {
+						issue.Fields[name] = value
+					}
+				}
+			}
+		}
+	}
+	issue.Fields["updated"] = timestamp
+}
+
+func sprintJSON(sprint *JiraSprintFixture) map[string]interface{} {
+	result := map[string]interface{}{
+		"id":            sprint.ID,
+		"name":          sprint.Name,
+		"state":         sprint.State,
+		"goal":          sprint.Goal,
+		"originBoardId": sprint.BoardID,
+	}
+	// Jira omits the dates of sprints that were never started
+	if sprint.StartDate != "" {
+		result["startDate"] = sprint.StartDate
+	}
+	if sprint.EndDate != "" {
+		result["endDate"] = sprint.EndDate
+	}
+	if sprint.Completed != "" {
+		result["completeDate"] = sprint.Completed
+	}
+	return result
+}
+
+func (b *Backend) registerJiraRoutes() {
+	b.handle("GET /rest/api/2/issue/{key}", b.jiraGetIssue)
+	b.handle("PUT /rest/api/2/issue/{key}", b.jiraUpdateIssue)
+	b.handle("POST /rest/api/2/issue", b.jiraCreateIssue)
+	b.handle("GET /rest/api/2/issue/{key}/transitions", b.jiraGetTransitions)
+	b.handle("POST /rest/api/2/issue/{key}/transitions", b.jiraDoTransition)
+	b.handle("GET /rest/api/2/issue/{key}/changelog", b.jiraListChangelog)
+	b.handle("GET /rest/api/2/issue/{key}/comment", b.jiraListComments)
+	b.handle("GET /rest/api/2/issue/{key}/worklog", b.jiraListWorklogs)
+	b.handle("POST /rest/api/2/issue/{key}/worklog", b.jiraAddWorklog)
+	b.handle("POST /rest/api/2/issue/{key}/comment", b.jiraAddComment)
+	b.handle("GET /rest/api/2/issue/{key}/comment/{id}", b.jiraGetComment)
+	b.handle("PUT /rest/api/2/issue/{key}/comment/{id}", b.jiraUpdateComment)
+	b.handle("DELETE /rest/api/2/issue/{key}/comment/{id}", b.jiraDeleteComment)
+	b.handle("GET /rest/api/2/search", b.jiraSearch)
+	b.handle("POST /rest/api/2/search", b.jiraSearch)
+	b.handle("POST /rest/api/2/jql/parse", b.jiraParseJQL)
+	b.handle("GET /rest/api/2/project/{key}", b.jiraGetProject)
+	b.handle("GET /rest/api/2/project/{key}/statuses", b.jiraProjectStatuses)
+	b.handle("GET /rest/api/2/project/{key}/versions", b.jiraProjectVersions)
+	b.handle("GET /rest/api/2/project/{key}/components", b.jiraProjectComponents)
+	b.handle("POST /rest/api/2/component", b.jiraCreateComponent)
+	b.handle("GET /rest/api/2/component/{id}/relatedIssueCounts", b.jiraComponentIssueCounts)
+	b.handle("GET /rest/api/2/issuetypescheme/project", b.jiraIssueTypeSchemes)
+	b.handle("GET /rest/api/2/workflowscheme/project", b.jiraWorkflowSchemes)
+	b.handle("GET /rest/api/2/priority", b.jiraListPriorities)
+	b.handle("GET /rest/api/2/resolution", b.jiraListResolutions)
+	b.handle("POST /rest/api/2/version", b.jiraCreateVersion)
+	b.handle("GET /rest/api/2/version/{id}", b.jiraGetVersion)
+	b.handle("PUT /rest/api/2/version/{id}", b.jiraUpdateVersion)
+	b.handle("GET /rest/api/2/filter/favourite", b.jiraFavouriteFilters)
+	b.handle("GET /rest/api/2/filter/my", b.jiraMyFilters)
+	b.handle("GET /rest/api/2/filter/search", b.jiraSearchFilters)
+	b.handle("GET /rest/api/2/filter/{id}", b.jiraGetFilter)
+	b.handle("POST /rest/api/2/filter", b.jiraCreateFilter)
+	b.handle("PUT /rest/api/2/filter/{id}", b.jiraUpdateFilter)
+	b.handle("GET /rest/api/2/dashboard", b.jiraListDashboards)
+	b.handle("GET /rest/api/2/myself", b.jiraMyself)
+	b.handle("GET /rest/api/2/user", b.jiraGetUser)
+	b.handle("GET /rest/api/2/user/search", b.jiraSearchUsers)
+	b.handle("GET /rest/api/2/user/assignable/search", b.jiraAssignableUsers)
+	b.handle("PUT /rest/api/2/issue/{key}/assignee", b.jiraAssignIssue)
+	b.handle("GET /rest/api/2/issue/{key}/watchers", b.jiraListWatchers)
+	b.handle("POST /rest/api/2/issue/{key}/watchers", b.jiraAddWatcher)
+	b.handle("DELETE /rest/api/2/issue/{key}/watchers", b.jiraRemoveWatcher)
+	b.handle("GET /rest/api/2/field", b.jiraListFields)
+	b.handle("GET /rest/api/2/status", b.jiraListStatuses)
+	b.handle("POST /rest/api/2/issue/{key}/attachments", b.jiraAddAttachments)
+	b.handle("GET /rest/api/2/attachment/{id}", b.jiraGetAttachment)
+	b.handle("GET /rest/api/2/attachment/content/{id}", b.jiraAttachmentContent)
+	b.handle("GET /rest/api/2/issueLinkType", b.jiraListLinkTypes)
+	b.handle("POST /rest/api/2/issueLink", b.jiraCreateLink)
+	b.handle("GET /rest/api/2/issueLink/{id}", b.jiraGetLink)
+	b.handle("DELETE /rest/api/2/issueLink/{id}", b.jiraDeleteLink)
+	// createmeta/{key}/issuetypes conflicts with {key}/comment/{id}, so it is matched by a broader pattern
+	b.handle("GET /rest/api/2/issue/{scope}/{key}/{collection}", b.jiraCreateMetaIssueTypes)
+	b.handle("GET /rest/api/2/issue/createmeta/{key}/issuetypes/{type}", b.jiraCreateMetaFields)
+
+	b.handle("GET /rest/servicedeskapi/servicedesk", b.serviceDeskList)
+	b.handle("GET /rest/servicedeskapi/servicedesk/{id}", b.serviceDeskGet)
+	b.handle("GET /rest/servicedeskapi/servicedesk/{id}/requesttype", b.serviceDeskRequestTypes)
+	b.handle("GET /rest/servicedeskapi/servicedesk/{id}/requesttype/{type}/field", b.serviceDeskRequestTypeFields)
+	b.handle("GET /rest/servicedeskapi/servicedesk/{id}/queue", b.serviceDeskQueues)
+	b.handle("GET /rest/servicedeskapi/servicedesk/{id}/queue/{queue}/issue", b.serviceDeskQueueIssues)
+	b.handle("POST /rest/servicedeskapi/request", b.serviceDeskCreateRequest)
+	b.handle("GET /rest/servicedeskapi/request/{key}", b.serviceDeskGetRequest)
+	b.handle("POST /rest/servicedeskapi/request/{key}/comment", b.serviceDeskAddComment)
+	b.handle("GET /rest/servicedeskapi/request/{key}/sla", b.serviceDeskSLAs)
+
+	b.handle("GET /rest/agile/1.0/board", b.agileListBoards)
+	b.handle("GET /rest/agile/1.0/board/{id}", b.agileGetBoard)
+	b.handle("GET /rest/agile/1.0/board/{id}/sprint", b.agileBoardSprints)
+	b.handle("GET /rest/agile/1.0/board/{id}/configuration", b.agileBoardConfiguration)
+	b.handle("GET /rest/agile/1.0/board/{id}/backlog", b.agileBoardBacklog)
+	b.handle("GET /rest/agile/1.0/board/{id}/sprint/{sprint}/issue", b.agileBoardSprintIssues)
+	b.handle("GET /rest/agile/1.0/sprint/{id}", b.agileGetSprint)
+	b.handle("GET /rest/agile/1.0/sprint/{id}/issue", b.agileSprintIssues)
+	b.handle("POST /rest/agile/1.0/sprint/{id}/issue", b.agileMoveToSprint)
+	b.handle("POST /rest/agile/1.0/sprint", b.agileCreateSprint)
+	b.handle("POST /rest/agile/1.0/sprint/{id}", b.agileUpdateSprint)
+	b.handle("PUT /rest/agile/1.0/sprint/{id}", b.agileUpdateSprint)
+	b.handle("POST /rest/agile/1.0/backlog/issue", b.agileMoveToBacklog)
+	b.handle("PUT /rest/agile/1.0/issue/rank", b.agileRankIssues)
+}
+
+func (b *Backend) jiraGetIssue(w http.ResponseWriter, r *http.Request) {
+	issue := b.jira.issue(r.PathValue("key"))
+	if issue == nil {
+		jiraError(w, http.StatusNotFound, "Issue does not exist or you do not have permission to see it.")
+		return
+	}
+	writeJSON(w, http.StatusOK, b.jira.render(issue, r))
+}
+
+func (b *Backend) jiraUpdateIssue(w http.ResponseWriter, r *http.Request) {
+	issue := b.jira.issue(r.PathValue("key"))
+	if issue == nil {
+		jiraError(w, http.StatusNotFound, "Issue does not exist or you do not have permission to see it.")
+		return
+	}
+
+	var payload struct {
+		Fields map[string]interface{} `json:"fields"`
+		Update map[string]interface{} `json:"update"`
+	}
+	if err := decodeJSON(r, &payload); err != nil {
+		jiraError(w, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+
+	now := b.timestamp()
+	b.jira.applyFields(issue, payload.Fields, now)
+	b.jira.applyOperations(issue, payload.Update, now)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (b *Backend) jiraCreateIssue(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		Fields map[string]interface{} `json:"fields"`
+		Update map[string]interface{} `json:"update"`
+	}
+	if err := decodeJSON(r, &payload); err != nil {
+		jiraError(w, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+
+	projectKey := nestedString(payload.Fields, "project", "key")
+	if projectKey == "" {
+		projectKey = nestedString(payload.Fields, "project", "id")
+	}
+	project := b.jira.project(projectKey)
+	if project == nil {
+		writeJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"errorMessages": []string{},
+			"errors":        map[string]string{"project": "valid project is required"},
+		})
+		return
+	}
+	if summary, _ := payload.Fields["summary"].(string); summary == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"errorMessages": []string{},
+			"errors":        map[string]string{"summary": "You must specify a summary of the issue."},
+		})
+		return
+	}
+	invalid := map[string]string{}
+	for _, field := range b.jira.fieldMetas(nestedString(payload.Fields, "issuetype", "name")) {
+		value, ok := payload.Fields[field.ID]
+		if field.Required && !ok {
+			invalid[field.ID] = field.Name + " is required."
+		}
+		if option, isOption := value.(map[string]interface{}); isOption && field.Schema["type"] == "option" && !allowsOption(field, option["value"]) {
+			invalid[field.ID] = fmt.Sprintf("Option value '%v' is not valid", option["value"])
+		}
+	}
+	if len(invalid) > 0 {
+		writeJSON(w, http.StatusBadRequest, map[string]interface{}{"errorMessages": []string{}, "errors": invalid})
+		return
+	}
+
+	b.jira.nextKey[project.Key]++
+	now := b.timestamp()
+	issue := b.jira.addIssue(JiraIssueFixture{
+		Key:      fmt.Sprintf("%s-%d", project.Key, b.jira.nextKey[project.Key]),
+		Type:     nestedString(payload.Fields, "issuetype", "name"),
+		Reporter: b.jira.currentUser,
+		Created:  now,
+		Updated:  now,
+	})
+	b.jira.applyFields(issue, payload.Fields, now)
+	b.jira.applyOperations(issue, payload.Update, now)
+	issue.Changelog = nil
+
+	writeJSON(w, http.StatusCreated, map[string]string{
+		"id":   issue.ID,
+		"key":  issue.Key,
+		"self": fmt.Sprintf("https://%s/rest/api/2/issue/%s", r.Host, issue.ID),
+	})
+}
+
+func (b *Backend) jiraGetTransitions(w http.ResponseWriter, r *http.Request) {
+	issue := b.jira.issue(r.PathValue("key"))
+	if issue == nil {
+		jiraError(w, http.StatusNotFound, "Issue does not exist or you do not have permission to see it.")
+		return
+	}
+	transitions := b.jira.availableTransitions(issue)
+	if strings.Contains(r.URL.Query().Get("expand"), "transitions.fields") {
+		for _, transition := range transitions {
+			transition["fields"] = b.jira.transitionFields(b.jira.transition(transition["id"].(string)))
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"transitions": transitions})
+}
+
+func (s *jiraState) transition(id string) *JiraTransitionFixture {
+	for i := range s.transitions {
+		if s.transitions[i].ID == id {
+			return &s.transitions[i]
+		}
+	}
+	return nil
+}
+
+// jiraDefaultPriorities are the priorities of a fixture that configures none, highest first as in Jira
+var jiraDefaultPriorities = []JiraPriorityFixture{
+	{ID: "1", Name: "Highest", Description: "This problem will block progress."},
+	{ID: "2", Name: "High", Description: "Serious problem that could block progress."},
+	{ID: "3", Name: "Medium", Description: "Has the potential to affect progress."},
+	{ID: "4", Name: "Low", Description: "Minor problem or easily worked around."},
+	{ID: "5", Name: "Lowest", Description: "Trivial problem with little or no impact on progress."},
+}
+
+// jiraResolutions are the resolutions of the site, shared by all projects
+var jiraResolutions = []map[string]interface{}{
+	{"id": "10000", "name": "Done", "description": "Work has been completed on this issue."},
+	{"id": "10001", "name": "Won't Do", "description": "This issue won't be actioned."},
+	{"id": "10002", "name": "Duplicate", "description": "The problem is a duplicate of an existing issue."},
+}
+
+// priorityValues lists the priorities as allowed values of the priority field
+func (s *jiraState) priorityValues() []map[string]interface{} {
+	values := make([]map[string]interface{}, 0, len(s.priorities))
+	for _, priority := range s.priorities {
+		values = append(values, map[string]interface{}{"id": priority.ID, "name": priority.Name, "description": priority.Description})
+	}
+	return values
+}
+
+// defaultPriority is the priority of issues created without one: Medium, or the middle priority
+func (s *jiraState) defaultPriority() JiraPriorityFixture {
+	for _, priority := range s.priorities {
+		if strings.EqualFold(priority.Name, "Medium") {
+			return priority
+		}
+	}
+	return s.priorities[len(s.priorities)/2]
+}
+
+// priorityRef references a configured priority by name or ID; unknown names are kept as they are
+func (s *jiraState) priorityRef(nameOrID string) map[string]interface{} {
+	for _, priority := range s.priorities {
+		if priority.ID == nameOrID || strings.EqualFold(priority.Name, nameOrID) {
+			return map[string]interface{}{"id": priority.ID, "name": priority.Name}
+		}
+	}
+	return map[string]interface{}{"name": nameOrID}
+}
+
+// priorityRank orders priority names the way Jira does, the highest priority ranking highest.
+// Unknown names rank below every priority.
+func (s *jiraState) priorityRank(nameOrID string) int {
+	for i, priority := range s.priorities {
+		if priority.ID == nameOrID || strings.EqualFold(priority.Name, nameOrID) {
+			return len(s.priorities) - i
+		}
+	}
+	return 0
+}
+
+// transitionFields describes the fields of a transition screen
+func (s *jiraState) transitionFields(transition *JiraTransitionFixture) map[string]interface{} {
+	metas := map[string]jiraFieldMeta{
+		"resolution": {
+			ID:            "resolution",
+			Name:          "Resolution",
+			Schema:        map[string]interface{}{"type": "resolution", "system": "resolution"},
+			AllowedValues: jiraResolutions,
+		},
+	}
+	for _, meta := range s.fieldMetas("") {
+		metas[meta.ID] = meta
+	}
+
+	fields := map[string]interface{}{}
+	for _, id := range transition.Fields {
+		meta, ok := metas[id]
+		if !ok {
+			continue
+		}
+		required := false
+		for _, requiredID := range transition.Required {
+			required = required || requiredID == id
+		}
+		field := map[string]interface{}{
+			"key":             id,
+			"name":            meta.Name,
+			"required":        required,
+			"schema":          meta.Schema,
+			"hasDefaultValue": false,
+			"operations":      []string{"set"},
+		}
+		if meta.AllowedValues != nil {
+			field["allowedValues"] = meta.AllowedValues
+		}
+		fields[id] = field
+	}
+	return fields
+}
+
+func (b *Backend) jiraDoTransition(w http.ResponseWriter, r *http.Request) {
+	issue := b.jira.issue(r.PathValue("key"))
+	if issue == nil {
+		jiraError(w, http.StatusNotFound, "Issue does not exist or you do not have permission to see it.")
+		return
+	}
+
+	var payload struct {
+		Transition struct {
+			ID string `json:"id"`
+		} `json:"transition"`
+		Fields map[string]interface{} `json:"fields"`
+		Update map[string]interface{} `json:"update"`
+	}
+	if err := decodeJSON(r, &payload); err != nil {
+		jiraError(w, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+
+	var target string
+	for _, transition := range b.jira.availableTransitions(issue) {
+		if transition["id"] == payload.Transition.ID {
+			if to, ok := transition["to"].(map[string]interface{}); ok {
+				target, _ = to["name"].(string)
+			}
+		}
+	}
+	if target == "" {
+		jiraError(w, http.StatusBadRequest, fmt.Sprintf("Transition id '%s' is not valid for this issue.", payload.Transition.ID))
+		return
+	}
+
+	screen := b.jira.transitionFields(b.jira.transition(payload.Transition.ID))
+	problems := map[string]string{}
+	for id := range payload.Fields {
+		if _, ok := screen[id]; !ok {
+			problems[id] = fmt.Sprintf("Field '%s' cannot be set. It is not on the appropriate screen, or unknown.", id)
+		}
+	}
+	for id, field := range screen {
+		if _, ok := payload.Fields[id]; field.(map[string]interface{})["required"] == true && !ok {
+			problems[id] = fmt.Sprintf("%s is required.", field.(map[string]interface{})["name"])
+		}
+	}
+	if len(problems) > 0 {
+		writeJSON(w, http.StatusBadRequest, map[string]interface{}{"errorMessages": []string{}, "errors": problems})
+		return
+	}
+
+	now := b.timestamp()
+	status := b.jira.status(target)
+	from := nestedString(issue.Fields, "status", "name")
+	issue.Fields["status"] = b.jira.statusRef(status)
+	if status.Category == "done" {
+		issue.Fields["resolution"] = map[string]interface{}{"name": "Done"}
+		issue.Fields["resolutiondate"] = now
+	} else {
+		delete(issue.Fields, "resolution")
+		delete(issue.Fields, "resolutiondate")
+	}
+	b.jira.recordChange(issue, now, "status", from, status.Name)
+	b.jira.applyFields(issue, payload.Fields, now)
+	b.jira.applyOperations(issue, payload.Update, now)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (b *Backend) jiraListChangelog(w http.ResponseWriter, r *http.Request) {
+	issue := b.jira.issue(r.PathValue("key"))
+	if issue == nil {
+		jiraError(w, http.StatusNotFound, "Issue does not exist or you do not have permission to see it.")
+		return
+	}
+
+	start, end := paginate(len(issue.Changelog), queryInt(r, "startAt", 0), queryInt(r, "maxResults", 100))
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"startAt":    start,
+		"maxResults": end - start,
+		"total":      len(issue.Changelog),
+		"isLast":     end == len(issue.Changelog),
+		"values":     issue.Changelog[start:end],
+	})
+}
+
+func (b *Backend) jiraListComments(w http.ResponseWriter, r *http.Request) {
+	issue := b.jira.issue(r.PathValue("key"))
+	if issue == nil {
+		jiraError(w, http.StatusNotFound, "Issue does not exist or you do not have permission to see it.")
+		return
+	}
+
+	comments := issue.Comments
+	if r.URL.Query().Get("orderBy") == "-created" {
+		comments = make([]map[string]interface{}, len(issue.Comments))
+		for i, comment := range issue.Comments {
+			comments[len(comments)-1-i] = comment
+		}
+	}
+
+	start, end := paginate(len(comments), queryInt(r, "startAt", 0), queryInt(r, "maxResults", 50))
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"startAt":    start,
+		"maxResults": end - start,
+		"total":      len(comments),
+		"comments":   comments[start:end],
+	})
+}
+
+// comment returns the index of the comment addressed by the request, writing a 404 when there is none
+func (b *Backend) jiraComment(w http.ResponseWriter, r *http.Request) (*jiraIssue, int) {
+	issue := b.jira.issue(r.PathValue("key"))
+	if issue == nil {
+		jiraError(w, http.StatusNotFound, "Issue does not exist or you do not have permission to see it.")
+		return nil, -1
+	}
+	for i, comment := range issue.Comments {
+		if comment["id"] == r.PathValue("id") {
+			return issue, i
+		}
+	}
+	jiraError(w, http.StatusNotFound, fmt.Sprintf("Can not find a comment for the id: %s.", r.PathValue("id")))
+	return nil, -1
+}
+
+func (b *Backend) jiraGetComment(w http.ResponseWriter, r *http.Request) {
+	issue, i := b.jiraComment(w, r)
+	if issue == nil {
+		return
+	}
+	writeJSON(w, http.StatusOK, issue.Comments[i])
+}
+
+func (b *Backend) jiraUpdateComment(w http.ResponseWriter, r *http.Request) {
+	issue, i := b.jiraComment(w, r)
+	if issue == nil {
+		return
+	}
+
+	var payload map[string]interface{}
+	if err := decodeJSON(r, &payload); err != nil {
+		jiraError(w, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+
+	comment := issue.Comments[i]
+	comment["body"] = fmt.Sprint(payload["body"])
+	comment["updated"] = b.timestamp()
+	if user := b.jira.user(b.jira.currentUser); user != nil {
+		comment["updateAuthor"] = userRef(user)
+	}
+	if visibility, ok := payload["visibility"]; ok {
+		comment["visibility"] = visibility
+	}
+
+	writeJSON(w, http.StatusOK, comment)
+}
+
+func (b *Backend) jiraDeleteComment(w http.ResponseWriter, r *http.Request) {
+	issue, i := b.jiraComment(w, r)
+	if issue == nil {
+		return
+	}
+	issue.Comments = append(issue.Comments[:i], issue.Comments[i+1:]...)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (b *Backend) jiraAddComment(w http.ResponseWriter, r *http.Request) {
+	issue := b.jira.issue(r.PathValue("key"))
+	if issue == nil {
+		jiraError(w, http.StatusNotFound, "Issue does not exist or you do not have permission to see it.")
+		return
+	}
+
+	var payload map[string]interface{}
+	if err := decodeJSON(r, &payload); err != nil {
+		jiraError(w, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+
+	comment := b.jira.addComment(issue, b.jira.currentUser, fmt.Sprint(payload["body"]), b.timestamp())
+	if visibility, ok := payload["visibility"]; ok {
+		comment["visibility"] = visibility
+	}
+
+	writeJSON(w, http.StatusCreated, comment)
+}
+
+func (b *Backend) jiraListWorklogs(w http.ResponseWriter, r *http.Request) {
+	issue := b.jira.issue(r.PathValue("key"))
+	if issue == nil {
+		jiraError(w, http.StatusNotFound, "Issue does not exist or you do not have permission to see it.")
+		return
+	}
+
+	worklogs := []map[string]interface{}{}
+	after := queryInt(r, "startedAfter", 0)
+	for _, worklog := range issue.Worklogs {
+		started, err := time.Parse(jiraTimeFormat, fmt.Sprint(worklog["started"]))
+		if err == nil && started.UnixMilli() >= int64(after) {
+			worklogs = append(worklogs, worklog)
+		}
+	}
+
+	start, end := paginate(len(worklogs), queryInt(r, "startAt", 0), queryInt(r, "maxResults", 5000))
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"startAt":    start,
+		"maxResults": end - start,
+		"total":      len(worklogs),
+		"worklogs":   worklogs[start:end],
+	})
+}
+
+func (b *Backend) jiraAddWorklog(w http.ResponseWriter, r *http.Request) {
+	issue := b.jira.issue(r.PathValue("key"))
+	if issue == nil {
+		jiraError(w, http.StatusNotFound, "Issue does not exist or you do not have permission to see it.")
+		return
+	}
+
+	var payload struct {
+		TimeSpent        string `json:"timeSpent"`
+		TimeSpentSeconds int    `json:"timeSpentSeconds"`
+		Started          string `json:"started"`
+		Comment          string `json:"comment"`
+	}
+	if err := decodeJSON(r, &payload); err != nil {
+		jiraError(w, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+
+	seconds := payload.TimeSpentSeconds
+	if payload.TimeSpent != "" {
+		var ok bool
+		if seconds, ok = jiraWorkSeconds(payload.TimeSpent); !ok {
+			jiraError(w, http.StatusBadRequest, "Invalid time duration entered.")
+			return
+		}
+	}
+	if seconds <= 0 {
+		jiraError(w, http.StatusBadRequest, "You must indicate the time spent working.")
+		return
+	}
+	timeSpent := payload.TimeSpent
+	if timeSpent == "" {
+		timeSpent = fmt.Sprintf("%dm", seconds/60)
+	}
+
+	now := b.timestamp()
+	started := payload.Started
+	if started == "" {
+		started = now
+	} else if _, err := time.Parse(jiraTimeFormat, started); err != nil {
+		jiraError(w, http.StatusBadRequest, "Invalid started date: "+started)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, b.jira.addWorklog(issue, b.jira.currentUser, started, timeSpent, seconds, payload.Comment, now))
+}
+
+// jiraAddAttachments accepts multipart uploads, which Jira only allows with the XSRF check disabled
+func (b *Backend) jiraAddAttachments(w http.ResponseWriter, r *http.Request) {
+	issue := b.jira.issue(r.PathValue("key"))
+	if issue == nil {
+		jiraError(w, http.StatusNotFound, "Issue does not exist or you do not have permission to see it.")
+		return
+	}
+	if r.Header.Get("X-Atlassian-Token") != "no-check" {
+		jiraError(w, http.StatusForbidden, "XSRF check failed")
+		return
+	}
+
+	reader, err := r.MultipartReader()
+	if err != nil {
+		jiraError(w, http.StatusBadRequest, "Invalid multipart request: "+err.Error())
+		return
+	}
+
+	now := b.timestamp()
+	attachments := []map[string]interface{}{}
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			jiraError(w, http.StatusBadRequest, "Invalid multipart request: "+err.Error())
+			return
+		}
+		if part.FormName() != "file" || part.FileName() == "" {
+			continue
+		}
+
+		content, err := io.ReadAll(part)
+		if err != nil {
+			jiraError(w, http.StatusBadRequest, "Invalid multipart request: "+err.Error())
+			return
+		}
+		mimeType := part.Header.Get("Content-Type")
+		if mimeType == "" || mimeType == "application/octet-stream" {
+			mimeType = http.DetectContentType(content)
+		}
+
+		attachment := b.jira.addAttachment(issue, b.jira.currentUser, part.FileName(), mimeType, content, now)
+		b.jira.recordChange(issue, now, "Attachment", "", attachment.Filename)
+		attachments = append(attachments, b.jira.attachmentJSON(attachment, r))
+	}
+
+	if len(attachments) == 0 {
+		jiraError(w, http.StatusBadRequest, "No file was attached.")
+		return
+	}
+	issue.Fields["updated"] = now
+
+	writeJSON(w, http.StatusOK, attachments)
+}
+
+func (b *Backend) jiraGetAttachment(w http.ResponseWriter, r *http.Request) {
+	attachment := b.jira.attachment(r.PathValue("id"))
+	if attachment == nil {
+		jiraError(w, http.StatusNotFound, "The attachment with id '"+r.PathValue("id")+"' does not exist")
+		return
+	}
+
+	result := b.jira.attachmentJSON(attachment, r)
+	result["id"], _ = strconv.Atoi(attachment.ID)
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (b *Backend) jiraAttachmentContent(w http.ResponseWriter, r *http.Request) {
+	attachment := b.jira.attachment(r.PathValue("id"))
+	if attachment == nil {
+		jiraError(w, http.StatusNotFound, "The attachment with id '"+r.PathValue("id")+"' does not exist")
+		return
+	}
+
+	w.Header().Set("Content-Type", attachment.MimeType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", attachment.Filename))
+	w.WriteHeader(http.StatusOK)
+	w.Write(attachment.Content)
+}
+
+func (b *Backend) jiraListLinkTypes(w http.ResponseWriter, r *http.Request) {
+	linkTypes := []map[string]interface{}{}
+	for i := range b.jira.linkTypes {
+		linkTypes = append(linkTypes, linkTypeJSON(&b.jira.linkTypes[i]))
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"issueLinkTypes": linkTypes})
+}
+
+func (b *Backend) jiraCreateLink(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		Type         map[string]string `json:"type"`
+		InwardIssue  map[string]string `json:"inwardIssue"`
+		OutwardIssue map[string]string `json:"outwardIssue"`
+	}
+	if err := decodeJSON(r, &payload); err != nil {
+		jiraError(w, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+
+	linkType := b.jira.linkType(payload.Type["name"])
+	if linkType == nil {
+		linkType = b.jira.linkType(payload.Type["id"])
+	}
+	if linkType == nil {
+		jiraError(w, http.StatusNotFound, "No issue link type with name '"+payload.Type["name"]+"' found.")
+		return
+	}
+	// the inward issue of the request is the one the outward description applies to
+	from, to := b.jira.issue(payload.InwardIssue["key"]), b.jira.issue(payload.OutwardIssue["key"])
+	if from == nil || to == nil {
+		jiraError(w, http.StatusNotFound, "Issue does not exist or you do not have permission to see it.")
+		return
+	}
+	if from == to {
+		jiraError(w, http.StatusBadRequest, "You cannot link an issue to itself.")
+		return
+	}
+
+	link := b.jira.link(linkType, from.Key, to.Key)
+	w.Header().Set("Location", fmt.Sprintf("https://%s/rest/api/2/issueLink/%s", r.Host, link.ID))
+	w.WriteHeader(http.StatusCreated)
+}
+
+// linkIndex returns the index of the link addressed by the request, writing a 404 when there is none
+func (b *Backend) linkIndex(w http.ResponseWriter, r *http.Request) int {
+	for i, link := range b.jira.links {
+		if link.ID == r.PathValue("id") {
+			return i
+		}
+	}
+	jiraError(w, http.StatusNotFound, "No issue link with id '"+r.PathValue("id")+"' exists.")
+	return -1
+}
+
+func (b *Backend) jiraGetLink(w http.ResponseWriter, r *http.Request) {
+	i := b.linkIndex(w, r)
+	if i < 0 {
+		return
+	}
+	link := b.jira.links[i]
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"id":           link.ID,
+		"type":         linkTypeJSON(link.Type),
+		"inwardIssue":  linkedIssue(b.jira.issue(link.From)),
+		"outwardIssue": linkedIssue(b.jira.issue(link.To)),
+	})
+}
+
+func (b *Backend) jiraDeleteLink(w http.ResponseWriter, r *http.Request) {
+	i := b.linkIndex(w, r)
+	if i < 0 {
+		return
+	}
+	b.jira.links = append(b.jira.links[:i], b.jira.links[i+1:]...)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (b *Backend) jiraSearch(w http.ResponseWriter, r *http.Request) {
+	jql := r.URL.Query().Get("jql")
+	startAt := queryInt(r, "startAt", 0)
+	maxResults := queryInt(r, "maxResults", 50)
+
+	if r.Method == http.MethodPost {
+		var payload struct {
+			JQL        string `json:"jql"`
+			StartAt    int    `json:"startAt"`
+			MaxResults int    `json:"maxResults"`
+		}
+		if err := decodeJSON(r, &payload); err != nil {
+			jiraError(w, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+			return
+		}
+		jql, startAt, maxResults = payload.JQL, payload.StartAt, payload.MaxResults
+	}
+
+	query, err := parseJQL(jql)
+	if err != nil {
+		jiraError(w, http.StatusBadRequest, fmt.Sprintf("Error in the JQL Query: %v", err))
+		return
+	}
+
+	var matched []*jiraIssue
+	for _, issue := range b.jira.issues {
+		if query.match(b.jira, issue) {
+			matched = append(matched, issue)
+		}
+	}
+	query.sort(b.jira, matched)
+
+	start, end := paginate(len(matched), startAt, maxResults)
+	issues := []map[string]interface{}{}
+	for _, issue := range matched[start:end] {
+		issues = append(issues, b.jira.render(issue, r))
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"startAt":    start,
+		"maxResults": maxResults,
+		"total":      len(matched),
+		"issues":     issues,
+	})
+}
+
+// jiraParseJQL reports the errors of each query, as the JQL parser endpoint does
+func (b *Backend) jiraParseJQL(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		Queries []string `json:"queries"`
+	}
+	if err := decodeJSON(r, &payload); err != nil {
+		jiraError(w, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+
+	queries := []map[string]interface{}{}
+	for _, jql := range payload.Queries {
+		parsed := map[string]interface{}{"query": jql}
+		if query, err := parseJQL(jql); err != nil {
+			parsed["errors"] = []string{err.Error()}
+		} else {
+			var fields []map[string]interface{}
+			for _, order := range query.orderBy {
+				direction := "asc"
+				if order.desc {
+					direction = "desc"
+				}
+				fields = append(fields, map[string]interface{}{"field": map[string]interface{}{"name": order.field}, "direction": direction})
+			}
+			parsed["structure"] = map[string]interface{}{"orderBy": map[string]interface{}{"fields": fields}}
+		}
+		queries = append(queries, parsed)
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"queries": queries})
+}
+
+func (b *Backend) jiraGetProject(w http.ResponseWriter, r *http.Request) {
+	project := b.jira.project(r.PathValue("key"))
+	if project == nil {
+		jiraError(w, http.StatusNotFound, "No project could be found with key '"+r.PathValue("key")+"'.")
+		return
+	}
+
+	result := b.jira.projectRef(project)
+	result["self"] = fmt.Sprintf("https://%s/rest/api/2/project/%s", r.Host, project.ID)
+	result["projectTypeKey"] = "software"
+	if b.jira.serviceDeskOf(project.Key) != nil {
+		result["projectTypeKey"] = "service_desk"
+	}
+	// the project lead is the default assignee of every fake project
+	result["assigneeType"] = "PROJECT_LEAD"
+	if lead := b.jira.user(project.Lead); lead != nil {
+		result["lead"] = userRef(lead)
+	}
+	var issueTypes []map[string]interface{}
+	for _, name := range project.IssueTypes {
+		issueTypes = append(issueTypes, b.jira.issueTypeRef(name))
+	}
+	result["issueTypes"] = issueTypes
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (b *Backend) jiraProjectVersions(w http.ResponseWriter, r *http.Request) {
+	project := b.jira.project(r.PathValue("key"))
+	if project == nil {
+		jiraError(w, http.StatusNotFound, "No project could be found with key '"+r.PathValue("key")+"'.")
+		return
+	}
+
+	today := b.now().UTC().Format("2006-01-02")
+	versions := []map[string]interface{}{}
+	for _, version := range b.jira.versions {
+		if version.Project == project.Key {
+			versions = append(versions, b.jira.versionJSON(version, r, today))
+		}
+	}
+	writeJSON(w, http.StatusOK, versions)
+}
+
+func (b *Backend) jiraGetVersion(w http.ResponseWriter, r *http.Request) {
+	version := b.jira.version("", r.PathValue("id"))
+	if version == nil {
+		jiraError(w, http.StatusNotFound, "Could not find version for id '"+r.PathValue("id")+"'")
+		return
+	}
+	writeJSON(w, http.StatusOK, b.jira.versionJSON(version, r, b.now().UTC().Format("2006-01-02")))
+}
+
+type jiraVersionPayload struct {
+	Name        *string `json:"name"`
+	Description *string `json:"description"`
+	StartDate   *string `json:"startDate"`
+	ReleaseDate *string `json:"releaseDate"`
+	Released    *bool   `json:"released"`
+	Archived    *bool   `json:"archived"`
+	ProjectID   int     `json:"projectId"`
+	Project     string  `json:"project"`
+	// MoveUnfixedIssuesTo is the URL of the version that takes over unresolved issues on release
+	MoveUnfixedIssuesTo string `json:"moveUnfixedIssuesTo"`
+}
+
+func (p jiraVersionPayload) apply(version *JiraVersionFixture) error {
+	for _, date := range []*string{p.StartDate, p.ReleaseDate} {
+		if date != nil && *date != "" {
+			if _, err := time.Parse("2006-01-02", *date); err != nil {
+				return fmt.Errorf("Invalid date format. Please enter the date in the format yyyy-MM-dd.")
+			}
+		}
+	}
+	if p.Name != nil {
+		version.Name = *p.Name
+	}
+	if p.Description != nil {
+		version.Description = *p.Description
+	}
+	if p.StartDate != nil {
+		version.StartDate = *p.StartDate
+	}
+	if p.ReleaseDate != nil {
+		version.ReleaseDate = *p.ReleaseDate
+	}
+	if p.Released != nil {
+		version.Released = *p.Released
+	}
+	if p.Archived != nil {
+		version.Archived = *p.Archived
+	}
+	return nil
+}
+
+func (b *Backend) jiraCreateVersion(w http.ResponseWriter, r *http.Request) {
+	var payload jiraVersionPayload
+	if err := decodeJSON(r, &payload); err != nil {
+		jiraError(w, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+
+	key := payload.Project
+	if payload.ProjectID != 0 {
+		key = strconv.Itoa(payload.ProjectID)
+	}
+	project := b.jira.project(key)
+	if project == nil {
+		jiraError(w, http.StatusBadRequest, "Project must be specified to create a version.")
+		return
+	}
+	if payload.Name == nil || strings.TrimSpace(*payload.Name) == "" {
+		jiraError(w, http.StatusBadRequest, "You must specify a valid version name")
+		return
+	}
+	if b.jira.version(project.Key, *payload.Name) != nil {
+		jiraError(w, http.StatusBadRequest, "A version with this name already exists in this project.")
+		return
+	}
+
+	b.jira.nextOther++
+	version := &JiraVersionFixture{ID: strconv.Itoa(10000 + b.jira.nextOther), Project: project.Key}
+	if err := payload.apply(version); err != nil {
+		jiraError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	b.jira.versions = append(b.jira.versions, version)
+
+	writeJSON(w, http.StatusCreated, b.jira.versionJSON(version, r, b.now().UTC().Format("2006-01-02")))
+}
+
+func (b *Backend) jiraUpdateVersion(w http.ResponseWriter, r *http.Request) {
+	version := b.jira.version("", r.PathValue("id"))
+	if version == nil {
+		jiraError(w, http.StatusNotFound, "Could not find version for id '"+r.PathValue("id")+"'")
+		return
+	}
+
+	var payload jiraVersionPayload
+	if err := decodeJSON(r, &payload); err != nil {
+		jiraError(w, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	if payload.Name != nil && *payload.Name != version.Name && b.jira.version(version.Project, *payload.Name) != nil {
+		jiraError(w, http.StatusBadRequest, "A version with this name already exists in this project.")
+		return
+	}
+
+	var target *JiraVersionFixture
+	if payload.MoveUnfixedIssuesTo != "" {
+		id := payload.MoveUnfixedIssuesTo[strings.LastIndex(payload.MoveUnfixedIssuesTo, "/")+1:]
+		if target = b.jira.version("", id); target == nil || target.Project != version.Project {
+			jiraError(w, http.StatusBadRequest, "Could not find version for id '"+id+"'")
+			return
+		}
+	}
+
+	if err := payload.apply(version); err != nil {
+		jiraError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if target != nil {
+		now := b.timestamp()
+		for _, issue := range b.jira.issues {
+			if category := b.jira.fieldValues(issue, "statuscategory"); len(category) > 0 && category[0] == "done" {
+				continue
+			}
+			versions, _ := issue.Fields["fixVersions"].([]interface{})
+			for i, ref := range versions {
+				if fmt.Sprint(ref.(map[string]interface{})["id"]) == version.ID {
+					versions[i] = versionRef(target)
+					b.jira.recordChange(issue, now, "Fix Version", version.Name, target.Name)
+				}
+			}
+		}
+	}
+
+	writeJSON(w, http.StatusOK, b.jira.versionJSON(version, r, b.now().UTC().Format("2006-01-02")))
+}
+
+func (b *Backend) jiraListStatuses(w http.ResponseWriter, r *http.Request) {
+	statuses := []map[string]interface{}{}
+	for i := range b.jira.statuses {
+		statuses = append(statuses, b.jira.statusRef(&b.jira.statuses[i]))
+	}
+	writeJSON(w, http.StatusOK, statuses)
+}
+
+func (b *Backend) jiraProjectStatuses(w http.ResponseWriter, r *http.Request) {
+	project := b.jira.project(r.PathValue("key"))
+	if project == nil {
+		jiraError(w, http.StatusNotFound, "No project could be found with key '"+r.PathValue("key")+"'.")
+		return
+	}
+
+	statuses := []map[string]interface{}{}
+	for i := range b.jira.statuses {
+		statuses = append(statuses, b.jira.statusRef(&b.jira.statuses[i]))
+	}
+
+	result := []map[string]interface{}{}
+	for _, typeName := range project.IssueTypes {
+		issueType := b.jira.issueTypeRef(typeName)
+		issueType["statuses"] = statuses
+		result = append(result, issueType)
+	}
+
+	writeJSON(w, http.StatusOK, result)
+}
+
+// componentJSON renders a component with the assignee its assignee type resolves to. As in Jira,
+// a component lead assignee without a lead falls back to the project default, the project lead.
+func (s *jiraState) componentJSON(component *JiraComponentFixture, r *http.Request) map[string]interface{} {
+	project := s.project(component.Project)
+	result := map[string]interface{}{
+		"self":                fmt.Sprintf("https://%s/rest/api/2/component/%s", r.Host, component.ID),
+		"id":                  component.ID,
+		"name":                component.Name,
+		"description":         component.Description,
+		"assigneeType":        component.AssigneeType,
+		"project":             component.Project,
+		"isAssigneeTypeValid": true,
+	}
+	if project != nil {
+		result["projectId"], _ = strconv.Atoi(project.ID)
+	}
+
+	lead := s.user(component.Lead)
+	if lead != nil {
+		result["lead"] = userRef(lead)
+		result["leadUserName"] = lead.username()
+	}
+
+	realType, realAssignee := component.AssigneeType, lead
+	switch component.AssigneeType {
+	case "UNASSIGNED":
+		realAssignee = nil
+	case "COMPONENT_LEAD":
+		if lead == nil {
+			result["isAssigneeTypeValid"] = false
+			realType = "PROJECT_DEFAULT"
+		}
+	}
+	if realType == "PROJECT_DEFAULT" || realType == "PROJECT_LEAD" {
+		realType, realAssignee = "PROJECT_LEAD", nil
+		if project != nil {
+			realAssignee = s.user(project.Lead)
+		}
+	}
+	result["realAssigneeType"] = realType
+	if realAssignee != nil {
+		result["realAssignee"] = userRef(realAssignee)
+		if component.AssigneeType != "UNASSIGNED" {
+			result["assignee"] = userRef(realAssignee)
+		}
+	}
+	return result
+}
+
+func (b *Backend) jiraProjectComponents(w http.ResponseWriter, r *http.Request) {
+	project := b.jira.project(r.PathValue("key"))
+	if project == nil {
+		jiraError(w, http.StatusNotFound, "No project could be found with key '"+r.PathValue("key")+"'.")
+		return
+	}
+
+	components := []map[string]interface{}{}
+	for _, component := range b.jira.components {
+		if component.Project == project.Key {
+			components = append(components, b.jira.componentJSON(component, r))
+		}
+	}
+	writeJSON(w, http.StatusOK, components)
+}
+
+func (b *Backend) jiraCreateComponent(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		Name          string `json:"name"`
+		Description   string `json:"description"`
+		Project       string `json:"project"`
+		ProjectID     int    `json:"projectId"`
+		AssigneeType  string `json:"assigneeType"`
+		LeadAccountID string `json:"leadAccountId"`
+		LeadUserName  string `json:"leadUserName"`
+	}
+	if err := decodeJSON(r, &payload); err != nil {
+		jiraError(w, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+
+	key := payload.Project
+	if payload.ProjectID != 0 {
+		key = strconv.Itoa(payload.ProjectID)
+	}
+	project := b.jira.project(key)
+	if project == nil {
+		jiraError(w, http.StatusBadRequest, "The project '"+key+"' does not exist.")
+		return
+	}
+	if strings.TrimSpace(payload.Name) == "" {
+		jiraError(w, http.StatusBadRequest, "The component name must not be empty.")
+		return
+	}
+	if b.jira.component(project.Key, payload.Name) != nil {
+		jiraError(w, http.StatusBadRequest, "A component with the name "+payload.Name+" already exists in this project.")
+		return
+	}
+
+	component := &JiraComponentFixture{Project: project.Key, Name: payload.Name, Description: payload.Description, AssigneeType: payload.AssigneeType}
+	switch component.AssigneeType {
+	case "":
+		component.AssigneeType = "PROJECT_DEFAULT"
+	case "PROJECT_DEFAULT", "COMPONENT_LEAD", "PROJECT_LEAD", "UNASSIGNED":
+	default:
+		jiraError(w, http.StatusBadRequest, "The default assignee type '"+payload.AssigneeType+"' is not valid.")
+		return
+	}
+	if lead := payload.LeadAccountID + payload.LeadUserName; lead != "" {
+		user := b.jira.user(lead)
+		if user == nil {
+			jiraError(w, http.StatusBadRequest, "The user '"+lead+"' does not exist.")
+			return
+		}
+		component.Lead = user.AccountID
+	}
+
+	b.jira.nextOther++
+	component.ID = strconv.Itoa(10100 + b.jira.nextOther)
+	b.jira.components = append(b.jira.components, component)
+
+	writeJSON(w, http.StatusCreated, b.jira.componentJSON(component, r))
+}
+
+func (b *Backend) jiraComponentIssueCounts(w http.ResponseWriter, r *http.Request) {
+	component := b.jira.component("", r.PathValue("id"))
+	if component == nil {
+		jiraError(w, http.StatusNotFound, "The component with id '"+r.PathValue("id")+"' does not exist.")
+		return
+	}
+
+	count := 0
+	for _, issue := range b.jira.issues {
+		items, _ := issue.Fields["components"].([]interface{})
+		for _, item := range items {
+			if ref, ok := item.(map[string]interface{}); ok && ref["id"] == component.ID {
+				count++
+			}
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"self":       fmt.Sprintf("https://%s/rest/api/2/component/%s", r.Host, component.ID),
+		"issueCount": count,
+	})
+}
+
+// projectSchemes returns the projects asked for with the projectId query parameter, in the order of the fixture
+func (s *jiraState) projectSchemes(r *http.Request) []*JiraProjectFixture {
+	var projects []*JiraProjectFixture
+	for i := range s.projects {
+		for _, id := range r.URL.Query()["projectId"] {
+			if s.projects[i].ID == id {
+				projects = append(projects, &s.projects[i])
+			}
+		}
+	}
+	return projects
+}
+
+func (b *Backend) jiraIssueTypeSchemes(w http.ResponseWriter, r *http.Request) {
+	values := []interface{}{}
+	for i, project := range b.jira.projectSchemes(r) {
+		scheme := map[string]interface{}{"id": "10000", "name": "Default Issue Type Scheme", "isDefault": true}
+		if project.IssueTypeScheme != "" {
+			scheme = map[string]interface{}{"id": strconv.Itoa(10100 + i), "name": project.IssueTypeScheme}
+		}
+		if len(project.IssueTypes) > 0 {
+			scheme["defaultIssueTypeId"] = b.jira.issueTypeRef(project.IssueTypes[0])["id"]
+		}
+		values = append(values, map[string]interface{}{"issueTypeScheme": scheme, "projectIds": []string{project.ID}})
+	}
+
+	start, end := paginate(len(values), queryInt(r, "startAt", 0), queryInt(r, "maxResults", 50))
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"startAt":    start,
+		"maxResults": queryInt(r, "maxResults", 50),
+		"total":      len(values),
+		"isLast":     end == len(values),
+		"values":     values[start:end],
+	})
+}
+
+func (b *Backend) jiraWorkflowSchemes(w http.ResponseWriter, r *http.Request) {
+	values := []interface{}{}
+	for i, project := range b.jira.projectSchemes(r) {
+		scheme := map[string]interface{}{
+			"id":                10000,
+			"name":              "Default Workflow Scheme",
+			"defaultWorkflow":   "jira",
+			"issueTypeMappings": map[string]string{},
+		}
+		if project.WorkflowScheme != "" {
+			mappings := map[string]string{}
+			for typeName, workflow := range project.Workflows {
+				mappings[fmt.Sprint(b.jira.issueTypeRef(typeName)["id"])] = workflow
+			}
+			scheme = map[string]interface{}{
+				"id":                10100 + i,
+				"name":              project.WorkflowScheme,
+				"defaultWorkflow":   project.DefaultWorkflow,
+				"issueTypeMappings": mappings,
+			}
+		}
+		scheme["self"] = fmt.Sprintf("https://%s/rest/api/2/workflowscheme/%v", r.Host, scheme["id"])
+		values = append(values, map[string]interface{}{"workflowScheme": scheme, "projectIds": []string{project.ID}})
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"values": values})
+}
+
+func (b *Backend) jiraListPriorities(w http.ResponseWriter, r *http.Request) {
+	priorities := []map[string]interface{}{}
+	for _, priority := range b.jira.priorities {
+		priorities = append(priorities, map[string]interface{}{
+			"self":        fmt.Sprintf("https://%s/rest/api/2/priority/%s", r.Host, priority.ID),
+			"id":          priority.ID,
+			"name":        priority.Name,
+			"description": priority.Description,
+		})
+	}
+	writeJSON(w, http.StatusOK, priorities)
+}
+
+func (b *Backend) jiraListResolutions(w http.ResponseWriter, r *http.Request) {
+	resolutions := []map[string]interface{}{}
+	for _, resolution := range jiraResolutions {
+		resolutions = append(resolutions, map[string]interface{}{
+			"self":        fmt.Sprintf("https://%s/rest/api/2/resolution/%s", r.Host, resolution["id"]),
+			"id":          resolution["id"],
+			"name":        resolution["name"],
+			"description": resolution["description"],
+		})
+	}
+	writeJSON(w, http.StatusOK, resolutions)
+}
+
+// jiraFieldMeta describes a field in the shape of the field list and the create metadata
+type jiraFieldMeta struct {
+	ID            string
+	Name          string
+	Schema        map[string]interface{}
+	Required      bool
+	AllowedValues []map[string]interface{}
+}
+
+// fieldMetas returns the system fields followed by the custom fields of the fixture
+func (s *jiraState) fieldMetas(issueType string) []jiraFieldMeta {
+	system := func(id, name, fieldType, items string, required bool) jiraFieldMeta {
+		schema := map[string]interface{}{"type": fieldType, "system": id}
+		if items != "" {
+			schema["items"] = items
+		}
+		return jiraFieldMeta{ID: id, Name: name, Schema: schema, Required: required}
+	}
+
+	priority := system("priority", "Priority", "priority", "", false)
+	priority.AllowedValues = s.priorityValues()
+
+	metas := []jiraFieldMeta{
+		system("project", "Project", "project", "", true),
+		system("issuetype", "Issue Type", "issuetype", "", true),
+		system("summary", "Summary", "string", "", true),
+		system("description", "Description", "string", "", false),
+		system("assignee", "Assignee", "user", "", false),
+		system("reporter", "Reporter", "user", "", false),
+		priority,
+		system("labels", "Labels", "array", "string", false),
+		system("components", "Components", "array", "component", false),
+		system("fixVersions", "Fix versions", "array", "version", false),
+		system("duedate", "Due date", "date", "", false),
+		system("parent", "Parent", "issuelink", "", strings.EqualFold(issueType, "Sub-task")),
+	}
+
+	for i, field := range s.fields {
+		schema := map[string]interface{}{"type": field.Type, "custom": field.Custom}
+		if field.Items != "" {
+			schema["items"] = field.Items
+		}
+		if _, number, ok := strings.Cut(field.ID, "_"); ok {
+			if id, err := strconv.Atoi(number); err == nil {
+				schema["customId"] = id
+			}
+		}
+		meta := jiraFieldMeta{ID: field.ID, Name: field.Name, Schema: schema, Required: field.Required}
+		for j, value := range field.AllowedValues {
+			meta.AllowedValues = append(meta.AllowedValues, map[string]interface{}{"id": strconv.Itoa((i+1)*100 + j), "value": value})
+		}
+		metas = append(metas, meta)
+	}
+	return metas
+}
+
+// allowsOption reports whether value is one of the options of a select field
+func allowsOption(field jiraFieldMeta, value interface{}) bool {
+	for _, allowed := range field.AllowedValues {
+		if allowed["value"] == value {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *Backend) jiraListFields(w http.ResponseWriter, r *http.Request) {
+	// fields that are set by Jira rather than on the create screen are listed too
+	metas := b.jira.fieldMetas("")
+	for _, field := range []struct{ id, name, fieldType string }{
+		{"status", "Status", "status"},
+		{"created", "Created", "datetime"},
+		{"updated", "Updated", "datetime"},
+		{"resolution", "Resolution", "resolution"},
+		{"resolutiondate", "Resolved", "datetime"},
+	} {
+		metas = append(metas, jiraFieldMeta{ID: field.id, Name: field.name, Schema: map[string]interface{}{"type": field.fieldType, "system": field.id}})
+	}
+
+	result := []map[string]interface{}{}
+	for _, meta := range metas {
+		clauseNames := []string{meta.ID}
+		if id, ok := meta.Schema["customId"]; ok {
+			clauseNames = []string{fmt.Sprintf("cf[%v]", id), meta.Name}
+		}
+		result = append(result, map[string]interface{}{
+			"id":          meta.ID,
+			"key":         meta.ID,
+			"name":        meta.Name,
+			"custom":      strings.HasPrefix(meta.ID, "customfield_"),
+			"orderable":   true,
+			"navigable":   true,
+			"searchable":  true,
+			"clauseNames": clauseNames,
+			"schema":      meta.Schema,
+		})
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (b *Backend) jiraCreateMetaIssueTypes(w http.ResponseWriter, r *http.Request) {
+	if r.PathValue("scope") != "createmeta" || r.PathValue("collection") != "issuetypes" {
+		http.NotFound(w, r)
+		return
+	}
+
+	project := b.jira.project(r.PathValue("key"))
+	if project == nil {
+		jiraError(w, http.StatusNotFound, "No project could be found with key '"+r.PathValue("key")+"'.")
+		return
+	}
+
+	issueTypes := []map[string]interface{}{}
+	for _, typeName := range project.IssueTypes {
+		issueTypes = append(issueTypes, b.jira.issueTypeRef(typeName))
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"startAt":    0,
+		"maxResults": 50,
+		"total":      len(issueTypes),
+		"issueTypes": issueTypes,
+	})
+}
+
+func (b *Backend) jiraCreateMetaFields(w http.ResponseWriter, r *http.Request) {
+	project := b.jira.project(r.PathValue("key"))
+	if project == nil {
+		jiraError(w, http.StatusNotFound, "No project could be found with key '"+r.PathValue("key")+"'.")
+		return
+	}
+
+	issueType := ""
+	for _, typeName := range project.IssueTypes {
+		if b.jira.issueTypeRef(typeName)["id"] == r.PathValue("type") {
+			issueType = typeName
+		}
+	}
+	if issueType == "" {
+		jiraError(w, http.StatusNotFound, "Issue type with id '"+r.PathValue("type")+"' does not exist in project "+project.Key+".")
+		return
+	}
+
+	fields := []map[string]interface{}{}
+	for _, meta := range b.jira.fieldMetas(issueType) {
+		field := map[string]interface{}{
+			"fieldId":         meta.ID,
+			"key":             meta.ID,
+			"name":            meta.Name,
+			"required":        meta.Required,
+			"schema":          meta.Schema,
+			"hasDefaultValue": meta.ID == "priority",
+			"operations":      []string{"set"},
+		}
+		if meta.AllowedValues != nil {
+			field["allowedValues"] = meta.AllowedValues
+		}
+		if meta.ID == "priority" {
+			priority := b.jira.defaultPriority()
+			field["defaultValue"] = map[string]interface{}{"id": priority.ID, "name": priority.Name}
+		}
+		fields = append(fields, field)
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"startAt":    0,
+		"maxResults": 200,
+		"total":      len(fields),
+		"fields":     fields,
+	})
+}
+
+func (b *Backend) jiraMyself(w http.ResponseWriter, r *http.Request) {
+	user := b.jira.user(b.jira.currentUser)
+	if user == nil {
+		jiraError(w, http.StatusUnauthorized, "Client must be authenticated to access this resource.")
+		return
+	}
+	writeJSON(w, http.StatusOK, userRef(user))
+}
+
+// userParam reads the user a request names, by account ID on Cloud and by username on Data Center
+func userParam(r *http.Request) string {
+	if accountID := r.URL.Query().Get("accountId"); accountID != "" {
+		return accountID
+	}
+	return r.URL.Query().Get("username")
+}
+
+func (b *Backend) jiraGetUser(w http.ResponseWriter, r *http.Request) {
+	user := b.jira.user(userParam(r))
+	if user == nil {
+		jiraError(w, http.StatusNotFound, "Specified user does not exist or you do not have required permissions")
+		return
+	}
+	writeJSON(w, http.StatusOK, userRef(user))
+}
+
+func (b *Backend) jiraSearchUsers(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query().Get("query")
+	if accountID := r.URL.Query().Get("accountId"); accountID != "" {
+		query = accountID
+	}
+	if username := r.URL.Query().Get("username"); username != "" {
+		query = username
+	}
+	if query == "" {
+		jiraError(w, http.StatusBadRequest, "One of 'query' or 'accountId' query parameters must be provided.")
+		return
+	}
+
+	b.writeUsers(w, r, b.jira.matchUsers(query))
+}
+
+// jiraAssignableUsers finds the users who can be assigned issues in a project, or a given issue
+func (b *Backend) jiraAssignableUsers(w http.ResponseWriter, r *http.Request) {
+	projectKey := r.URL.Query().Get("project")
+	if issueKey := r.URL.Query().Get("issueKey"); issueKey != "" {
+		issue := b.jira.issue(issueKey)
+		if issue == nil {
+			jiraError(w, http.StatusNotFound, "Issue does not exist or you do not have permission to see it.")
+			return
+		}
+		projectKey = nestedString(issue.Fields, "project", "key")
+	}
+	project := b.jira.project(projectKey)
+	if project == nil {
+		jiraError(w, http.StatusNotFound, "No project could be found with key '"+projectKey+"'.")
+		return
+	}
+
+	query := r.URL.Query().Get("query")
+	if accountID := r.URL.Query().Get("accountId"); accountID != "" {
+		query = accountID
+	}
+	if username := r.URL.Query().Get("username"); username != "" {
+		query = username
+	}
+
+	var users []*JiraUserFixture
+	for _, user := range b.jira.matchUsers(query) {
+		if b.jira.assignable(project, user) {
+			users = append(users, user)
+		}
+	}
+	b.writeUsers(w, r, users)
+}
+
+func (b *Backend) writeUsers(w http.ResponseWriter, r *http.Request, users []*JiraUserFixture) {
+	start, end := paginate(len(users), queryInt(r, "startAt", 0), queryInt(r, "maxResults", 50))
+	result := []map[string]interface{}{}
+	for _, user := range users[start:end] {
+		result = append(result, userRef(user))
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// jiraAssignIssue assigns an issue; a null account unassigns it and -1 picks the project lead as default assignee
+func (b *Backend) jiraAssignIssue(w http.ResponseWriter, r *http.Request) {
+	issue := b.jira.issue(r.PathValue("key"))
+	if issue == nil {
+		jiraError(w, http.StatusNotFound, "Issue does not exist or you do not have permission to see it.")
+		return
+	}
+
+	var payload struct {
+		AccountID *string `json:"accountId"`
+		Name      *string `json:"name"`
+	}
+	if err := decodeJSON(r, &payload); err != nil {
+		jiraError(w, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	if payload.AccountID == nil {
+		payload.AccountID = payload.Name
+	}
+
+	project := b.jira.project(nestedString(issue.Fields, "project", "key"))
+	var user *JiraUserFixture
+	if payload.AccountID != nil {
+		accountID := *payload.AccountID
+		if accountID == "-1" && project != nil {
+			accountID = project.Lead
+		}
+		if user = b.jira.user(accountID); user == nil || !b.jira.assignable(project, user) {
+			jiraError(w, http.StatusBadRequest, "User '"+accountID+"' cannot be assigned issues.")
+			return
+		}
+	}
+
+	now := b.timestamp()
+	from := nestedString(issue.Fields, "assignee", "displayName")
+	if user == nil {
+		delete(issue.Fields, "assignee")
+		b.jira.recordChange(issue, now, "assignee", from, "")
+	} else {
+		issue.Fields["assignee"] = userRef(user)
+		b.jira.recordChange(issue, now, "assignee", from, user.DisplayName)
+		b.jira.watch(issue, user)
+	}
+	issue.Fields["updated"] = now
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (b *Backend) jiraListWatchers(w http.ResponseWriter, r *http.Request) {
+	issue := b.jira.issue(r.PathValue("key"))
+	if issue == nil {
+		jiraError(w, http.StatusNotFound, "Issue does not exist or you do not have permission to see it.")
+		return
+	}
+
+	current := b.jira.user(b.jira.currentUser)
+	watching := false
+	watchers := []map[string]interface{}{}
+	for _, id := range issue.Watchers {
+		if user := b.jira.user(id); user != nil {
+			watchers = append(watchers, userRef(user))
+			watching = watching || user == current
+		}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"self":       fmt.Sprintf("https://%s/rest/api/2/issue/%s/watchers", r.Host, issue.Key),
+		"isWatching": watching,
+		"watchCount": len(watchers),
+		"watchers":   watchers,
+	})
+}
+
+// jiraAddWatcher reads the account ID as a bare JSON string; an empty body adds the current user
+func (b *Backend) jiraAddWatcher(w http.ResponseWriter, r *http.Request) {
+	issue := b.jira.issue(r.PathValue("key"))
+	if issue == nil {
+		jiraError(w, http.StatusNotFound, "Issue does not exist or you do not have permission to see it.")
+		return
+	}
+
+	accountID := b.jira.currentUser
+	if err := decodeJSON(r, &accountID); err != nil && err != io.EOF {
+		jiraError(w, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+
+	user := b.jira.user(accountID)
+	if user == nil {
+		jiraError(w, http.StatusNotFound, "The user \""+accountID+"\" does not exist.")
+		return
+	}
+	b.jira.watch(issue, user)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (b *Backend) jiraRemoveWatcher(w http.ResponseWriter, r *http.Request) {
+	issue := b.jira.issue(r.PathValue("key"))
+	if issue == nil {
+		jiraError(w, http.StatusNotFound, "Issue does not exist or you do not have permission to see it.")
+		return
+	}
+
+	accountID := userParam(r)
+	user := b.jira.user(accountID)
+	if user == nil {
+		jiraError(w, http.StatusNotFound, "The user \""+accountID+"\" does not exist.")
+		return
+	}
+	for i, id := range issue.Watchers {
+		if id == user.AccountID {
+			issue.Watchers = append(issue.Watchers[:i], issue.Watchers[i+1:]...)
+			break
+		}
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func boardJSON(board *JiraBoardFixture) map[string]interface{} {
+	return map[string]interface{}{
+		"id":   board.ID,
+		"name": board.Name,
+		"type": board.Type,
+		"location": map[string]interface{}{
+			"projectKey": board.Project,
+		},
+	}
+}
+
+func (b *Backend) agileListBoards(w http.ResponseWriter, r *http.Request) {
+	project := r.URL.Query().Get("projectKeyOrId")
+	boardType := r.URL.Query().Get("type")
+	name := strings.ToLower(r.URL.Query().Get("name"))
+
+	var boards []map[string]interface{}
+	for i := range b.jira.boards {
+		board := &b.jira.boards[i]
+		if project != "" && !strings.EqualFold(board.Project, project) {
+			continue
+		}
+		if boardType != "" && !strings.EqualFold(board.Type, boardType) {
+			continue
+		}
+		if name != "" && !strings.Contains(strings.ToLower(board.Name), name) {
+			continue
+		}
+		boards = append(boards, boardJSON(board))
+	}
+
+	start, end := paginate(len(boards), queryInt(r, "startAt", 0), queryInt(r, "maxResults", 50))
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"startAt":    start,
+		"maxResults": end - start,
+		"total":      len(boards),
+		"isLast":     end == len(boards),
+		"values":     boards[start:end],
+	})
+}
+
+func (b *Backend) agileGetBoard(w http.ResponseWriter, r *http.Request) {
+	id, _ := pathInt(r, "id")
+	board := b.jira.board(id)
+	if board == nil {
+		jiraError(w, http.StatusNotFound, "Board does not exist or you do not have permission to see it.")
+		return
+	}
+	writeJSON(w, http.StatusOK, boardJSON(board))
+}
+
+func (b *Backend) agileBoardSprints(w http.ResponseWriter, r *http.Request) {
+	id, _ := pathInt(r, "id")
+	if b.jira.board(id) == nil {
+		jiraError(w, http.StatusNotFound, "Board does not exist or you do not have permission to see it.")
+		return
+	}
+
+	states := r.URL.Query().Get("state")
+	var sprints []map[string]interface{}
+	for _, sprint := range b.jira.sprints {
+		if sprint.BoardID != id {
+			continue
+		}
+		if states != "" && !strings.Contains(states, sprint.State) {
+			continue
+		}
+		sprints = append(sprints, sprintJSON(sprint))
+	}
+
+	start, end := paginate(len(sprints), queryInt(r, "startAt", 0), queryInt(r, "maxResults", 50))
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"startAt":    start,
+		"maxResults": end - start,
+		"total":      len(sprints),
+		"isLast":     end == len(sprints),
+		"values":     sprints[start:end],
+	})
+}
+
+func (b *Backend) agileGetSprint(w http.ResponseWriter, r *http.Request) {
+	id, _ := pathInt(r, "id")
+	sprint := b.jira.sprint(id)
+	if sprint == nil {
+		jiraError(w, http.StatusNotFound, "Sprint does not exist or you do not have permission to see it.")
+		return
+	}
+	writeJSON(w, http.StatusOK, sprintJSON(sprint))
+}
+
+func (b *Backend) agileSprintIssues(w http.ResponseWriter, r *http.Request) {
+	id, _ := pathInt(r, "id")
+	if b.jira.sprint(id) == nil {
+		jiraError(w, http.StatusNotFound, "Sprint does not exist or you do not have permission to see it.")
+		return
+	}
+
+	b.agileIssuePage(w, r, func(issue *jiraIssue) bool {
+		return b.jira.sprintOf[issue.Key] == id
+	})
+}
+
+func (b *Backend) agileBoardSprintIssues(w http.ResponseWriter, r *http.Request) {
+	id, _ := pathInt(r, "id")
+	board := b.jira.board(id)
+	if board == nil {
+		jiraError(w, http.StatusNotFound, "Board does not exist or you do not have permission to see it.")
+		return
+	}
+	sprintID, _ := pathInt(r, "sprint")
+	if b.jira.sprint(sprintID) == nil {
+		jiraError(w, http.StatusNotFound, "Sprint does not exist or you do not have permission to see it.")
+		return
+	}
+
+	b.agileIssuePage(w, r, func(issue *jiraIssue) bool {
+		return b.jira.sprintOf[issue.Key] == sprintID && nestedString(issue.Fields, "project", "key") == board.Project
+	})
+}
+
+// agileBoardBacklog lists the issues of the board's project that are in no active or future sprint
+func (b *Backend) agileBoardBacklog(w http.ResponseWriter, r *http.Request) {
+	id, _ := pathInt(r, "id")
+	board := b.jira.board(id)
+	if board == nil {
+		jiraError(w, http.StatusNotFound, "Board does not exist or you do not have permission to see it.")
+		return
+	}
+
+	b.agileIssuePage(w, r, func(issue *jiraIssue) bool {
+		if nestedString(issue.Fields, "project", "key") != board.Project {
+			return false
+		}
+		sprint := b.jira.sprint(b.jira.sprintOf[issue.Key])
+		return sprint == nil || sprint.State == "closed"
+	})
+}
+
+// agileIssuePage writes the page of issues, in rank order, that match the filter and the jql parameter
+func (b *Backend) agileIssuePage(w http.ResponseWriter, r *http.Request, filter func(*jiraIssue) bool) {
+	query, err := parseJQL(r.URL.Query().Get("jql"))
+	if err != nil {
+		jiraError(w, http.StatusBadRequest, fmt.Sprintf("Error in the JQL Query: %v", err))
+		return
+	}
+
+	var issues []map[string]interface{}
+	for _, issue := range b.jira.issues {
+		if filter(issue) && query.match(b.jira, issue) {
+			issues = append(issues, b.jira.render(issue, r))
+		}
+	}
+
+	start, end := paginate(len(issues), queryInt(r, "startAt", 0), queryInt(r, "maxResults", 50))
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"startAt":    start,
+		"maxResults": end - start,
+		"total":      len(issues),
+		"issues":     issues[start:end],
+	})
+}
+
+func (b *Backend) agileBoardConfiguration(w http.ResponseWriter, r *http.Request) {
+	id, _ := pathInt(r, "id")
+	board := b.jira.board(id)
+	if board == nil {
+		jiraError(w, http.StatusNotFound, "Board does not exist or you do not have permission to see it.")
+		return
+	}
+
+	fixtureColumns := board.Columns
+	if len(fixtureColumns) == 0 {
+		for _, status := range b.jira.statuses {
+			fixtureColumns = append(fixtureColumns, JiraColumnFixture{Name: status.Name, Statuses: []string{status.Name}})
+		}
+	}
+	columns := []map[string]interface{}{}
+	for _, column := range fixtureColumns {
+		statuses := []map[string]interface{}{}
+		for _, name := range column.Statuses {
+			if status := b.jira.status(name); status != nil {
+				statuses = append(statuses, map[string]interface{}{
+					"id":   status.ID,
+					"self": fmt.Sprintf("https://%s/rest/api/2/status/%s", r.Host, status.ID),
+				})
+			}
+		}
+		columns = append(columns, map[string]interface{}{"name": column.Name, "statuses": statuses})
+	}
+
+	estimation := map[string]interface{}{"type": "issueCount"}
+	if board.Estimation != "" {
+		name := board.Estimation
+		for _, field := range b.jira.fields {
+			if field.ID == board.Estimation {
+				name = field.Name
+			}
+		}
+		estimation = map[string]interface{}{
+			"type":  "field",
+			"field": map[string]interface{}{"fieldId": board.Estimation, "displayName": name},
+		}
+	}
+
+	result := boardJSON(board)
+	result["filter"] = map[string]interface{}{"id": strconv.Itoa(10000 + board.ID)}
+	result["columnConfig"] = map[string]interface{}{"columns": columns, "constraintType": "none"}
+	result["estimation"] = estimation
+	result["ranking"] = map[string]interface{}{"rankCustomFieldId": 10019}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (b *Backend) agileMoveToSprint(w http.ResponseWriter, r *http.Request) {
+	id, _ := pathInt(r, "id")
+	if b.jira.sprint(id) == nil {
+		jiraError(w, http.StatusNotFound, "Sprint does not exist or you do not have permission to see it.")
+		return
+	}
+
+	if b.jira.sprint(id).State == "closed" {
+		jiraError(w, http.StatusBadRequest, "Issues can only be moved to open or active sprints.")
+		return
+	}
+
+	var payload agileRankPayload
+	if err := decodeJSON(r, &payload); err != nil {
+		jiraError(w, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+
+	for _, key := range payload.Issues {
+		issue := b.jira.issue(key)
+		if issue == nil {
+			jiraError(w, http.StatusBadRequest, fmt.Sprintf("Issue %s does not exist.", key))
+			return
+		}
+		b.jira.moveToSprint(issue, id, b.timestamp())
+	}
+	if payload.RankBeforeIssue != "" || payload.RankAfterIssue != "" {
+		b.jira.rank(payload)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type agileRankPayload struct {
+	Issues          []string `json:"issues"`
+	RankBeforeIssue string   `json:"rankBeforeIssue"`
+	RankAfterIssue  string   `json:"rankAfterIssue"`
+}
+
+// rank moves the issues of the payload, in order, before or after the reference issue. The
+// order of s.issues is the rank order.
+func (s *jiraState) rank(payload agileRankPayload) error {
+	reference := s.issue(payload.RankBeforeIssue + payload.RankAfterIssue)
+	if reference == nil {
+		return fmt.Errorf("Issue %s does not exist.", payload.RankBeforeIssue+payload.RankAfterIssue)
+	}
+
+	moved := map[*jiraIssue]bool{}
+	var ranked []*jiraIssue
+	for _, key := range payload.Issues {
+		issue := s.issue(key)
+		if issue == nil {
+			return fmt.Errorf("Issue %s does not exist.", key)
+		}
+		if issue == reference {
+			return fmt.Errorf("Issue %s cannot be ranked relative to itself.", key)
+		}
+		moved[issue] = true
+		ranked = append(ranked, issue)
+	}
+
+	var issues []*jiraIssue
+	for _, issue := range s.issues {
+		if moved[issue] {
+			continue
+		}
+		if issue == reference && payload.RankBeforeIssue != "" {
+			issues = append(issues, ranked...)
+		}
+		issues = append(issues, issue)
+		if issue == reference && payload.RankAfterIssue != "" {
+			issues = append(issues, ranked...)
+		}
+	}
+	s.issues = issues
+	return nil
+}
+
+func (b *Backend) agileRankIssues(w http.ResponseWriter, r *http.Request) {
+	var payload agileRankPayload
+	if err := decodeJSON(r, &payload); err != nil {
+		jiraError(w, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	if (payload.RankBeforeIssue == "") == (payload.RankAfterIssue == "") {
+		jiraError(w, http.StatusBadRequest, "Exactly one of rankBeforeIssue and rankAfterIssue must be given.")
+		return
+	}
+	if err := b.jira.rank(payload); err != nil {
+		jiraError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (b *Backend) agileMoveToBacklog(w http.ResponseWriter, r *http.Request) {
+	var payload agileRankPayload
+	if err := decodeJSON(r, &payload); err != nil {
+		jiraError(w, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+
+	for _, key := range payload.Issues {
+		issue := b.jira.issue(key)
+		if issue == nil {
+			jiraError(w, http.StatusBadRequest, fmt.Sprintf("Issue %s does not exist.", key))
+			return
+		}
+		b.jira.moveToSprint(issue, 0, b.timestamp())
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type agileSprintPayload struct {
+	Name          *string `json:"name"`
+	Goal          *string `json:"goal"`
+	State         *string `json:"state"`
+	StartDate     *string `json:"startDate"`
+	EndDate       *string `json:"endDate"`
+	OriginBoardID int     `json:"originBoardId"`
+}
+
+func (b *Backend) agileCreateSprint(w http.ResponseWriter, r *http.Request) {
+	var payload agileSprintPayload
+	if err := decodeJSON(r, &payload); err != nil {
+		jiraError(w, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	if payload.Name == nil || *payload.Name == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"errorMessages": []string{},
+			"errors":        map[string]string{"name": "The sprint name is required."},
+		})
+		return
+	}
+	if b.jira.board(payload.OriginBoardID) == nil {
+		jiraError(w, http.StatusBadRequest, "Board does not exist or you do not have permission to see it.")
+		return
+	}
+
+	id := 1
+	for _, sprint := range b.jira.sprints {
+		id = max(id, sprint.ID+1)
+	}
+	sprint := &JiraSprintFixture{ID: id, BoardID: payload.OriginBoardID, Name: *payload.Name, State: "future"}
+	if payload.Goal != nil {
+		sprint.Goal = *payload.Goal
+	}
+	if payload.StartDate != nil {
+		sprint.StartDate = *payload.StartDate
+	}
+	if payload.EndDate != nil {
+		sprint.EndDate = *payload.EndDate
+	}
+	b.jira.sprints = append(b.jira.sprints, sprint)
+
+	writeJSON(w, http.StatusCreated, sprintJSON(sprint))
+}
+
+func (b *Backend) agileUpdateSprint(w http.ResponseWriter, r *http.Request) {
+	id, _ := pathInt(r, "id")
+	sprint := b.jira.sprint(id)
+	if sprint == nil {
+		jiraError(w, http.StatusNotFound, "Sprint does not exist or you do not have permission to see it.")
+		return
+	}
+
+	var payload agileSprintPayload
+	if err := decodeJSON(r, &payload); err != nil {
+		jiraError(w, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+
+	updated := *sprint
+	if payload.Name != nil {
+		updated.Name = *payload.Name
+	}
+	if payload.Goal != nil {
+		updated.Goal = *payload.Goal
+	}
+	if payload.StartDate != nil {
+		updated.StartDate = *payload.StartDate
+	}
+	if payload.EndDate != nil {
+		updated.EndDate = *payload.EndDate
+	}
+	if payload.State != nil && !strings.EqualFold(*payload.State, sprint.State) {
+		state := strings.ToLower(*payload.State)
+		switch {
+		case sprint.State == "future" && state == "active":
+			if updated.StartDate == "" || updated.EndDate == "" {
+				jiraError(w, http.StatusBadRequest, "Sprint must have a start and end date to be started.")
+				return
+			}
+			for _, other := range b.jira.sprints {
+				if other.BoardID == sprint.BoardID && other.State == "active" {
+					jiraError(w, http.StatusBadRequest, "Sprint cannot be started because sprint "+other.Name+" is already active.")
+					return
+				}
+			}
+		case sprint.State == "active" && state == "closed":
+			updated.Completed = b.now().UTC().Format("2006-01-02T15:04:05.000Z")
+		default:
+			jiraError(w, http.StatusBadRequest, fmt.Sprintf("A sprint cannot be moved from %s to %s.", sprint.State, state))
+			return
+		}
+		updated.State = state
+	}
+	if sprint.State == "closed" && payload.State == nil {
+		jiraError(w, http.StatusBadRequest, "A closed sprint cannot be updated.")
+		return
+	}
+
+	*sprint = updated
+	writeJSON(w, http.StatusOK, sprintJSON(sprint))
+}
+
+// filterVisible reports whether the current user can see a filter or dashboard
+func (s *jiraState) filterVisible(owner string, shares []string) bool {
+	return len(shares) > 0 || s.user(owner) == s.user(s.currentUser)
+}
+
+func (s *jiraState) filter(id string) *JiraFilterFixture {
+	for _, filter := range s.filters {
+		if filter.ID == id && s.filterVisible(filter.Owner, filter.Shares) {
+			return filter
+		}
+	}
+	return nil
+}
+
+// sharePermissions renders the shares of a filter or dashboard
+func (s *jiraState) sharePermissions(shares []string) []map[string]interface{} {
+	permissions := []map[string]interface{}{}
+	for i, share := range shares {
+		kind, name, _ := strings.Cut(share, ":")
+		permission := map[string]interface{}{"id": 10000 + i, "type": kind}
+		switch kind {
+		case "project":
+			if project := s.project(name); project != nil {
+				permission["project"] = s.projectRef(project)
+			}
+		case "group":
+			permission["group"] = map[string]interface{}{"name": name}
+		}
+		permissions = append(permissions, permission)
+	}
+	return permissions
+}
+
+func (s *jiraState) filterJSON(filter *JiraFilterFixture, r *http.Request) map[string]interface{} {
+	result := map[string]interface{}{
+		"self":             fmt.Sprintf("https://%s/rest/api/2/filter/%s", r.Host, filter.ID),
+		"id":               filter.ID,
+		"name":             filter.Name,
+		"jql":              filter.JQL,
+		"favourite":        filter.Favourite,
+		"viewUrl":          fmt.Sprintf("https://%s/issues/?filter=%s", r.Host, filter.ID),
+		"searchUrl":        fmt.Sprintf("https://%s/rest/api/2/search?jql=%s", r.Host, url.QueryEscape(filter.JQL)),
+		"sharePermissions": s.sharePermissions(filter.Shares),
+	}
+	if filter.Description != "" {
+		result["description"] = filter.Description
+	}
+	if owner := s.user(filter.Owner); owner != nil {
+		result["owner"] = userRef(owner)
+	}
+	return result
+}
+
+func (b *Backend) writeFilters(w http.ResponseWriter, r *http.Request, keep func(*JiraFilterFixture) bool) {
+	result := []map[string]interface{}{}
+	for _, filter := range b.jira.filters {
+		if b.jira.filterVisible(filter.Owner, filter.Shares) && keep(filter) {
+			result = append(result, b.jira.filterJSON(filter, r))
+		}
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (b *Backend) jiraFavouriteFilters(w http.ResponseWriter, r *http.Request) {
+	b.writeFilters(w, r, func(filter *JiraFilterFixture) bool { return filter.Favourite })
+}
+
+func (b *Backend) jiraMyFilters(w http.ResponseWriter, r *http.Request) {
+	me := b.jira.user(b.jira.currentUser)
+	favourites := r.URL.Query().Get("includeFavourites") == "true"
+	b.writeFilters(w, r, func(filter *JiraFilterFixture) bool {
+		return b.jira.user(filter.Owner) == me || (favourites && filter.Favourite)
+	})
+}
+
+func (b *Backend) jiraSearchFilters(w http.ResponseWriter, r *http.Request) {
+	name := strings.ToLower(r.URL.Query().Get("filterName"))
+	var matched []*JiraFilterFixture
+	for _, filter := range b.jira.filters {
+		if b.jira.filterVisible(filter.Owner, filter.Shares) && strings.Contains(strings.ToLower(filter.Name), name) {
+			matched = append(matched, filter)
+		}
+	}
+
+	start, end := paginate(len(matched), queryInt(r, "startAt", 0), queryInt(r, "maxResults", 50))
+	values := []map[string]interface{}{}
+	for _, filter := range matched[start:end] {
+		values = append(values, b.jira.filterJSON(filter, r))
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"startAt":    start,
+		"maxResults": queryInt(r, "maxResults", 50),
+		"total":      len(matched),
+		"isLast":     end == len(matched),
+		"values":     values,
+	})
+}
+
+func (b *Backend) jiraGetFilter(w http.ResponseWriter, r *http.Request) {
+	filter := b.jira.filter(r.PathValue("id"))
+	if filter == nil {
+		jiraError(w, http.StatusBadRequest, "The selected filter is not available to you, perhaps it has been deleted or had its permissions changed.")
+		return
+	}
+	writeJSON(w, http.StatusOK, b.jira.filterJSON(filter, r))
+}
+
+// jiraFilterPayload is the body of a filter create or update, where absent fields are left as they are
+type jiraFilterPayload struct {
+	Name             *string `json:"name"`
+	Description      *string `json:"description"`
+	JQL              *string `json:"jql"`
+	Favourite        *bool   `json:"favourite"`
+	SharePermissions []struct {
+		Type    string `json:"type"`
+		Project *struct {
+			ID  string `json:"id"`
+			Key string `json:"key"`
+		} `json:"project"`
+		Group *struct {
+			Name string `json:"name"`
+		} `json:"group"`
+	} `json:"sharePermissions"`
+}
+
+func (s *jiraState) applyFilter(filter *JiraFilterFixture, payload *jiraFilterPayload) error {
+	if payload.Name != nil {
+		if strings.TrimSpace(*payload.Name) == "" {
+			return fmt.Errorf("You must specify a name for the filter.")
+		}
+		for _, other := range s.filters {
+			if other != filter && other.Owner == filter.Owner && strings.EqualFold(other.Name, *payload.Name) {
+				return fmt.Errorf("Filter with same name already exists.")
+			}
+		}
+		filter.Name = *payload.Name
+	}
+	if payload.JQL != nil {
+		if _, err := parseJQL(*payload.JQL); err != nil {
+			return fmt.Errorf("Error in the JQL Query: %v", err)
+		}
+		filter.JQL = *payload.JQL
+	}
+	if payload.Description != nil {
+		filter.Description = *payload.Description
+	}
+	if payload.Favourite != nil {
+		filter.Favourite = *payload.Favourite
+	}
+	if payload.SharePermissions != nil {
+		filter.Shares = nil
+		for _, permission := range payload.SharePermissions {
+			share := permission.Type
+			switch {
+			case permission.Project != nil:
+				project := s.project(permission.Project.Key)
+				if project == nil {
+					project = s.project(permission.Project.ID)
+				}
+				if project == nil {
+					return fmt.Errorf("No project could be found for the share permission.")
+				}
+				share += ":" + project.Key
+			case permission.Group != nil:
+				share += ":" + permission.Group.Name
+			}
+			filter.Shares = append(filter.Shares, share)
+		}
+	}
+	return nil
+}
+
+func (b *Backend) jiraCreateFilter(w http.ResponseWriter, r *http.Request) {
+	var payload jiraFilterPayload
+	if err := decodeJSON(r, &payload); err != nil {
+		jiraError(w, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	if payload.Name == nil || payload.JQL == nil {
+		jiraError(w, http.StatusBadRequest, "A filter needs a name and a JQL query.")
+		return
+	}
+
+	b.jira.nextOther++
+	filter := &JiraFilterFixture{ID: strconv.Itoa(10000 + b.jira.nextOther), Owner: b.jira.currentUser}
+	if err := b.jira.applyFilter(filter, &payload); err != nil {
+		jiraError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	b.jira.filters = append(b.jira.filters, filter)
+
+	writeJSON(w, http.StatusOK, b.jira.filterJSON(filter, r))
+}
+
+func (b *Backend) jiraUpdateFilter(w http.ResponseWriter, r *http.Request) {
+	filter := b.jira.filter(r.PathValue("id"))
+	if filter == nil {
+		jiraError(w, http.StatusBadRequest, "The selected filter is not available to you, perhaps it has been deleted or had its permissions changed.")
+		return
+	}
+	if b.jira.user(filter.Owner) != b.jira.user(b.jira.currentUser) {
+		jiraError(w, http.StatusBadRequest, "You do not have permission to edit this filter.")
+		return
+	}
+
+	var payload jiraFilterPayload
+	if err := decodeJSON(r, &payload); err != nil {
+		jiraError(w, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	updated := *filter
+	if err := b.jira.applyFilter(&updated, &payload); err != nil {
+		jiraError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	*filter = updated
+
+	writeJSON(w, http.StatusOK, b.jira.filterJSON(filter, r))
+}
+
+func (b *Backend) jiraListDashboards(w http.ResponseWriter, r *http.Request) {
+	me := b.jira.user(b.jira.currentUser)
+	var matched []JiraDashboardFixture
+	for _, dashboard := range b.jira.dashboards {
+		if !b.jira.filterVisible(dashboard.Owner, dashboard.Shares) {
+			continue
+		}
+		switch r.URL.Query().Get("filter") {
+		case "favourite":
+			if !dashboard.Favourite {
+				continue
+			}
+		case "my":
+			if b.jira.user(dashboard.Owner) != me {
+				continue
+			}
+		}
+		matched = append(matched, dashboard)
+	}
+
+	start, end := paginate(len(matched), queryInt(r, "startAt", 0), queryInt(r, "maxResults", 20))
+	dashboards := []map[string]interface{}{}
+	for _, dashboard := range matched[start:end] {
+		result := map[string]interface{}{
+			"id":               dashboard.ID,
+			"name":             dashboard.Name,
+			"isFavourite":      dashboard.Favourite,
+			"self":             fmt.Sprintf("https://%s/rest/api/2/dashboard/%s", r.Host, dashboard.ID),
+			"view":             fmt.Sprintf("https://%s/jira/dashboards/%s", r.Host, dashboard.ID),
+			"sharePermissions": b.jira.sharePermissions(dashboard.Shares),
+		}
+		if owner := b.jira.user(dashboard.Owner); owner != nil {
+			result["owner"] = userRef(owner)
+		}
+		dashboards = append(dashboards, result)
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"startAt":    start,
+		"maxResults": queryInt(r, "maxResults", 20),
+		"total":      len(matched),
+		"dashboards": dashboards,
+	})
+}
+
+func (s *jiraState) serviceDesk(id string) *JiraServiceDeskFixture {
+	for i := range s.desks {
+		if s.desks[i].ID == id {
+			return &s.desks[i]
+		}
+	}
+	return nil
+}
+
+func (s *jiraState) serviceDeskOf(projectKey string) *JiraServiceDeskFixture {
+	for i := range s.desks {
+		if strings.EqualFold(s.desks[i].Project, projectKey) {
+			return &s.desks[i]
+		}
+	}
+	return nil
+}
+
+func (desk *JiraServiceDeskFixture) requestType(id string) *JiraRequestTypeFixture {
+	for i := range desk.RequestTypes {
+		if desk.RequestTypes[i].ID == id {
+			return &desk.RequestTypes[i]
+		}
+	}
+	return nil
+}
+
+// serviceDeskError mimics the error payload of the Service Management API, which differs from Jira's
+func serviceDeskError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]interface{}{
+		"errorMessage":     message,
+		"i18nErrorMessage": map[string]interface{}{"i18nKey": "", "parameters": []string{}},
+	})
+}
+
+// serviceDeskPage writes a page of values, paged with start and limit as the Service Management API does
+func serviceDeskPage(w http.ResponseWriter, r *http.Request, values []interface{}) {
+	start, end := paginate(len(values), queryInt(r, "start", 0), queryInt(r, "limit", 50))
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"size":       end - start,
+		"start":      start,
+		"limit":      queryInt(r, "limit", 50),
+		"isLastPage": end == len(values),
+		"values":     append([]interface{}{}, values[start:end]...),
+	})
+}
+
+// serviceDeskDate renders a time as the Service Management API does
+func serviceDeskDate(t time.Time) map[string]interface{} {
+	return map[string]interface{}{
+		"iso8601":     t.UTC().Format("2006-01-02T15:04:05-0700"),
+		"jira":        t.UTC().Format(jiraTimeFormat),
+		"friendly":    t.UTC().Format("02/Jan/06 3:04 PM"),
+		"epochMillis": t.UnixMilli(),
+	}
+}
+
+// serviceDeskDuration renders a duration as the Service Management API does, e.g. -1h 30m
+func serviceDeskDuration(d time.Duration) map[string]interface{} {
+	sign := ""
+	if d < 0 {
+		sign, d = "-", -d
+	}
+	hours, minutes := int(d.Hours()), int(d.Minutes())%60
+	friendly := fmt.Sprintf("%s%dm", sign, minutes)
+	if hours > 0 {
+		friendly = fmt.Sprintf("%s%dh %dm", sign, hours, minutes)
+	}
+	millis := d.Milliseconds()
+	if sign != "" {
+		millis = -millis
+	}
+	return map[string]interface{}{"millis": millis, "friendly": friendly}
+}
+
+func (s *jiraState) serviceDeskJSON(desk *JiraServiceDeskFixture) map[string]interface{} {
+	result := map[string]interface{}{"id": desk.ID, "projectKey": desk.Project}
+	if project := s.project(desk.Project); project != nil {
+		result["projectId"] = project.ID
+		result["projectName"] = project.Name
+	}
+	return result
+}
+
+func (s *jiraState) requestTypeJSON(desk *JiraServiceDeskFixture, requestType *JiraRequestTypeFixture) map[string]interface{} {
+	return map[string]interface{}{
+		"id":            requestType.ID,
+		"name":          requestType.Name,
+		"description":   requestType.Description,
+		"helpText":      requestType.HelpText,
+		"issueTypeId":   s.issueTypeRef(requestType.IssueType)["id"],
+		"serviceDeskId": desk.ID,
+		"groupIds":      []string{},
+	}
+}
+
+// requestFields returns the fields of a request type's form, in form order
+func (s *jiraState) requestFields(requestType *JiraRequestTypeFixture) []jiraFieldMeta {
+	var fields []jiraFieldMeta
+	for _, id := range requestType.Fields {
+		for _, field := range s.fieldMetas(requestType.IssueType) {
+			if field.ID == id {
+				field.Required = false
+				for _, required := range requestType.Required {
+					field.Required = field.Required || required == id
+				}
+				fields = append(fields, field)
+			}
+		}
+	}
+	return fields
+}
+
+// customerRequestJSON renders an issue raised through a service desk as a customer request
+func (s *jiraState) customerRequestJSON(issue *jiraIssue, r *http.Request) map[string]interface{} {
+	desk := s.serviceDeskOf(nestedString(issue.Fields, "project", "key"))
+	requestType := desk.requestType(s.requestTypeOf[issue.Key])
+	created, _ := time.Parse(jiraTimeFormat, fmt.Sprint(issue.Fields["created"]))
+	updated, _ := time.Parse(jiraTimeFormat, fmt.Sprint(issue.Fields["updated"]))
+
+	values := []map[string]interface{}{}
+	if requestType != nil {
+		for _, field := range s.requestFields(requestType) {
+			if value, ok := issue.Fields[field.ID]; ok {
+				values = append(values, map[string]interface{}{"fieldId": field.ID, "label": field.Name, "value": value})
+			}
+		}
+	}
+
+	categories := map[string]string{"new": "NEW", "indeterminate": "INDETERMINATE", "done": "DONE"}
+	status := s.status(nestedString(issue.Fields, "status", "name"))
+	result := map[string]interface{}{
+		"issueId":            issue.ID,
+		"issueKey":           issue.Key,
+		"serviceDeskId":      desk.ID,
+		"createdDate":        serviceDeskDate(created),
+		"reporter":           issue.Fields["reporter"],
+		"requestFieldValues": values,
+		"currentStatus": map[string]interface{}{
+			"status":         status.Name,
+			"statusCategory": categories[status.Category],
+			"statusDate":     serviceDeskDate(updated),
+		},
+		"_links": map[string]interface{}{
+			"web":   fmt.Sprintf("https://%s/servicedesk/customer/portal/%s/%s", r.Host, desk.ID, issue.Key),
+			"agent": fmt.Sprintf("https://%s/browse/%s", r.Host, issue.Key),
+		},
+	}
+	if requestType != nil {
+		result["requestTypeId"] = requestType.ID
+		result["requestType"] = s.requestTypeJSON(desk, requestType)
+	}
+	return result
+}
+
+// request returns the service desk request addressed by the request, writing a 404 when there is none
+func (b *Backend) request(w http.ResponseWriter, r *http.Request) *jiraIssue {
+	issue := b.jira.issue(r.PathValue("key"))
+	if issue == nil || b.jira.serviceDeskOf(nestedString(issue.Fields, "project", "key")) == nil {
+		serviceDeskError(w, http.StatusNotFound, "The request could not be found or you do not have permission to see it.")
+		return nil
+	}
+	return issue
+}
+
+// pathServiceDesk returns the service desk addressed by the request, writing a 404 when there is none
+func (b *Backend) pathServiceDesk(w http.ResponseWriter, r *http.Request) *JiraServiceDeskFixture {
+	desk := b.jira.serviceDesk(r.PathValue("id"))
+	if desk == nil {
+		serviceDeskError(w, http.StatusNotFound, "The service desk does not exist or you do not have permission to see it.")
+	}
+	return desk
+}
+
+func (b *Backend) serviceDeskList(w http.ResponseWriter, r *http.Request) {
+	var desks []interface{}
+	for i := range b.jira.desks {
+		desks = append(desks, b.jira.serviceDeskJSON(&b.jira.desks[i]))
+	}
+	serviceDeskPage(w, r, desks)
+}
+
+func (b *Backend) serviceDeskGet(w http.ResponseWriter, r *http.Request) {
+	if desk := b.pathServiceDesk(w, r); desk != nil {
+		writeJSON(w, http.StatusOK, b.jira.serviceDeskJSON(desk))
+	}
+}
+
+func (b *Backend) serviceDeskRequestTypes(w http.ResponseWriter, r *http.Request) {
+	desk := b.pathServiceDesk(w, r)
+	if desk == nil {
+		return
+	}
+
+	query := strings.ToLower(r.URL.Query().Get("searchQuery"))
+	var types []interface{}
+	for i := range desk.RequestTypes {
+		if strings.Contains(strings.ToLower(desk.RequestTypes[i].Name), query) {
+			types = append(types, b.jira.requestTypeJSON(desk, &desk.RequestTypes[i]))
+		}
+	}
+	serviceDeskPage(w, r, types)
+}
+
+func (b *Backend) serviceDeskRequestTypeFields(w http.ResponseWriter, r *http.Request) {
+	desk := b.pathServiceDesk(w, r)
+	if desk == nil {
+		return
+	}
+	requestType := desk.requestType(r.PathValue("type"))
+	if requestType == nil {
+		serviceDeskError(w, http.StatusNotFound, "The request type does not exist.")
+		return
+	}
+
+	fields := []map[string]interface{}{}
+	for _, field := range b.jira.requestFields(requestType) {
+		valid := []map[string]interface{}{}
+		for _, allowed := range field.AllowedValues {
+			label, _ := allowed["value"].(string)
+			if label == "" {
+				label, _ = allowed["name"].(string)
+			}
+			valid = append(valid, map[string]interface{}{"value": allowed["id"], "label": label, "children": []interface{}{}})
+		}
+		fields = append(fields, map[string]interface{}{
+			"fieldId":     field.ID,
+			"name":        field.Name,
+			"required":    field.Required,
+			"visible":     true,
+			"validValues": valid,
+			"jiraSchema":  field.Schema,
+		})
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"requestTypeFields":         fields,
+		"canRaiseOnBehalfOf":        true,
+		"canAddRequestParticipants": true,
+	})
+}
+
+func (b *Backend) serviceDeskQueues(w http.ResponseWriter, r *http.Request) {
+	desk := b.pathServiceDesk(w, r)
+	if desk == nil {
+		return
+	}
+
+	var queues []interface{}
+	for _, queue := range desk.Queues {
+		result := map[string]interface{}{
+			"id":     queue.ID,
+			"name":   queue.Name,
+			"jql":    queue.JQL,
+			"fields": []string{"issuetype", "issuekey", "summary", "reporter", "assignee", "status", "created"},
+		}
+		if r.URL.Query().Get("includeCount") == "true" {
+			result["issueCount"] = len(b.jira.queueIssues(queue))
+		}
+		queues = append(queues, result)
+	}
+	serviceDeskPage(w, r, queues)
+}
+
+func (s *jiraState) queueIssues(queue JiraQueueFixture) []*jiraIssue {
+	query, err := parseJQL(queue.JQL)
+	if err != nil {
+		return nil
+	}
+
+	var matched []*jiraIssue
+	for _, issue := range s.issues {
+		if query.match(s, issue) {
+			matched = append(matched, issue)
+		}
+	}
+	query.sort(s, matched)
+	return matched
+}
+
+func (b *Backend) serviceDeskQueueIssues(w http.ResponseWriter, r *http.Request) {
+	desk := b.pathServiceDesk(w, r)
+	if desk == nil {
+		return
+	}
+
+	for _, queue := range desk.Queues {
+		if queue.ID == r.PathValue("queue") {
+			var issues []interface{}
+			for _, issue := range b.jira.queueIssues(queue) {
+				issues = append(issues, b.jira.render(issue, r))
+			}
+			serviceDeskPage(w, r, issues)
+			return
+		}
+	}
+	serviceDeskError(w, http.StatusNotFound, "The queue does not exist.")
+}
+
+// serviceDeskCreateRequest raises a request the way the customer portal does, checking the
+// request type's required fields and options
+func (b *Backend) serviceDeskCreateRequest(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		ServiceDeskID      string                 `json:"serviceDeskId"`
+		RequestTypeID      string                 `json:"requestTypeId"`
+		RequestFieldValues map[string]interface{} `json:"requestFieldValues"`
+		RaiseOnBehalfOf    string                 `json:"raiseOnBehalfOf"`
+	}
+	if err := decodeJSON(r, &payload); err != nil {
+		serviceDeskError(w, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+
+	desk := b.jira.serviceDesk(payload.ServiceDeskID)
+	if desk == nil {
+		serviceDeskError(w, http.StatusNotFound, "The service desk does not exist or you do not have permission to see it.")
+		return
+	}
+	requestType := desk.requestType(payload.RequestTypeID)
+	if requestType == nil {
+		serviceDeskError(w, http.StatusBadRequest, "The request type does not exist in this service desk.")
+		return
+	}
+
+	fields := b.jira.requestFields(requestType)
+	for id, value := range payload.RequestFieldValues {
+		known := false
+		for _, field := range fields {
+			if field.ID != id {
+				continue
+			}
+			known = true
+			if option, isOption := value.(map[string]interface{}); isOption && field.Schema["type"] == "option" && !allowsOption(field, option["value"]) {
+				serviceDeskError(w, http.StatusBadRequest, fmt.Sprintf("%s: Option value '%v' is not valid", field.Name, option["value"]))
+				return
+			}
+		}
+		if !known {
+			serviceDeskError(w, http.StatusBadRequest, fmt.Sprintf("The field '%s' is not on the form of request type %s.", id, requestType.Name))
+			return
+		}
+	}
+	for _, field := range fields {
+		if value, ok := payload.RequestFieldValues[field.ID]; field.Required && (!ok || value == nil || value == "") {
+			serviceDeskError(w, http.StatusBadRequest, field.Name+" is required.")
+			return
+		}
+	}
+
+	reporter := b.jira.currentUser
+	if payload.RaiseOnBehalfOf != "" {
+		user := b.jira.user(payload.RaiseOnBehalfOf)
+		if user == nil {
+			serviceDeskError(w, http.StatusBadRequest, "The user '"+payload.RaiseOnBehalfOf+"' does not exist.")
+			return
+		}
+		reporter = user.AccountID
+	}
+
+	b.jira.nextKey[desk.Project]++
+	now := b.timestamp()
+	issue := b.jira.addIssue(JiraIssueFixture{
+		Key:         fmt.Sprintf("%s-%d", desk.Project, b.jira.nextKey[desk.Project]),
+		Type:        requestType.IssueType,
+		Reporter:    reporter,
+		RequestType: requestType.ID,
+		Created:     now,
+		Updated:     now,
+	})
+	b.jira.applyFields(issue, payload.RequestFieldValues, now)
+	issue.Changelog = nil
+
+	writeJSON(w, http.StatusCreated, b.jira.customerRequestJSON(issue, r))
+}
+
+func (b *Backend) serviceDeskGetRequest(w http.ResponseWriter, r *http.Request) {
+	if issue := b.request(w, r); issue != nil {
+		writeJSON(w, http.StatusOK, b.jira.customerRequestJSON(issue, r))
+	}
+}
+
+// serviceDeskAddComment adds a comment that customers see when public, or only agents see otherwise
+func (b *Backend) serviceDeskAddComment(w http.ResponseWriter, r *http.Request) {
+	issue := b.request(w, r)
+	if issue == nil {
+		return
+	}
+
+	var payload struct {
+		Body   string `json:"body"`
+		Public bool   `json:"public"`
+	}
+	if err := decodeJSON(r, &payload); err != nil {
+		serviceDeskError(w, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+	if strings.TrimSpace(payload.Body) == "" {
+		serviceDeskError(w, http.StatusBadRequest, "The comment body must not be empty.")
+		return
+	}
+
+	now := b.now()
+	comment := b.jira.addComment(issue, b.jira.currentUser, payload.Body, now.UTC().Format(jiraTimeFormat))
+	comment["jsdPublic"] = payload.Public
+	issue.Fields["updated"] = comment["created"]
+
+	writeJSON(w, http.StatusCreated, map[string]interface{}{
+		"id":      comment["id"],
+		"body":    payload.Body,
+		"public":  payload.Public,
+		"author":  comment["author"],
+		"created": serviceDeskDate(now),
+	})
+}
+
+// slaStop returns when the SLA of a request stopped counting, if it has
+func (s *jiraState) slaStop(issue *jiraIssue, sla JiraSLAFixture) (time.Time, bool) {
+	var stop string
+	switch sla.Stop {
+	case "response":
+		reporter := nestedString(issue.Fields, "reporter", "accountId")
+		for _, comment := range issue.Comments {
+			author, _ := comment["author"].(map[string]interface{})
+			if comment["jsdPublic"] != false && author != nil && author["accountId"] != reporter {
+				stop = fmt.Sprint(comment["created"])
+				break
+			}
+		}
+	default:
+		if status := s.status(nestedString(issue.Fields, "status", "name")); status != nil && status.Category == "done" {
+			stop = fmt.Sprint(issue.Fields["updated"])
+			if resolved, ok := issue.Fields["resolutiondate"].(string); ok {
+				stop = resolved
+			}
+		}
+	}
+
+	if stop == "" {
+		return time.Time{}, false
+	}
+	stopped, err := time.Parse(jiraTimeFormat, stop)
+	return stopped, err == nil
+}
+
+func (b *Backend) serviceDeskSLAs(w http.ResponseWriter, r *http.Request) {
+	issue := b.request(w, r)
+	if issue == nil {
+		return
+	}
+
+	desk := b.jira.serviceDeskOf(nestedString(issue.Fields, "project", "key"))
+	created, _ := time.Parse(jiraTimeFormat, fmt.Sprint(issue.Fields["created"]))
+	var slas []interface{}
+	for _, sla := range desk.SLAs {
+		seconds, _ := jiraWorkSeconds(sla.Goal)
+		goal := time.Duration(seconds) * time.Second
+
+		stop, stopped := b.jira.slaStop(issue, sla)
+		end := b.now()
+		if stopped {
+			end = stop
+		}
+		elapsed := end.Sub(created)
+
+		cycle := map[string]interface{}{
+			"startTime":     serviceDeskDate(created),
+			"breachTime":    serviceDeskDate(created.Add(goal)),
+			"breached":      elapsed > goal,
+			"goalDuration":  serviceDeskDuration(goal),
+			"elapsedTime":   serviceDeskDuration(elapsed),
+			"remainingTime": serviceDeskDuration(goal - elapsed),
+		}
+		result := map[string]interface{}{
+			"id":              sla.ID,
+			"name":            sla.Name,
+			"completedCycles": []interface{}{},
+		}
+		if stopped {
+			cycle["stopTime"] = serviceDeskDate(stop)
+			result["completedCycles"] = []interface{}{cycle}
+		} else {
+			cycle["paused"] = false
+			cycle["withinCalendarHours"] = true
+			result["ongoingCycle"] = cycle
+		}
+		slas = append(slas, result)
+	}
+	serviceDeskPage(w, r, slas)
+}
@@ -0,0 +1,414 @@
+package fake
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// jqlQuery is a parsed subset of JQL: field clauses combined with AND, OR, NOT and
+// parentheses, followed by an optional ORDER BY.
+type jqlQuery struct {
+	match   func(*jiraState, *jiraIssue) bool
+	orderBy []jqlOrder
+}
+
+type jqlOrder struct {
+	field string
+	desc  bool
+}
+
+type jqlToken struct {
+	text   string
+	quoted bool
+}
+
+type jqlParser struct {
+	tokens []jqlToken
+	pos    int
+}
+
+var jqlFields = map[string]bool{
+	"project": true, "key": true, "issuekey": true, "id": true, "summary": true, "description": true,
+	"text": true, "status": true, "statuscategory": true, "issuetype": true, "type": true,
+	"assignee": true, "reporter": true, "priority": true, "labels": true, "parent": true,
+	"sprint": true, "created": true, "updated": true, "resolution": true,
+	"worklogdate": true, "fixversion": true, "component": true,
+}
+
+func tokenizeJQL(input string) ([]jqlToken, error) {
+	var tokens []jqlToken
+	runes := []rune(input)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '"' || r == '\'':
+			end := i + 1
+			var sb strings.Builder
+			for end < len(runes) && runes[end] != r {
+				if runes[end] == '\\' && end+1 < len(runes) {
+					end++
+				}
+				sb.WriteRune(runes[end])
+				end++
+			}
+			if end >= len(runes) {
+				return nil, fmt.Errorf("unterminated string starting at position %d", i)
+			}
+			tokens = append(tokens, jqlToken{text: sb.String(), quoted: true})
+			i = end + 1
+		case r == '(' || r == ')' || r == ',':
+			tokens = append(tokens, jqlToken{text: string(r)})
+			i++
+		case r == '!' || r == '=' || r == '~' || r == '<' || r == '>':
+			end := i + 1
+			if end < len(runes) && (runes[end] == '=' || runes[end] == '~') {
+				end++
+			}
+			tokens = append(tokens, jqlToken{text: string(runes[i:end])})
+			i = end
+		default:
+			end := i
+			for end < len(runes) && !unicode.IsSpace(runes[end]) && !strings.ContainsRune(`()",'=!~<>`, runes[end]) {
+				end++
+			}
+			word := string(runes[i:end])
+			// keep function calls such as currentUser() as a single token
+			if end+1 < len(runes) && runes[end] == '(' && runes[end+1] == ')' {
+				word += "()"
+				end += 2
+			}
+			tokens = append(tokens, jqlToken{text: word})
+			i = end
+		}
+	}
+
+	return tokens, nil
+}
+
+func parseJQL(input string) (*jqlQuery, error) {
+	tokens, err := tokenizeJQL(input)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &jqlParser{tokens: tokens}
+	query := &jqlQuery{match: func(*jiraState, *jiraIssue) bool { return true }}
+
+	if !p.atKeyword("order") && !p.done() {
+		query.match, err = p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if p.atKeyword("order") {
+		p.pos++
+		if !p.atKeyword("by") {
+			return nil, fmt.Errorf("expected BY after ORDER")
+		}
+		p.pos++
+		for {
+			field, ok := p.next()
+			if !ok {
+				return nil, fmt.Errorf("expected a field after ORDER BY")
+			}
+			order := jqlOrder{field: strings.ToLower(field.text)}
+			if p.atKeyword("desc") {
+				order.desc = true
+				p.pos++
+			} else if p.atKeyword("asc") {
+				p.pos++
+			}
+			query.orderBy = append(query.orderBy, order)
+			if !p.atSymbol(",") {
+				break
+			}
+			p.pos++
+		}
+	}
+
+	if !p.done() {
+		return nil, fmt.Errorf("unexpected token %q", p.tokens[p.pos].text)
+	}
+
+	return query, nil
+}
+
+func (p *jqlParser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *jqlParser) next() (jqlToken, bool) {
+	if p.done() {
+		return jqlToken{}, false
+	}
+	token := p.tokens[p.pos]
+	p.pos++
+	return token, true
+}
+
+func (p *jqlParser) atKeyword(keyword string) bool {
+	return !p.done() && !p.tokens[p.pos].quoted && strings.EqualFold(p.tokens[p.pos].text, keyword)
+}
+
+func (p *jqlParser) atSymbol(symbol string) bool {
+	return !p.done() && !p.tokens[p.pos].quoted && p.tokens[p.pos].text == symbol
+}
+
+type jqlMatcher = func(*jiraState, *jiraIssue) bool
+
+func (p *jqlParser) parseOr() (jqlMatcher, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.atKeyword("or") {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(s *jiraState, i *jiraIssue) bool { return l(s, i) || right(s, i) }
+	}
+	return left, nil
+}
+
+func (p *jqlParser) parseAnd() (jqlMatcher, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.atKeyword("and") {
+		p.pos++
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(s *jiraState, i *jiraIssue) bool { return l(s, i) && right(s, i) }
+	}
+	return left, nil
+}
+
+func (p *jqlParser) parseNot() (jqlMatcher, error) {
+	if p.atKeyword("not") {
+		p.pos++
+		inner, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return func(s *jiraState, i *jiraIssue) bool { return !inner(s, i) }, nil
+	}
+	if p.atSymbol("(") {
+		p.pos++
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.atSymbol(")") {
+			return nil, fmt.Errorf("expected )")
+		}
+		p.pos++
+		return inner, nil
+	}
+	return p.parseClause()
+}
+
+func (p *jqlParser) parseClause() (jqlMatcher, error) {
+	fieldToken, ok := p.next()
+	if !ok {
+		return nil, fmt.Errorf("expected a field")
+	}
+	field := strings.ToLower(fieldToken.text)
+	if !jqlFields[field] {
+		return nil, fmt.Errorf("Field '%s' does not exist or you do not have permission to view it.", fieldToken.text)
+	}
+
+	opToken, ok := p.next()
+	if !ok {
+		return nil, fmt.Errorf("expected an operator after %s", fieldToken.text)
+	}
+	op := strings.ToLower(opToken.text)
+
+	switch op {
+	case "not":
+		if !p.atKeyword("in") {
+			return nil, fmt.Errorf("expected IN after NOT")
+		}
+		p.pos++
+		op = "not in"
+	case "is":
+		if p.atKeyword("not") {
+			p.pos++
+			op = "is not"
+		}
+	}
+
+	var values []string
+	switch op {
+	case "in", "not in":
+		if !p.atSymbol("(") {
+			return nil, fmt.Errorf("expected ( after %s", strings.ToUpper(op))
+		}
+		p.pos++
+		for !p.atSymbol(")") {
+			value, ok := p.next()
+			if !ok {
+				return nil, fmt.Errorf("expected )")
+			}
+			if value.text != "," || value.quoted {
+				values = append(values, value.text)
+			}
+		}
+		p.pos++
+	case "=", "!=", "~", "!~", "is", "is not", ">", ">=", "<", "<=":
+		value, ok := p.next()
+		if !ok {
+			return nil, fmt.Errorf("expected a value after %s", opToken.text)
+		}
+		values = []string{value.text}
+	default:
+		return nil, fmt.Errorf("unsupported operator %q", opToken.text)
+	}
+
+	return func(s *jiraState, issue *jiraIssue) bool {
+		actual := s.fieldValues(issue, field)
+		expected := s.resolveJQLValues(values)
+
+		switch op {
+		case "=", "in":
+			return anyEqual(actual, expected)
+		case "!=", "not in":
+			return !anyEqual(actual, expected)
+		case "is":
+			return len(actual) == 0
+		case "is not":
+			return len(actual) > 0
+		case "~":
+			return anyContains(actual, expected)
+		case "!~":
+			return !anyContains(actual, expected)
+		default:
+			return anyCompare(s.comparable(field, actual), s.comparable(field, expected), op)
+		}
+	}, nil
+}
+
+// resolveJQLValues expands JQL functions into concrete values
+func (s *jiraState) resolveJQLValues(values []string) []string {
+	var resolved []string
+	for _, value := range values {
+		switch strings.ToLower(value) {
+		case "empty", "null":
+			resolved = append(resolved, "")
+		case "currentuser()":
+			resolved = append(resolved, s.currentUser)
+		case "opensprints()":
+			for _, sprint := range s.sprints {
+				if sprint.State == "active" || sprint.State == "future" {
+					resolved = append(resolved, fmt.Sprint(sprint.ID))
+				}
+			}
+		case "closedsprints()":
+			for _, sprint := range s.sprints {
+				if sprint.State == "closed" {
+					resolved = append(resolved, fmt.Sprint(sprint.ID))
+				}
+			}
+		default:
+			resolved = append(resolved, value)
+		}
+	}
+	return resolved
+}
+
+func anyEqual(actual, expected []string) bool {
+	for _, a := range actual {
+		for _, e := range expected {
+			if strings.EqualFold(a, e) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func anyContains(actual, expected []string) bool {
+	for _, a := range actual {
+		for _, e := range expected {
+			if strings.Contains(strings.ToLower(a), strings.ToLower(strings.Trim(e, "*"))) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// comparable turns field values into strings that compare in the order Jira sorts them.
+// Priorities follow the configured order rather than their names.
+func (s *jiraState) comparable(field string, values []string) []string {
+	if field != "priority" {
+		return values
+	}
+	ranks := make([]string, 0, len(values))
+	for _, value := range values {
+		ranks = append(ranks, fmt.Sprintf("%04d", s.priorityRank(value)))
+	}
+	return ranks
+}
+
+func anyCompare(actual, expected []string, op string) bool {
+	for _, a := range actual {
+		for _, e := range expected {
+			cmp := strings.Compare(a, e)
+			switch op {
+			case ">":
+				if cmp > 0 {
+					return true
+				}
+			case ">=":
+				if cmp >= 0 {
+					return true
+				}
+			case "<":
+				if cmp < 0 {
+					return true
+				}
+			case "<=":
+				if cmp <= 0 {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+func (q *jqlQuery) sort(s *jiraState, issues []*jiraIssue) {
+	if len(q.orderBy) == 0 {
+		return
+	}
+	sort.SliceStable(issues, func(i, j int) bool {
+		for _, order := range q.orderBy {
+			a := strings.Join(s.comparable(order.field, s.fieldValues(issues[i], order.field)), ",")
+			b := strings.Join(s.comparable(order.field, s.fieldValues(issues[j], order.field)), ",")
+			if order.field == "key" || order.field == "issuekey" || order.field == "id" {
+				a, b = fmt.Sprintf("%010s", issues[i].ID), fmt.Sprintf("%010s", issues[j].ID)
+			}
+			if a == b {
+				continue
+			}
+			if order.desc {
+				return a > b
+			}
+			return a < b
+		}
+		return false
+	})
+}
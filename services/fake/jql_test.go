@@ -0,0 +1,79 @@
+package fake
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func searchKeys(t *testing.T, b *Backend, jql string) []string {
+	t.Helper()
+
+	recorder := httptest.NewRecorder()
+	b.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/rest/api/2/search?fields=priority&jql="+url.QueryEscape(jql), nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("search %q: status %d: %s", jql, recorder.Code, recorder.Body.String())
+	}
+
+	var result struct {
+		Issues []struct {
+			Key string `json:"key"`
+		} `json:"issues"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &result); err != nil {
+		t.Fatalf("search %q: %v", jql, err)
+	}
+	keys := []string{}
+	for _, issue := range result.Issues {
+		keys = append(keys, issue.Key)
+	}
+	return keys
+}
+
+func TestJQLPriorityOrder(t *testing.T) {
+	fixture, err := LoadFixture("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := New(fixture)
+
+	tests := []struct {
+		jql  string
+		want []string
+	}{
+		{`project = KP AND priority != Medium ORDER BY priority DESC`, []string{"KP-5", "KP-1", "KP-4"}},
+		{`project = KP AND priority != Medium ORDER BY priority ASC`, []string{"KP-4", "KP-1", "KP-5"}},
+		{`project = KP AND priority >= High ORDER BY key`, []string{"KP-1", "KP-5"}},
+		{`project = KP AND priority < Medium`, []string{"KP-4"}},
+	}
+	for _, test := range tests {
+		if got := searchKeys(t, b, test.jql); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: got %v, want %v", test.jql, got, test.want)
+		}
+	}
+}
+
+func TestJQLConfiguredPriorityOrder(t *testing.T) {
+	fixture := &Fixture{Jira: JiraFixture{
+		Projects:   []JiraProjectFixture{{ID: "10000", Key: "OPS", Name: "Operations", IssueTypes: []string{"Task"}}},
+		Statuses:   []JiraStatusFixture{{ID: "1", Name: "Open", Category: "new"}},
+		Priorities: []JiraPriorityFixture{{ID: "1", Name: "Urgent"}, {ID: "2", Name: "Normal"}, {ID: "3", Name: "Backlog"}},
+		Issues: []JiraIssueFixture{
+			{Key: "OPS-1", Type: "Task", Summary: "a", Status: "Open", Priority: "Normal"},
+			{Key: "OPS-2", Type: "Task", Summary: "b", Status: "Open", Priority: "Backlog"},
+			{Key: "OPS-3", Type: "Task", Summary: "c", Status: "Open", Priority: "Urgent"},
+		},
+	}}
+	b := New(fixture)
+
+	// Alphabetically Backlog < Normal < Urgent, which is not the configured order
+	if got, want := searchKeys(t, b, "project = OPS ORDER BY priority DESC"), []string{"OPS-3", "OPS-1", "OPS-2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if got, want := searchKeys(t, b, "project = OPS AND priority > Backlog ORDER BY priority"), []string{"OPS-1", "OPS-3"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
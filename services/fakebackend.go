@@ -0,0 +1,25 @@
+package services
+
+import (
+	"github.com/nguyenvanduocit/dev-kit/services/fake"
+)
+
+var fakeBackend *fake.Backend
+
+// EnableFakeBackend serves every upstream API from an in-memory fake seeded with the
+// fixture at path, or with the built-in demo fixture when path is empty.
+// It must be called before any client is created.
+func EnableFakeBackend(path string) error {
+	fixture, err := fake.LoadFixture(path)
+	if err != nil {
+		return err
+	}
+	fakeBackend = fake.New(fixture)
+	return nil
+}
+
+// IsOffline reports whether upstream traffic is served without the network,
+// either from cassettes or from the fake backend
+func IsOffline() bool {
+	return replayDir != "" || fakeBackend != nil
+}
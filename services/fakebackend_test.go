@@ -0,0 +1,184 @@
+package services
+
+import (
+	"context"
+	"log"
+	"os"
+	"testing"
+
+	"github.com/ctreminiom/go-atlassian/pkg/infra/models"
+	"github.com/google/go-github/v60/github"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+func TestMain(m *testing.M) {
+	if err := EnableFakeBackend(""); err != nil {
+		log.Fatal(err)
+	}
+	os.Exit(m.Run())
+}
+
+func TestFakeJiraIssueAndTransition(t *testing.T) {
+	ctx := context.Background()
+	client := JiraClient()
+
+	created, _, err := client.Issue.Create(ctx, &models.IssueSchemeV2{
+		Fields: &models.IssueFieldsSchemeV2{
+			Project:   &models.ProjectScheme{Key: "KP"},
+			IssueType: &models.IssueTypeScheme{Name: "Task"},
+			Summary:   "Exercise the fake backend",
+		},
+	}, nil)
+	if err != nil {
+		t.Fatalf("create issue: %v", err)
+	}
+
+	issue, _, err := client.Issue.Get(ctx, created.Key, nil, []string{"transitions"})
+	if err != nil {
+		t.Fatalf("get issue: %v", err)
+	}
+	if issue.Fields.Summary != "Exercise the fake backend" || issue.Fields.Status.Name != "To Do" {
+		t.Fatalf("got %q in %q, want the new summary in To Do", issue.Fields.Summary, issue.Fields.Status.Name)
+	}
+
+	if _, err := client.Issue.Move(ctx, created.Key, "21", nil); err != nil {
+		t.Fatalf("transition issue: %v", err)
+	}
+	issue, _, err = client.Issue.Get(ctx, created.Key, []string{"status"}, nil)
+	if err != nil {
+		t.Fatalf("get issue: %v", err)
+	}
+	if issue.Fields.Status.Name != "In Progress" {
+		t.Errorf("status after Start Progress is %q, want In Progress", issue.Fields.Status.Name)
+	}
+
+	if _, err := client.Issue.Move(ctx, "KP-2", "21", nil); err == nil {
+		t.Errorf("Start Progress from Done succeeded, want an error")
+	}
+}
+
+func TestFakeJiraSprint(t *testing.T) {
+	ctx := context.Background()
+	client := AgileClient()
+
+	sprint, _, err := client.Sprint.Get(ctx, 2)
+	if err != nil {
+		t.Fatalf("get sprint: %v", err)
+	}
+	if sprint.Name != "KP Sprint 2" || sprint.State != "active" {
+		t.Fatalf("got sprint %q (%s), want the active KP Sprint 2", sprint.Name, sprint.State)
+	}
+
+	if _, err := client.Sprint.Move(ctx, 3, &models.SprintMovePayloadScheme{Issues: []string{"KP-6"}}); err != nil {
+		t.Fatalf("move issue to sprint: %v", err)
+	}
+	page, _, err := client.Sprint.Issues(ctx, 3, nil, 0, 50)
+	if err != nil {
+		t.Fatalf("list sprint issues: %v", err)
+	}
+	if len(page.Issues) != 1 || page.Issues[0].Key != "KP-6" {
+		t.Errorf("sprint 3 holds %d issues, want only KP-6", len(page.Issues))
+	}
+}
+
+func TestFakeConfluencePage(t *testing.T) {
+	ctx := context.Background()
+	client := ConfluenceClient()
+
+	page, _, err := client.Content.Get(ctx, "65537", []string{"body.storage"}, 1)
+	if err != nil {
+		t.Fatalf("get page: %v", err)
+	}
+	if page.Title != "Engineering Home" {
+		t.Errorf("got page %q, want Engineering Home", page.Title)
+	}
+
+	created, _, err := client.Content.Create(ctx, &models.ContentScheme{
+		Type:      "page",
+		Title:     "Fake backend notes",
+		Space:     &models.SpaceScheme{Key: "ENG"},
+		Ancestors: []*models.ContentScheme{{ID: "65537"}},
+		Body:      &models.BodyScheme{Storage: &models.BodyNodeScheme{Value: "<p>Hello</p>", Representation: "storage"}},
+	})
+	if err != nil {
+		t.Fatalf("create page: %v", err)
+	}
+
+	page, _, err = client.Content.Get(ctx, created.ID, []string{"body.storage"}, 1)
+	if err != nil {
+		t.Fatalf("get created page: %v", err)
+	}
+	if page.Body == nil || page.Body.Storage == nil || page.Body.Storage.Value != "<p>Hello</p>" {
+		t.Errorf("created page does not keep its body")
+	}
+}
+
+func TestFakeGitHubPullRequests(t *testing.T) {
+	ctx := context.Background()
+	client := github.NewClient(DefaultHttpClient()).WithAuthToken("offline")
+
+	pulls, _, err := client.PullRequests.List(ctx, "acme", "dev-kit", &github.PullRequestListOptions{State: "all"})
+	if err != nil {
+		t.Fatalf("list pull requests: %v", err)
+	}
+	if len(pulls) != 2 {
+		t.Fatalf("got %d pull requests, want 2", len(pulls))
+	}
+
+	pull, _, err := client.PullRequests.Get(ctx, "acme", "dev-kit", 43)
+	if err != nil {
+		t.Fatalf("get pull request: %v", err)
+	}
+	if pull.GetTitle() != "Add fake backends" || pull.GetState() != "open" {
+		t.Errorf("got %q (%s), want the open Add fake backends", pull.GetTitle(), pull.GetState())
+	}
+
+	if _, _, err := client.Issues.CreateComment(ctx, "acme", "dev-kit", 43, &github.IssueComment{Body: github.String("Looks good")}); err != nil {
+		t.Fatalf("comment on pull request: %v", err)
+	}
+	comments, _, err := client.Issues.ListComments(ctx, "acme", "dev-kit", 43, nil)
+	if err != nil {
+		t.Fatalf("list comments: %v", err)
+	}
+	if len(comments) == 0 || comments[len(comments)-1].GetBody() != "Looks good" {
+		t.Errorf("the new comment is not listed")
+	}
+}
+
+func TestFakeGitLabMergeRequests(t *testing.T) {
+	client, err := gitlab.NewClient("offline", gitlab.WithBaseURL("https://offline.gitlab.invalid"), gitlab.WithHTTPClient(DefaultHttpClient()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mrs, _, err := client.MergeRequests.ListProjectMergeRequests("acme/api", &gitlab.ListProjectMergeRequestsOptions{})
+	if err != nil {
+		t.Fatalf("list merge requests: %v", err)
+	}
+	if len(mrs) != 1 || mrs[0].IID != 7 {
+		t.Fatalf("got %d merge requests, want only !7", len(mrs))
+	}
+
+	if _, _, err := client.Notes.CreateMergeRequestNote("acme/api", 7, &gitlab.CreateMergeRequestNoteOptions{Body: gitlab.Ptr("Configurable in v2")}); err != nil {
+		t.Fatalf("create note: %v", err)
+	}
+	notes, _, err := client.Notes.ListMergeRequestNotes("acme/api", 7, &gitlab.ListMergeRequestNotesOptions{})
+	if err != nil {
+		t.Fatalf("list notes: %v", err)
+	}
+	found := false
+	for _, note := range notes {
+		found = found || note.Body == "Configurable in v2"
+	}
+	if len(notes) < 2 || !found {
+		t.Errorf("got %d notes without the new one", len(notes))
+	}
+
+	pipelines, _, err := client.Pipelines.ListProjectPipelines("acme/api", &gitlab.ListProjectPipelinesOptions{Status: gitlab.Ptr(gitlab.Failed)})
+	if err != nil {
+		t.Fatalf("list pipelines: %v", err)
+	}
+	if len(pipelines) != 1 || pipelines[0].ID != 9002 {
+		t.Errorf("got %d failed pipelines, want only 9002", len(pipelines))
+	}
+}
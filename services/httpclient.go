@@ -10,6 +10,25 @@ import (
 )
 
 var DefaultHttpClient = sync.OnceValue(func() *http.Client {
+	if fakeBackend != nil {
+		return &http.Client{Transport: fakeBackend}
+	}
+
+	if replay != nil {
+		return &http.Client{Transport: replay}
+	}
+
+	transport := proxyTransport()
+
+	if recordDir != "" {
+		return &http.Client{Transport: newRecordingTransport(transport, recordDir)}
+	}
+
+	return &http.Client{Transport: transport}
+})
+
+// proxyTransport is the transport to the real services, going through PROXY_URL when it is set
+var proxyTransport = sync.OnceValue(func() *http.Transport {
 	transport := &http.Transport{}
 
 	proxyURL := os.Getenv("PROXY_URL")
@@ -22,5 +41,5 @@ var DefaultHttpClient = sync.OnceValue(func() *http.Client {
 		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
 	}
 
-	return &http.Client{Transport: transport}
+	return transport
 })
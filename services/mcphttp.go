@@ -0,0 +1,291 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// MCPHTTPClient talks to an MCP server over the streamable HTTP transport. Every request is a
+// POST whose answer is either a JSON-RPC response or an event stream carrying it.
+type MCPHTTPClient struct {
+	url        string
+	headers    map[string]string
+	httpClient *http.Client
+	requestID  atomic.Int64
+
+	mu        sync.Mutex
+	sessionID string
+}
+
+type jsonRPCResponse struct {
+	ID     *int64          `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// mcpHTTPTimeout bounds a whole request to a proxied server, including a streamed answer
+const mcpHTTPTimeout = 2 * time.Minute
+
+// NewMCPHTTPClient creates a client for the MCP endpoint at url. headers are sent with every request.
+// Requests go through PROXY_URL but never through the fake backend, cassettes or the recorder, as
+// extensions are not part of the recorded services and their headers may carry credentials.
+func NewMCPHTTPClient(url string, headers map[string]string) *MCPHTTPClient {
+	return &MCPHTTPClient{
+		url:        url,
+		headers:    headers,
+		httpClient: &http.Client{Transport: proxyTransport(), Timeout: mcpHTTPTimeout},
+	}
+}
+
+func (c *MCPHTTPClient) Initialize(ctx context.Context, request mcp.InitializeRequest) (*mcp.InitializeResult, error) {
+	var result mcp.InitializeResult
+	if err := c.call(ctx, "initialize", request.Params, &result); err != nil {
+		return nil, err
+	}
+
+	if err := c.notify(ctx, "notifications/initialized"); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func (c *MCPHTTPClient) ListTools(ctx context.Context, request mcp.ListToolsRequest) (*mcp.ListToolsResult, error) {
+	var result mcp.ListToolsResult
+	if err := c.call(ctx, "tools/list", request.Params, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (c *MCPHTTPClient) CallTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	raw, err := c.request(ctx, "tools/call", request.Params)
+	if err != nil {
+		return nil, err
+	}
+	result, err := mcp.ParseCallToolResult(&raw)
+	if err != nil {
+		return nil, fmt.Errorf("tools/call: failed to decode result: %v", err)
+	}
+	return result, nil
+}
+
+func (c *MCPHTTPClient) ListPrompts(ctx context.Context, request mcp.ListPromptsRequest) (*mcp.ListPromptsResult, error) {
+	var result mcp.ListPromptsResult
+	if err := c.call(ctx, "prompts/list", request.Params, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (c *MCPHTTPClient) GetPrompt(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	raw, err := c.request(ctx, "prompts/get", request.Params)
+	if err != nil {
+		return nil, err
+	}
+	result, err := mcp.ParseGetPromptResult(&raw)
+	if err != nil {
+		return nil, fmt.Errorf("prompts/get: failed to decode result: %v", err)
+	}
+	return result, nil
+}
+
+func (c *MCPHTTPClient) ListResources(ctx context.Context, request mcp.ListResourcesRequest) (*mcp.ListResourcesResult, error) {
+	var result mcp.ListResourcesResult
+	if err := c.call(ctx, "resources/list", request.Params, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (c *MCPHTTPClient) ListResourceTemplates(ctx context.Context, request mcp.ListResourceTemplatesRequest) (*mcp.ListResourceTemplatesResult, error) {
+	var result mcp.ListResourceTemplatesResult
+	if err := c.call(ctx, "resources/templates/list", request.Params, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (c *MCPHTTPClient) ReadResource(ctx context.Context, request mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	raw, err := c.request(ctx, "resources/read", request.Params)
+	if err != nil {
+		return nil, err
+	}
+	result, err := mcp.ParseReadResourceResult(&raw)
+	if err != nil {
+		return nil, fmt.Errorf("resources/read: failed to decode result: %v", err)
+	}
+	return result, nil
+}
+
+// Close ends the session on the server, if it started one
+func (c *MCPHTTPClient) Close() error {
+	c.mu.Lock()
+	sessionID := c.sessionID
+	c.mu.Unlock()
+	if sessionID == "" {
+		return nil
+	}
+
+	req, err := http.NewRequest(http.MethodDelete, c.url, nil)
+	if err != nil {
+		return err
+	}
+	c.setHeaders(req)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+func (c *MCPHTTPClient) call(ctx context.Context, method string, params interface{}, result interface{}) error {
+	raw, err := c.request(ctx, method, params)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(raw, result); err != nil {
+		return fmt.Errorf("%s: failed to decode result: %v", method, err)
+	}
+	return nil
+}
+
+// request sends a JSON-RPC request and returns its raw result. Results holding content
+// interfaces are decoded with the mcp.Parse* helpers, everything else through call.
+func (c *MCPHTTPClient) request(ctx context.Context, method string, params interface{}) (json.RawMessage, error) {
+	id := c.requestID.Add(1)
+	resp, err := c.post(ctx, map[string]interface{}{
+		"jsonrpc": mcp.JSONRPC_VERSION,
+		"id":      id,
+		"method":  method,
+		"params":  params,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	response, err := readJSONRPCResponse(resp, id)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", method, err)
+	}
+	if response.Error != nil {
+		return nil, fmt.Errorf("%s: %s (code %d)", method, response.Error.Message, response.Error.Code)
+	}
+	return response.Result, nil
+}
+
+func (c *MCPHTTPClient) notify(ctx context.Context, method string) error {
+	resp, err := c.post(ctx, map[string]interface{}{
+		"jsonrpc": mcp.JSONRPC_VERSION,
+		"method":  method,
+	})
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+func (c *MCPHTTPClient) post(ctx context.Context, message interface{}) (*http.Response, error) {
+	body, err := json.Marshal(message)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	c.setHeaders(req)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode >= 300 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		resp.Body.Close()
+		return nil, fmt.Errorf("unexpected status %s: %s", resp.Status, strings.TrimSpace(string(data)))
+	}
+
+	if sessionID := resp.Header.Get("Mcp-Session-Id"); sessionID != "" {
+		c.mu.Lock()
+		c.sessionID = sessionID
+		c.mu.Unlock()
+	}
+
+	return resp, nil
+}
+
+func (c *MCPHTTPClient) setHeaders(req *http.Request) {
+	for key, value := range c.headers {
+		req.Header.Set(key, value)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.sessionID != "" {
+		req.Header.Set("Mcp-Session-Id", c.sessionID)
+	}
+}
+
+// readJSONRPCResponse finds the response to request id in a JSON body or an event stream
+func readJSONRPCResponse(resp *http.Response, id int64) (*jsonRPCResponse, error) {
+	if !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		var response jsonRPCResponse
+		if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+			return nil, fmt.Errorf("failed to decode response: %v", err)
+		}
+		return &response, nil
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	var data strings.Builder
+	for scanner.Scan() {
+		line := scanner.Text()
+		if value, ok := strings.CutPrefix(line, "data:"); ok {
+			data.WriteString(strings.TrimPrefix(value, " "))
+			continue
+		}
+		if line != "" || data.Len() == 0 {
+			continue
+		}
+
+		// A blank line ends the event; anything but our response is a notification or server request
+		var response jsonRPCResponse
+		err := json.Unmarshal([]byte(data.String()), &response)
+		data.Reset()
+		if err == nil && response.ID != nil && *response.ID == id {
+			return &response, nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	var response jsonRPCResponse
+	if data.Len() > 0 && json.Unmarshal([]byte(data.String()), &response) == nil && response.ID != nil && *response.ID == id {
+		return &response, nil
+	}
+
+	return nil, fmt.Errorf("event stream ended without a response")
+}
@@ -122,6 +122,15 @@ func (s *SequentialThinkingServer) processThought(input map[string]interface{})
 	return mcp.NewToolResultText(string(jsonResponse)), nil
 }
 
+func init() {
+	RegisterGroup(ToolGroup{
+		Name:        "codereview",
+		Description: "Step-by-step reasoning for code reviews",
+		Access:      AccessRead,
+		Register:    infallible(RegisterCodeReviewTool),
+	})
+}
+
 func RegisterCodeReviewTool(s *server.MCPServer) {
 	thinkingServer := NewSequentialThinkingServer()
 
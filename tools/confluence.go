@@ -3,7 +3,6 @@ package tools
 import (
 	"context"
 	"fmt"
-	"strconv"
 	"time"
 
 	htmltomarkdown "github.com/JohannesKaufmann/html-to-markdown/v2"
@@ -15,6 +14,17 @@ import (
 	"github.com/nguyenvanduocit/dev-kit/util"
 )
 
+func init() {
+	RegisterGroup(ToolGroup{
+		Name:            "confluence",
+		Description:     "Search, read and edit Confluence pages",
+		RequiredEnvFunc: services.AtlassianRequiredEnv,
+		Offline:         true,
+		Access:          AccessWrite,
+		Register:        infallible(RegisterConfluenceTool),
+	})
+}
+
 // registerConfluenceTool is a function that registers the confluence tools to the server
 func RegisterConfluenceTool(s *server.MCPServer) {
 	tool := mcp.NewTool("confluence_search",
@@ -22,14 +32,14 @@ func RegisterConfluenceTool(s *server.MCPServer) {
 		mcp.WithString("query", mcp.Required(), mcp.Description("Atlassian Confluence Query Language (CQL)")),
 	)
 
-	s.AddTool(tool, confluenceSearchHandler)
+	s.AddTool(tool, util.ErrorGuard(util.TypedHandler(tool, confluenceSearchHandler)))
 
 	// Add new tool for getting page content
 	pageTool := mcp.NewTool("confluence_get_page",
 		mcp.WithDescription("Get Confluence page content"),
 		mcp.WithString("page_id", mcp.Required(), mcp.Description("Confluence page ID")),
 	)
-	s.AddTool(pageTool, util.ErrorGuard(confluencePageHandler))
+	s.AddTool(pageTool, util.ErrorGuard(util.TypedHandler(pageTool, confluencePageHandler)))
 
 	// Add new tool for creating Confluence pages
 	createPageTool := mcp.NewTool("confluence_create_page",
@@ -39,7 +49,7 @@ func RegisterConfluenceTool(s *server.MCPServer) {
 		mcp.WithString("content", mcp.Required(), mcp.Description("Content of the page in storage format (XHTML)")),
 		mcp.WithString("parent_id", mcp.Description("ID of the parent page (optional)")),
 	)
-	s.AddTool(createPageTool, util.ErrorGuard(confluenceCreatePageHandler))
+	s.AddTool(createPageTool, util.ErrorGuard(util.TypedHandler(createPageTool, confluenceCreatePageHandler)))
 
 	// Add new tool for updating Confluence pages
 	updatePageTool := mcp.NewTool("confluence_update_page",
@@ -49,18 +59,35 @@ func RegisterConfluenceTool(s *server.MCPServer) {
 		mcp.WithString("content", mcp.Description("New content of the page in storage format (XHTML)")),
 		mcp.WithString("version_number", mcp.Description("Version number for optimistic locking (optional)")),
 	)
-	s.AddTool(updatePageTool, util.ErrorGuard(confluenceUpdatePageHandler))
+	s.AddTool(updatePageTool, util.ErrorGuard(util.TypedHandler(updatePageTool, confluenceUpdatePageHandler)))
+}
+
+type confluenceSearchArgs struct {
+	Query string `json:"query"`
+}
+
+type confluencePageArgs struct {
+	PageID string `json:"page_id"`
+}
+
+type confluenceCreatePageArgs struct {
+	SpaceKey string `json:"space_key"`
+	Title    string `json:"title"`
+	Content  string `json:"content"`
+	ParentID string `json:"parent_id"`
+}
+
+type confluenceUpdatePageArgs struct {
+	PageID        string `json:"page_id"`
+	Title         string `json:"title"`
+	Content       string `json:"content"`
+	VersionNumber int    `json:"version_number"`
 }
 
 // confluenceSearchHandler is a handler for the confluence search tool
-func confluenceSearchHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+func confluenceSearchHandler(args confluenceSearchArgs) (*mcp.CallToolResult, error) {
 	client := services.ConfluenceClient()
 
-	// Get search query from arguments
-	query, ok := arguments["query"].(string)
-	if !ok {
-		return nil, fmt.Errorf("query argument is required")
-	}
 	ctx := context.Background()
 	options := &models.SearchContentOptions{
 		Limit: 5,
@@ -68,7 +95,7 @@ func confluenceSearchHandler(arguments map[string]interface{}) (*mcp.CallToolRes
 
 	var results string
 
-	contents, response, err := client.Search.Content(ctx, query, options)
+	contents, response, err := client.Search.Content(ctx, args.Query, options)
 	if err != nil {
 		if response != nil {
 			return nil, fmt.Errorf("search failed: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
@@ -101,18 +128,12 @@ Body:
 	return mcp.NewToolResultText(results), nil
 }
 
-func confluencePageHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+func confluencePageHandler(args confluencePageArgs) (*mcp.CallToolResult, error) {
 	client := services.ConfluenceClient()
 
-	// Get page ID from arguments
-	pageID, ok := arguments["page_id"].(string)
-	if !ok {
-		return nil, fmt.Errorf("page_id argument is required")
-	}
-
 	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second)
 	defer cancel()
-	content, response, err := client.Content.Get(ctx, pageID, []string{"body.storage"}, 1)
+	content, response, err := client.Content.Get(ctx, args.PageID, []string{"body.storage"}, 1)
 	if err != nil {
 		if response != nil {
 			return nil, fmt.Errorf("failed to get page: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
@@ -142,45 +163,29 @@ Content:
 }
 
 // confluenceCreatePageHandler handles the creation of new Confluence pages
-func confluenceCreatePageHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+func confluenceCreatePageHandler(args confluenceCreatePageArgs) (*mcp.CallToolResult, error) {
 	client := services.ConfluenceClient()
 
-	// Extract required arguments
-	spaceKey, ok := arguments["space_key"].(string)
-	if !ok {
-		return nil, fmt.Errorf("space_key argument is required")
-	}
-
-	title, ok := arguments["title"].(string)
-	if !ok {
-		return nil, fmt.Errorf("title argument is required")
-	}
-
-	content, ok := arguments["content"].(string)
-	if !ok {
-		return nil, fmt.Errorf("content argument is required")
-	}
-
 	// Create page payload
 	payload := &models.ContentScheme{
 		Type:  "page",
-		Title: title,
+		Title: args.Title,
 		Space: &models.SpaceScheme{
-			Key: spaceKey,
+			Key: args.SpaceKey,
 		},
 		Body: &models.BodyScheme{
 			Storage: &models.BodyNodeScheme{
-				Value:          content,
+				Value:          args.Content,
 				Representation: "storage",
 			},
 		},
 	}
 
 	// Handle optional parent ID
-	if parentID, ok := arguments["parent_id"].(string); ok && parentID != "" {
+	if args.ParentID != "" {
 		payload.Ancestors = []*models.ContentScheme{
 			{
-				ID: parentID,
+				ID: args.ParentID,
 			},
 		}
 	}
@@ -208,14 +213,9 @@ func confluenceCreatePageHandler(arguments map[string]interface{}) (*mcp.CallToo
 }
 
 // confluenceUpdatePageHandler handles updating existing Confluence pages
-func confluenceUpdatePageHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+func confluenceUpdatePageHandler(args confluenceUpdatePageArgs) (*mcp.CallToolResult, error) {
 	client := services.ConfluenceClient()
-
-	// Extract required arguments
-	pageID, ok := arguments["page_id"].(string)
-	if !ok {
-		return nil, fmt.Errorf("page_id argument is required")
-	}
+	pageID := args.PageID
 
 	// Get current page version
 	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second)
@@ -240,27 +240,23 @@ func confluenceUpdatePageHandler(arguments map[string]interface{}) (*mcp.CallToo
 	}
 
 	// Handle optional title update
-	if title, ok := arguments["title"].(string); ok && title != "" {
-		payload.Title = title
+	if args.Title != "" {
+		payload.Title = args.Title
 	}
 
 	// Handle content update
-	if content, ok := arguments["content"].(string); ok && content != "" {
+	if args.Content != "" {
 		payload.Body = &models.BodyScheme{
 			Storage: &models.BodyNodeScheme{
-				Value:          content,
+				Value:          args.Content,
 				Representation: "storage",
 			},
 		}
 	}
 
 	// Handle version number override
-	if versionStr, ok := arguments["version_number"].(string); ok && versionStr != "" {
-		version, err := strconv.Atoi(versionStr)
-		if err != nil {
-			return nil, fmt.Errorf("invalid version_number: %v", err)
-		}
-		payload.Version.Number = version
+	if args.VersionNumber != 0 {
+		payload.Version.Number = args.VersionNumber
 	}
 
 	// Update the page
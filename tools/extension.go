@@ -0,0 +1,214 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/nguyenvanduocit/dev-kit/util"
+)
+
+const extensionTimeout = 60 * time.Second
+
+// Extension is a tool group provided by a program outside dev-kit
+type Extension struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// Type is "exec" for executables speaking the extension protocol, or "mcp" for MCP servers
+	Type    string            `json:"type"`
+	Command string            `json:"command"`
+	Args    []string          `json:"args"`
+	Env     map[string]string `json:"env"`
+	// Transport is how an MCP extension is reached: "stdio" runs Command, "sse" and "http" connect to URL
+	Transport   string            `json:"transport"`
+	URL         string            `json:"url"`
+	Headers     map[string]string `json:"headers"`
+	RequiredEnv []string          `json:"required_env"`
+	Access      Access            `json:"access"`
+}
+
+// ExtensionConfig is the content of the file passed with -extensions
+type ExtensionConfig struct {
+	Extensions []Extension `json:"extensions"`
+}
+
+// LoadExtensions reads the extension config file and adds a tool group for every extension in it
+func LoadExtensions(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read extension config: %v", err)
+	}
+
+	var config ExtensionConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return fmt.Errorf("failed to parse extension config %s: %v", path, err)
+	}
+
+	for _, ext := range config.Extensions {
+		if ext.Type == "mcp" && ext.Transport == "" {
+			ext.Transport = "stdio"
+			if ext.URL != "" {
+				ext.Transport = "http"
+			}
+		}
+		switch {
+		case ext.Type == "mcp" && ext.Transport != "stdio" && ext.URL == "":
+			return fmt.Errorf("extension %q has no url", ext.Name)
+		case (ext.Type != "mcp" || ext.Transport == "stdio") && ext.Command == "":
+			return fmt.Errorf("extension %q has no command", ext.Name)
+		}
+		if ext.Access != "" && ext.Access != AccessRead && ext.Access != AccessWrite {
+			return fmt.Errorf("extension %q has invalid access %q, expected read or write", ext.Name, ext.Access)
+		}
+
+		var register func(s *server.MCPServer) error
+		switch ext.Type {
+		case "exec":
+			register = ext.registerExec
+		case "mcp":
+			if ext.Transport != "stdio" && ext.Transport != "sse" && ext.Transport != "http" {
+				return fmt.Errorf("extension %q has invalid transport %q, expected stdio, sse or http", ext.Name, ext.Transport)
+			}
+			// the SSE client of mcp-go cannot send custom headers, so they would be silently dropped
+			if len(ext.Headers) > 0 && ext.Transport != "http" {
+				return fmt.Errorf("extension %q sets headers, which only the http transport sends, not %s", ext.Name, ext.Transport)
+			}
+			register = ext.registerMCP
+		default:
+			return fmt.Errorf("extension %q has invalid type %q, expected exec or mcp", ext.Name, ext.Type)
+		}
+
+		description := ext.Description
+		if description == "" {
+			description = "Extension " + ext.Command + ext.URL
+		}
+
+		if err := addGroup(ToolGroup{
+			Name:        ext.Name,
+			Description: description,
+			RequiredEnv: ext.RequiredEnv,
+			Access:      ext.Access,
+			Register:    register,
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// toolName namespaces a tool of the extension so it cannot clash with built-in tools
+func (ext Extension) toolName(name string) string {
+	return ext.Name + "_" + name
+}
+
+func (ext Extension) environ() []string {
+	env := os.Environ()
+	for key, value := range ext.Env {
+		env = append(env, key+"="+os.ExpandEnv(value))
+	}
+	return env
+}
+
+// Executable extensions are run once per request. `<command> [args...] describe` prints
+// {"tools": [<MCP tool definitions>]}. `<command> [args...] call` reads
+// {"tool": "<name>", "arguments": {...}} on stdin and prints {"result": "..."} or
+// {"error": "..."}.
+
+type execDescribeResponse struct {
+	Tools []mcp.Tool `json:"tools"`
+}
+
+type execCallRequest struct {
+	Tool      string                 `json:"tool"`
+	Arguments map[string]interface{} `json:"arguments"`
+}
+
+type execCallResponse struct {
+	Result string `json:"result"`
+	Error  string `json:"error"`
+}
+
+func (ext Extension) registerExec(s *server.MCPServer) error {
+	output, err := ext.run("describe", nil)
+	if err != nil {
+		return err
+	}
+
+	var response execDescribeResponse
+	if err := json.Unmarshal(output, &response); err != nil {
+		return fmt.Errorf("extension %s returned an invalid tool list: %v", ext.Name, err)
+	}
+
+	for _, tool := range response.Tools {
+		if tool.Name == "" {
+			return fmt.Errorf("extension %s returned a tool without a name", ext.Name)
+		}
+		if tool.InputSchema.Type == "" {
+			tool.InputSchema.Type = "object"
+		}
+
+		name := tool.Name
+		exposed := tool
+		exposed.Name = ext.toolName(name)
+		s.AddTool(exposed, util.ErrorGuard(func(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+			// Decoding into an empty struct only validates the arguments against the schema
+			if err := util.DecodeArguments(tool.InputSchema, arguments, &struct{}{}); err != nil {
+				return nil, err
+			}
+			return ext.callExec(name, arguments)
+		}))
+	}
+
+	return nil
+}
+
+func (ext Extension) callExec(tool string, arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	input, err := json.Marshal(execCallRequest{Tool: tool, Arguments: arguments})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode arguments: %v", err)
+	}
+
+	output, err := ext.run("call", input)
+	if err != nil {
+		return nil, err
+	}
+
+	var response execCallResponse
+	if err := json.Unmarshal(output, &response); err != nil {
+		return nil, fmt.Errorf("extension %s returned an invalid response: %v", ext.Name, err)
+	}
+	if response.Error != "" {
+		return mcp.NewToolResultError(response.Error), nil
+	}
+
+	return mcp.NewToolResultText(response.Result), nil
+}
+
+func (ext Extension) run(command string, input []byte) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), extensionTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, ext.Command, append(append([]string{}, ext.Args...), command)...)
+	cmd.Env = ext.environ()
+	cmd.Stdin = bytes.NewReader(input)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, fmt.Errorf("extension %s timed out after %v", ext.Name, extensionTimeout)
+		}
+		return nil, fmt.Errorf("extension %s failed: %v\n%s", ext.Name, err, strings.TrimSpace(stderr.String()))
+	}
+
+	return stdout.Bytes(), nil
+}
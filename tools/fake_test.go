@@ -0,0 +1,209 @@
+package tools
+
+import (
+	"log"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/nguyenvanduocit/dev-kit/services"
+)
+
+// The tool handlers are exercised end to end against the fake backend seeded with the demo fixture
+func TestMain(m *testing.M) {
+	if err := services.EnableFakeBackend(""); err != nil {
+		log.Fatal(err)
+	}
+	os.Exit(m.Run())
+}
+
+// resultText returns the text of a tool result, failing the test when the handler returned an
+// error. It is curried so handler calls can be passed straight in: resultText(t)(handler(args)).
+func resultText(t *testing.T) func(result *mcp.CallToolResult, err error) string {
+	return func(result *mcp.CallToolResult, err error) string {
+		t.Helper()
+		if err != nil {
+			t.Fatalf("handler failed: %v", err)
+		}
+
+		var sb strings.Builder
+		for _, content := range result.Content {
+			if text, ok := content.(mcp.TextContent); ok {
+				sb.WriteString(text.Text)
+			}
+		}
+		if result.IsError {
+			t.Fatalf("handler returned an error result: %s", sb.String())
+		}
+		return sb.String()
+	}
+}
+
+func assertContains(t *testing.T, text string, wants ...string) {
+	t.Helper()
+	for _, want := range wants {
+		if !strings.Contains(text, want) {
+			t.Errorf("output does not contain %q:\n%s", want, text)
+		}
+	}
+}
+
+func TestJiraIssueHandlers(t *testing.T) {
+	text := resultText(t)(jiraIssueHandler(jiraIssueKeyArgs{IssueKey: "KP-3"}))
+	assertContains(t, text, "KP-3", "In Review")
+
+	text = resultText(t)(jiraCreateIssueHandler(jiraCreateIssueArgs{
+		ProjectKey:  "KP",
+		Summary:     "Cover the tools with tests",
+		Description: "Run the handlers against the **fake** backend.",
+		IssueType:   "Task",
+		Priority:    "High",
+		Labels:      "testing",
+	}))
+	key := regexp.MustCompile(`KP-\d+`).FindString(text)
+	if key == "" {
+		t.Fatalf("no issue key in the create output:\n%s", text)
+	}
+	assertContains(t, text, "/browse/"+key)
+
+	text = resultText(t)(jiraUpdateIssueHandler(jiraUpdateIssueArgs{IssueKey: key, Description: "Run the handlers against the fake backend."}))
+	assertContains(t, text, "/browse/"+key)
+
+	// To Do reaches In Review only through In Progress
+	text = resultText(t)(jiraTransitionIssueHandler(jiraTransitionIssueArgs{IssueKey: key, Status: "In Review", Comment: "Ready for review"}))
+	assertContains(t, text, "In Review")
+
+	text = resultText(t)(jiraIssueHandler(jiraIssueKeyArgs{IssueKey: key}))
+	assertContains(t, text, "Cover the tools with tests", "In Review", "High")
+}
+
+func TestJiraListStatusesHandler(t *testing.T) {
+	text := resultText(t)(jiraGetStatusesHandler(jiraProjectArgs{ProjectKey: "KP"}))
+	assertContains(t, text, "Issue types (", "- Story (ID: ", "In Review (ID: ")
+
+	// jira_list_statuses is the status section of jira_describe_project
+	described := resultText(t)(jiraDescribeProjectHandler(jiraProjectArgs{ProjectKey: "KP"}))
+	for _, line := range strings.Split(strings.TrimSpace(text), "\n") {
+		if strings.HasPrefix(line, "  Statuses: ") {
+			assertContains(t, described, line)
+		}
+	}
+}
+
+func TestJiraListComponentsHandler(t *testing.T) {
+	text := resultText(t)(jiraListComponentsHandler(jiraListComponentsArgs{ProjectKey: "KP", IssueCounts: true}))
+	for _, want := range []string{"Backend", "CLI", "Docs"} {
+		assertContains(t, text, "- "+want+" (ID: ")
+	}
+	// Docs is only on KP-6, Backend on KP-2 and KP-5
+	if !regexp.MustCompile(`- Docs \(ID: .*, 1 issue\n`).MatchString(text) {
+		t.Errorf("Docs is not shown with 1 issue:\n%s", text)
+	}
+	if !regexp.MustCompile(`- Backend \(ID: .*, 2 issues\n`).MatchString(text) {
+		t.Errorf("Backend is not shown with 2 issues:\n%s", text)
+	}
+}
+
+func TestJiraSearchHandler(t *testing.T) {
+	text := resultText(t)(jiraSearchHandler(jiraSearchArgs{JQL: `project = KP AND summary ~ "order by" ORDER BY key`, OrderBy: "key DESC", Fields: "summary", MaxResults: 10}))
+	if count := strings.Count(text, "Summary: "); count != 0 {
+		t.Errorf("got %d summaries for a query matching nothing:\n%s", count, text)
+	}
+
+	text = resultText(t)(jiraSearchHandler(jiraSearchArgs{JQL: "key = KP-3", Fields: "summary", MaxResults: 10}))
+	if count := strings.Count(text, "Fake backends for local development"); count != 1 {
+		t.Errorf("the summary is shown %d times, want once:\n%s", count, text)
+	}
+}
+
+func TestJiraBulkUpdateHandler(t *testing.T) {
+	text := resultText(t)(jiraCreateIssueHandler(jiraCreateIssueArgs{ProjectKey: "KP", Summary: "Bulk update target", IssueType: "Task"}))
+	key := regexp.MustCompile(`KP-\d+`).FindString(text)
+
+	// like jira_transition_issue, bulk updates reach In Review from To Do through In Progress
+	text = resultText(t)(jiraBulkUpdateHandler(jiraBulkUpdateArgs{IssueKeys: key, Transition: "In Review", DryRun: true}))
+	assertContains(t, text, "1 of 1 issue can be updated", "move from To Do to In Review")
+
+	text = resultText(t)(jiraBulkUpdateHandler(jiraBulkUpdateArgs{IssueKeys: key, Transition: "In Review", AddLabels: "bulk"}))
+	assertContains(t, text, "Updated 1 of 1 issue", "moved to In Review via")
+
+	text = resultText(t)(jiraBulkUpdateHandler(jiraBulkUpdateArgs{IssueKeys: key + ",KP-999", AddLabels: "again"}))
+	assertContains(t, text, "Updated 1 of 2 issues", "1 issue failed")
+}
+
+func TestJiraBulkCreateHandler(t *testing.T) {
+	outline := "- Offline mode\n  - Story: Cache responses\n    - Sub-task: Pick a cache directory\n  - Task: Document it"
+	text := resultText(t)(jiraBulkCreateHandler(jiraBulkCreateArgs{ProjectKey: "KP", Outline: outline}))
+	assertContains(t, text, "Created 4 issues", "Epic: Offline mode", "Story: Cache responses", "Sub-task: Pick a cache directory")
+
+	// only issues under an epic go through the epic link, anything under a story hangs off it
+	text = resultText(t)(jiraBulkCreateHandler(jiraBulkCreateArgs{ProjectKey: "KP", Outline: "- Sub-task: Write the fixture", Parent: "KP-3"}))
+	assertContains(t, text, "Created 1 issue in KP", "under KP-3")
+
+	_, err := jiraBulkCreateHandler(jiraBulkCreateArgs{ProjectKey: "KP", Outline: "- Epic\n  - Story\n    - Subtask\n      - Too deep"})
+	if err == nil || !strings.Contains(err.Error(), "line 4") {
+		t.Errorf("got %v, want an error about line 4 being nested too deep", err)
+	}
+}
+
+func TestJiraSprintHandlers(t *testing.T) {
+	text := resultText(t)(jiraListSprintHandler(jiraListSprintArgs{BoardID: 1, State: "active,future"}))
+	assertContains(t, text, "KP Sprint 2", "KP Sprint 3")
+
+	resultText(t)(jiraMoveToSprintHandler(jiraMoveToSprintArgs{SprintID: 3, IssueKeys: "KP-4"}))
+	text = resultText(t)(jiraGetSprintIssuesHandler(jiraGetSprintIssuesArgs{SprintID: 3}))
+	assertContains(t, text, "KP-4")
+}
+
+func TestConfluencePageHandlers(t *testing.T) {
+	text := resultText(t)(confluencePageHandler(confluencePageArgs{PageID: "65538"}))
+	assertContains(t, text, "Release Process")
+
+	text = resultText(t)(confluenceCreatePageHandler(confluenceCreatePageArgs{SpaceKey: "ENG", Title: "Tool tests", Content: "<p>Draft</p>", ParentID: "65537"}))
+	id := regexp.MustCompile(`\b\d{5,}\b`).FindString(text)
+	if id == "" {
+		t.Fatalf("no page ID in the create output:\n%s", text)
+	}
+
+	resultText(t)(confluenceUpdatePageHandler(confluenceUpdatePageArgs{PageID: id, Title: "Tool tests", Content: "<p>Final</p>"}))
+	text = resultText(t)(confluencePageHandler(confluencePageArgs{PageID: id}))
+	assertContains(t, text, "Final")
+}
+
+func TestGitHubPullRequestHandlers(t *testing.T) {
+	repo := githubRepoArgs{Owner: "acme", Repo: "dev-kit"}
+
+	text := resultText(t)(listPullRequestsHandler(githubListArgs{githubRepoArgs: repo, State: "open"}))
+	assertContains(t, text, "#43", "Add fake backends")
+	if strings.Contains(text, "#41") {
+		t.Errorf("closed pull request #41 is listed as open:\n%s", text)
+	}
+
+	text = resultText(t)(getPullRequestHandler(githubNumberArgs{githubRepoArgs: repo, Number: 43}))
+	assertContains(t, text, "Add fake backends")
+
+	resultText(t)(commentOnPullRequestHandler(githubCommentArgs{githubNumberArgs: githubNumberArgs{githubRepoArgs: repo, Number: 43}, Comment: "Tested against the fake"}))
+}
+
+func TestGitLabMergeRequestHandlers(t *testing.T) {
+	project := gitlabProjectArgs{ProjectPath: "acme/api"}
+
+	text := resultText(t)(listMergeRequestsHandler(gitlabListMRsArgs{gitlabProjectArgs: project, State: "opened"}))
+	assertContains(t, text, "Add rate limiting middleware")
+
+	text = resultText(t)(getMergeRequestHandler(gitlabMRArgs{gitlabProjectArgs: project, MRIID: 7}))
+	assertContains(t, text, "Add rate limiting middleware")
+
+	mr := gitlabMRArgs{gitlabProjectArgs: project, MRIID: 7}
+	resultText(t)(commentOnMergeRequestHandler(gitlabMRCommentArgs{gitlabMRArgs: mr, Comment: "Make it configurable"}))
+	text = resultText(t)(listMRCommentsHandler(mr))
+	assertContains(t, text, "Should the limit be configurable?", "Make it configurable")
+
+	text = resultText(t)(listPipelinesHandler(gitlabListPipelinesArgs{gitlabProjectArgs: project, Status: "failed"}))
+	assertContains(t, text, "9002")
+	if strings.Contains(text, "9001") {
+		t.Errorf("successful pipeline 9001 is listed as failed:\n%s", text)
+	}
+}
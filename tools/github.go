@@ -11,19 +11,34 @@ import (
 	"github.com/google/go-github/v60/github"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
+	"github.com/nguyenvanduocit/dev-kit/services"
 	"github.com/nguyenvanduocit/dev-kit/util"
 )
 
 var githubClient = sync.OnceValue[*github.Client](func() *github.Client {
 	token := os.Getenv("GITHUB_TOKEN")
+	if token == "" && services.IsOffline() {
+		token = "offline"
+	}
 	if token == "" {
 		log.Fatal("GITHUB_TOKEN is required")
 	}
 
-	client := github.NewClient(nil).WithAuthToken(token)
+	client := github.NewClient(services.DefaultHttpClient()).WithAuthToken(token)
 	return client
 })
 
+func init() {
+	RegisterGroup(ToolGroup{
+		Name:        "github",
+		Description: "Repositories, pull requests and issues on GitHub",
+		RequiredEnv: []string{"GITHUB_TOKEN"},
+		Offline:     true,
+		Access:      AccessWrite,
+		Register:    infallible(RegisterGitHubTool),
+	})
+}
+
 // RegisterGitHubTool registers the GitHub tool with the MCP server
 func RegisterGitHubTool(s *server.MCPServer) {
 	listReposTool := mcp.NewTool("github_list_repos",
@@ -117,41 +132,72 @@ func RegisterGitHubTool(s *server.MCPServer) {
 		mcp.WithString("action", mcp.Required(), mcp.Description("Action to take (close/reopen)")),
 	)
 
-	s.AddTool(listReposTool, util.ErrorGuard(listReposHandler))
-	s.AddTool(repoDetailsTool, util.ErrorGuard(getRepoHandler))
-	s.AddTool(prListTool, util.ErrorGuard(listPullRequestsHandler))
-	s.AddTool(prDetailsTool, util.ErrorGuard(getPullRequestHandler))
-	s.AddTool(prCommentTool, util.ErrorGuard(commentOnPullRequestHandler))
-	s.AddTool(fileContentTool, util.ErrorGuard(getGitHubFileContentHandler))
-	s.AddTool(createPRTool, util.ErrorGuard(createPullRequestHandler))
-	s.AddTool(prActionTool, util.ErrorGuard(prActionHandler))
-	s.AddTool(issueListTool, util.ErrorGuard(listIssuesHandler))
-	s.AddTool(issueDetailsTool, util.ErrorGuard(getIssueHandler))
-	s.AddTool(issueCommentTool, util.ErrorGuard(commentOnIssueHandler))
-	s.AddTool(issueActionTool, util.ErrorGuard(issueActionHandler))
+	s.AddTool(listReposTool, util.ErrorGuard(util.TypedHandler(listReposTool, listReposHandler)))
+	s.AddTool(repoDetailsTool, util.ErrorGuard(util.TypedHandler(repoDetailsTool, getRepoHandler)))
+	s.AddTool(prListTool, util.ErrorGuard(util.TypedHandler(prListTool, listPullRequestsHandler)))
+	s.AddTool(prDetailsTool, util.ErrorGuard(util.TypedHandler(prDetailsTool, getPullRequestHandler)))
+	s.AddTool(prCommentTool, util.ErrorGuard(util.TypedHandler(prCommentTool, commentOnPullRequestHandler)))
+	s.AddTool(fileContentTool, util.ErrorGuard(util.TypedHandler(fileContentTool, getGitHubFileContentHandler)))
+	s.AddTool(createPRTool, util.ErrorGuard(util.TypedHandler(createPRTool, createPullRequestHandler)))
+	s.AddTool(prActionTool, util.ErrorGuard(util.TypedHandler(prActionTool, prActionHandler)))
+	s.AddTool(issueListTool, util.ErrorGuard(util.TypedHandler(issueListTool, listIssuesHandler)))
+	s.AddTool(issueDetailsTool, util.ErrorGuard(util.TypedHandler(issueDetailsTool, getIssueHandler)))
+	s.AddTool(issueCommentTool, util.ErrorGuard(util.TypedHandler(issueCommentTool, commentOnIssueHandler)))
+	s.AddTool(issueActionTool, util.ErrorGuard(util.TypedHandler(issueActionTool, issueActionHandler)))
 }
 
-func listReposHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
-	ownerVal, ok := arguments["owner"]
-	if !ok || ownerVal == nil {
-		return nil, fmt.Errorf("missing required argument: owner")
-	}
-	owner, ok := ownerVal.(string)
-	if !ok || owner == "" {
-		return nil, fmt.Errorf("owner must be a non-empty string")
-	}
+type githubRepoArgs struct {
+	Owner string `json:"owner"`
+	Repo  string `json:"repo"`
+}
 
-	repoTypeVal, ok := arguments["type"]
-	if !ok || repoTypeVal == nil {
-		return nil, fmt.Errorf("missing required argument: type")
-	}
-	repoType, ok := repoTypeVal.(string)
-	if !ok {
-		return nil, fmt.Errorf("type must be a string")
-	}
+type githubNumberArgs struct {
+	githubRepoArgs
+	Number int `json:"number"`
+}
+
+type githubListReposArgs struct {
+	Owner string `json:"owner"`
+	Type  string `json:"type"`
+}
+
+type githubListArgs struct {
+	githubRepoArgs
+	State string `json:"state"`
+}
 
+type githubCommentArgs struct {
+	githubNumberArgs
+	Comment string `json:"comment"`
+}
+
+type githubFileContentArgs struct {
+	githubRepoArgs
+	Path string `json:"path"`
+	Ref  string `json:"ref"`
+}
+
+type githubCreatePRArgs struct {
+	githubRepoArgs
+	Title string `json:"title"`
+	Head  string `json:"head"`
+	Base  string `json:"base"`
+	Body  string `json:"body"`
+}
+
+type githubActionArgs struct {
+	githubNumberArgs
+	Action string `json:"action"`
+}
+
+type githubListIssuesArgs struct {
+	githubListArgs
+	IncludeBody bool `json:"include_body"`
+}
+
+func listReposHandler(args githubListReposArgs) (*mcp.CallToolResult, error) {
 	opt := &github.RepositoryListOptions{
-		Type: repoType,
+		Type: args.Type,
 		ListOptions: github.ListOptions{
 			PerPage: 100,
 		},
@@ -160,7 +206,7 @@ func listReposHandler(arguments map[string]interface{}) (*mcp.CallToolResult, er
 	var repos []*github.Repository
 	var err error
 
-	repos, _, err = githubClient().Repositories.List(context.Background(), owner, opt)
+	repos, _, err = githubClient().Repositories.List(context.Background(), args.Owner, opt)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list repositories: %v", err)
 	}
@@ -180,11 +226,8 @@ func listReposHandler(arguments map[string]interface{}) (*mcp.CallToolResult, er
 	return mcp.NewToolResultText(result.String()), nil
 }
 
-func getRepoHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
-	owner := arguments["owner"].(string)
-	repo := arguments["repo"].(string)
-
-	repository, _, err := githubClient().Repositories.Get(context.Background(), owner, repo)
+func getRepoHandler(args githubRepoArgs) (*mcp.CallToolResult, error) {
+	repository, _, err := githubClient().Repositories.Get(context.Background(), args.Owner, args.Repo)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get repository: %v", err)
 	}
@@ -206,19 +249,15 @@ func getRepoHandler(arguments map[string]interface{}) (*mcp.CallToolResult, erro
 	return mcp.NewToolResultText(result.String()), nil
 }
 
-func listPullRequestsHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
-	owner := arguments["owner"].(string)
-	repo := arguments["repo"].(string)
-	state := arguments["state"].(string)
-
+func listPullRequestsHandler(args githubListArgs) (*mcp.CallToolResult, error) {
 	opt := &github.PullRequestListOptions{
-		State: state,
+		State: args.State,
 		ListOptions: github.ListOptions{
 			PerPage: 100,
 		},
 	}
 
-	prs, _, err := githubClient().PullRequests.List(context.Background(), owner, repo, opt)
+	prs, _, err := githubClient().PullRequests.List(context.Background(), args.Owner, args.Repo, opt)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list pull requests: %v", err)
 	}
@@ -244,15 +283,8 @@ func listPullRequestsHandler(arguments map[string]interface{}) (*mcp.CallToolRes
 	return mcp.NewToolResultText(result.String()), nil
 }
 
-func getPullRequestHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
-	owner := arguments["owner"].(string)
-	repo := arguments["repo"].(string)
-	number := arguments["number"].(string)
-
-	prNumber := 0
-	fmt.Sscanf(number, "%d", &prNumber)
-
-	pr, _, err := githubClient().PullRequests.Get(context.Background(), owner, repo, prNumber)
+func getPullRequestHandler(args githubNumberArgs) (*mcp.CallToolResult, error) {
+	pr, _, err := githubClient().PullRequests.Get(context.Background(), args.Owner, args.Repo, args.Number)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get pull request: %v", err)
 	}
@@ -274,7 +306,7 @@ func getPullRequestHandler(arguments map[string]interface{}) (*mcp.CallToolResul
 	result.WriteString(fmt.Sprintf("\nDescription:\n%s\n", pr.GetBody()))
 
 	// Get PR comments
-	comments, _, err := githubClient().Issues.ListComments(context.Background(), owner, repo, prNumber, nil)
+	comments, _, err := githubClient().Issues.ListComments(context.Background(), args.Owner, args.Repo, args.Number, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get pull request comments: %v", err)
 	}
@@ -292,20 +324,12 @@ func getPullRequestHandler(arguments map[string]interface{}) (*mcp.CallToolResul
 	return mcp.NewToolResultText(result.String()), nil
 }
 
-func commentOnPullRequestHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
-	owner := arguments["owner"].(string)
-	repo := arguments["repo"].(string)
-	number := arguments["number"].(string)
-	comment := arguments["comment"].(string)
-
-	prNumber := 0
-	fmt.Sscanf(number, "%d", &prNumber)
-
+func commentOnPullRequestHandler(args githubCommentArgs) (*mcp.CallToolResult, error) {
 	issueComment := &github.IssueComment{
-		Body: github.String(comment),
+		Body: github.String(args.Comment),
 	}
 
-	_, _, err := githubClient().Issues.CreateComment(context.Background(), owner, repo, prNumber, issueComment)
+	_, _, err := githubClient().Issues.CreateComment(context.Background(), args.Owner, args.Repo, args.Number, issueComment)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create comment: %v", err)
 	}
@@ -313,21 +337,13 @@ func commentOnPullRequestHandler(arguments map[string]interface{}) (*mcp.CallToo
 	return mcp.NewToolResultText("Comment created successfully"), nil
 }
 
-func getGitHubFileContentHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
-	owner := arguments["owner"].(string)
-	repo := arguments["repo"].(string)
-	path := arguments["path"].(string)
-	ref := ""
-	if refArg, ok := arguments["ref"]; ok {
-		ref = refArg.(string)
-	}
-
+func getGitHubFileContentHandler(args githubFileContentArgs) (*mcp.CallToolResult, error) {
 	opts := &github.RepositoryContentGetOptions{}
-	if ref != "" {
-		opts.Ref = ref
+	if args.Ref != "" {
+		opts.Ref = args.Ref
 	}
 
-	content, _, _, err := githubClient().Repositories.GetContents(context.Background(), owner, repo, path, opts)
+	content, _, _, err := githubClient().Repositories.GetContents(context.Background(), args.Owner, args.Repo, args.Path, opts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get file content: %v", err)
 	}
@@ -340,25 +356,15 @@ func getGitHubFileContentHandler(arguments map[string]interface{}) (*mcp.CallToo
 	return mcp.NewToolResultText(decodedContent), nil
 }
 
-func createPullRequestHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
-	owner := arguments["owner"].(string)
-	repo := arguments["repo"].(string)
-	title := arguments["title"].(string)
-	head := arguments["head"].(string)
-	base := arguments["base"].(string)
-	body := ""
-	if bodyArg, ok := arguments["body"]; ok {
-		body = bodyArg.(string)
-	}
-
+func createPullRequestHandler(args githubCreatePRArgs) (*mcp.CallToolResult, error) {
 	newPR := &github.NewPullRequest{
-		Title: github.String(title),
-		Head:  github.String(head),
-		Base:  github.String(base),
-		Body:  github.String(body),
+		Title: github.String(args.Title),
+		Head:  github.String(args.Head),
+		Base:  github.String(args.Base),
+		Body:  github.String(args.Body),
 	}
 
-	pr, _, err := githubClient().PullRequests.Create(context.Background(), owner, repo, newPR)
+	pr, _, err := githubClient().PullRequests.Create(context.Background(), args.Owner, args.Repo, newPR)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create pull request: %v", err)
 	}
@@ -366,22 +372,14 @@ func createPullRequestHandler(arguments map[string]interface{}) (*mcp.CallToolRe
 	return mcp.NewToolResultText(fmt.Sprintf("Pull request created successfully: %s", pr.GetHTMLURL())), nil
 }
 
-func prActionHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
-	owner := arguments["owner"].(string)
-	repo := arguments["repo"].(string)
-	number := arguments["number"].(string)
-	action := arguments["action"].(string)
-
-	prNumber := 0
-	fmt.Sscanf(number, "%d", &prNumber)
-
-	switch action {
+func prActionHandler(args githubActionArgs) (*mcp.CallToolResult, error) {
+	switch args.Action {
 	case "approve":
 		// Create approval review
 		review := &github.PullRequestReviewRequest{
 			Event: github.String("APPROVE"),
 		}
-		_, _, err := githubClient().PullRequests.CreateReview(context.Background(), owner, repo, prNumber, review)
+		_, _, err := githubClient().PullRequests.CreateReview(context.Background(), args.Owner, args.Repo, args.Number, review)
 		if err != nil {
 			return nil, fmt.Errorf("failed to approve pull request: %v", err)
 		}
@@ -392,40 +390,19 @@ func prActionHandler(arguments map[string]interface{}) (*mcp.CallToolResult, err
 		pr := &github.PullRequest{
 			State: github.String("closed"),
 		}
-		_, _, err := githubClient().PullRequests.Edit(context.Background(), owner, repo, prNumber, pr)
+		_, _, err := githubClient().PullRequests.Edit(context.Background(), args.Owner, args.Repo, args.Number, pr)
 		if err != nil {
 			return nil, fmt.Errorf("failed to close pull request: %v", err)
 		}
 		return mcp.NewToolResultText("Pull request closed successfully"), nil
 
 	default:
-		return nil, fmt.Errorf("invalid action: %s. Must be either 'approve' or 'close'", action)
+		return nil, fmt.Errorf("invalid action: %s. Must be either 'approve' or 'close'", args.Action)
 	}
 }
 
-func listIssuesHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
-	owner, ok := arguments["owner"].(string)
-	if !ok || owner == "" {
-		return nil, fmt.Errorf("missing or invalid owner parameter")
-	}
-
-	repo, ok := arguments["repo"].(string)
-	if !ok || repo == "" {
-		return nil, fmt.Errorf("missing or invalid repo parameter")
-	}
-
-	state, ok := arguments["state"].(string)
-	if !ok || state == "" {
-		state = "open" // Default to open if not specified
-	}
-
-	// Safely handle include_body parameter with a default value
-	includeBody := false
-	if includeBodyVal, exists := arguments["include_body"]; exists {
-		if boolVal, ok := includeBodyVal.(bool); ok {
-			includeBody = boolVal
-		}
-	}
+func listIssuesHandler(args githubListIssuesArgs) (*mcp.CallToolResult, error) {
+	owner, repo, state := args.Owner, args.Repo, args.State
 
 	opt := &github.IssueListByRepoOptions{
 		State: state,
@@ -489,7 +466,7 @@ func listIssuesHandler(arguments map[string]interface{}) (*mcp.CallToolResult, e
 			}
 		}
 
-		if includeBody && issue.GetBody() != "" {
+		if args.IncludeBody && issue.GetBody() != "" {
 			result.WriteString(fmt.Sprintf("\nDescription:\n%s\n", issue.GetBody()))
 		}
 		result.WriteString("\n")
@@ -498,15 +475,8 @@ func listIssuesHandler(arguments map[string]interface{}) (*mcp.CallToolResult, e
 	return mcp.NewToolResultText(result.String()), nil
 }
 
-func getIssueHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
-	owner := arguments["owner"].(string)
-	repo := arguments["repo"].(string)
-	number := arguments["number"].(string)
-
-	issueNumber := 0
-	fmt.Sscanf(number, "%d", &issueNumber)
-
-	issue, _, err := githubClient().Issues.Get(context.Background(), owner, repo, issueNumber)
+func getIssueHandler(args githubNumberArgs) (*mcp.CallToolResult, error) {
+	issue, _, err := githubClient().Issues.Get(context.Background(), args.Owner, args.Repo, args.Number)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get issue: %v", err)
 	}
@@ -530,7 +500,7 @@ func getIssueHandler(arguments map[string]interface{}) (*mcp.CallToolResult, err
 	result.WriteString(fmt.Sprintf("\nDescription:\n%s\n", issue.GetBody()))
 
 	// Get issue comments
-	comments, _, err := githubClient().Issues.ListComments(context.Background(), owner, repo, issueNumber, nil)
+	comments, _, err := githubClient().Issues.ListComments(context.Background(), args.Owner, args.Repo, args.Number, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get issue comments: %v", err)
 	}
@@ -548,20 +518,12 @@ func getIssueHandler(arguments map[string]interface{}) (*mcp.CallToolResult, err
 	return mcp.NewToolResultText(result.String()), nil
 }
 
-func commentOnIssueHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
-	owner := arguments["owner"].(string)
-	repo := arguments["repo"].(string)
-	number := arguments["number"].(string)
-	comment := arguments["comment"].(string)
-
-	issueNumber := 0
-	fmt.Sscanf(number, "%d", &issueNumber)
-
+func commentOnIssueHandler(args githubCommentArgs) (*mcp.CallToolResult, error) {
 	issueComment := &github.IssueComment{
-		Body: github.String(comment),
+		Body: github.String(args.Comment),
 	}
 
-	_, _, err := githubClient().Issues.CreateComment(context.Background(), owner, repo, issueNumber, issueComment)
+	_, _, err := githubClient().Issues.CreateComment(context.Background(), args.Owner, args.Repo, args.Number, issueComment)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create comment: %v", err)
 	}
@@ -569,14 +531,8 @@ func commentOnIssueHandler(arguments map[string]interface{}) (*mcp.CallToolResul
 	return mcp.NewToolResultText("Comment created successfully"), nil
 }
 
-func issueActionHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
-	owner := arguments["owner"].(string)
-	repo := arguments["repo"].(string)
-	number := arguments["number"].(string)
-	action := arguments["action"].(string)
-
-	issueNumber := 0
-	fmt.Sscanf(number, "%d", &issueNumber)
+func issueActionHandler(args githubActionArgs) (*mcp.CallToolResult, error) {
+	action := args.Action
 
 	var state string
 	switch action {
@@ -592,7 +548,7 @@ func issueActionHandler(arguments map[string]interface{}) (*mcp.CallToolResult,
 		State: &state,
 	}
 
-	_, _, err := githubClient().Issues.Edit(context.Background(), owner, repo, issueNumber, issue)
+	_, _, err := githubClient().Issues.Edit(context.Background(), args.Owner, args.Repo, args.Number, issue)
 	if err != nil {
 		return nil, fmt.Errorf("failed to %s issue: %v", action, err)
 	}
@@ -4,13 +4,13 @@ import (
 	"fmt"
 	"log"
 	"os"
-	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
+	"github.com/nguyenvanduocit/dev-kit/services"
 	"github.com/nguyenvanduocit/dev-kit/util"
 	"github.com/pkg/errors"
 	gitlab "gitlab.com/gitlab-org/api/client-go"
@@ -18,16 +18,22 @@ import (
 
 var gitlabClient = sync.OnceValue[*gitlab.Client](func() *gitlab.Client {
 	token := os.Getenv("GITLAB_TOKEN")
+	if token == "" && services.IsOffline() {
+		token = "offline"
+	}
 	if token == "" {
 		log.Fatal("GITLAB_TOKEN is required")
 	}
 
 	host := os.Getenv("GITLAB_HOST")
+	if host == "" && services.IsOffline() {
+		host = "https://offline.gitlab.invalid"
+	}
 	if host == "" {
 		log.Fatal("GITLAB_HOST is required")
 	}
 
-	client, err := gitlab.NewClient(token, gitlab.WithBaseURL(host))
+	client, err := gitlab.NewClient(token, gitlab.WithBaseURL(host), gitlab.WithHTTPClient(services.DefaultHttpClient()))
 	if err != nil {
 		log.Fatal(errors.WithMessage(err, "failed to create gitlab client"))
 	}
@@ -35,6 +41,17 @@ var gitlabClient = sync.OnceValue[*gitlab.Client](func() *gitlab.Client {
 	return client
 })
 
+func init() {
+	RegisterGroup(ToolGroup{
+		Name:        "gitlab",
+		Description: "Projects, merge requests, pipelines and commits on GitLab",
+		RequiredEnv: []string{"GITLAB_HOST", "GITLAB_TOKEN"},
+		Offline:     true,
+		Access:      AccessWrite,
+		Register:    infallible(RegisterGitLabTool),
+	})
+}
+
 // RegisterGitLabTool registers the GitLab tool with the MCP server
 func RegisterGitLabTool(s *server.MCPServer) {
 	listProjectsTool := mcp.NewTool("gitlab_list_projects",
@@ -121,23 +138,90 @@ func RegisterGitLabTool(s *server.MCPServer) {
 		mcp.WithString("description", mcp.Description("Merge request description")),
 	)
 
-	s.AddTool(listProjectsTool, util.ErrorGuard(listProjectsHandler))
-	s.AddTool(projectTool, util.ErrorGuard(getProjectHandler))
-	s.AddTool(mrListTool, util.ErrorGuard(listMergeRequestsHandler))
-	s.AddTool(mrDetailsTool, util.ErrorGuard(getMergeRequestHandler))
-	s.AddTool(mrCommentTool, util.ErrorGuard(commentOnMergeRequestHandler))
-	s.AddTool(listMRCommentsTool, util.ErrorGuard(listMRCommentsHandler))
-	s.AddTool(fileContentTool, util.ErrorGuard(getFileContentHandler))
-	s.AddTool(pipelineTool, util.ErrorGuard(listPipelinesHandler))
-	s.AddTool(commitsTool, util.ErrorGuard(listCommitsHandler))
-	s.AddTool(commitDetailsTool, util.ErrorGuard(getCommitDetailsHandler))
-	s.AddTool(userEventsTool, util.ErrorGuard(listUserEventsHandler))
-	s.AddTool(listGroupUsersTool, util.ErrorGuard(listGroupUsersHandler))
-	s.AddTool(createMRTool, util.ErrorGuard(createMergeRequestHandler))
+	s.AddTool(listProjectsTool, util.ErrorGuard(util.TypedHandler(listProjectsTool, listProjectsHandler)))
+	s.AddTool(projectTool, util.ErrorGuard(util.TypedHandler(projectTool, getProjectHandler)))
+	s.AddTool(mrListTool, util.ErrorGuard(util.TypedHandler(mrListTool, listMergeRequestsHandler)))
+	s.AddTool(mrDetailsTool, util.ErrorGuard(util.TypedHandler(mrDetailsTool, getMergeRequestHandler)))
+	s.AddTool(mrCommentTool, util.ErrorGuard(util.TypedHandler(mrCommentTool, commentOnMergeRequestHandler)))
+	s.AddTool(listMRCommentsTool, util.ErrorGuard(util.TypedHandler(listMRCommentsTool, listMRCommentsHandler)))
+	s.AddTool(fileContentTool, util.ErrorGuard(util.TypedHandler(fileContentTool, getFileContentHandler)))
+	s.AddTool(pipelineTool, util.ErrorGuard(util.TypedHandler(pipelineTool, listPipelinesHandler)))
+	s.AddTool(commitsTool, util.ErrorGuard(util.TypedHandler(commitsTool, listCommitsHandler)))
+	s.AddTool(commitDetailsTool, util.ErrorGuard(util.TypedHandler(commitDetailsTool, getCommitDetailsHandler)))
+	s.AddTool(userEventsTool, util.ErrorGuard(util.TypedHandler(userEventsTool, listUserEventsHandler)))
+	s.AddTool(listGroupUsersTool, util.ErrorGuard(util.TypedHandler(listGroupUsersTool, listGroupUsersHandler)))
+	s.AddTool(createMRTool, util.ErrorGuard(util.TypedHandler(createMRTool, createMergeRequestHandler)))
+}
+
+type gitlabGroupArgs struct {
+	GroupID string `json:"group_id"`
+}
+
+type gitlabListProjectsArgs struct {
+	gitlabGroupArgs
+	Search string `json:"search"`
+}
+
+type gitlabProjectArgs struct {
+	ProjectPath string `json:"project_path"`
+}
+
+type gitlabListMRsArgs struct {
+	gitlabProjectArgs
+	State string `json:"state"`
+}
+
+type gitlabMRArgs struct {
+	gitlabProjectArgs
+	MRIID int `json:"mr_iid"`
+}
+
+type gitlabMRCommentArgs struct {
+	gitlabMRArgs
+	Comment string `json:"comment"`
+}
+
+type gitlabFileContentArgs struct {
+	gitlabProjectArgs
+	FilePath string `json:"file_path"`
+	Ref      string `json:"ref"`
+}
+
+type gitlabListPipelinesArgs struct {
+	gitlabProjectArgs
+	Status string `json:"status"`
+}
+
+type gitlabDateRangeArgs struct {
+	Since string `json:"since"`
+	Until string `json:"until"`
+}
+
+type gitlabListCommitsArgs struct {
+	gitlabProjectArgs
+	gitlabDateRangeArgs
+	Ref string `json:"ref"`
+}
+
+type gitlabCommitArgs struct {
+	gitlabProjectArgs
+	CommitSHA string `json:"commit_sha"`
+}
+
+type gitlabUserEventsArgs struct {
+	gitlabDateRangeArgs
+	Username string `json:"username"`
 }
 
-func listProjectsHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
-	groupID := arguments["group_id"].(string)
+type gitlabCreateMRArgs struct {
+	gitlabProjectArgs
+	SourceBranch string `json:"source_branch"`
+	TargetBranch string `json:"target_branch"`
+	Title        string `json:"title"`
+	Description  string `json:"description"`
+}
+
+func listProjectsHandler(args gitlabListProjectsArgs) (*mcp.CallToolResult, error) {
 
 	opt := &gitlab.ListGroupProjectsOptions{
 		Archived: gitlab.Ptr(false),
@@ -148,11 +232,11 @@ func listProjectsHandler(arguments map[string]interface{}) (*mcp.CallToolResult,
 		},
 	}
 
-	if search, ok := arguments["search"]; ok {
-		opt.Search = gitlab.Ptr(search.(string))
+	if args.Search != "" {
+		opt.Search = gitlab.Ptr(args.Search)
 	}
 
-	projects, _, err := gitlabClient().Groups.ListGroupProjects(groupID, opt)
+	projects, _, err := gitlabClient().Groups.ListGroupProjects(args.GroupID, opt)
 	if err != nil {
 		return nil, fmt.Errorf("failed to search projects: %v", err)
 	}
@@ -166,8 +250,8 @@ func listProjectsHandler(arguments map[string]interface{}) (*mcp.CallToolResult,
 	return mcp.NewToolResultText(result), nil
 }
 
-func getProjectHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
-	projectID := arguments["project_path"].(string)
+func getProjectHandler(args gitlabProjectArgs) (*mcp.CallToolResult, error) {
+	projectID := args.ProjectPath
 
 	// Get project details
 	project, _, err := gitlabClient().Projects.GetProject(projectID, nil)
@@ -207,22 +291,15 @@ func getProjectHandler(arguments map[string]interface{}) (*mcp.CallToolResult, e
 	return mcp.NewToolResultText(result), nil
 }
 
-func listMergeRequestsHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
-	projectID := arguments["project_path"].(string)
-
-	state := "all"
-	if value, ok := arguments["state"]; ok {
-		state = value.(string)
-	}
-
+func listMergeRequestsHandler(args gitlabListMRsArgs) (*mcp.CallToolResult, error) {
 	opt := &gitlab.ListProjectMergeRequestsOptions{
-		State: gitlab.String(state),
+		State: gitlab.String(args.State),
 		ListOptions: gitlab.ListOptions{
 			PerPage: 100,
 		},
 	}
 
-	mrs, _, err := gitlabClient().MergeRequests.ListProjectMergeRequests(projectID, opt)
+	mrs, _, err := gitlabClient().MergeRequests.ListProjectMergeRequests(args.ProjectPath, opt)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list merge requests: %v", err)
 	}
@@ -279,23 +356,15 @@ func listMergeRequestsHandler(arguments map[string]interface{}) (*mcp.CallToolRe
 	return mcp.NewToolResultText(result.String()), nil
 }
 
-func getMergeRequestHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
-	projectID := arguments["project_path"].(string)
-	mrIIDStr := arguments["mr_iid"].(string)
-
-	mrIID, err := strconv.Atoi(mrIIDStr)
-	if err != nil {
-		return nil, fmt.Errorf("invalid mr_iid: %v", err)
-	}
-
+func getMergeRequestHandler(args gitlabMRArgs) (*mcp.CallToolResult, error) {
 	// Get MR details
-	mr, _, err := gitlabClient().MergeRequests.GetMergeRequest(projectID, mrIID, nil)
+	mr, _, err := gitlabClient().MergeRequests.GetMergeRequest(args.ProjectPath, args.MRIID, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get merge request: %v", err)
 	}
 
 	// Get detailed changes
-	changes, _, err := gitlabClient().MergeRequests.ListMergeRequestDiffs(projectID, mrIID, nil)
+	changes, _, err := gitlabClient().MergeRequests.ListMergeRequestDiffs(args.ProjectPath, args.MRIID, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get merge request changes: %v", err)
 	}
@@ -351,21 +420,12 @@ func getMergeRequestHandler(arguments map[string]interface{}) (*mcp.CallToolResu
 	return mcp.NewToolResultText(result.String()), nil
 }
 
-func commentOnMergeRequestHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
-	projectID := arguments["project_path"].(string)
-	mrIIDStr := arguments["mr_iid"].(string)
-	comment := arguments["comment"].(string)
-
-	mrIID, err := strconv.Atoi(mrIIDStr)
-	if err != nil {
-		return nil, fmt.Errorf("invalid mr_iid: %v", err)
-	}
-
+func commentOnMergeRequestHandler(args gitlabMRCommentArgs) (*mcp.CallToolResult, error) {
 	opt := &gitlab.CreateMergeRequestNoteOptions{
-		Body: gitlab.String(comment),
+		Body: gitlab.String(args.Comment),
 	}
 
-	note, _, err := gitlabClient().Notes.CreateMergeRequestNote(projectID, mrIID, opt)
+	note, _, err := gitlabClient().Notes.CreateMergeRequestNote(args.ProjectPath, args.MRIID, opt)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create comment: %v", err)
 	}
@@ -376,17 +436,11 @@ func commentOnMergeRequestHandler(arguments map[string]interface{}) (*mcp.CallTo
 	return mcp.NewToolResultText(result), nil
 }
 
-func getFileContentHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
-	projectID := arguments["project_path"].(string)
-	filePath := arguments["file_path"].(string)
-
-	ref := "develop"
-	if value, ok := arguments["ref"]; ok {
-		ref = value.(string)
-	}
+func getFileContentHandler(args gitlabFileContentArgs) (*mcp.CallToolResult, error) {
+	filePath, ref := args.FilePath, args.Ref
 
 	// Get raw file content
-	fileContent, _, err := gitlabClient().RepositoryFiles.GetRawFile(projectID, filePath, &gitlab.GetRawFileOptions{
+	fileContent, _, err := gitlabClient().RepositoryFiles.GetRawFile(args.ProjectPath, filePath, &gitlab.GetRawFileOptions{
 		Ref: gitlab.Ptr(ref),
 	})
 	if err != nil {
@@ -404,13 +458,12 @@ func getFileContentHandler(arguments map[string]interface{}) (*mcp.CallToolResul
 	return mcp.NewToolResultText(result.String()), nil
 }
 
-func listPipelinesHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
-	projectID := arguments["project_path"].(string)
-	status := arguments["status"].(string)
+func listPipelinesHandler(args gitlabListPipelinesArgs) (*mcp.CallToolResult, error) {
+	projectID := args.ProjectPath
 
 	opt := &gitlab.ListProjectPipelinesOptions{}
-	if status != "all" {
-		opt.Status = gitlab.Ptr(gitlab.BuildStateValue(status))
+	if args.Status != "all" {
+		opt.Status = gitlab.Ptr(gitlab.BuildStateValue(args.Status))
 	}
 
 	pipelines, _, err := gitlabClient().Pipelines.ListProjectPipelines(projectID, opt)
@@ -433,21 +486,12 @@ func listPipelinesHandler(arguments map[string]interface{}) (*mcp.CallToolResult
 	return mcp.NewToolResultText(result.String()), nil
 }
 
-func listCommitsHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
-	projectID := arguments["project_path"].(string)
-	since, ok := arguments["since"].(string)
-	if !ok {
-		return nil, fmt.Errorf("missing required argument: since")
-	}
+func listCommitsHandler(args gitlabListCommitsArgs) (*mcp.CallToolResult, error) {
+	projectID, since, ref := args.ProjectPath, args.Since, args.Ref
 
 	until := time.Now().Format("2006-01-02")
-	if value, ok := arguments["until"]; ok {
-		until = value.(string)
-	}
-
-	ref := "develop"
-	if value, ok := arguments["ref"]; ok {
-		ref = value.(string)
+	if args.Until != "" {
+		until = args.Until
 	}
 
 	sinceTime, err := time.Parse("2006-01-02", since)
@@ -493,9 +537,8 @@ func listCommitsHandler(arguments map[string]interface{}) (*mcp.CallToolResult,
 	return mcp.NewToolResultText(result.String()), nil
 }
 
-func getCommitDetailsHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
-	projectID := arguments["project_path"].(string)
-	commitSHA := arguments["commit_sha"].(string)
+func getCommitDetailsHandler(args gitlabCommitArgs) (*mcp.CallToolResult, error) {
+	projectID, commitSHA := args.ProjectPath, args.CommitSHA
 
 	commit, _, err := gitlabClient().Commits.GetCommit(projectID, commitSHA, nil)
 	if err != nil {
@@ -557,16 +600,12 @@ func getDiffStatus(diff *gitlab.Diff) string {
 	return "Modified"
 }
 
-func listUserEventsHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
-	username := arguments["username"].(string)
-	since, ok := arguments["since"].(string)
-	if !ok {
-		return nil, fmt.Errorf("missing required argument: since")
-	}
+func listUserEventsHandler(args gitlabUserEventsArgs) (*mcp.CallToolResult, error) {
+	username, since := args.Username, args.Since
 
 	until := time.Now().Format("2006-01-02")
-	if value, ok := arguments["until"]; ok {
-		until = value.(string)
+	if args.Until != "" {
+		until = args.Until
 	}
 
 	sinceTime, err := time.Parse("2006-01-02", since)
@@ -626,8 +665,8 @@ func listUserEventsHandler(arguments map[string]interface{}) (*mcp.CallToolResul
 	return mcp.NewToolResultText(result.String()), nil
 }
 
-func listGroupUsersHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
-	groupID := arguments["group_id"].(string)
+func listGroupUsersHandler(args gitlabGroupArgs) (*mcp.CallToolResult, error) {
+	groupID := args.GroupID
 
 	opt := &gitlab.ListGroupMembersOptions{
 		ListOptions: gitlab.ListOptions{
@@ -676,24 +715,19 @@ func getAccessLevelString(level gitlab.AccessLevelValue) string {
 	}
 }
 
-func createMergeRequestHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
-	projectID := arguments["project_path"].(string)
-	sourceBranch := arguments["source_branch"].(string)
-	targetBranch := arguments["target_branch"].(string)
-	title := arguments["title"].(string)
-
+func createMergeRequestHandler(args gitlabCreateMRArgs) (*mcp.CallToolResult, error) {
 	opt := &gitlab.CreateMergeRequestOptions{
-		Title:        gitlab.String(title),
-		SourceBranch: gitlab.String(sourceBranch),
-		TargetBranch: gitlab.String(targetBranch),
+		Title:        gitlab.String(args.Title),
+		SourceBranch: gitlab.String(args.SourceBranch),
+		TargetBranch: gitlab.String(args.TargetBranch),
 	}
 
 	// Add description if provided
-	if description, ok := arguments["description"]; ok {
-		opt.Description = gitlab.String(description.(string))
+	if args.Description != "" {
+		opt.Description = gitlab.String(args.Description)
 	}
 
-	mr, _, err := gitlabClient().MergeRequests.CreateMergeRequest(projectID, opt)
+	mr, _, err := gitlabClient().MergeRequests.CreateMergeRequest(args.ProjectPath, opt)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create merge request: %v", err)
 	}
@@ -716,14 +750,8 @@ func createMergeRequestHandler(arguments map[string]interface{}) (*mcp.CallToolR
 	return mcp.NewToolResultText(result.String()), nil
 }
 
-func listMRCommentsHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
-	projectID := arguments["project_path"].(string)
-	mrIIDStr := arguments["mr_iid"].(string)
-
-	mrIID, err := strconv.Atoi(mrIIDStr)
-	if err != nil {
-		return nil, fmt.Errorf("invalid mr_iid: %v", err)
-	}
+func listMRCommentsHandler(args gitlabMRArgs) (*mcp.CallToolResult, error) {
+	projectID, mrIID := args.ProjectPath, args.MRIID
 
 	opt := &gitlab.ListMergeRequestNotesOptions{
 		ListOptions: gitlab.ListOptions{
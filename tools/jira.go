@@ -3,7 +3,7 @@ package tools
 import (
 	"context"
 	"fmt"
-	"strconv"
+	"net/http"
 	"strings"
 	"time"
 
@@ -12,36 +12,66 @@ import (
 	"github.com/mark3labs/mcp-go/server"
 	"github.com/nguyenvanduocit/dev-kit/services"
 	"github.com/nguyenvanduocit/dev-kit/util"
+	"github.com/nguyenvanduocit/dev-kit/util/markup"
 )
 
+func init() {
+	RegisterGroup(ToolGroup{
+		Name:            "jira",
+		Description:     "Issues, sprints, statuses and transitions in Jira",
+		RequiredEnvFunc: services.AtlassianRequiredEnv,
+		Offline:         true,
+		Access:          AccessWrite,
+		Register:        infallible(RegisterJiraTool),
+	})
+}
+
 // RegisterJiraTool registers the Jira tools to the server
 func RegisterJiraTool(s *server.MCPServer) {
 	// Get issue details tool
 	jiraGetIssueTool := mcp.NewTool("jira_get_issue",
-		mcp.WithDescription("Retrieve detailed information about a specific Jira issue including its status, assignee, description, subtasks, and available transitions"),
+		mcp.WithDescription("Retrieve detailed information about a specific Jira issue including its status, assignee, description, parent, subtasks, links, attachments, and available transitions"),
 		mcp.WithString("issue_key", mcp.Required(), mcp.Description("The unique identifier of the Jira issue (e.g., KP-2, PROJ-123)")),
 	)
-	s.AddTool(jiraGetIssueTool, util.ErrorGuard(jiraIssueHandler))
+	s.AddTool(jiraGetIssueTool, util.ErrorGuard(util.TypedHandler(jiraGetIssueTool, jiraIssueHandler)))
 
 	// Search issues tool
 	jiraSearchTool := mcp.NewTool("jira_search_issue",
-		mcp.WithDescription("Search for Jira issues using JQL (Jira Query Language). Returns key details like summary, status, assignee, and priority for matching issues"),
+		mcp.WithDescription("Search for Jira issues using JQL (Jira Query Language). Returns key details like summary, status, assignee, and priority for matching issues, plus any other fields asked for. The JQL is validated first and errors are explained. Can also count the matching issues grouped by a field"),
 		mcp.WithString("jql", mcp.Required(), mcp.Description("JQL query string (e.g., 'project = KP AND status = \"In Progress\"')")),
+		mcp.WithString("fields", mcp.Description("Comma-separated extra fields to return, by name or ID, custom fields included (e.g., labels, fixVersions, Story point estimate)")),
+		mcp.WithString("expand", mcp.Description("Comma-separated extra data to return: changelog, renderedFields")),
+		mcp.WithString("order_by", mcp.Description("Order of the results, replacing any ORDER BY in the JQL (e.g., priority DESC, updated)")),
+		mcp.WithNumber("max_results", mcp.DefaultNumber(30), mcp.Min(0), mcp.Max(100), mcp.Description("Maximum number of issues to return, 0 to only return the counts of group_by")),
+		mcp.WithNumber("start_at", mcp.DefaultNumber(0), mcp.Min(0), mcp.Description("Index of the first issue to return, for paging")),
+		mcp.WithString("group_by", mcp.Description("Count all matching issues grouped by this field, by name or ID (e.g., status, assignee, labels)")),
 	)
 
 	// List sprints tool
 	jiraListSprintTool := mcp.NewTool("jira_list_sprints",
-		mcp.WithDescription("List all active and future sprints for a specific Jira board, including sprint IDs, names, states, and dates"),
-		mcp.WithString("board_id", mcp.Required(), mcp.Description("Numeric ID of the Jira board (can be found in board URL)")),
+		mcp.WithDescription("List the sprints of a specific Jira board, including sprint IDs, names, states, goals and dates"),
+		mcp.WithString("board_id", mcp.Required(), mcp.Description("Numeric ID of the Jira board, see jira_list_boards")),
+		mcp.WithString("state", mcp.DefaultString("active,future"), mcp.Description("Comma separated sprint states to list: active, future, closed")),
 	)
 
 	// Create issue tool
 	jiraCreateIssueTool := mcp.NewTool("jira_create_issue",
-		mcp.WithDescription("Create a new Jira issue with specified details. Returns the created issue's key, ID, and URL"),
+		mcp.WithDescription("Create a new Jira issue with specified details. Returns the created issue's key, ID, and URL. Use jira_get_create_meta to find the required fields and their allowed values"),
 		mcp.WithString("project_key", mcp.Required(), mcp.Description("Project identifier where the issue will be created (e.g., KP, PROJ)")),
 		mcp.WithString("summary", mcp.Required(), mcp.Description("Brief title or headline of the issue")),
-		mcp.WithString("description", mcp.Required(), mcp.Description("Detailed explanation of the issue")),
+		mcp.WithString("description", mcp.Required(), mcp.Description("Detailed explanation of the issue in Markdown")),
 		mcp.WithString("issue_type", mcp.Required(), mcp.Description("Type of issue to create (common types: Bug, Task, Story, Epic)")),
+		mcp.WithString("assignee", mcp.Description("Account ID of the assignee (optional)")),
+		mcp.WithString("reporter", mcp.Description("Account ID of the reporter (optional, defaults to the current user)")),
+		mcp.WithString("priority", mcp.Description("Priority name (optional, e.g., High)")),
+		mcp.WithString("labels", mcp.Description("Comma separated labels (optional, e.g., backend,urgent)")),
+		mcp.WithString("components", mcp.Description("Comma separated component names (optional)")),
+		mcp.WithString("fix_versions", mcp.Description("Comma separated fix version names (optional)")),
+		mcp.WithString("parent", mcp.Description("Key of the parent issue, required for subtasks (optional, e.g., KP-12)")),
+		mcp.WithString("epic", mcp.Description("Key of the epic the issue belongs to (optional, e.g., KP-1)")),
+		mcp.WithNumber("story_points", mcp.Min(0), mcp.Description("Story point estimate (optional)")),
+		mcp.WithString("due_date", mcp.Pattern(`^\d{4}-\d{2}-\d{2}$`), mcp.Description("Due date as YYYY-MM-DD (optional)")),
+		mcp.WithString("custom_fields", mcp.Description("JSON object of other fields by name or ID, e.g. {\"Team\": \"Platform\", \"customfield_10031\": \"Production, Staging\"}. Options, users and versions can be given by name; objects are sent as is (optional)")),
 	)
 
 	// Update issue tool
@@ -49,57 +79,131 @@ func RegisterJiraTool(s *server.MCPServer) {
 		mcp.WithDescription("Modify an existing Jira issue's details. Supports partial updates - only specified fields will be changed"),
 		mcp.WithString("issue_key", mcp.Required(), mcp.Description("The unique identifier of the issue to update (e.g., KP-2)")),
 		mcp.WithString("summary", mcp.Description("New title for the issue (optional)")),
-		mcp.WithString("description", mcp.Description("New description for the issue (optional)")),
+		mcp.WithString("description", mcp.Description("New description for the issue in Markdown (optional)")),
 	)
 
 	// Add status list tool
 	jiraStatusListTool := mcp.NewTool("jira_list_statuses",
-		mcp.WithDescription("Retrieve all available issue status IDs and their names for a specific Jira project"),
+		mcp.WithDescription("Retrieve all available issue status IDs and their names for a specific Jira project, by issue type. jira_describe_project shows the same along with the rest of the project configuration"),
 		mcp.WithString("project_key", mcp.Required(), mcp.Description("Project identifier (e.g., KP, PROJ)")),
 	)
 
 	// Add new tool definition in RegisterJiraTool function
 	jiraTransitionTool := mcp.NewTool("jira_transition_issue",
-		mcp.WithDescription("Transition an issue through its workflow using a valid transition ID. Get available transitions from jira_get_issue"),
+		mcp.WithDescription("Transition an issue through its workflow, either to a target status by name or with a transition ID from jira_get_issue. When no transition leads directly to the status, the issue is moved through intermediate statuses"),
 		mcp.WithString("issue_key", mcp.Required(), mcp.Description("The issue to transition (e.g., KP-123)")),
-		mcp.WithString("transition_id", mcp.Required(), mcp.Description("Transition ID from available transitions list")),
-		mcp.WithString("comment", mcp.Description("Optional comment to add with transition")),
+		mcp.WithString("status", mcp.Description("Name of the target status (e.g., In Review); either status or transition_id is required")),
+		mcp.WithString("transition_id", mcp.Description("Transition ID from available transitions list")),
+		mcp.WithString("comment", mcp.Description("Optional comment in Markdown to add with transition")),
+		mcp.WithString("resolution", mcp.Description("Resolution name for transitions that require one (optional, e.g., Done, Won't Do)")),
+		mcp.WithString("fields", mcp.Description("JSON object of other transition screen fields by name or ID (optional, e.g., {\"Fix versions\": \"1.2\"})")),
 	)
 
-	s.AddTool(jiraSearchTool, util.ErrorGuard(jiraSearchHandler))
-	s.AddTool(jiraListSprintTool, util.ErrorGuard(jiraListSprintHandler))
-	s.AddTool(jiraCreateIssueTool, util.ErrorGuard(jiraCreateIssueHandler))
-	s.AddTool(jiraUpdateIssueTool, util.ErrorGuard(jiraUpdateIssueHandler))
-	s.AddTool(jiraStatusListTool, util.ErrorGuard(jiraGetStatusesHandler))
-	s.AddTool(jiraTransitionTool, util.ErrorGuard(jiraTransitionIssueHandler))
+	s.AddTool(jiraSearchTool, util.ErrorGuard(util.TypedHandler(jiraSearchTool, jiraSearchHandler)))
+	s.AddTool(jiraListSprintTool, util.ErrorGuard(util.TypedHandler(jiraListSprintTool, jiraListSprintHandler)))
+	s.AddTool(jiraCreateIssueTool, util.ErrorGuard(util.TypedHandler(jiraCreateIssueTool, jiraCreateIssueHandler)))
+	s.AddTool(jiraUpdateIssueTool, util.ErrorGuard(util.TypedHandler(jiraUpdateIssueTool, jiraUpdateIssueHandler)))
+	s.AddTool(jiraStatusListTool, util.ErrorGuard(util.TypedHandler(jiraStatusListTool, jiraGetStatusesHandler)))
+	s.AddTool(jiraTransitionTool, util.ErrorGuard(util.TypedHandler(jiraTransitionTool, jiraTransitionIssueHandler)))
+
+	registerJiraCommentTools(s)
+	registerJiraFieldTools(s)
+	registerJiraSprintTools(s)
+	registerJiraBoardTools(s)
+	registerJiraSprintReportTools(s)
+	registerJiraHistoryTools(s)
+	registerJiraWorklogTools(s)
+	registerJiraLinkTools(s)
+	registerJiraAttachmentTools(s)
+	registerJiraUserTools(s)
+	registerJiraVersionTools(s)
+	registerJiraBulkTools(s)
+	registerJiraFilterTools(s)
+	registerJiraProjectTools(s)
+	registerJiraServiceDeskTools(s)
 }
 
-func jiraUpdateIssueHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+// jiraRequest sends a request to a Jira REST endpoint that go-atlassian does not cover, or
+// covers incorrectly. The response body is decoded into out when it is not nil.
+func jiraRequest(ctx context.Context, method, endpoint string, payload, out interface{}) (*models.ResponseScheme, error) {
 	client := services.JiraClient()
 
-	issueKey, ok := arguments["issue_key"].(string)
-	if !ok {
-		return nil, fmt.Errorf("issue_key argument is required")
+	request, err := client.NewRequest(ctx, method, endpoint, "", payload)
+	if err != nil {
+		return nil, err
 	}
 
+	return client.Call(request, out)
+}
+
+type jiraIssueKeyArgs struct {
+	IssueKey string `json:"issue_key"`
+}
+
+type jiraProjectArgs struct {
+	ProjectKey string `json:"project_key"`
+}
+
+type jiraSearchArgs struct {
+	JQL        string `json:"jql"`
+	Fields     string `json:"fields"`
+	Expand     string `json:"expand"`
+	OrderBy    string `json:"order_by"`
+	MaxResults int    `json:"max_results"`
+	StartAt    int    `json:"start_at"`
+	GroupBy    string `json:"group_by"`
+}
+
+type jiraListSprintArgs struct {
+	BoardID int    `json:"board_id"`
+	State   string `json:"state"`
+}
+
+type jiraCreateIssueArgs struct {
+	ProjectKey   string   `json:"project_key"`
+	Summary      string   `json:"summary"`
+	Description  string   `json:"description"`
+	IssueType    string   `json:"issue_type"`
+	Assignee     string   `json:"assignee"`
+	Reporter     string   `json:"reporter"`
+	Priority     string   `json:"priority"`
+	Labels       string   `json:"labels"`
+	Components   string   `json:"components"`
+	FixVersions  string   `json:"fix_versions"`
+	Parent       string   `json:"parent"`
+	Epic         string   `json:"epic"`
+	StoryPoints  *float64 `json:"story_points"`
+	DueDate      string   `json:"due_date"`
+	CustomFields string   `json:"custom_fields"`
+}
+
+type jiraUpdateIssueArgs struct {
+	IssueKey    string `json:"issue_key"`
+	Summary     string `json:"summary"`
+	Description string `json:"description"`
+}
+
+func jiraUpdateIssueHandler(args jiraUpdateIssueArgs) (*mcp.CallToolResult, error) {
+	client := services.JiraClient()
+
 	// Create update payload
 	payload := &models.IssueSchemeV2{
 		Fields: &models.IssueFieldsSchemeV2{},
 	}
 
 	// Check and add optional fields if provided
-	if summary, ok := arguments["summary"].(string); ok && summary != "" {
-		payload.Fields.Summary = summary
+	if args.Summary != "" {
+		payload.Fields.Summary = args.Summary
 	}
 
-	if description, ok := arguments["description"].(string); ok && description != "" {
-		payload.Fields.Description = description
+	if args.Description != "" {
+		payload.Fields.Description = markup.MarkdownToWiki(args.Description)
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second)
 	defer cancel()
 
-	response, err := client.Issue.Update(ctx, issueKey, true, payload, nil, nil)
+	response, err := client.Issue.Update(ctx, args.IssueKey, true, payload, nil, nil)
 	if err != nil {
 		if response != nil {
 			return nil, fmt.Errorf("failed to update issue: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
@@ -107,45 +211,20 @@ func jiraUpdateIssueHandler(arguments map[string]interface{}) (*mcp.CallToolResu
 		return nil, fmt.Errorf("failed to update issue: %v", err)
 	}
 
-	return mcp.NewToolResultText("Issue updated successfully!"), nil
+	return mcp.NewToolResultText(fmt.Sprintf("Issue updated successfully!\nKey: %s\nURL: %s", args.IssueKey, services.JiraBrowseURL(args.IssueKey))), nil
 }
 
-func jiraCreateIssueHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
-	client := services.JiraClient()
-
-	projectKey, ok := arguments["project_key"].(string)
-	if !ok {
-		return nil, fmt.Errorf("project_key argument is required")
-	}
-
-	summary, ok := arguments["summary"].(string)
-	if !ok {
-		return nil, fmt.Errorf("summary argument is required")
-	}
-
-	description, ok := arguments["description"].(string)
-	if !ok {
-		return nil, fmt.Errorf("description argument is required")
-	}
-
-	issueType, ok := arguments["issue_type"].(string)
-	if !ok {
-		return nil, fmt.Errorf("issue_type argument is required")
-	}
-
+func jiraCreateIssueHandler(args jiraCreateIssueArgs) (*mcp.CallToolResult, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second)
 	defer cancel()
 
-	var payload = models.IssueSchemeV2{
-		Fields: &models.IssueFieldsSchemeV2{
-			Summary:     summary,
-			Project:     &models.ProjectScheme{Key: projectKey},
-			Description: description,
-			IssueType:   &models.IssueTypeScheme{Name: issueType},
-		},
+	fields, err := args.fields(ctx)
+	if err != nil {
+		return nil, err
 	}
 
-	issue, response, err := client.Issue.Create(ctx, &payload, nil)
+	issue := new(models.IssueResponseScheme)
+	response, err := jiraRequest(ctx, http.MethodPost, "rest/api/2/issue", map[string]interface{}{"fields": fields}, issue)
 	if err != nil {
 		if response != nil {
 			return nil, fmt.Errorf("failed to create issue: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
@@ -153,123 +232,140 @@ func jiraCreateIssueHandler(arguments map[string]interface{}) (*mcp.CallToolResu
 		return nil, fmt.Errorf("failed to create issue: %v", err)
 	}
 
-	result := fmt.Sprintf("Issue created successfully!\nKey: %s\nID: %s\nURL: %s", issue.Key, issue.ID, issue.Self)
+	result := fmt.Sprintf("Issue created successfully!\nKey: %s\nID: %s\nURL: %s", issue.Key, issue.ID, services.JiraBrowseURL(issue.Key))
 	return mcp.NewToolResultText(result), nil
 }
 
-func jiraListSprintHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
-	boardIDStr, ok := arguments["board_id"].(string)
-	if !ok {
-		return nil, fmt.Errorf("board_id argument is required")
+// fields builds the "fields" object of the create request
+func (args jiraCreateIssueArgs) fields(ctx context.Context) (map[string]interface{}, error) {
+	fields := map[string]interface{}{
+		"project":     map[string]interface{}{"key": args.ProjectKey},
+		"summary":     args.Summary,
+		"description": markup.MarkdownToWiki(args.Description),
+		"issuetype":   map[string]interface{}{"name": args.IssueType},
 	}
 
-	boardID, err := strconv.Atoi(boardIDStr)
-	if err != nil {
-		return nil, fmt.Errorf("invalid board_id: %v", err)
+	if args.Assignee != "" {
+		fields["assignee"] = jiraUserRef(args.Assignee)
 	}
-
-	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second)
-	defer cancel()
-
-	sprints, response, err := services.AgileClient().Board.Sprints(ctx, boardID, 0, 50, []string{"active", "future"})
-	if err != nil {
-		if response != nil {
-			return nil, fmt.Errorf("failed to get sprints: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
+	if args.Reporter != "" {
+		fields["reporter"] = jiraUserRef(args.Reporter)
+	}
+	if args.Priority != "" {
+		fields["priority"] = map[string]interface{}{"name": args.Priority}
+	}
+	if args.Labels != "" {
+		fields["labels"] = splitList(args.Labels)
+	}
+	if args.Components != "" {
+		components, err := jiraFieldValue(&models.IssueFieldSchemaScheme{Type: "array", Items: "component"}, args.Components)
+		if err != nil {
+			return nil, fmt.Errorf("components: %v", err)
 		}
-		return nil, fmt.Errorf("failed to get sprints: %v", err)
+		fields["components"] = components
 	}
-
-	if len(sprints.Values) == 0 {
-		return mcp.NewToolResultText("No sprints found for this board."), nil
+	if args.FixVersions != "" {
+		fixVersions, err := jiraFieldValue(&models.IssueFieldSchemaScheme{Type: "array", Items: "version"}, args.FixVersions)
+		if err != nil {
+			return nil, fmt.Errorf("fixVersions: %v", err)
+		}
+		fields["fixVersions"] = fixVersions
 	}
-
-	var result string
-	for _, sprint := range sprints.Values {
-		result += fmt.Sprintf("ID: %d\nName: %s\nState: %s\nStartDate: %s\nEndDate: %s\n\n", sprint.ID, sprint.Name, sprint.State, sprint.StartDate, sprint.EndDate)
+	if args.Parent != "" {
+		fields["parent"] = map[string]interface{}{"key": args.Parent}
 	}
-
-	return mcp.NewToolResultText(result), nil
-}
-
-func jiraSearchHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
-	client := services.JiraClient()
-
-	// Get search text from arguments
-	jql, ok := arguments["jql"].(string)
-	if !ok {
-		return nil, fmt.Errorf("jql argument is required")
+	if args.DueDate != "" {
+		fields["duedate"] = args.DueDate
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second)
-	defer cancel()
+	customFields, err := parseJiraFieldsArgument("custom_fields", args.CustomFields)
+	if err != nil {
+		return nil, err
+	}
+	if args.Epic == "" && args.StoryPoints == nil && len(customFields) == 0 {
+		return fields, nil
+	}
 
-	searchResult, response, err := client.Issue.Search.Get(ctx, jql, nil, nil, 0, 30, "")
+	// Epics, story points and custom fields are custom fields whose IDs differ between instances
+	catalog, err := jiraFieldCatalog(ctx)
 	if err != nil {
-		if response != nil {
-			return nil, fmt.Errorf("failed to search issues: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
+		return nil, err
+	}
+
+	if args.Epic != "" {
+		epicLink := jiraEpicLinkField(catalog)
+		switch {
+		case epicLink != nil:
+			fields[epicLink.ID] = args.Epic
+		case args.Parent != "" && args.Parent != args.Epic:
+			return nil, fmt.Errorf("this Jira links epics as parents, so parent and epic cannot both be given")
+		default:
+			fields["parent"] = map[string]interface{}{"key": args.Epic}
 		}
-		return nil, fmt.Errorf("failed to search issues: %v", err)
 	}
 
-	if len(searchResult.Issues) == 0 {
-		return mcp.NewToolResultText("No issues found matching the search criteria."), nil
+	if args.StoryPoints != nil {
+		storyPoints := jiraStoryPointsField(catalog)
+		if storyPoints == nil {
+			return nil, fmt.Errorf("no story points field found, pass it in custom_fields instead")
+		}
+		fields[storyPoints.ID] = *args.StoryPoints
 	}
 
-	var sb strings.Builder
-	for _, issue := range searchResult.Issues {
-		sb.WriteString(fmt.Sprintf("Key: %s\n", issue.Key))
-
-		if issue.Fields.Summary != "" {
-			sb.WriteString(fmt.Sprintf("Summary: %s\n", issue.Fields.Summary))
+	for name, value := range customFields {
+		field := findJiraField(catalog, name)
+		if field == nil {
+			return nil, fmt.Errorf("unknown field %q, use jira_get_create_meta to list the fields of the project", name)
 		}
-
-		if issue.Fields.Status != nil && issue.Fields.Status.Name != "" {
-			sb.WriteString(fmt.Sprintf("Status: %s\n", issue.Fields.Status.Name))
+		converted, err := jiraFieldValue(field.Schema, value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for field %s: %v", field.Name, err)
 		}
+		fields[field.ID] = converted
+	}
 
-		if issue.Fields.Created != "" {
-			sb.WriteString(fmt.Sprintf("Created: %s\n", issue.Fields.Created))
-		}
+	return fields, nil
+}
 
-		if issue.Fields.Updated != "" {
-			sb.WriteString(fmt.Sprintf("Updated: %s\n", issue.Fields.Updated))
+func jiraListSprintHandler(args jiraListSprintArgs) (*mcp.CallToolResult, error) {
+	states := splitList(strings.ToLower(args.State))
+	if len(states) == 0 {
+		states = []string{"active", "future"}
+	}
+	for _, state := range states {
+		if state != "active" && state != "future" && state != "closed" {
+			return nil, fmt.Errorf("invalid sprint state %q, expected active, future or closed", state)
 		}
+	}
 
-		if issue.Fields.Assignee != nil {
-			sb.WriteString(fmt.Sprintf("Assignee: %s\n", issue.Fields.Assignee.DisplayName))
-		} else {
-			sb.WriteString("Assignee: Unassigned\n")
-		}
+	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second)
+	defer cancel()
 
-		if issue.Fields.Priority != nil {
-			sb.WriteString(fmt.Sprintf("Priority: %s\n", issue.Fields.Priority.Name))
-		} else {
-			sb.WriteString("Priority: Unset\n")
-		}
+	sprints, err := jiraBoardSprints(ctx, args.BoardID, states)
+	if err != nil {
+		return nil, err
+	}
 
-		if issue.Fields.Resolutiondate != "" {
-			sb.WriteString(fmt.Sprintf("Resolution date: %s\n", issue.Fields.Resolutiondate))
-		}
+	if len(sprints) == 0 {
+		return mcp.NewToolResultText("No sprints found for this board."), nil
+	}
 
-		sb.WriteString("\n")
+	var result strings.Builder
+	for _, sprint := range sprints {
+		result.WriteString(formatJiraSprint((*models.SprintScheme)(sprint)))
+		result.WriteString("\n")
 	}
 
-	return mcp.NewToolResultText(sb.String()), nil
+	return mcp.NewToolResultText(result.String()), nil
 }
 
-func jiraIssueHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+func jiraIssueHandler(args jiraIssueKeyArgs) (*mcp.CallToolResult, error) {
 	client := services.JiraClient()
 
-	// Get issue key from arguments
-	issueKey, ok := arguments["issue_key"].(string)
-	if !ok {
-		return nil, fmt.Errorf("issue_key argument is required")
-	}
-
 	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second)
 	defer cancel()
 
-	issue, response, err := client.Issue.Get(ctx, issueKey, nil, []string{"transitions"})
+	issue, response, err := client.Issue.Get(ctx, args.IssueKey, nil, []string{"transitions"})
 	if err != nil {
 		if response != nil {
 			return nil, fmt.Errorf("failed to get issue: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
@@ -286,6 +382,41 @@ func jiraIssueHandler(arguments map[string]interface{}) (*mcp.CallToolResult, er
 		}
 	}
 
+	// Build parent and links strings
+	var parent string
+	if issue.Fields.Parent != nil {
+		parent = fmt.Sprintf("Parent: %s", issue.Fields.Parent.Key)
+		if issue.Fields.Parent.Fields != nil {
+			parent += ": " + issue.Fields.Parent.Fields.Summary
+		}
+		parent += "\n"
+	}
+
+	var links string
+	if len(issue.Fields.IssueLinks) > 0 {
+		links = "\nLinks:\n"
+		for _, link := range issue.Fields.IssueLinks {
+			links += formatJiraIssueLink(link)
+		}
+	}
+
+	var attachments string
+	if files := parseJiraIssueAttachments(response.Bytes.Bytes()); len(files) > 0 {
+		attachments = fmt.Sprintf("\nAttachments (%d):\n", len(files))
+		for _, file := range files {
+			attachments += formatJiraAttachment(file)
+		}
+	}
+
+	// Build comments string
+	var comments string
+	if issue.Fields.Comment != nil && len(issue.Fields.Comment.Comments) > 0 {
+		comments = fmt.Sprintf("\nComments (%d):\n", issue.Fields.Comment.Total)
+		for _, comment := range issue.Fields.Comment.Comments {
+			comments += formatJiraComment(comment) + "\n"
+		}
+	}
+
 	// Build transitions string
 	var transitions string
 	for _, transition := range issue.Transitions {
@@ -319,9 +450,9 @@ Assignee: %s
 Created: %s
 Updated: %s
 Priority: %s
-Description:
-%s
+%sDescription:
 %s
+%s%s%s%s
 Available Transitions:
 %s`,
 		issue.Key,
@@ -332,26 +463,25 @@ Available Transitions:
 		issue.Fields.Created,
 		issue.Fields.Updated,
 		priorityName,
-		issue.Fields.Description,
+		parent,
+		markup.WikiToMarkdown(issue.Fields.Description),
 		subtasks,
+		links,
+		attachments,
+		comments,
 		transitions,
 	)
 
 	return mcp.NewToolResultText(result), nil
 }
 
-func jiraGetStatusesHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+func jiraGetStatusesHandler(args jiraProjectArgs) (*mcp.CallToolResult, error) {
 	client := services.JiraClient()
 
-	projectKey, ok := arguments["project_key"].(string)
-	if !ok {
-		return nil, fmt.Errorf("project_key argument is required")
-	}
-
 	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second)
 	defer cancel()
 
-	issueTypes, response, err := client.Project.Statuses(ctx, projectKey)
+	issueTypes, response, err := client.Project.Statuses(ctx, args.ProjectKey)
 	if err != nil {
 		if response != nil {
 			return nil, fmt.Errorf("failed to get statuses: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
@@ -363,51 +493,5 @@ func jiraGetStatusesHandler(arguments map[string]interface{}) (*mcp.CallToolResu
 		return mcp.NewToolResultText("No issue types found for this project."), nil
 	}
 
-	var result strings.Builder
-	result.WriteString("Available Statuses:\n")
-	for _, issueType := range issueTypes {
-		result.WriteString(fmt.Sprintf("\nIssue Type: %s\n", issueType.Name))
-		for _, status := range issueType.Statuses {
-			result.WriteString(fmt.Sprintf("  - %s: %s\n", status.Name, status.ID))
-		}
-	}
-
-	return mcp.NewToolResultText(result.String()), nil
-}
-
-func jiraTransitionIssueHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
-	client := services.JiraClient()
-
-	issueKey, ok := arguments["issue_key"].(string)
-	if !ok || issueKey == "" {
-		return nil, fmt.Errorf("valid issue_key is required")
-	}
-
-	transitionID, ok := arguments["transition_id"].(string)
-	if !ok || transitionID == "" {
-		return nil, fmt.Errorf("valid transition_id is required")
-	}
-
-	var options *models.IssueMoveOptionsV2
-	if comment, ok := arguments["comment"].(string); ok && comment != "" {
-		options = &models.IssueMoveOptionsV2{
-			Fields: &models.IssueSchemeV2{
-			},
-		}
-	}
-
-	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second)
-	defer cancel()
-
-	response, err := client.Issue.Move(ctx, issueKey, transitionID, options)
-	if err != nil {
-		if response != nil {
-			return nil, fmt.Errorf("transition failed: %s (endpoint: %s)", 
-				response.Bytes.String(), 
-				response.Endpoint)
-		}
-		return nil, fmt.Errorf("transition failed: %v", err)
-	}
-
-	return mcp.NewToolResultText("Issue transition completed successfully"), nil
+	return mcp.NewToolResultText(formatJiraIssueTypeStatuses(issueTypes, &jiraProjectSchemes{})), nil
 }
@@ -0,0 +1,366 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ctreminiom/go-atlassian/pkg/infra/models"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/nguyenvanduocit/dev-kit/services"
+	"github.com/nguyenvanduocit/dev-kit/util"
+)
+
+const (
+	// maxAttachmentDownload bounds what is fetched at all; text beyond max_bytes is cut after download
+	maxAttachmentDownload = 20 << 20
+	// maxAttachmentImage keeps images small enough to be passed to the model
+	maxAttachmentImage = 5 << 20
+	// jiraAttachmentBoundary is fixed so that recorded uploads replay from cassettes
+	jiraAttachmentBoundary = "dev-kit-jira-attachment"
+)
+
+// jiraImageAttachmentTypes are the image types MCP clients can display
+var jiraImageAttachmentTypes = map[string]bool{
+	"image/png":  true,
+	"image/jpeg": true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+var jiraTextAttachmentTypes = map[string]bool{
+	"application/json":       true,
+	"application/x-ndjson":   true,
+	"application/xml":        true,
+	"application/javascript": true,
+	"application/x-yaml":     true,
+	"application/yaml":       true,
+	"application/x-sh":       true,
+	"application/sql":        true,
+}
+
+// jiraTextAttachmentExtensions catch logs that Jira stores as application/octet-stream
+var jiraTextAttachmentExtensions = map[string]bool{
+	".log": true, ".txt": true, ".out": true, ".err": true, ".trace": true,
+	".md": true, ".csv": true, ".tsv": true, ".json": true, ".har": true,
+	".xml": true, ".yaml": true, ".yml": true, ".ini": true, ".conf": true,
+	".properties": true, ".sql": true, ".sh": true, ".diff": true, ".patch": true,
+}
+
+func registerJiraAttachmentTools(s *server.MCPServer) {
+	jiraListAttachmentsTool := mcp.NewTool("jira_list_attachments",
+		mcp.WithDescription("List the files attached to a Jira issue with their IDs, names, types, sizes, authors and upload times"),
+		mcp.WithString("issue_key", mcp.Required(), mcp.Description("The unique identifier of the Jira issue (e.g., KP-2, PROJ-123)")),
+	)
+
+	jiraGetAttachmentTool := mcp.NewTool("jira_get_attachment",
+		mcp.WithDescription("Fetch the content of a Jira attachment. Text and log files are returned inline, cut to max_bytes; PNG, JPEG, GIF and WebP images are returned as images; other files return their metadata only"),
+		mcp.WithString("attachment_id", mcp.Required(), mcp.Description("ID of the attachment, from jira_list_attachments")),
+		mcp.WithNumber("max_bytes", mcp.DefaultNumber(50000), mcp.Min(1000), mcp.Max(1000000), mcp.Description("Maximum number of bytes of a text attachment to return")),
+		mcp.WithBoolean("tail", mcp.DefaultBool(false), mcp.Description("Return the end of a text attachment instead of its beginning when it is longer than max_bytes, useful for logs")),
+	)
+
+	jiraUploadAttachmentTool := mcp.NewTool("jira_upload_attachment",
+		mcp.WithDescription("Attach a local file or generated text to a Jira issue. Give either file_path or content"),
+		mcp.WithString("issue_key", mcp.Required(), mcp.Description("The issue to attach the file to (e.g., KP-2)")),
+		mcp.WithString("file_path", mcp.Description("Absolute path of a local file to upload")),
+		mcp.WithString("content", mcp.Description("Text to upload as a file instead of a local file, e.g. a generated report")),
+		mcp.WithString("filename", mcp.Description("Name of the attachment, required with content (defaults to the name of file_path)")),
+	)
+
+	s.AddTool(jiraListAttachmentsTool, util.ErrorGuard(util.TypedHandler(jiraListAttachmentsTool, jiraListAttachmentsHandler)))
+	s.AddTool(jiraGetAttachmentTool, util.ErrorGuard(util.TypedHandler(jiraGetAttachmentTool, jiraGetAttachmentHandler)))
+	s.AddTool(jiraUploadAttachmentTool, util.ErrorGuard(util.TypedHandler(jiraUploadAttachmentTool, jiraUploadAttachmentHandler)))
+}
+
+type jiraGetAttachmentArgs struct {
+	AttachmentID string `json:"attachment_id"`
+	MaxBytes     int    `json:"max_bytes"`
+	Tail         bool   `json:"tail"`
+}
+
+type jiraUploadAttachmentArgs struct {
+	IssueKey string `json:"issue_key"`
+	FilePath string `json:"file_path"`
+	Content  string `json:"content"`
+	Filename string `json:"filename"`
+}
+
+// jiraIssueAttachments reads the attachment field, which the go-atlassian issue model leaves out
+type jiraIssueAttachments struct {
+	Fields struct {
+		Attachment []*models.IssueAttachmentScheme `json:"attachment"`
+	} `json:"fields"`
+}
+
+// parseJiraIssueAttachments returns the attachments of an issue response, oldest first
+func parseJiraIssueAttachments(data []byte) []*models.IssueAttachmentScheme {
+	var issue jiraIssueAttachments
+	if err := json.Unmarshal(data, &issue); err != nil {
+		return nil
+	}
+
+	attachments := issue.Fields.Attachment
+	sort.SliceStable(attachments, func(i, j int) bool {
+		return attachments[i].Created < attachments[j].Created
+	})
+	return attachments
+}
+
+func jiraListAttachmentsHandler(args jiraIssueKeyArgs) (*mcp.CallToolResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second)
+	defer cancel()
+
+	endpoint := fmt.Sprintf("rest/api/2/issue/%s?fields=attachment", args.IssueKey)
+	response, err := jiraRequest(ctx, http.MethodGet, endpoint, nil, nil)
+	if err != nil {
+		if response != nil {
+			return nil, fmt.Errorf("failed to list attachments: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
+		}
+		return nil, fmt.Errorf("failed to list attachments: %v", err)
+	}
+
+	attachments := parseJiraIssueAttachments(response.Bytes.Bytes())
+	if len(attachments) == 0 {
+		return mcp.NewToolResultText(fmt.Sprintf("No attachments found on %s.", args.IssueKey)), nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Attachments on %s (%d):\n\n", args.IssueKey, len(attachments)))
+	for _, attachment := range attachments {
+		sb.WriteString(formatJiraAttachment(attachment))
+	}
+
+	return mcp.NewToolResultText(sb.String()), nil
+}
+
+func jiraGetAttachmentHandler(args jiraGetAttachmentArgs) (*mcp.CallToolResult, error) {
+	client := services.JiraClient()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second*5)
+	defer cancel()
+
+	metadata, response, err := client.Issue.Attachment.Metadata(ctx, args.AttachmentID)
+	if err != nil {
+		if response != nil {
+			return nil, fmt.Errorf("failed to get attachment: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
+		}
+		return nil, fmt.Errorf("failed to get attachment: %v", err)
+	}
+
+	header := formatJiraAttachment(&models.IssueAttachmentScheme{
+		ID:       args.AttachmentID,
+		Filename: metadata.Filename,
+		Author:   metadata.Author,
+		Created:  metadata.Created,
+		Size:     metadata.Size,
+		MimeType: metadata.MimeType,
+	})
+
+	mimeType := jiraAttachmentMimeType(metadata.Filename, metadata.MimeType)
+	image := jiraImageAttachmentTypes[mimeType]
+	text := jiraIsTextAttachment(metadata.Filename, mimeType)
+
+	switch {
+	case !image && !text:
+		return mcp.NewToolResultText(header + "\nThe content is binary and is not returned. Download it from " + metadata.Content), nil
+	case image && metadata.Size > maxAttachmentImage:
+		return mcp.NewToolResultText(fmt.Sprintf("%s\nThe image is larger than %s and is not returned. Download it from %s", header, formatJiraAttachmentSize(maxAttachmentImage), metadata.Content)), nil
+	case metadata.Size > maxAttachmentDownload:
+		return mcp.NewToolResultText(fmt.Sprintf("%s\nThe file is larger than %s and is not returned. Download it from %s", header, formatJiraAttachmentSize(maxAttachmentDownload), metadata.Content)), nil
+	}
+
+	response, err = client.Issue.Attachment.Download(ctx, args.AttachmentID, true)
+	if err != nil {
+		if response != nil {
+			return nil, fmt.Errorf("failed to download attachment: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
+		}
+		return nil, fmt.Errorf("failed to download attachment: %v", err)
+	}
+	content := response.Bytes.Bytes()
+
+	if image {
+		return mcp.NewToolResultImage(header, base64.StdEncoding.EncodeToString(content), mimeType), nil
+	}
+
+	body, cut := truncateJiraAttachment(content, args.MaxBytes, args.Tail)
+	var sb strings.Builder
+	sb.WriteString(header)
+	if cut {
+		part := "first"
+		if args.Tail {
+			part = "last"
+		}
+		sb.WriteString(fmt.Sprintf("\nShowing the %s %d of %d bytes.\n", part, len(body), len(content)))
+	}
+	sb.WriteString("\n")
+	sb.WriteString(strings.ToValidUTF8(string(body), "\uFFFD"))
+
+	return mcp.NewToolResultText(sb.String()), nil
+}
+
+func jiraUploadAttachmentHandler(args jiraUploadAttachmentArgs) (*mcp.CallToolResult, error) {
+	if (args.FilePath == "") == (args.Content == "") {
+		return nil, fmt.Errorf("give either file_path or content")
+	}
+
+	filename := args.Filename
+	content := []byte(args.Content)
+	if args.FilePath != "" {
+		data, err := os.ReadFile(args.FilePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %v", args.FilePath, err)
+		}
+		content = data
+		if filename == "" {
+			filename = filepath.Base(args.FilePath)
+		}
+	}
+	if filename == "" {
+		return nil, fmt.Errorf("filename is required with content")
+	}
+
+	body, contentType, err := jiraAttachmentBody(filename, content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build upload: %v", err)
+	}
+
+	client := services.JiraClient()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second*8)
+	defer cancel()
+
+	endpoint := fmt.Sprintf("rest/api/2/issue/%s/attachments", args.IssueKey)
+	request, err := client.NewRequest(ctx, http.MethodPost, endpoint, contentType, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload attachment: %v", err)
+	}
+
+	var attachments []*models.IssueAttachmentScheme
+	response, err := client.Call(request, &attachments)
+	if err != nil {
+		if response != nil {
+			return nil, fmt.Errorf("failed to upload attachment: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
+		}
+		return nil, fmt.Errorf("failed to upload attachment: %v", err)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Attached to %s:\n\n", args.IssueKey))
+	for _, attachment := range attachments {
+		sb.WriteString(formatJiraAttachment(attachment))
+	}
+
+	return mcp.NewToolResultText(sb.String()), nil
+}
+
+// jiraAttachmentBody encodes a file as the multipart form Jira expects, typed by its extension
+func jiraAttachmentBody(filename string, content []byte) (*bytes.Buffer, string, error) {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	if err := writer.SetBoundary(jiraAttachmentBoundary); err != nil {
+		return nil, "", err
+	}
+
+	mimeType := mime.TypeByExtension(filepath.Ext(filename))
+	if mimeType == "" {
+		mimeType = "application/octet-stream"
+	}
+
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename="%s"`, strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(filename)))
+	header.Set("Content-Type", mimeType)
+
+	part, err := writer.CreatePart(header)
+	if err != nil {
+		return nil, "", err
+	}
+	if _, err := part.Write(content); err != nil {
+		return nil, "", err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, "", err
+	}
+
+	return body, writer.FormDataContentType(), nil
+}
+
+// jiraAttachmentMimeType drops parameters such as charset and falls back to the extension
+// when Jira only knows the file as application/octet-stream
+func jiraAttachmentMimeType(filename, mimeType string) string {
+	mimeType, _, _ = strings.Cut(strings.ToLower(mimeType), ";")
+	mimeType = strings.TrimSpace(mimeType)
+	if mimeType == "" || mimeType == "application/octet-stream" {
+		if byExtension := mime.TypeByExtension(strings.ToLower(filepath.Ext(filename))); byExtension != "" {
+			mimeType, _, _ = strings.Cut(byExtension, ";")
+		}
+	}
+	return mimeType
+}
+
+func jiraIsTextAttachment(filename, mimeType string) bool {
+	return strings.HasPrefix(mimeType, "text/") ||
+		jiraTextAttachmentTypes[mimeType] ||
+		strings.HasSuffix(mimeType, "+json") ||
+		strings.HasSuffix(mimeType, "+xml") ||
+		jiraTextAttachmentExtensions[strings.ToLower(filepath.Ext(filename))]
+}
+
+// truncateJiraAttachment keeps the first or last max bytes of content without splitting a UTF-8 character
+func truncateJiraAttachment(content []byte, max int, tail bool) ([]byte, bool) {
+	if max <= 0 || len(content) <= max {
+		return content, false
+	}
+
+	if tail {
+		start := len(content) - max
+		for start < len(content) && !utf8.RuneStart(content[start]) {
+			start++
+		}
+		return content[start:], true
+	}
+
+	end := max
+	for end > 0 && !utf8.RuneStart(content[end]) {
+		end--
+	}
+	return content[:end], true
+}
+
+func formatJiraAttachment(attachment *models.IssueAttachmentScheme) string {
+	author := "Unknown"
+	if attachment.Author != nil {
+		author = attachment.Author.DisplayName
+	}
+
+	mimeType := attachment.MimeType
+	if mimeType == "" {
+		mimeType = "unknown type"
+	}
+
+	return fmt.Sprintf("- %s (ID: %s, %s, %s) by %s on %s\n", attachment.Filename, attachment.ID, mimeType, formatJiraAttachmentSize(attachment.Size), author, attachment.Created)
+}
+
+func formatJiraAttachmentSize(size int) string {
+	switch {
+	case size >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(size)/(1<<20))
+	case size >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(size)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", size)
+	}
+}
@@ -0,0 +1,60 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestJiraAttachmentHandlers(t *testing.T) {
+	text := resultText(t)(jiraCreateIssueHandler(jiraCreateIssueArgs{ProjectKey: "KP", Summary: "Attachment target", IssueType: "Task"}))
+	key := regexp.MustCompile(`KP-\d+`).FindString(text)
+
+	text = resultText(t)(jiraListAttachmentsHandler(jiraIssueKeyArgs{IssueKey: key}))
+	assertContains(t, text, "No attachments found on "+key)
+
+	text = resultText(t)(jiraUploadAttachmentHandler(jiraUploadAttachmentArgs{IssueKey: key, Content: "# Report\n\nAll green", Filename: "report.md"}))
+	assertContains(t, text, "Attached to "+key, "- report.md (ID: ", "by Alice Nguyen")
+
+	path := filepath.Join(t.TempDir(), "build.log")
+	log := strings.Repeat("step ok\n", 100) + "step failed: exit 1\n"
+	if err := os.WriteFile(path, []byte(log), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	text = resultText(t)(jiraUploadAttachmentHandler(jiraUploadAttachmentArgs{IssueKey: key, FilePath: path}))
+	assertContains(t, text, "- build.log (ID: ")
+	id := regexp.MustCompile(`\(ID: (\w+)`).FindStringSubmatch(text)
+	if id == nil {
+		t.Fatalf("no attachment ID in the upload output:\n%s", text)
+	}
+
+	text = resultText(t)(jiraListAttachmentsHandler(jiraIssueKeyArgs{IssueKey: key}))
+	assertContains(t, text, "Attachments on "+key+" (2):", "- report.md (ID: ", "- build.log (ID: "+id[1])
+
+	text = resultText(t)(jiraGetAttachmentHandler(jiraGetAttachmentArgs{AttachmentID: id[1], MaxBytes: 20, Tail: true}))
+	assertContains(t, text, "Showing the last 20 of 820 bytes.", "step failed: exit 1")
+
+	if _, err := jiraUploadAttachmentHandler(jiraUploadAttachmentArgs{IssueKey: key, FilePath: path, Content: "both"}); err == nil {
+		t.Error("uploading both a file and content succeeded, want an error")
+	}
+	if _, err := jiraUploadAttachmentHandler(jiraUploadAttachmentArgs{IssueKey: key, Content: "no name"}); err == nil {
+		t.Error("uploading content without a filename succeeded, want an error")
+	}
+}
+
+func TestTruncateJiraAttachment(t *testing.T) {
+	content := []byte("héllo wörld")
+
+	if got, cut := truncateJiraAttachment(content, 100, false); cut || string(got) != string(content) {
+		t.Errorf("got %q, %v for content under the limit, want it unchanged", got, cut)
+	}
+	// limits falling inside é or ö drop the character rather than split it
+	if got, cut := truncateJiraAttachment(content, 2, false); !cut || string(got) != "h" {
+		t.Errorf("got %q, %v, want \"h\" cut", got, cut)
+	}
+	if got, cut := truncateJiraAttachment(content, 4, true); !cut || string(got) != "rld" {
+		t.Errorf("got %q, %v, want \"rld\" cut", got, cut)
+	}
+}
@@ -0,0 +1,442 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ctreminiom/go-atlassian/pkg/infra/models"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/nguyenvanduocit/dev-kit/services"
+	"github.com/nguyenvanduocit/dev-kit/util"
+)
+
+// maxBoardIssues limits how many issues of a sprint or backlog are listed
+const maxBoardIssues = 500
+
+// jiraBoardIssueFields are the fields read for listing the issues of a board
+var jiraBoardIssueFields = []string{"summary", "status", "issuetype", "assignee", "priority"}
+
+func registerJiraBoardTools(s *server.MCPServer) {
+	jiraListBoardsTool := mcp.NewTool("jira_list_boards",
+		mcp.WithDescription("List Jira boards with their IDs, types and projects. Use the IDs with the sprint and backlog tools"),
+		mcp.WithString("project_key", mcp.Description("Only list the boards of this project (optional, e.g., KP)")),
+		mcp.WithString("type", mcp.Enum("scrum", "kanban", "simple"), mcp.Description("Only list boards of this type (optional)")),
+		mcp.WithString("name", mcp.Description("Only list boards whose name contains this text (optional)")),
+	)
+	s.AddTool(jiraListBoardsTool, util.ErrorGuard(util.TypedHandler(jiraListBoardsTool, jiraListBoardsHandler)))
+
+	jiraGetBoardConfigurationTool := mcp.NewTool("jira_get_board_configuration",
+		mcp.WithDescription("Get the configuration of a Jira board: its columns with the statuses mapped to them, its estimation field and its filter"),
+		mcp.WithString("board_id", mcp.Required(), mcp.Description("Numeric ID of the Jira board, see jira_list_boards")),
+	)
+	s.AddTool(jiraGetBoardConfigurationTool, util.ErrorGuard(util.TypedHandler(jiraGetBoardConfigurationTool, jiraGetBoardConfigurationHandler)))
+
+	jiraGetBacklogTool := mcp.NewTool("jira_get_backlog",
+		mcp.WithDescription("List the backlog of a Jira board in rank order, with estimates. The backlog holds the issues that are in no active or future sprint"),
+		mcp.WithString("board_id", mcp.Required(), mcp.Description("Numeric ID of the Jira board, see jira_list_boards")),
+		mcp.WithString("jql", mcp.Description("JQL to filter the backlog further (optional, e.g., 'assignee = currentUser()')")),
+		mcp.WithNumber("max_results", mcp.Min(1), mcp.Max(maxBoardIssues), mcp.DefaultNumber(50), mcp.Description("Maximum number of issues to list")),
+		mcp.WithBoolean("group_by_column", mcp.DefaultBool(false), mcp.Description("Group the issues by the board column of their status instead of listing them in rank order")),
+	)
+	s.AddTool(jiraGetBacklogTool, util.ErrorGuard(util.TypedHandler(jiraGetBacklogTool, jiraGetBacklogHandler)))
+
+	jiraGetSprintIssuesTool := mcp.NewTool("jira_get_sprint_issues",
+		mcp.WithDescription("List the issues of a Jira sprint grouped by the columns of its board, with estimates per column"),
+		mcp.WithString("sprint_id", mcp.Required(), mcp.Description("Numeric ID of the sprint, see jira_list_sprints")),
+		mcp.WithString("jql", mcp.Description("JQL to filter the issues further (optional)")),
+		mcp.WithBoolean("group_by_column", mcp.DefaultBool(true), mcp.Description("Group the issues by the board column of their status instead of listing them in rank order")),
+	)
+	s.AddTool(jiraGetSprintIssuesTool, util.ErrorGuard(util.TypedHandler(jiraGetSprintIssuesTool, jiraGetSprintIssuesHandler)))
+}
+
+type jiraListBoardsArgs struct {
+	ProjectKey string `json:"project_key"`
+	Type       string `json:"type"`
+	Name       string `json:"name"`
+}
+
+type jiraBoardArgs struct {
+	BoardID int `json:"board_id"`
+}
+
+type jiraGetBacklogArgs struct {
+	BoardID       int    `json:"board_id"`
+	JQL           string `json:"jql"`
+	MaxResults    int    `json:"max_results"`
+	GroupByColumn bool   `json:"group_by_column"`
+}
+
+type jiraGetSprintIssuesArgs struct {
+	SprintID      int    `json:"sprint_id"`
+	JQL           string `json:"jql"`
+	GroupByColumn bool   `json:"group_by_column"`
+}
+
+// jiraBoardLayout is what listing issues by board column needs to know about a board
+type jiraBoardLayout struct {
+	// ProjectKey is the project of the board, empty when its filter spans several projects
+	ProjectKey string
+	Columns    []*models.BoardColumnScheme
+	// EstimationField is the ID of the estimation field, empty when issues are counted
+	EstimationField string
+	EstimationName  string
+}
+
+// column returns the name of the column a status is mapped to
+func (l *jiraBoardLayout) column(statusID string) string {
+	for _, column := range l.Columns {
+		for _, status := range column.Statuses {
+			if status.ID == statusID {
+				return column.Name
+			}
+		}
+	}
+	return ""
+}
+
+func jiraListBoardsHandler(args jiraListBoardsArgs) (*mcp.CallToolResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second)
+	defer cancel()
+
+	opts := &models.GetBoardsOptions{
+		ProjectKeyOrID: args.ProjectKey,
+		BoardType:      args.Type,
+		BoardName:      args.Name,
+	}
+
+	var boards []*models.BoardScheme
+	for startAt := 0; ; {
+		page, response, err := services.AgileClient().Board.Gets(ctx, opts, startAt, 50)
+		if err != nil {
+			if response != nil {
+				return nil, fmt.Errorf("failed to list boards: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
+			}
+			return nil, fmt.Errorf("failed to list boards: %v", err)
+		}
+
+		boards = append(boards, page.Values...)
+		startAt += len(page.Values)
+		if page.IsLast || len(page.Values) == 0 {
+			break
+		}
+	}
+
+	if len(boards) == 0 {
+		return mcp.NewToolResultText("No boards found."), nil
+	}
+
+	var sb strings.Builder
+	for _, board := range boards {
+		sb.WriteString(fmt.Sprintf("ID: %d\nName: %s\nType: %s\n", board.ID, board.Name, board.Type))
+		if board.Location != nil && board.Location.ProjectKey != "" {
+			sb.WriteString(fmt.Sprintf("Project: %s\n", board.Location.ProjectKey))
+		}
+		sb.WriteString("\n")
+	}
+
+	return mcp.NewToolResultText(sb.String()), nil
+}
+
+func jiraGetBoardConfigurationHandler(args jiraBoardArgs) (*mcp.CallToolResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second)
+	defer cancel()
+
+	config, err := jiraBoardConfiguration(ctx, args.BoardID)
+	if err != nil {
+		return nil, err
+	}
+	statuses, err := jiraStatusNames(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Board: %s (ID: %d)\nType: %s\n", config.Name, config.ID, config.Type))
+	if config.Location != nil && config.Location.ProjectKey != "" {
+		sb.WriteString(fmt.Sprintf("Project: %s\n", config.Location.ProjectKey))
+	}
+	if config.Filter != nil && config.Filter.ID != "" {
+		sb.WriteString(fmt.Sprintf("Filter ID: %s\n", config.Filter.ID))
+	}
+
+	estimation := "issue count"
+	if config.Estimation != nil && config.Estimation.Field != nil && config.Estimation.Field.FieldID != "" {
+		estimation = fmt.Sprintf("%s (%s)", config.Estimation.Field.DisplayName, config.Estimation.Field.FieldID)
+	}
+	sb.WriteString(fmt.Sprintf("Estimation: %s\n", estimation))
+	if config.Ranking != nil && config.Ranking.RankCustomFieldID != 0 {
+		sb.WriteString(fmt.Sprintf("Rank field: customfield_%d\n", config.Ranking.RankCustomFieldID))
+	}
+
+	if config.ColumnConfig != nil {
+		sb.WriteString("\nColumns:\n")
+		for _, column := range config.ColumnConfig.Columns {
+			var names []string
+			for _, status := range column.Statuses {
+				if name, ok := statuses[status.ID]; ok {
+					names = append(names, name)
+				} else {
+					names = append(names, "status "+status.ID)
+				}
+			}
+			if len(names) == 0 {
+				names = append(names, "no statuses")
+			}
+			sb.WriteString(fmt.Sprintf("- %s: %s\n", column.Name, strings.Join(names, ", ")))
+		}
+		if config.ColumnConfig.ConstraintType != "" && config.ColumnConfig.ConstraintType != "none" {
+			sb.WriteString(fmt.Sprintf("Column constraints: %s\n", config.ColumnConfig.ConstraintType))
+		}
+	}
+
+	return mcp.NewToolResultText(sb.String()), nil
+}
+
+func jiraGetBacklogHandler(args jiraGetBacklogArgs) (*mcp.CallToolResult, error) {
+	limit := args.MaxResults
+	if limit <= 0 {
+		limit = 50
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second*3)
+	defer cancel()
+
+	layout, err := jiraGetBoardLayout(ctx, args.BoardID)
+	if err != nil {
+		return nil, err
+	}
+
+	client := services.AgileClient()
+	issues, estimates, total, err := jiraBoardIssues(layout, limit, func(opts *models.IssueOptionScheme, startAt, maxResults int) (*models.BoardIssuePageScheme, *models.ResponseScheme, error) {
+		opts.JQL = args.JQL
+		return client.Board.Backlog(ctx, args.BoardID, opts, startAt, maxResults)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get backlog: %v", err)
+	}
+
+	header := fmt.Sprintf("Backlog of board %d", args.BoardID)
+	return mcp.NewToolResultText(formatJiraBoardIssues(header, layout, issues, total, estimates, args.GroupByColumn)), nil
+}
+
+func jiraGetSprintIssuesHandler(args jiraGetSprintIssuesArgs) (*mcp.CallToolResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second*3)
+	defer cancel()
+
+	sprint, err := jiraGetSprint(ctx, args.SprintID)
+	if err != nil {
+		return nil, err
+	}
+	layout, err := jiraGetBoardLayout(ctx, sprint.OriginBoardID)
+	if err != nil {
+		return nil, err
+	}
+
+	client := services.AgileClient()
+	issues, estimates, total, err := jiraBoardIssues(layout, maxBoardIssues, func(opts *models.IssueOptionScheme, startAt, maxResults int) (*models.BoardIssuePageScheme, *models.ResponseScheme, error) {
+		opts.JQL = args.JQL
+		return client.Board.IssuesBySprint(ctx, sprint.OriginBoardID, sprint.ID, opts, startAt, maxResults)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sprint issues: %v", err)
+	}
+
+	header := fmt.Sprintf("Sprint %s (ID: %d, %s)", sprint.Name, sprint.ID, sprint.State)
+	if sprint.Goal != "" {
+		header += "\nGoal: " + sprint.Goal
+	}
+	return mcp.NewToolResultText(formatJiraBoardIssues(header, layout, issues, total, estimates, args.GroupByColumn)), nil
+}
+
+func jiraBoardConfiguration(ctx context.Context, boardID int) (*models.BoardConfigurationScheme, error) {
+	config, response, err := services.AgileClient().Board.Configuration(ctx, boardID)
+	if err != nil {
+		if response != nil {
+			return nil, fmt.Errorf("failed to get board configuration: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
+		}
+		return nil, fmt.Errorf("failed to get board configuration: %v", err)
+	}
+	return config, nil
+}
+
+// jiraStatusNames maps the IDs of all statuses to their names, as board columns only reference status IDs
+func jiraStatusNames(ctx context.Context) (map[string]string, error) {
+	var statuses []*models.StatusScheme
+	response, err := jiraRequest(ctx, http.MethodGet, "rest/api/2/status", nil, &statuses)
+	if err != nil {
+		if response != nil {
+			return nil, fmt.Errorf("failed to get statuses: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
+		}
+		return nil, fmt.Errorf("failed to get statuses: %v", err)
+	}
+
+	names := map[string]string{}
+	for _, status := range statuses {
+		names[status.ID] = status.Name
+	}
+	return names, nil
+}
+
+func jiraGetBoardLayout(ctx context.Context, boardID int) (*jiraBoardLayout, error) {
+	config, err := jiraBoardConfiguration(ctx, boardID)
+	if err != nil {
+		return nil, err
+	}
+
+	layout := &jiraBoardLayout{}
+	if config.Location != nil {
+		layout.ProjectKey = config.Location.ProjectKey
+	}
+	if config.ColumnConfig != nil {
+		layout.Columns = config.ColumnConfig.Columns
+	}
+	if config.Estimation != nil && config.Estimation.Field != nil {
+		layout.EstimationField = config.Estimation.Field.FieldID
+		layout.EstimationName = config.Estimation.Field.DisplayName
+	}
+	return layout, nil
+}
+
+// jiraBoardIssues reads up to limit issues from a paged agile endpoint, along with the
+// estimates of those that have one. It returns the total number of matching issues too.
+func jiraBoardIssues(layout *jiraBoardLayout, limit int, page func(opts *models.IssueOptionScheme, startAt, maxResults int) (*models.BoardIssuePageScheme, *models.ResponseScheme, error)) ([]*models.IssueSchemeV2, map[string]float64, int, error) {
+	fields := jiraBoardIssueFields
+	if layout.EstimationField != "" {
+		fields = append(append([]string{}, fields...), layout.EstimationField)
+	}
+
+	var issues []*models.IssueSchemeV2
+	estimates := map[string]float64{}
+	for {
+		opts := &models.IssueOptionScheme{Fields: fields, ValidateQuery: true}
+		result, response, err := page(opts, len(issues), min(100, limit-len(issues)))
+		if err != nil {
+			if response != nil {
+				return nil, nil, 0, fmt.Errorf("%s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
+			}
+			return nil, nil, 0, err
+		}
+
+		issues = append(issues, result.Issues...)
+		if layout.EstimationField != "" {
+			// custom fields are not part of the issue scheme, so they are read from the response; pages without estimates fail to parse
+			values, _ := models.ParseFloatCustomFields(response.Bytes, layout.EstimationField)
+			for key, value := range values {
+				estimates[key] = value
+			}
+		}
+
+		if len(result.Issues) == 0 || len(issues) >= result.Total || len(issues) >= limit {
+			return issues, estimates, max(result.Total, len(issues)), nil
+		}
+	}
+}
+
+// formatJiraBoardIssues lists issues in the given order or grouped by board column, in the order of the columns
+func formatJiraBoardIssues(header string, layout *jiraBoardLayout, issues []*models.IssueSchemeV2, total int, estimates map[string]float64, groupByColumn bool) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s\nIssues: %d", header, total))
+	if len(issues) < total {
+		sb.WriteString(fmt.Sprintf(", showing the first %d", len(issues)))
+	}
+	if layout.EstimationField != "" {
+		sb.WriteString(fmt.Sprintf(", %s: %s", layout.EstimationName, formatJiraEstimate(jiraEstimateTotal(issues, estimates))))
+	}
+	sb.WriteString("\n")
+
+	if len(issues) == 0 {
+		return sb.String()
+	}
+
+	if !groupByColumn {
+		sb.WriteString("\n")
+		for _, issue := range issues {
+			sb.WriteString(formatJiraBoardIssue(layout, issue, estimates))
+		}
+		return sb.String()
+	}
+
+	groups := map[string][]*models.IssueSchemeV2{}
+	for _, issue := range issues {
+		column := ""
+		if issue.Fields != nil && issue.Fields.Status != nil {
+			column = layout.column(issue.Fields.Status.ID)
+		}
+		groups[column] = append(groups[column], issue)
+	}
+
+	names := make([]string, 0, len(layout.Columns)+1)
+	for _, column := range layout.Columns {
+		names = append(names, column.Name)
+	}
+	// issues whose status is mapped to no column are not shown on the board
+	names = append(names, "")
+
+	for _, name := range names {
+		group := groups[name]
+		if name == "" {
+			if len(group) == 0 {
+				continue
+			}
+			name = "Not on the board"
+		}
+		sb.WriteString(fmt.Sprintf("\n%s (%d", name, len(group)))
+		if layout.EstimationField != "" {
+			sb.WriteString(", " + formatJiraEstimate(jiraEstimateTotal(group, estimates)))
+		}
+		sb.WriteString("):\n")
+		for _, issue := range group {
+			sb.WriteString(formatJiraBoardIssue(layout, issue, estimates))
+		}
+	}
+
+	return sb.String()
+}
+
+func formatJiraBoardIssue(layout *jiraBoardLayout, issue *models.IssueSchemeV2, estimates map[string]float64) string {
+	if issue.Fields == nil {
+		return fmt.Sprintf("- %s\n", issue.Key)
+	}
+
+	details := []string{}
+	if issue.Fields.Status != nil {
+		details = append(details, issue.Fields.Status.Name)
+	}
+	if issue.Fields.Assignee != nil {
+		details = append(details, issue.Fields.Assignee.DisplayName)
+	} else {
+		details = append(details, "Unassigned")
+	}
+	if issue.Fields.Priority != nil {
+		details = append(details, issue.Fields.Priority.Name)
+	}
+	if estimate, ok := estimates[issue.Key]; ok {
+		details = append(details, formatJiraEstimate(estimate))
+	}
+
+	issueType := ""
+	if issue.Fields.IssueType != nil {
+		issueType = fmt.Sprintf("[%s] ", issue.Fields.IssueType.Name)
+	}
+	return fmt.Sprintf("- %s %s%s (%s)\n", issue.Key, issueType, issue.Fields.Summary, strings.Join(details, ", "))
+}
+
+func jiraEstimateTotal(issues []*models.IssueSchemeV2, estimates map[string]float64) float64 {
+	var total float64
+	for _, issue := range issues {
+		total += estimates[issue.Key]
+	}
+	return total
+}
+
+func formatJiraEstimate(points float64) string {
+	if points == 1 {
+		return "1 point"
+	}
+	return fmt.Sprintf("%s points", strconv.FormatFloat(points, 'f', -1, 64))
+}
@@ -0,0 +1,1016 @@
+package tools
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ctreminiom/go-atlassian/pkg/infra/models"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/nguyenvanduocit/dev-kit/services"
+	"github.com/nguyenvanduocit/dev-kit/util"
+	"github.com/nguyenvanduocit/dev-kit/util/markup"
+)
+
+// maxBulkIssues bounds how many issues one bulk update may change
+const maxBulkIssues = 200
+
+// jiraOutlineStepTimeout bounds every step of a bulk create, such as looking up a user or creating
+// one issue, which with the field catalog and the create itself takes up to three requests
+const jiraOutlineStepTimeout = 4 * time.Second * 5
+
+func registerJiraBulkTools(s *server.MCPServer) {
+	jiraBulkUpdateTool := mcp.NewTool("jira_bulk_update",
+		mcp.WithDescription("Apply the same changes to many Jira issues at once: labels, assignee, priority, fields, a transition, a sprint and a comment. Issues are picked by JQL or a list of keys and updated in parallel, and a report lists the result of every issue. Use dry_run to preview the changes first"),
+		mcp.WithString("jql", mcp.Description("JQL query selecting the issues to update (e.g., project = KP AND sprint in openSprints() AND labels = triage)")),
+		mcp.WithString("issue_keys", mcp.Description("Comma-separated issue keys to update, instead of jql (e.g., KP-2,KP-3)")),
+		mcp.WithNumber("max_issues", mcp.DefaultNumber(50), mcp.Min(1), mcp.Max(maxBulkIssues), mcp.Description("Refuse to run when more issues than this match, to guard against a too broad query")),
+		mcp.WithString("add_labels", mcp.Description("Comma-separated labels to add")),
+		mcp.WithString("remove_labels", mcp.Description("Comma-separated labels to remove")),
+		mcp.WithString("assignee", mcp.Description("Display name, email or account ID of the new assignee, or one of me, default, unassigned")),
+		mcp.WithString("priority", mcp.Description("Name of the new priority (e.g., High)")),
+		mcp.WithString("fields", mcp.Description("JSON object of other fields to set, keyed by field name or ID (e.g., {\"Story Points\": 3})")),
+		mcp.WithString("transition", mcp.Description("Name of a transition, or of the status to move every issue to (e.g., In Progress). A status without a direct transition is reached through several, like jira_transition_issue does. Issues already in that status are left as they are")),
+		mcp.WithNumber("sprint_id", mcp.Description("ID of the sprint to move the issues to")),
+		mcp.WithString("comment", mcp.Description("Comment to add to every issue, in Markdown")),
+		mcp.WithBoolean("dry_run", mcp.DefaultBool(false), mcp.Description("Only report what would change, without updating any issue")),
+		mcp.WithNumber("concurrency", mcp.DefaultNumber(5), mcp.Min(1), mcp.Max(10), mcp.Description("How many issues are updated at the same time")),
+	)
+
+	jiraBulkCreateTool := mcp.NewTool("jira_bulk_create",
+		mcp.WithDescription(`Create an epic, its stories and their subtasks in one go from a nested Markdown list or a CSV table, wiring the parent links. Markdown items read "- [Type:] Summary | field=value | field=value", nested by indentation, with indented text below an item becoming its description; without a type, top-level items are epics, their children stories and the next level subtasks. CSV needs a summary column and may have id, type, parent (an earlier id or an existing issue key), description and field columns. Fields are assignee, reporter, priority, labels, components, fix_versions, due_date, story_points or any other field name. Issues are created in order and a failure stops there, reporting how to resume`),
+		mcp.WithString("project_key", mcp.Required(), mcp.Description("Project to create the issues in (e.g., KP)")),
+		mcp.WithString("outline", mcp.Required(), mcp.Description("The Markdown outline or CSV table of issues")),
+		mcp.WithString("format", mcp.DefaultString("markdown"), mcp.Enum("markdown", "csv"), mcp.Description("Format of the outline")),
+		mcp.WithString("parent", mcp.Description("Existing epic or issue to put the top-level items under; they then default to stories (optional, e.g., KP-1)")),
+		mcp.WithString("created", mcp.Description("Items created by an earlier, interrupted call, as comma-separated item=key pairs (e.g., 1=KP-10,2=KP-11). They are skipped and their keys used as parents")),
+		mcp.WithBoolean("dry_run", mcp.DefaultBool(false), mcp.Description("Only show the issues that would be created")),
+	)
+
+	s.AddTool(jiraBulkUpdateTool, util.ErrorGuard(util.TypedHandler(jiraBulkUpdateTool, jiraBulkUpdateHandler)))
+	s.AddTool(jiraBulkCreateTool, util.ErrorGuard(util.TypedHandler(jiraBulkCreateTool, jiraBulkCreateHandler)))
+}
+
+type jiraBulkUpdateArgs struct {
+	JQL          string `json:"jql"`
+	IssueKeys    string `json:"issue_keys"`
+	MaxIssues    int    `json:"max_issues"`
+	AddLabels    string `json:"add_labels"`
+	RemoveLabels string `json:"remove_labels"`
+	Assignee     string `json:"assignee"`
+	Priority     string `json:"priority"`
+	Fields       string `json:"fields"`
+	Transition   string `json:"transition"`
+	SprintID     int    `json:"sprint_id"`
+	Comment      string `json:"comment"`
+	DryRun       bool   `json:"dry_run"`
+	Concurrency  int    `json:"concurrency"`
+}
+
+type jiraBulkCreateArgs struct {
+	ProjectKey string `json:"project_key"`
+	Outline    string `json:"outline"`
+	Format     string `json:"format"`
+	Parent     string `json:"parent"`
+	Created    string `json:"created"`
+	DryRun     bool   `json:"dry_run"`
+}
+
+// jiraBulkChange is the change set of a bulk update, resolved once for all issues
+type jiraBulkChange struct {
+	update      map[string]interface{}
+	fields      map[string]interface{}
+	assign      bool
+	assigneeID  interface{}
+	transition  string
+	sprintID    int
+	comment     string
+	description []string
+
+	// statuses caches the statuses of every project met, as a status may only be reachable in several transitions
+	mu       sync.Mutex
+	statuses map[string]map[string]*models.ProjectStatusDetailsScheme
+}
+
+// jiraBulkResult is the outcome of a bulk update for one issue
+type jiraBulkResult struct {
+	key     string
+	summary string
+	done    []string
+	err     error
+	dryRun  bool
+}
+
+func jiraBulkUpdateHandler(args jiraBulkUpdateArgs) (*mcp.CallToolResult, error) {
+	if (args.JQL == "") == (args.IssueKeys == "") {
+		return nil, fmt.Errorf("exactly one of jql or issue_keys is required")
+	}
+	if args.MaxIssues <= 0 {
+		args.MaxIssues = 50
+	}
+	if args.Concurrency <= 0 {
+		args.Concurrency = 5
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second*4)
+	defer cancel()
+
+	change, err := args.change(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	issues, err := jiraBulkIssues(ctx, args.JQL, args.IssueKeys, args.MaxIssues)
+	if err != nil {
+		return nil, err
+	}
+	if len(issues) == 0 {
+		return mcp.NewToolResultText("No issues match, nothing was updated."), nil
+	}
+
+	results := make([]*jiraBulkResult, len(issues))
+	limit := make(chan struct{}, args.Concurrency)
+	var wg sync.WaitGroup
+	for i, issue := range issues {
+		wg.Add(1)
+		go func(i int, issue *models.IssueSchemeV2) {
+			defer wg.Done()
+			limit <- struct{}{}
+			defer func() { <-limit }()
+			results[i] = change.apply(issue, args.DryRun)
+			results[i].dryRun = args.DryRun
+		}(i, issue)
+	}
+	wg.Wait()
+
+	failed := 0
+	for _, result := range results {
+		if result.err != nil {
+			failed++
+		}
+	}
+	var sb strings.Builder
+	if args.DryRun {
+		sb.WriteString(fmt.Sprintf("Dry run, no issue was changed. %d of %s can be updated with: %s\n\n", len(results)-failed, formatJiraIssueCount(len(results)), strings.Join(change.description, "; ")))
+	} else {
+		sb.WriteString(fmt.Sprintf("Updated %d of %s with: %s\n", len(results)-failed, formatJiraIssueCount(len(results)), strings.Join(change.description, "; ")))
+		if failed > 0 {
+			sb.WriteString(fmt.Sprintf("%s failed, changes listed before a failure were still made.\n", formatJiraIssueCount(failed)))
+		}
+		sb.WriteString("\n")
+	}
+	for _, result := range results {
+		sb.WriteString(formatJiraBulkResult(result))
+	}
+
+	return mcp.NewToolResultText(sb.String()), nil
+}
+
+// change validates the arguments and resolves names to IDs before any issue is touched
+func (args jiraBulkUpdateArgs) change(ctx context.Context) (*jiraBulkChange, error) {
+	change := &jiraBulkChange{
+		update:     map[string]interface{}{},
+		transition: strings.TrimSpace(args.Transition),
+		sprintID:   args.SprintID,
+		comment:    args.Comment,
+	}
+
+	var labelOps []interface{}
+	for _, label := range splitList(args.AddLabels) {
+		labelOps = append(labelOps, map[string]interface{}{"add": label})
+	}
+	for _, label := range splitList(args.RemoveLabels) {
+		labelOps = append(labelOps, map[string]interface{}{"remove": label})
+	}
+	if len(labelOps) > 0 {
+		change.update["labels"] = labelOps
+		if args.AddLabels != "" {
+			change.description = append(change.description, "add labels "+strings.Join(splitList(args.AddLabels), ", "))
+		}
+		if args.RemoveLabels != "" {
+			change.description = append(change.description, "remove labels "+strings.Join(splitList(args.RemoveLabels), ", "))
+		}
+	}
+
+	values, err := parseJiraFieldsArgument("fields", args.Fields)
+	if err != nil {
+		return nil, err
+	}
+	if len(values) > 0 {
+		catalog, err := jiraFieldCatalog(ctx)
+		if err != nil {
+			return nil, err
+		}
+		change.fields = map[string]interface{}{}
+		for name, value := range values {
+			field := findJiraField(catalog, name)
+			if field == nil {
+				return nil, fmt.Errorf("unknown field %q, use jira_get_create_meta to list the fields of the project", name)
+			}
+			converted, err := jiraFieldValue(field.Schema, value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value for field %s: %v", field.Name, err)
+			}
+			change.fields[field.ID] = converted
+			change.description = append(change.description, fmt.Sprintf("set %s to %v", field.Name, value))
+		}
+	}
+	if args.Priority != "" {
+		if change.fields == nil {
+			change.fields = map[string]interface{}{}
+		}
+		change.fields["priority"] = map[string]interface{}{"name": args.Priority}
+		change.description = append(change.description, "set priority to "+args.Priority)
+	}
+
+	if args.Assignee != "" {
+		change.assign = true
+		switch strings.ToLower(strings.TrimSpace(args.Assignee)) {
+		case "unassigned", "none":
+			change.description = append(change.description, "unassign")
+		case "default":
+			change.assigneeID = "-1"
+			change.description = append(change.description, "assign to the project's default assignee")
+		default:
+			user, err := jiraFindUser(ctx, args.Assignee, "")
+			if err != nil {
+				return nil, err
+			}
+			change.assigneeID = jiraUserID(user)
+			change.description = append(change.description, "assign to "+user.DisplayName)
+		}
+	}
+
+	if change.transition != "" {
+		change.description = append(change.description, "transition to "+change.transition)
+	}
+	if change.sprintID != 0 {
+		change.description = append(change.description, fmt.Sprintf("move to sprint %d", change.sprintID))
+	}
+	if change.comment != "" {
+		change.description = append(change.description, "add a comment")
+	}
+
+	if len(change.description) == 0 {
+		return nil, fmt.Errorf("no change given, pass at least one of add_labels, remove_labels, assignee, priority, fields, transition, sprint_id or comment")
+	}
+	return change, nil
+}
+
+// apply makes the changes to one issue, stopping at the first failing step
+func (change *jiraBulkChange) apply(issue *models.IssueSchemeV2, dryRun bool) *jiraBulkResult {
+	result := &jiraBulkResult{key: issue.Key}
+
+	// each step takes one request, apart from the transitions which take two, like in jira_transition_issue
+	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second*(3+maxTransitionHops))
+	defer cancel()
+
+	if issue.Fields == nil {
+		fetched, response, err := services.JiraClient().Issue.Get(ctx, issue.Key, []string{"summary", "status", "project"}, nil)
+		if err != nil {
+			if response != nil {
+				result.err = fmt.Errorf("failed to get issue: %s", strings.TrimSpace(response.Bytes.String()))
+			} else {
+				result.err = fmt.Errorf("failed to get issue: %v", err)
+			}
+			return result
+		}
+		issue = fetched
+	}
+	result.summary = issue.Fields.Summary
+
+	// a transition named in the arguments is made directly, a status is reached through as many transitions as it takes
+	var transition *jiraTransition
+	var target *models.ProjectStatusDetailsScheme
+	var categories map[string]*models.ProjectStatusDetailsScheme
+	status := ""
+	if issue.Fields.Status != nil {
+		status = issue.Fields.Status.Name
+	}
+	if change.transition != "" && !strings.EqualFold(status, change.transition) {
+		transitions, err := jiraIssueTransitions(ctx, issue.Key)
+		if err != nil {
+			result.err = err
+			return result
+		}
+		for _, candidate := range transitions {
+			if strings.EqualFold(candidate.Name, change.transition) || (candidate.To != nil && strings.EqualFold(candidate.To.Name, change.transition)) {
+				transition = candidate
+				break
+			}
+		}
+		if transition == nil && issue.Fields.Project != nil {
+			categories, err = change.projectStatuses(ctx, issue.Fields.Project.Key)
+			if err != nil {
+				result.err = err
+				return result
+			}
+			target = categories[strings.ToLower(change.transition)]
+		}
+		if transition == nil && (target == nil || nextJiraTransition(transitions, target, map[string]bool{strings.ToLower(status): true}, categories) == nil) {
+			result.err = fmt.Errorf("no transition %q from %s, available transitions: %s", change.transition, status, describeJiraTransitions(transitions))
+			return result
+		}
+	}
+
+	if dryRun {
+		if len(change.update) > 0 || len(change.fields) > 0 || change.comment != "" {
+			result.done = append(result.done, "update")
+		}
+		if change.assign {
+			result.done = append(result.done, "assign")
+		}
+		if transition != nil {
+			result.done = append(result.done, fmt.Sprintf("%s to %s", transition.Name, transition.To.Name))
+		}
+		if target != nil {
+			result.done = append(result.done, fmt.Sprintf("move from %s to %s in several transitions", status, target.Name))
+		}
+		if change.sprintID != 0 {
+			result.done = append(result.done, fmt.Sprintf("move to sprint %d", change.sprintID))
+		}
+		return result
+	}
+
+	if len(change.update) > 0 || len(change.fields) > 0 || change.comment != "" {
+		payload := map[string]interface{}{}
+		update := map[string]interface{}{}
+		for name, ops := range change.update {
+			update[name] = ops
+		}
+		if change.comment != "" {
+			update["comment"] = []interface{}{
+				map[string]interface{}{"add": map[string]interface{}{"body": markup.MarkdownToWiki(change.comment)}},
+			}
+		}
+		if len(update) > 0 {
+			payload["update"] = update
+		}
+		if len(change.fields) > 0 {
+			payload["fields"] = change.fields
+		}
+
+		endpoint := fmt.Sprintf("rest/api/2/issue/%s", issue.Key)
+		response, err := jiraRequest(ctx, http.MethodPut, endpoint, payload, nil)
+		if err != nil {
+			if response != nil {
+				result.err = fmt.Errorf("failed to update issue: %s", strings.TrimSpace(response.Bytes.String()))
+			} else {
+				result.err = fmt.Errorf("failed to update issue: %v", err)
+			}
+			return result
+		}
+		result.done = append(result.done, "updated")
+	}
+
+	if change.assign {
+		endpoint := fmt.Sprintf("rest/api/2/issue/%s/assignee", issue.Key)
+		response, err := jiraRequest(ctx, http.MethodPut, endpoint, jiraUserRef(change.assigneeID), nil)
+		if err != nil {
+			if response != nil {
+				result.err = fmt.Errorf("failed to assign issue: %s", strings.TrimSpace(response.Bytes.String()))
+			} else {
+				result.err = fmt.Errorf("failed to assign issue: %v", err)
+			}
+			return result
+		}
+		result.done = append(result.done, "assigned")
+	}
+
+	if transition != nil {
+		if err := jiraDoTransition(ctx, issue.Key, transition, nil, ""); err != nil {
+			result.err = err
+			return result
+		}
+		result.done = append(result.done, "moved to "+transition.To.Name)
+	}
+
+	if target != nil {
+		path, err := jiraMoveToStatus(ctx, issue.Key, status, target, categories, nil, "")
+		if err != nil {
+			result.err = err
+			return result
+		}
+		result.done = append(result.done, fmt.Sprintf("moved to %s via %s", target.Name, strings.Join(path, " → ")))
+	}
+
+	if change.sprintID != 0 {
+		if err := jiraMoveToSprint(ctx, change.sprintID, &models.SprintMovePayloadScheme{Issues: []string{issue.Key}}); err != nil {
+			result.err = err
+			return result
+		}
+		result.done = append(result.done, fmt.Sprintf("moved to sprint %d", change.sprintID))
+	}
+
+	return result
+}
+
+// projectStatuses returns the statuses of a project by lower-cased name, fetching them once per project
+func (change *jiraBulkChange) projectStatuses(ctx context.Context, projectKey string) (map[string]*models.ProjectStatusDetailsScheme, error) {
+	change.mu.Lock()
+	defer change.mu.Unlock()
+
+	if statuses, ok := change.statuses[projectKey]; ok {
+		return statuses, nil
+	}
+	statuses, err := jiraStatusCategories(ctx, projectKey)
+	if err != nil {
+		return nil, err
+	}
+	if change.statuses == nil {
+		change.statuses = map[string]map[string]*models.ProjectStatusDetailsScheme{}
+	}
+	change.statuses[projectKey] = statuses
+	return statuses, nil
+}
+
+// jiraBulkIssues finds the issues to update, refusing when more than maxIssues match
+func jiraBulkIssues(ctx context.Context, jql, issueKeys string, maxIssues int) ([]*models.IssueSchemeV2, error) {
+	fields := []string{"summary", "status", "project"}
+
+	if issueKeys != "" {
+		keys := splitList(issueKeys)
+		if len(keys) > maxIssues {
+			return nil, fmt.Errorf("%d issue keys given, more than max_issues (%d)", len(keys), maxIssues)
+		}
+		// the issues are fetched one by one when applying the change, as a JQL query fails as a whole on a single unknown key
+		var issues []*models.IssueSchemeV2
+		for _, key := range keys {
+			issues = append(issues, &models.IssueSchemeV2{Key: strings.ToUpper(key)})
+		}
+		return issues, nil
+	}
+
+	var issues []*models.IssueSchemeV2
+	for len(issues) <= maxIssues {
+		result, response, err := services.JiraClient().Issue.Search.Get(ctx, jql, fields, nil, len(issues), maxIssues+1-len(issues), "")
+		if err != nil {
+			if response != nil {
+				return nil, fmt.Errorf("failed to search issues: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
+			}
+			return nil, fmt.Errorf("failed to search issues: %v", err)
+		}
+		if result.Total > maxIssues {
+			return nil, fmt.Errorf("%d issues match the query, more than max_issues (%d), narrow the query or raise max_issues", result.Total, maxIssues)
+		}
+		issues = append(issues, result.Issues...)
+		if len(result.Issues) == 0 || len(issues) >= result.Total {
+			break
+		}
+	}
+	return issues, nil
+}
+
+func formatJiraIssueCount(count int) string {
+	if count == 1 {
+		return "1 issue"
+	}
+	return fmt.Sprintf("%d issues", count)
+}
+
+func formatJiraBulkResult(result *jiraBulkResult) string {
+	line := "- " + result.key
+	if result.summary != "" {
+		line += " " + result.summary
+	}
+	switch {
+	case result.err != nil:
+		line += ": failed, " + strings.TrimSpace(result.err.Error())
+		if len(result.done) > 0 {
+			line += " (already " + strings.Join(result.done, ", ") + ")"
+		}
+	case len(result.done) == 0:
+		line += ": nothing to change"
+	case result.dryRun:
+		line += ": would " + strings.Join(result.done, ", ")
+	default:
+		line += ": " + strings.Join(result.done, ", ")
+	}
+	return line + "\n"
+}
+
+// jiraOutlineItem is an issue to create, read from one item or row of an outline
+type jiraOutlineItem struct {
+	id          string
+	line        int
+	depth       int
+	parent      *jiraOutlineItem
+	parentKey   string
+	issueType   string
+	summary     string
+	description []string
+	fields      [][2]string
+	key         string
+	existed     bool
+}
+
+var (
+	jiraOutlineBullet   = regexp.MustCompile(`^( *)(?:[-*+]|\d+[.)])\s+(.*)$`)
+	jiraOutlineIssueKey = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*-\d+$`)
+)
+
+func jiraBulkCreateHandler(args jiraBulkCreateArgs) (*mcp.CallToolResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), jiraOutlineStepTimeout)
+	types, err := jiraCreateIssueTypes(ctx, args.ProjectKey)
+	cancel()
+	if err != nil {
+		return nil, err
+	}
+
+	var items []*jiraOutlineItem
+	switch args.Format {
+	case "csv":
+		items, err = parseJiraCSVOutline(args.Outline)
+	default:
+		items, err = parseJiraMarkdownOutline(args.Outline, types)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, fmt.Errorf("the outline has no items")
+	}
+
+	if err := assignJiraOutlineTypes(items, types, args.Parent != ""); err != nil {
+		return nil, err
+	}
+
+	created := map[string]string{}
+	for _, pair := range splitList(args.Created) {
+		id, key, ok := strings.Cut(pair, "=")
+		if !ok || !jiraOutlineIssueKey.MatchString(strings.TrimSpace(key)) {
+			return nil, fmt.Errorf("created must list item=key pairs such as 1=KP-10, got %q", pair)
+		}
+		created[strings.TrimSpace(id)] = strings.ToUpper(strings.TrimSpace(key))
+	}
+	for _, item := range items {
+		if key, ok := created[item.id]; ok {
+			item.key, item.existed = key, true
+			delete(created, item.id)
+		}
+	}
+	for id := range created {
+		return nil, fmt.Errorf("created lists item %s, which is not in the outline", id)
+	}
+
+	// everything that can be checked without creating an issue is checked up front, so that a bad row fails before the first issue is created
+	requests := make([]*jiraCreateIssueArgs, len(items))
+	users := map[string]string{}
+	for i, item := range items {
+		request := &jiraCreateIssueArgs{ProjectKey: args.ProjectKey, Summary: item.summary, IssueType: item.issueType}
+		if err := item.request(request, users); err != nil {
+			return nil, fmt.Errorf("item %s (line %d): %v", item.id, item.line, err)
+		}
+		requests[i] = request
+	}
+	for query := range users {
+		ctx, cancel := context.WithTimeout(context.Background(), jiraOutlineStepTimeout)
+		user, err := jiraFindUser(ctx, query, "")
+		cancel()
+		if err != nil {
+			return nil, err
+		}
+		users[query] = jiraUserID(user)
+	}
+
+	// the parent's type decides how an item is linked to it, so existing parents are looked up too
+	parentTypes := map[string]string{}
+	for _, item := range items {
+		if parentKey := item.parentJiraKey(args.Parent); item.parent == nil && parentKey != "" {
+			parentTypes[parentKey] = ""
+		}
+	}
+	for key := range parentTypes {
+		issueType, err := jiraOutlineParentType(key)
+		if err != nil {
+			return nil, err
+		}
+		parentTypes[key] = issueType
+	}
+
+	if args.DryRun {
+		var sb strings.Builder
+		sb.WriteString(fmt.Sprintf("Dry run, %s would be created in %s:\n", formatJiraIssueCount(len(items)), args.ProjectKey))
+		for _, item := range items {
+			sb.WriteString(formatJiraOutlineItem(item, args.Parent))
+		}
+		return mcp.NewToolResultText(sb.String()), nil
+	}
+
+	for i, item := range items {
+		if item.existed {
+			continue
+		}
+
+		request := requests[i]
+		for _, user := range []*string{&request.Assignee, &request.Reporter} {
+			if *user != "" {
+				*user = users[*user]
+			}
+		}
+		if parentKey := item.parentJiraKey(args.Parent); parentKey != "" {
+			// issues under an epic are put in it, subtasks and the children of other issues hang off their parent
+			parentType := parentTypes[parentKey]
+			if item.parent != nil {
+				parentType = item.parent.issueType
+			}
+			if strings.EqualFold(parentType, "Epic") && !jiraOutlineIsSubtask(types, item.issueType) {
+				request.Epic = parentKey
+			} else {
+				request.Parent = parentKey
+			}
+		}
+
+		if err := createJiraOutlineItem(request, item); err != nil {
+			return mcp.NewToolResultError(formatJiraOutlineFailure(args, items, item, err)), nil
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Created %s in %s:\n", formatJiraIssueCount(jiraOutlineCreatedCount(items)), args.ProjectKey))
+	for _, item := range items {
+		sb.WriteString(formatJiraOutlineItem(item, args.Parent))
+	}
+	return mcp.NewToolResultText(sb.String()), nil
+}
+
+func createJiraOutlineItem(request *jiraCreateIssueArgs, item *jiraOutlineItem) error {
+	ctx, cancel := context.WithTimeout(context.Background(), jiraOutlineStepTimeout)
+	defer cancel()
+
+	fields, err := request.fields(ctx)
+	if err != nil {
+		return err
+	}
+
+	issue := new(models.IssueResponseScheme)
+	response, err := jiraRequest(ctx, http.MethodPost, "rest/api/2/issue", map[string]interface{}{"fields": fields}, issue)
+	if err != nil {
+		if response != nil {
+			return fmt.Errorf("failed to create issue: %s", strings.TrimSpace(response.Bytes.String()))
+		}
+		return fmt.Errorf("failed to create issue: %v", err)
+	}
+	item.key = issue.Key
+	return nil
+}
+
+// jiraOutlineParentType returns the issue type of an existing issue the outline goes under
+func jiraOutlineParentType(key string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), jiraOutlineStepTimeout)
+	defer cancel()
+
+	issue, response, err := services.JiraClient().Issue.Get(ctx, key, []string{"issuetype"}, nil)
+	if err != nil {
+		if response != nil {
+			return "", fmt.Errorf("failed to get parent %s: %s (endpoint: %s)", key, response.Bytes.String(), response.Endpoint)
+		}
+		return "", fmt.Errorf("failed to get parent %s: %v", key, err)
+	}
+	if issue.Fields == nil || issue.Fields.IssueType == nil {
+		return "", nil
+	}
+	return issue.Fields.IssueType.Name, nil
+}
+
+// parseJiraMarkdownOutline reads a nested list, where indented lines that are not list items continue the description of the item above
+func parseJiraMarkdownOutline(outline string, types []*models.IssueTypeScheme) ([]*jiraOutlineItem, error) {
+	type level struct {
+		indent int
+		item   *jiraOutlineItem
+	}
+
+	var items []*jiraOutlineItem
+	var stack []level
+	for i, line := range strings.Split(strings.ReplaceAll(outline, "\t", "    "), "\n") {
+		line = strings.TrimRight(line, " \r")
+		if line == "" {
+			if len(stack) > 0 {
+				last := stack[len(stack)-1].item
+				last.description = append(last.description, "")
+			}
+			continue
+		}
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+
+		match := jiraOutlineBullet.FindStringSubmatch(line)
+		if match == nil {
+			if len(stack) == 0 || indent <= stack[len(stack)-1].indent {
+				return nil, fmt.Errorf("line %d is neither a list item nor indented below one: %s", i+1, strings.TrimSpace(line))
+			}
+			last := stack[len(stack)-1]
+			last.item.description = append(last.item.description, strings.TrimPrefix(line, strings.Repeat(" ", last.indent+2)))
+			continue
+		}
+
+		for len(stack) > 0 && stack[len(stack)-1].indent >= indent {
+			stack = stack[:len(stack)-1]
+		}
+		item := &jiraOutlineItem{id: strconv.Itoa(len(items) + 1), line: i + 1, depth: len(stack)}
+		if len(stack) > 0 {
+			item.parent = stack[len(stack)-1].item
+		}
+
+		parts := strings.Split(match[2], "|")
+		item.summary = strings.TrimSpace(parts[0])
+		if name, summary, ok := strings.Cut(item.summary, ":"); ok && findJiraIssueType(types, strings.TrimSpace(name)) != nil {
+			item.issueType, item.summary = strings.TrimSpace(name), strings.TrimSpace(summary)
+		}
+		for _, part := range parts[1:] {
+			name, value, ok := strings.Cut(part, "=")
+			if !ok {
+				name, value, ok = strings.Cut(part, ":")
+			}
+			if !ok {
+				return nil, fmt.Errorf("line %d: %q should be a field=value pair", i+1, strings.TrimSpace(part))
+			}
+			item.fields = append(item.fields, [2]string{strings.TrimSpace(name), strings.TrimSpace(value)})
+		}
+		if item.summary == "" {
+			return nil, fmt.Errorf("line %d has no summary", i+1)
+		}
+
+		items = append(items, item)
+		stack = append(stack, level{indent, item})
+	}
+	return items, nil
+}
+
+// parseJiraCSVOutline reads a table with a header row, where rows refer to their parent by id or row number
+func parseJiraCSVOutline(outline string) ([]*jiraOutlineItem, error) {
+	reader := csv.NewReader(strings.NewReader(strings.TrimSpace(outline)))
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV: %v", err)
+	}
+	if len(rows) < 2 {
+		return nil, fmt.Errorf("the CSV needs a header row and at least one issue row")
+	}
+
+	header := make([]string, len(rows[0]))
+	hasSummary := false
+	for i, name := range rows[0] {
+		header[i] = normalizeJiraOutlineField(name)
+		hasSummary = hasSummary || header[i] == "summary"
+	}
+	if !hasSummary {
+		return nil, fmt.Errorf("the CSV header needs a summary column, got: %s", strings.Join(rows[0], ", "))
+	}
+
+	var items []*jiraOutlineItem
+	byID := map[string]*jiraOutlineItem{}
+	for i, row := range rows[1:] {
+		item := &jiraOutlineItem{id: strconv.Itoa(i + 1), line: i + 2}
+		parent := ""
+		for column, value := range row {
+			if column >= len(header) {
+				return nil, fmt.Errorf("line %d has more columns than the header", item.line)
+			}
+			value = strings.TrimSpace(value)
+			switch header[column] {
+			case "id":
+				if value != "" {
+					item.id = value
+				}
+			case "summary":
+				item.summary = value
+			case "type", "issue type", "issuetype":
+				item.issueType = value
+			case "parent":
+				parent = value
+			default:
+				if value != "" {
+					item.fields = append(item.fields, [2]string{rows[0][column], value})
+				}
+			}
+		}
+
+		if item.summary == "" {
+			return nil, fmt.Errorf("line %d has no summary", item.line)
+		}
+		if byID[item.id] != nil {
+			return nil, fmt.Errorf("line %d repeats the id %s", item.line, item.id)
+		}
+		switch {
+		case parent == "":
+		case byID[parent] != nil:
+			item.parent = byID[parent]
+			item.depth = item.parent.depth + 1
+		case jiraOutlineIssueKey.MatchString(parent):
+			item.parentKey = strings.ToUpper(parent)
+			item.depth = 1
+		default:
+			return nil, fmt.Errorf("line %d: parent %q is neither the id of an earlier row nor an issue key", item.line, parent)
+		}
+
+		byID[item.id] = item
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// assignJiraOutlineTypes checks the given issue types and fills in the missing ones from the depth of the item
+func assignJiraOutlineTypes(items []*jiraOutlineItem, types []*models.IssueTypeScheme, underParent bool) error {
+	var levels [3]string
+	for _, issueType := range types {
+		switch {
+		case issueType.Subtask:
+			if levels[2] == "" {
+				levels[2] = issueType.Name
+			}
+		case strings.EqualFold(issueType.Name, "Epic"):
+			levels[0] = issueType.Name
+		case strings.EqualFold(issueType.Name, "Story"):
+			levels[1] = issueType.Name
+		}
+	}
+
+	for _, item := range items {
+		// items under the parent argument sit one level lower than their place in the outline
+		depth := item.depth
+		if underParent && item.root().parentKey == "" {
+			depth++
+		}
+		if depth > 2 {
+			return fmt.Errorf("item %s (line %d) is nested too deep, Jira issues go at most three levels deep: epic, story and subtask", item.id, item.line)
+		}
+
+		if item.issueType != "" {
+			issueType := findJiraIssueType(types, item.issueType)
+			if issueType == nil {
+				return fmt.Errorf("item %s (line %d): issue type %q does not exist in the project, available types: %s", item.id, item.line, item.issueType, describeJiraIssueTypes(types))
+			}
+			item.issueType = issueType.Name
+			continue
+		}
+
+		if levels[depth] == "" {
+			return fmt.Errorf("item %s (line %d) has no type and the project has no default for its level, give one of: %s", item.id, item.line, describeJiraIssueTypes(types))
+		}
+		item.issueType = levels[depth]
+	}
+	return nil
+}
+
+// request sets the fields of the item on the create request, collecting the users to resolve
+func (item *jiraOutlineItem) request(request *jiraCreateIssueArgs, users map[string]string) error {
+	request.Description = strings.TrimSpace(strings.Join(item.description, "\n"))
+
+	custom := map[string]interface{}{}
+	for _, field := range item.fields {
+		name, value := field[0], field[1]
+		switch normalizeJiraOutlineField(name) {
+		case "description":
+			request.Description = value
+		case "assignee":
+			request.Assignee = value
+			users[value] = ""
+		case "reporter":
+			request.Reporter = value
+			users[value] = ""
+		case "priority":
+			request.Priority = value
+		case "labels", "label":
+			request.Labels = value
+		case "components", "component":
+			request.Components = value
+		case "fix versions", "fix version", "fixversions", "fixversion":
+			request.FixVersions = value
+		case "due date", "duedate", "due":
+			if _, err := time.Parse("2006-01-02", value); err != nil {
+				return fmt.Errorf("due date must be YYYY-MM-DD, got %q", value)
+			}
+			request.DueDate = value
+		case "story points", "points", "sp":
+			points, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return fmt.Errorf("story points must be a number, got %q", value)
+			}
+			request.StoryPoints = &points
+		default:
+			custom[name] = value
+		}
+	}
+
+	if len(custom) > 0 {
+		encoded, err := json.Marshal(custom)
+		if err != nil {
+			return err
+		}
+		request.CustomFields = string(encoded)
+	}
+	return nil
+}
+
+// root is the top-level item the item is nested in
+func (item *jiraOutlineItem) root() *jiraOutlineItem {
+	for item.parent != nil {
+		item = item.parent
+	}
+	return item
+}
+
+// parentJiraKey is the key of the issue the item goes under, once its parent item is created
+func (item *jiraOutlineItem) parentJiraKey(parent string) string {
+	switch {
+	case item.parent != nil:
+		return item.parent.key
+	case item.parentKey != "":
+		return item.parentKey
+	}
+	return parent
+}
+
+func normalizeJiraOutlineField(name string) string {
+	name = strings.NewReplacer("_", " ", "-", " ").Replace(strings.ToLower(name))
+	return strings.Join(strings.Fields(name), " ")
+}
+
+func findJiraIssueType(types []*models.IssueTypeScheme, nameOrID string) *models.IssueTypeScheme {
+	for _, issueType := range types {
+		if issueType.ID == nameOrID || strings.EqualFold(issueType.Name, nameOrID) {
+			return issueType
+		}
+	}
+	return nil
+}
+
+func describeJiraIssueTypes(types []*models.IssueTypeScheme) string {
+	var names []string
+	for _, issueType := range types {
+		names = append(names, issueType.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
+func jiraOutlineIsSubtask(types []*models.IssueTypeScheme, name string) bool {
+	issueType := findJiraIssueType(types, name)
+	return issueType != nil && issueType.Subtask
+}
+
+func jiraOutlineCreatedCount(items []*jiraOutlineItem) int {
+	count := 0
+	for _, item := range items {
+		if item.key != "" && !item.existed {
+			count++
+		}
+	}
+	return count
+}
+
+func formatJiraOutlineItem(item *jiraOutlineItem, parent string) string {
+	key := item.key
+	if key == "" {
+		key = "(not created)"
+	}
+	line := fmt.Sprintf("%s- %s. %s %s: %s", strings.Repeat("  ", item.depth), item.id, key, item.issueType, item.summary)
+
+	var details []string
+	if item.existed {
+		details = append(details, "created before")
+	}
+	if item.parent == nil {
+		if parentKey := item.parentJiraKey(parent); parentKey != "" {
+			details = append(details, "under "+parentKey)
+		}
+	}
+	for _, field := range item.fields {
+		details = append(details, field[0]+"="+field[1])
+	}
+	if len(details) > 0 {
+		line += " (" + strings.Join(details, ", ") + ")"
+	}
+	return line + "\n"
+}
+
+// formatJiraOutlineFailure reports what was created before item failed and how to carry on from there
+func formatJiraOutlineFailure(args jiraBulkCreateArgs, items []*jiraOutlineItem, failed *jiraOutlineItem, err error) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Item %s (line %d) failed: %s\n\n", failed.id, failed.line, strings.TrimSpace(err.Error())))
+	sb.WriteString(fmt.Sprintf("Created %s in %s before the failure, nothing after it was created:\n", formatJiraIssueCount(jiraOutlineCreatedCount(items)), args.ProjectKey))
+
+	var pairs []string
+	for _, item := range items {
+		sb.WriteString(formatJiraOutlineItem(item, args.Parent))
+		if item.key != "" {
+			pairs = append(pairs, item.id+"="+item.key)
+		}
+	}
+
+	sb.WriteString(fmt.Sprintf("\nTo resume, fix item %s and call jira_bulk_create again with the same outline", failed.id))
+	if len(pairs) > 0 {
+		sb.WriteString(fmt.Sprintf(" and created=%q", strings.Join(pairs, ",")))
+	}
+	sb.WriteString(".\n")
+	return sb.String()
+}
@@ -0,0 +1,213 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ctreminiom/go-atlassian/pkg/infra/models"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/nguyenvanduocit/dev-kit/services"
+	"github.com/nguyenvanduocit/dev-kit/util"
+	"github.com/nguyenvanduocit/dev-kit/util/markup"
+)
+
+func registerJiraCommentTools(s *server.MCPServer) {
+	jiraListCommentsTool := mcp.NewTool("jira_list_comments",
+		mcp.WithDescription("List the comments of a Jira issue with their IDs, authors, creation and update times, and visibility restrictions"),
+		mcp.WithString("issue_key", mcp.Required(), mcp.Description("The unique identifier of the Jira issue (e.g., KP-2, PROJ-123)")),
+		mcp.WithString("order_by", mcp.DefaultString("created"), mcp.Enum("created", "-created"), mcp.Description("Sort order: created for oldest first, -created for newest first")),
+		mcp.WithNumber("start_at", mcp.DefaultNumber(0), mcp.Min(0), mcp.Description("Index of the first comment to return")),
+		mcp.WithNumber("max_results", mcp.DefaultNumber(50), mcp.Min(1), mcp.Max(100), mcp.Description("Maximum number of comments to return")),
+	)
+
+	jiraAddCommentTool := mcp.NewTool("jira_add_comment",
+		mcp.WithDescription("Add a comment to a Jira issue, optionally visible only to members of a project role or group"),
+		mcp.WithString("issue_key", mcp.Required(), mcp.Description("The issue to comment on (e.g., KP-2)")),
+		mcp.WithString("body", mcp.Required(), mcp.Description("Comment text in Markdown")),
+		mcp.WithString("visibility_type", mcp.Enum("role", "group"), mcp.Description("Restrict the comment to a project role or a group (optional)")),
+		mcp.WithString("visibility_value", mcp.Description("Name of the role or group the comment is restricted to, required with visibility_type (e.g., Developers)")),
+	)
+
+	jiraUpdateCommentTool := mcp.NewTool("jira_update_comment",
+		mcp.WithDescription("Replace the text of an existing comment on a Jira issue and optionally change its visibility"),
+		mcp.WithString("issue_key", mcp.Required(), mcp.Description("The issue the comment belongs to (e.g., KP-2)")),
+		mcp.WithString("comment_id", mcp.Required(), mcp.Description("ID of the comment, from jira_list_comments")),
+		mcp.WithString("body", mcp.Required(), mcp.Description("New comment text in Markdown")),
+		mcp.WithString("visibility_type", mcp.Enum("role", "group"), mcp.Description("Restrict the comment to a project role or a group (optional)")),
+		mcp.WithString("visibility_value", mcp.Description("Name of the role or group the comment is restricted to, required with visibility_type")),
+	)
+
+	jiraDeleteCommentTool := mcp.NewTool("jira_delete_comment",
+		mcp.WithDescription("Delete a comment from a Jira issue"),
+		mcp.WithString("issue_key", mcp.Required(), mcp.Description("The issue the comment belongs to (e.g., KP-2)")),
+		mcp.WithString("comment_id", mcp.Required(), mcp.Description("ID of the comment, from jira_list_comments")),
+	)
+
+	s.AddTool(jiraListCommentsTool, util.ErrorGuard(util.TypedHandler(jiraListCommentsTool, jiraListCommentsHandler)))
+	s.AddTool(jiraAddCommentTool, util.ErrorGuard(util.TypedHandler(jiraAddCommentTool, jiraAddCommentHandler)))
+	s.AddTool(jiraUpdateCommentTool, util.ErrorGuard(util.TypedHandler(jiraUpdateCommentTool, jiraUpdateCommentHandler)))
+	s.AddTool(jiraDeleteCommentTool, util.ErrorGuard(util.TypedHandler(jiraDeleteCommentTool, jiraDeleteCommentHandler)))
+}
+
+type jiraListCommentsArgs struct {
+	IssueKey   string `json:"issue_key"`
+	OrderBy    string `json:"order_by"`
+	StartAt    int    `json:"start_at"`
+	MaxResults int    `json:"max_results"`
+}
+
+type jiraCommentVisibilityArgs struct {
+	VisibilityType  string `json:"visibility_type"`
+	VisibilityValue string `json:"visibility_value"`
+}
+
+type jiraAddCommentArgs struct {
+	jiraCommentVisibilityArgs
+	IssueKey string `json:"issue_key"`
+	Body     string `json:"body"`
+}
+
+type jiraCommentArgs struct {
+	IssueKey  string `json:"issue_key"`
+	CommentID string `json:"comment_id"`
+}
+
+type jiraUpdateCommentArgs struct {
+	jiraCommentArgs
+	jiraCommentVisibilityArgs
+	Body string `json:"body"`
+}
+
+// visibility returns the restriction described by the arguments, or nil for a public comment
+func (args jiraCommentVisibilityArgs) visibility() (*models.CommentVisibilityScheme, error) {
+	if args.VisibilityType == "" && args.VisibilityValue == "" {
+		return nil, nil
+	}
+	if args.VisibilityType == "" || args.VisibilityValue == "" {
+		return nil, fmt.Errorf("visibility_type and visibility_value must be given together")
+	}
+	return &models.CommentVisibilityScheme{Type: args.VisibilityType, Value: args.VisibilityValue}, nil
+}
+
+func jiraListCommentsHandler(args jiraListCommentsArgs) (*mcp.CallToolResult, error) {
+	client := services.JiraClient()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second)
+	defer cancel()
+
+	page, response, err := client.Issue.Comment.Gets(ctx, args.IssueKey, args.OrderBy, nil, args.StartAt, args.MaxResults)
+	if err != nil {
+		if response != nil {
+			return nil, fmt.Errorf("failed to list comments: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
+		}
+		return nil, fmt.Errorf("failed to list comments: %v", err)
+	}
+
+	if len(page.Comments) == 0 {
+		return mcp.NewToolResultText(fmt.Sprintf("No comments found on %s.", args.IssueKey)), nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Comments %d-%d of %d on %s:\n\n", page.StartAt+1, page.StartAt+len(page.Comments), page.Total, args.IssueKey))
+	for _, comment := range page.Comments {
+		sb.WriteString(formatJiraComment(comment))
+		sb.WriteString("\n")
+	}
+
+	return mcp.NewToolResultText(sb.String()), nil
+}
+
+func jiraAddCommentHandler(args jiraAddCommentArgs) (*mcp.CallToolResult, error) {
+	visibility, err := args.visibility()
+	if err != nil {
+		return nil, err
+	}
+
+	client := services.JiraClient()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second)
+	defer cancel()
+
+	payload := &models.CommentPayloadSchemeV2{Body: markup.MarkdownToWiki(args.Body), Visibility: visibility}
+	comment, response, err := client.Issue.Comment.Add(ctx, args.IssueKey, payload, nil)
+	if err != nil {
+		if response != nil {
+			return nil, fmt.Errorf("failed to add comment: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
+		}
+		return nil, fmt.Errorf("failed to add comment: %v", err)
+	}
+
+	return mcp.NewToolResultText("Comment added successfully!\n" + formatJiraComment(comment)), nil
+}
+
+func jiraUpdateCommentHandler(args jiraUpdateCommentArgs) (*mcp.CallToolResult, error) {
+	visibility, err := args.visibility()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second)
+	defer cancel()
+
+	// go-atlassian has no comment update for the v2 API, so the request is sent directly
+	endpoint := fmt.Sprintf("rest/api/2/issue/%s/comment/%s", args.IssueKey, args.CommentID)
+	payload := &models.CommentPayloadSchemeV2{Body: markup.MarkdownToWiki(args.Body), Visibility: visibility}
+	comment := new(models.IssueCommentSchemeV2)
+	response, err := jiraRequest(ctx, http.MethodPut, endpoint, payload, comment)
+	if err != nil {
+		if response != nil {
+			return nil, fmt.Errorf("failed to update comment: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
+		}
+		return nil, fmt.Errorf("failed to update comment: %v", err)
+	}
+
+	return mcp.NewToolResultText("Comment updated successfully!\n" + formatJiraComment(comment)), nil
+}
+
+func jiraDeleteCommentHandler(args jiraCommentArgs) (*mcp.CallToolResult, error) {
+	client := services.JiraClient()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second)
+	defer cancel()
+
+	response, err := client.Issue.Comment.Delete(ctx, args.IssueKey, args.CommentID)
+	if err != nil {
+		if response != nil {
+			return nil, fmt.Errorf("failed to delete comment: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
+		}
+		return nil, fmt.Errorf("failed to delete comment: %v", err)
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("Comment %s deleted from %s", args.CommentID, args.IssueKey)), nil
+}
+
+func formatJiraComment(comment *models.IssueCommentSchemeV2) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("ID: %s\n", comment.ID))
+
+	author := "Unknown"
+	if comment.Author != nil {
+		author = comment.Author.DisplayName
+	}
+	sb.WriteString(fmt.Sprintf("Author: %s\n", author))
+	sb.WriteString(fmt.Sprintf("Created: %s\n", comment.Created))
+
+	if comment.Updated != "" && comment.Updated != comment.Created {
+		updated := comment.Updated
+		if comment.UpdateAuthor != nil && (comment.Author == nil || jiraUserID(comment.UpdateAuthor) != jiraUserID(comment.Author)) {
+			updated += " by " + comment.UpdateAuthor.DisplayName
+		}
+		sb.WriteString(fmt.Sprintf("Updated: %s\n", updated))
+	}
+
+	if comment.Visibility != nil {
+		sb.WriteString(fmt.Sprintf("Visibility: %s %s\n", comment.Visibility.Type, comment.Visibility.Value))
+	}
+
+	sb.WriteString(fmt.Sprintf("Body:\n%s\n", markup.WikiToMarkdown(comment.Body)))
+	return sb.String()
+}
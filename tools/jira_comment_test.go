@@ -0,0 +1,45 @@
+package tools
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestJiraCommentHandlers(t *testing.T) {
+	text := resultText(t)(jiraCreateIssueHandler(jiraCreateIssueArgs{ProjectKey: "KP", Summary: "Comment target", IssueType: "Task"}))
+	key := regexp.MustCompile(`KP-\d+`).FindString(text)
+
+	text = resultText(t)(jiraListCommentsHandler(jiraListCommentsArgs{IssueKey: key, OrderBy: "created", MaxResults: 50}))
+	assertContains(t, text, "No comments found on "+key)
+
+	text = resultText(t)(jiraAddCommentHandler(jiraAddCommentArgs{IssueKey: key, Body: "Needs **review**"}))
+	id := regexp.MustCompile(`ID: (\S+)`).FindStringSubmatch(text)
+	if id == nil {
+		t.Fatalf("no comment ID in the add output:\n%s", text)
+	}
+	assertContains(t, text, "Author: Alice Nguyen", "Needs **review**")
+
+	restricted := jiraAddCommentArgs{IssueKey: key, Body: "Internal note"}
+	restricted.VisibilityType = "role"
+	restricted.VisibilityValue = "Developers"
+	text = resultText(t)(jiraAddCommentHandler(restricted))
+	assertContains(t, text, "Visibility: role Developers")
+
+	restricted.VisibilityValue = ""
+	if _, err := jiraAddCommentHandler(restricted); err == nil {
+		t.Error("adding a comment with a visibility type but no value succeeded, want an error")
+	}
+
+	text = resultText(t)(jiraListCommentsHandler(jiraListCommentsArgs{IssueKey: key, OrderBy: "-created", MaxResults: 50}))
+	assertContains(t, text, "Comments 1-2 of 2 on "+key, "Needs **review**", "Internal note")
+
+	update := jiraUpdateCommentArgs{jiraCommentArgs: jiraCommentArgs{IssueKey: key, CommentID: id[1]}, Body: "Reviewed"}
+	text = resultText(t)(jiraUpdateCommentHandler(update))
+	assertContains(t, text, "Comment updated successfully", "Reviewed")
+
+	text = resultText(t)(jiraDeleteCommentHandler(jiraCommentArgs{IssueKey: key, CommentID: id[1]}))
+	assertContains(t, text, "Comment "+id[1]+" deleted from "+key)
+
+	text = resultText(t)(jiraListCommentsHandler(jiraListCommentsArgs{IssueKey: key, OrderBy: "created", MaxResults: 50}))
+	assertContains(t, text, "Comments 1-1 of 1", "Internal note")
+}
@@ -0,0 +1,361 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ctreminiom/go-atlassian/pkg/infra/models"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/nguyenvanduocit/dev-kit/services"
+	"github.com/nguyenvanduocit/dev-kit/util"
+	"github.com/nguyenvanduocit/dev-kit/util/markup"
+)
+
+// maxAllowedValues limits how many allowed values of a field are listed
+const maxAllowedValues = 25
+
+func registerJiraFieldTools(s *server.MCPServer) {
+	jiraGetCreateMetaTool := mcp.NewTool("jira_get_create_meta",
+		mcp.WithDescription("List the issue types of a Jira project, or the fields of an issue type with whether they are required, their types and allowed values. Use it to find the values jira_create_issue needs"),
+		mcp.WithString("project_key", mcp.Required(), mcp.Description("Project identifier (e.g., KP, PROJ)")),
+		mcp.WithString("issue_type", mcp.Description("Name or ID of the issue type whose fields to list (optional, lists the issue types when omitted)")),
+	)
+
+	s.AddTool(jiraGetCreateMetaTool, util.ErrorGuard(util.TypedHandler(jiraGetCreateMetaTool, jiraGetCreateMetaHandler)))
+}
+
+type jiraGetCreateMetaArgs struct {
+	ProjectKey string `json:"project_key"`
+	IssueType  string `json:"issue_type"`
+}
+
+// jiraCreateMetaField is a field of the create screen of an issue type or of a transition screen
+type jiraCreateMetaField struct {
+	FieldID         string                         `json:"fieldId"`
+	Name            string                         `json:"name"`
+	Required        bool                           `json:"required"`
+	Schema          *models.IssueFieldSchemaScheme `json:"schema"`
+	AllowedValues   []map[string]interface{}       `json:"allowedValues"`
+	HasDefaultValue bool                           `json:"hasDefaultValue"`
+	DefaultValue    interface{}                    `json:"defaultValue"`
+}
+
+// Jira Cloud returns the create metadata under issueTypes and fields, Data Center under values
+type jiraCreateMetaIssueTypes struct {
+	IssueTypes []*models.IssueTypeScheme `json:"issueTypes"`
+	Values     []*models.IssueTypeScheme `json:"values"`
+}
+
+type jiraCreateMetaFields struct {
+	Fields []*jiraCreateMetaField `json:"fields"`
+	Values []*jiraCreateMetaField `json:"values"`
+}
+
+func jiraGetCreateMetaHandler(args jiraGetCreateMetaArgs) (*mcp.CallToolResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second)
+	defer cancel()
+
+	issueTypes, err := jiraCreateIssueTypes(ctx, args.ProjectKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if args.IssueType == "" {
+		var sb strings.Builder
+		sb.WriteString(fmt.Sprintf("Issue types of %s:\n", args.ProjectKey))
+		for _, issueType := range issueTypes {
+			sb.WriteString(fmt.Sprintf("- %s (ID: %s)", issueType.Name, issueType.ID))
+			if issueType.Subtask {
+				sb.WriteString(" [subtask]")
+			}
+			if issueType.Description != "" {
+				sb.WriteString(": " + issueType.Description)
+			}
+			sb.WriteString("\n")
+		}
+		return mcp.NewToolResultText(sb.String()), nil
+	}
+
+	var issueType *models.IssueTypeScheme
+	var names []string
+	for _, candidate := range issueTypes {
+		if candidate.ID == args.IssueType || strings.EqualFold(candidate.Name, args.IssueType) {
+			issueType = candidate
+		}
+		names = append(names, candidate.Name)
+	}
+	if issueType == nil {
+		return nil, fmt.Errorf("issue type %q not found in %s, available types: %s", args.IssueType, args.ProjectKey, strings.Join(names, ", "))
+	}
+
+	fields, err := jiraCreateFields(ctx, args.ProjectKey, issueType.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// required fields first, then by name
+	sort.SliceStable(fields, func(i, j int) bool {
+		if fields[i].Required != fields[j].Required {
+			return fields[i].Required
+		}
+		return fields[i].Name < fields[j].Name
+	})
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Fields for creating a %s in %s:\n", issueType.Name, args.ProjectKey))
+	required := true
+	sb.WriteString("\nRequired:\n")
+	for _, field := range fields {
+		if required && !field.Required {
+			required = false
+			sb.WriteString("\nOptional:\n")
+		}
+		sb.WriteString(formatJiraCreateMetaField(field))
+	}
+
+	return mcp.NewToolResultText(sb.String()), nil
+}
+
+func formatJiraCreateMetaField(field *jiraCreateMetaField) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("- %s (%s): %s", field.Name, field.FieldID, jiraFieldType(field.Schema)))
+
+	if len(field.AllowedValues) > 0 {
+		var values []string
+		for i, value := range field.AllowedValues {
+			if i == maxAllowedValues {
+				values = append(values, fmt.Sprintf("... and %d more", len(field.AllowedValues)-maxAllowedValues))
+				break
+			}
+			values = append(values, jiraAllowedValueName(value))
+		}
+		sb.WriteString("; allowed: " + strings.Join(values, ", "))
+	}
+
+	if field.HasDefaultValue {
+		if value, ok := field.DefaultValue.(map[string]interface{}); ok {
+			sb.WriteString("; default: " + jiraAllowedValueName(value))
+		} else if field.DefaultValue != nil {
+			sb.WriteString(fmt.Sprintf("; default: %v", field.DefaultValue))
+		}
+	}
+
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+func jiraFieldType(schema *models.IssueFieldSchemaScheme) string {
+	if schema == nil {
+		return "unknown"
+	}
+	if schema.Type == "array" && schema.Items != "" {
+		return "array of " + schema.Items
+	}
+	return schema.Type
+}
+
+// jiraAllowedValueName returns the name an allowed value is referred to by
+func jiraAllowedValueName(value map[string]interface{}) string {
+	for _, key := range []string{"name", "value", "key", "id"} {
+		if name, ok := value[key].(string); ok && name != "" {
+			return name
+		}
+	}
+	return fmt.Sprint(value)
+}
+
+func jiraCreateIssueTypes(ctx context.Context, projectKey string) ([]*models.IssueTypeScheme, error) {
+	endpoint := fmt.Sprintf("rest/api/2/issue/createmeta/%s/issuetypes?maxResults=200", url.PathEscape(projectKey))
+	page := new(jiraCreateMetaIssueTypes)
+	response, err := jiraRequest(ctx, http.MethodGet, endpoint, nil, page)
+	if err != nil {
+		if response != nil {
+			return nil, fmt.Errorf("failed to get issue types: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
+		}
+		return nil, fmt.Errorf("failed to get issue types: %v", err)
+	}
+
+	return append(page.IssueTypes, page.Values...), nil
+}
+
+func jiraCreateFields(ctx context.Context, projectKey, issueTypeID string) ([]*jiraCreateMetaField, error) {
+	endpoint := fmt.Sprintf("rest/api/2/issue/createmeta/%s/issuetypes/%s?maxResults=200", url.PathEscape(projectKey), url.PathEscape(issueTypeID))
+	page := new(jiraCreateMetaFields)
+	response, err := jiraRequest(ctx, http.MethodGet, endpoint, nil, page)
+	if err != nil {
+		if response != nil {
+			return nil, fmt.Errorf("failed to get create metadata: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
+		}
+		return nil, fmt.Errorf("failed to get create metadata: %v", err)
+	}
+
+	return append(page.Fields, page.Values...), nil
+}
+
+// jiraFieldCatalog lists every system and custom field of the instance
+func jiraFieldCatalog(ctx context.Context) ([]*models.IssueFieldScheme, error) {
+	client := services.JiraClient()
+
+	fields, response, err := client.Issue.Field.Gets(ctx)
+	if err != nil {
+		if response != nil {
+			return nil, fmt.Errorf("failed to list fields: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
+		}
+		return nil, fmt.Errorf("failed to list fields: %v", err)
+	}
+
+	return fields, nil
+}
+
+// findJiraField looks a field up by ID or, ignoring case, by name
+func findJiraField(fields []*models.IssueFieldScheme, nameOrID string) *models.IssueFieldScheme {
+	for _, field := range fields {
+		if field.ID == nameOrID {
+			return field
+		}
+	}
+	for _, field := range fields {
+		if strings.EqualFold(field.Name, nameOrID) {
+			return field
+		}
+	}
+	return nil
+}
+
+// jiraEpicLinkField returns the Epic Link field of instances that link issues to epics with it rather than as parents
+func jiraEpicLinkField(fields []*models.IssueFieldScheme) *models.IssueFieldScheme {
+	for _, field := range fields {
+		if field.Schema != nil && field.Schema.Custom == "com.pyxis.greenhopper.jira:gh-epic-link" {
+			return field
+		}
+	}
+	return nil
+}
+
+// jiraStoryPointsField returns the story points field, named differently by company and team managed projects
+func jiraStoryPointsField(fields []*models.IssueFieldScheme) *models.IssueFieldScheme {
+	if field := findJiraField(fields, "Story Points"); field != nil {
+		return field
+	}
+	return findJiraField(fields, "Story point estimate")
+}
+
+// jiraFieldValue converts a plain value, such as an option name or a comma separated list,
+// into the representation Jira expects for a field with the given schema. Objects and
+// arrays of objects are passed through unchanged.
+func jiraFieldValue(schema *models.IssueFieldSchemaScheme, value interface{}) (interface{}, error) {
+	if schema == nil {
+		return value, nil
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return v, nil
+	case []interface{}:
+		if len(v) > 0 {
+			if _, ok := v[0].(map[string]interface{}); ok {
+				return v, nil
+			}
+		}
+	}
+
+	if schema.Type == "array" {
+		var items []interface{}
+		switch v := value.(type) {
+		case []interface{}:
+			items = v
+		default:
+			for _, item := range splitList(fmt.Sprint(v)) {
+				items = append(items, item)
+			}
+		}
+
+		values := []interface{}{}
+		for _, item := range items {
+			converted, err := jiraScalarFieldValue(schema.Items, "", item)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, converted)
+		}
+		return values, nil
+	}
+
+	return jiraScalarFieldValue(schema.Type, schema.Custom, value)
+}
+
+func jiraScalarFieldValue(fieldType, custom string, value interface{}) (interface{}, error) {
+	text := fmt.Sprint(value)
+
+	switch fieldType {
+	case "number":
+		switch v := value.(type) {
+		case float64:
+			return v, nil
+		case string:
+			number, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			if err != nil {
+				return nil, fmt.Errorf("expected a number, got %q", v)
+			}
+			return number, nil
+		}
+		return nil, fmt.Errorf("expected a number, got %v", value)
+	case "string":
+		if strings.HasSuffix(custom, ":textarea") {
+			return markup.MarkdownToWiki(text), nil
+		}
+		return text, nil
+	case "date":
+		if _, err := time.Parse("2006-01-02", text); err != nil {
+			return nil, fmt.Errorf("expected a date as YYYY-MM-DD, got %q", text)
+		}
+		return text, nil
+	case "option":
+		return map[string]interface{}{"value": text}, nil
+	case "option-with-child":
+		parent, child, ok := strings.Cut(text, ">")
+		option := map[string]interface{}{"value": strings.TrimSpace(parent)}
+		if ok {
+			option["child"] = map[string]interface{}{"value": strings.TrimSpace(child)}
+		}
+		return option, nil
+	case "user":
+		return jiraUserRef(text), nil
+	case "project", "issuelink":
+		return map[string]interface{}{"key": text}, nil
+	case "priority", "version", "component", "issuetype", "resolution", "securitylevel", "group":
+		return map[string]interface{}{"name": text}, nil
+	}
+	return value, nil
+}
+
+// splitList splits a comma separated list, dropping empty items
+func splitList(list string) []string {
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseJiraFieldsArgument parses an argument holding a JSON object keyed by field name or ID
+func parseJiraFieldsArgument(name, fields string) (map[string]interface{}, error) {
+	values := map[string]interface{}{}
+	if strings.TrimSpace(fields) == "" {
+		return values, nil
+	}
+	if err := json.Unmarshal([]byte(fields), &values); err != nil {
+		return nil, fmt.Errorf("%s must be a JSON object of field names or IDs to values: %v", name, err)
+	}
+	return values, nil
+}
@@ -0,0 +1,400 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ctreminiom/go-atlassian/pkg/infra/models"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/nguyenvanduocit/dev-kit/util"
+)
+
+// jiraFilterExpand asks for the filter properties that the filter search leaves out by default
+const jiraFilterExpand = "description,favourite,jql,owner,sharePermissions,viewUrl"
+
+func registerJiraFilterTools(s *server.MCPServer) {
+	jiraListFiltersTool := mcp.NewTool("jira_list_filters",
+		mcp.WithDescription("List saved Jira filters with their IDs, owners, sharing and JQL: your favourite filters, the ones you own, or all filters shared with you"),
+		mcp.WithString("scope", mcp.DefaultString("favourite"), mcp.Enum("favourite", "mine", "all"), mcp.Description("Which filters to list")),
+		mcp.WithString("query", mcp.Description("Only list filters whose name contains this text (optional)")),
+	)
+
+	jiraRunFilterTool := mcp.NewTool("jira_run_filter",
+		mcp.WithDescription("Run a saved Jira filter by name or ID and return its issues like jira_search_issue does"),
+		mcp.WithString("filter", mcp.Required(), mcp.Description("Name or ID of the filter (e.g., My team's open bugs, 10200)")),
+		mcp.WithString("fields", mcp.Description("Comma-separated extra fields to return, by name or ID (e.g., labels, Story point estimate)")),
+		mcp.WithString("expand", mcp.Description("Comma-separated extra data to return: changelog, renderedFields")),
+		mcp.WithString("order_by", mcp.Description("Order of the results, replacing the filter's ORDER BY (e.g., priority DESC, updated)")),
+		mcp.WithNumber("max_results", mcp.DefaultNumber(30), mcp.Min(0), mcp.Max(100), mcp.Description("Maximum number of issues to return, 0 to only return the counts of group_by")),
+		mcp.WithNumber("start_at", mcp.DefaultNumber(0), mcp.Min(0), mcp.Description("Index of the first issue to return, for paging")),
+		mcp.WithString("group_by", mcp.Description("Count all matching issues grouped by this field (e.g., status, assignee)")),
+	)
+
+	jiraSaveFilterTool := mcp.NewTool("jira_save_filter",
+		mcp.WithDescription("Save a JQL query as a Jira filter, or update the query, name, description or sharing of a filter you own. The JQL is validated first"),
+		mcp.WithString("filter", mcp.Description("Name or ID of the filter to update; leave empty to create a new filter")),
+		mcp.WithString("name", mcp.Description("Name of the filter, required when creating one")),
+		mcp.WithString("jql", mcp.Description("JQL query of the filter, required when creating one")),
+		mcp.WithString("description", mcp.Description("Description of the filter (optional)")),
+		mcp.WithString("share", mcp.Description("Comma-separated audiences to share the filter with: project:KEY, group:NAME or authenticated for all logged-in users; none makes it private (optional)")),
+		mcp.WithBoolean("favourite", mcp.Description("Add the filter to, or remove it from, your favourites (optional)")),
+	)
+
+	jiraListDashboardsTool := mcp.NewTool("jira_list_dashboards",
+		mcp.WithDescription("List Jira dashboards with their IDs, owners and links"),
+		mcp.WithString("scope", mcp.DefaultString("favourite"), mcp.Enum("favourite", "mine", "all"), mcp.Description("Which dashboards to list")),
+	)
+
+	s.AddTool(jiraListFiltersTool, util.ErrorGuard(util.TypedHandler(jiraListFiltersTool, jiraListFiltersHandler)))
+	s.AddTool(jiraRunFilterTool, util.ErrorGuard(util.TypedHandler(jiraRunFilterTool, jiraRunFilterHandler)))
+	s.AddTool(jiraSaveFilterTool, util.ErrorGuard(util.TypedHandler(jiraSaveFilterTool, jiraSaveFilterHandler)))
+	s.AddTool(jiraListDashboardsTool, util.ErrorGuard(util.TypedHandler(jiraListDashboardsTool, jiraListDashboardsHandler)))
+}
+
+type jiraListFiltersArgs struct {
+	Scope string `json:"scope"`
+	Query string `json:"query"`
+}
+
+type jiraRunFilterArgs struct {
+	Filter     string `json:"filter"`
+	Fields     string `json:"fields"`
+	Expand     string `json:"expand"`
+	OrderBy    string `json:"order_by"`
+	MaxResults int    `json:"max_results"`
+	StartAt    int    `json:"start_at"`
+	GroupBy    string `json:"group_by"`
+}
+
+type jiraSaveFilterArgs struct {
+	Filter      string `json:"filter"`
+	Name        string `json:"name"`
+	JQL         string `json:"jql"`
+	Description string `json:"description"`
+	Share       string `json:"share"`
+	Favourite   *bool  `json:"favourite"`
+}
+
+type jiraListDashboardsArgs struct {
+	Scope string `json:"scope"`
+}
+
+// jiraFilter adds the description, which the go-atlassian filter model leaves out
+type jiraFilter struct {
+	models.FilterScheme
+	Description string `json:"description,omitempty"`
+}
+
+func jiraListFiltersHandler(args jiraListFiltersArgs) (*mcp.CallToolResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second*2)
+	defer cancel()
+
+	var filters []*jiraFilter
+	var err error
+	switch args.Scope {
+	case "mine":
+		filters, err = jiraListFilters(ctx, "rest/api/2/filter/my?expand="+jiraFilterExpand)
+	case "all":
+		filters, err = jiraSearchFilters(ctx, args.Query)
+	default:
+		filters, err = jiraListFilters(ctx, "rest/api/2/filter/favourite?expand="+jiraFilterExpand)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var sb strings.Builder
+	for _, filter := range filters {
+		if args.Query == "" || strings.Contains(strings.ToLower(filter.Name), strings.ToLower(args.Query)) {
+			sb.WriteString(formatJiraFilter(filter))
+		}
+	}
+	if sb.Len() == 0 {
+		return mcp.NewToolResultText("No filters found."), nil
+	}
+	return mcp.NewToolResultText(sb.String()), nil
+}
+
+func jiraRunFilterHandler(args jiraRunFilterArgs) (*mcp.CallToolResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second*2)
+	defer cancel()
+
+	filter, err := jiraFindFilter(ctx, args.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := jiraSearch(jiraSearchArgs{
+		JQL:        filter.Jql,
+		Fields:     args.Fields,
+		Expand:     args.Expand,
+		OrderBy:    args.OrderBy,
+		MaxResults: args.MaxResults,
+		StartAt:    args.StartAt,
+		GroupBy:    args.GroupBy,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("filter %s (ID: %s) failed: %v", filter.Name, filter.ID, err)
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("Filter: %s (ID: %s)\nJQL: %s\n\n%s", filter.Name, filter.ID, filter.Jql, result)), nil
+}
+
+func jiraSaveFilterHandler(args jiraSaveFilterArgs) (*mcp.CallToolResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second*3)
+	defer cancel()
+
+	payload := map[string]interface{}{}
+	if args.Name != "" {
+		payload["name"] = args.Name
+	}
+	if args.Description != "" {
+		payload["description"] = args.Description
+	}
+	if args.Favourite != nil {
+		payload["favourite"] = *args.Favourite
+	}
+	if args.Share != "" {
+		permissions, err := jiraSharePermissions(args.Share)
+		if err != nil {
+			return nil, err
+		}
+		payload["sharePermissions"] = permissions
+	}
+	if args.JQL != "" {
+		if err := jiraValidateJQL(ctx, args.JQL); err != nil {
+			return nil, err
+		}
+		payload["jql"] = args.JQL
+	}
+
+	method, endpoint, action := http.MethodPost, "rest/api/2/filter", "created"
+	if args.Filter != "" {
+		existing, err := jiraFindFilter(ctx, args.Filter)
+		if err != nil {
+			return nil, err
+		}
+		if len(payload) == 0 {
+			return nil, fmt.Errorf("nothing to change, pass name, jql, description, share or favourite")
+		}
+		method, endpoint, action = http.MethodPut, "rest/api/2/filter/"+existing.ID, "updated"
+	} else if args.Name == "" || args.JQL == "" {
+		return nil, fmt.Errorf("name and jql are required to create a filter")
+	}
+
+	filter := new(jiraFilter)
+	response, err := jiraRequest(ctx, method, endpoint+"?expand="+jiraFilterExpand, payload, filter)
+	if err != nil {
+		if response != nil {
+			return nil, fmt.Errorf("failed to save filter: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
+		}
+		return nil, fmt.Errorf("failed to save filter: %v", err)
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("Filter %s.\n%s", action, formatJiraFilter(filter))), nil
+}
+
+func jiraListDashboardsHandler(args jiraListDashboardsArgs) (*mcp.CallToolResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second*2)
+	defer cancel()
+
+	params := url.Values{}
+	switch args.Scope {
+	case "mine":
+		params.Set("filter", "my")
+	case "all":
+	default:
+		params.Set("filter", "favourite")
+	}
+
+	var dashboards []*models.DashboardScheme
+	for {
+		params.Set("startAt", strconv.Itoa(len(dashboards)))
+		params.Set("maxResults", "50")
+		page := new(models.DashboardPageScheme)
+		response, err := jiraRequest(ctx, http.MethodGet, "rest/api/2/dashboard?"+params.Encode(), nil, page)
+		if err != nil {
+			if response != nil {
+				return nil, fmt.Errorf("failed to list dashboards: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
+			}
+			return nil, fmt.Errorf("failed to list dashboards: %v", err)
+		}
+		dashboards = append(dashboards, page.Dashboards...)
+		if len(page.Dashboards) == 0 || len(dashboards) >= page.Total {
+			break
+		}
+	}
+
+	if len(dashboards) == 0 {
+		return mcp.NewToolResultText("No dashboards found."), nil
+	}
+
+	var sb strings.Builder
+	for _, dashboard := range dashboards {
+		sb.WriteString(fmt.Sprintf("- %s (ID: %s)", dashboard.Name, dashboard.ID))
+		if dashboard.Owner != nil {
+			sb.WriteString(" owned by " + dashboard.Owner.DisplayName)
+		}
+		if dashboard.IsFavourite {
+			sb.WriteString(", favourite")
+		}
+		sb.WriteString(", shared with " + formatJiraSharePermissions(dashboard.SharePermissions))
+		if dashboard.View != "" {
+			sb.WriteString("\n  " + dashboard.View)
+		}
+		sb.WriteString("\n")
+	}
+	return mcp.NewToolResultText(sb.String()), nil
+}
+
+func jiraListFilters(ctx context.Context, endpoint string) ([]*jiraFilter, error) {
+	var filters []*jiraFilter
+	response, err := jiraRequest(ctx, http.MethodGet, endpoint, nil, &filters)
+	if err != nil {
+		if response != nil {
+			return nil, fmt.Errorf("failed to list filters: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
+		}
+		return nil, fmt.Errorf("failed to list filters: %v", err)
+	}
+	return filters, nil
+}
+
+// jiraSearchFilters finds the filters visible to the user by name. Jira Data Center has no
+// filter search, so there it falls back to the user's own and favourite filters.
+func jiraSearchFilters(ctx context.Context, name string) ([]*jiraFilter, error) {
+	var filters []*jiraFilter
+	for {
+		params := url.Values{}
+		params.Set("filterName", name)
+		params.Set("expand", jiraFilterExpand)
+		params.Set("startAt", strconv.Itoa(len(filters)))
+		params.Set("maxResults", "100")
+
+		var page struct {
+			Total  int           `json:"total"`
+			IsLast bool          `json:"isLast"`
+			Values []*jiraFilter `json:"values"`
+		}
+		response, err := jiraRequest(ctx, http.MethodGet, "rest/api/2/filter/search?"+params.Encode(), nil, &page)
+		if err != nil {
+			if response != nil && response.Code == http.StatusNotFound {
+				return jiraListFilters(ctx, "rest/api/2/filter/my?includeFavourites=true&expand="+jiraFilterExpand)
+			}
+			if response != nil {
+				return nil, fmt.Errorf("failed to search filters: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
+			}
+			return nil, fmt.Errorf("failed to search filters: %v", err)
+		}
+		filters = append(filters, page.Values...)
+		if page.IsLast || len(page.Values) == 0 || len(filters) >= page.Total {
+			return filters, nil
+		}
+	}
+}
+
+// jiraFindFilter picks a filter by ID, or by name: an exact name wins, otherwise a single partial match is accepted
+func jiraFindFilter(ctx context.Context, nameOrID string) (*jiraFilter, error) {
+	nameOrID = strings.TrimSpace(nameOrID)
+	if _, err := strconv.Atoi(nameOrID); err == nil {
+		filter := new(jiraFilter)
+		response, err := jiraRequest(ctx, http.MethodGet, fmt.Sprintf("rest/api/2/filter/%s?expand=%s", nameOrID, jiraFilterExpand), nil, filter)
+		if err != nil {
+			if response != nil {
+				return nil, fmt.Errorf("failed to get filter: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
+			}
+			return nil, fmt.Errorf("failed to get filter: %v", err)
+		}
+		return filter, nil
+	}
+
+	candidates, err := jiraSearchFilters(ctx, nameOrID)
+	if err != nil {
+		return nil, err
+	}
+
+	var exact, partial []*jiraFilter
+	for _, filter := range candidates {
+		switch {
+		case strings.EqualFold(filter.Name, nameOrID):
+			exact = append(exact, filter)
+		case strings.Contains(strings.ToLower(filter.Name), strings.ToLower(nameOrID)):
+			partial = append(partial, filter)
+		}
+	}
+	if len(exact) == 0 {
+		exact = partial
+	}
+
+	switch len(exact) {
+	case 1:
+		return exact[0], nil
+	case 0:
+		return nil, fmt.Errorf("no filter named %q found, use jira_list_filters to find it", nameOrID)
+	}
+	var names []string
+	for _, filter := range exact {
+		names = append(names, strings.TrimSuffix(formatJiraFilter(filter), "\n"))
+	}
+	return nil, fmt.Errorf("several filters match %q, pass the ID of one of them:\n%s", nameOrID, strings.Join(names, "\n"))
+}
+
+// jiraSharePermissions parses the share argument into share permissions
+func jiraSharePermissions(share string) ([]map[string]interface{}, error) {
+	permissions := []map[string]interface{}{}
+	for _, audience := range splitList(share) {
+		kind, name, _ := strings.Cut(audience, ":")
+		switch strings.ToLower(kind) {
+		case "none", "private":
+		case "project":
+			permissions = append(permissions, map[string]interface{}{"type": "project", "project": map[string]interface{}{"key": name}})
+		case "group":
+			permissions = append(permissions, map[string]interface{}{"type": "group", "group": map[string]interface{}{"name": name}})
+		case "authenticated":
+			permissions = append(permissions, map[string]interface{}{"type": "authenticated"})
+		default:
+			return nil, fmt.Errorf("unknown share %q, use project:KEY, group:NAME, authenticated or none", audience)
+		}
+	}
+	return permissions, nil
+}
+
+func formatJiraFilter(filter *jiraFilter) string {
+	line := fmt.Sprintf("- %s (ID: %s)", filter.Name, filter.ID)
+	if filter.Owner != nil {
+		line += " owned by " + filter.Owner.DisplayName
+	}
+	if filter.Favourite {
+		line += ", favourite"
+	}
+	line += ", shared with " + formatJiraSharePermissions(filter.SharePermissions)
+	if filter.Description != "" {
+		line += "\n  " + filter.Description
+	}
+	return line + "\n  JQL: " + filter.Jql + "\n"
+}
+
+func formatJiraSharePermissions(permissions []*models.SharePermissionScheme) string {
+	if len(permissions) == 0 {
+		return "nobody"
+	}
+	var audiences []string
+	for _, permission := range permissions {
+		switch {
+		case permission.Project != nil:
+			audiences = append(audiences, "project "+permission.Project.Key)
+		case permission.Group != nil:
+			audiences = append(audiences, "group "+permission.Group.Name)
+		case permission.Type == "authenticated" || permission.Type == "loggedin":
+			audiences = append(audiences, "all logged-in users")
+		default:
+			audiences = append(audiences, permission.Type)
+		}
+	}
+	return strings.Join(audiences, ", ")
+}
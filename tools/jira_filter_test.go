@@ -0,0 +1,73 @@
+package tools
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestJiraListFiltersHandler(t *testing.T) {
+	text := resultText(t)(jiraListFiltersHandler(jiraListFiltersArgs{Scope: "favourite"}))
+	assertContains(t, text,
+		"- My team's open bugs (ID: 10200) owned by Alice Nguyen, favourite, shared with project KP\n  Unresolved bugs in Kit Platform\n  JQL: ",
+		"- KP in review (ID: 10201) owned by Bob Tran, favourite, shared with all logged-in users",
+	)
+
+	text = resultText(t)(jiraListFiltersHandler(jiraListFiltersArgs{Scope: "all", Query: "done"}))
+	assertContains(t, text, "- KP done this quarter (ID: 10202)")
+	if strings.Contains(text, "10200") {
+		t.Errorf("filters not matching the query are listed:\n%s", text)
+	}
+}
+
+func TestJiraRunFilterHandler(t *testing.T) {
+	text := resultText(t)(jiraRunFilterHandler(jiraRunFilterArgs{Filter: "10200", MaxResults: 30}))
+	assertContains(t, text, "Filter: My team's open bugs (ID: 10200)", "JQL: project = KP AND issuetype = Bug", "KP-5", "Search ignores maxResults")
+	if strings.Contains(text, "KP-3") {
+		t.Errorf("story KP-3 is in the results of a bug filter:\n%s", text)
+	}
+
+	// a partial name is enough when it matches a single filter
+	text = resultText(t)(jiraRunFilterHandler(jiraRunFilterArgs{Filter: "in review", MaxResults: 30}))
+	assertContains(t, text, "Filter: KP in review (ID: 10201)", "KP-3")
+
+	_, err := jiraRunFilterHandler(jiraRunFilterArgs{Filter: "KP", MaxResults: 30})
+	if err == nil || !strings.Contains(err.Error(), "several filters match") {
+		t.Errorf("got %v, want an error about several matching filters", err)
+	}
+	_, err = jiraRunFilterHandler(jiraRunFilterArgs{Filter: "nothing like this", MaxResults: 30})
+	if err == nil || !strings.Contains(err.Error(), "no filter named") {
+		t.Errorf("got %v, want an error about the missing filter", err)
+	}
+}
+
+func TestJiraSaveFilterHandler(t *testing.T) {
+	favourite := true
+	text := resultText(t)(jiraSaveFilterHandler(jiraSaveFilterArgs{Name: "Urgent bugs", JQL: "project = KP AND priority = Highest", Description: "Drop everything", Share: "project:KP, group:developers", Favourite: &favourite}))
+	assertContains(t, text, "Filter created.", "- Urgent bugs (ID: ", "owned by Alice Nguyen, favourite, shared with project KP, group developers", "Drop everything")
+
+	text = resultText(t)(jiraSaveFilterHandler(jiraSaveFilterArgs{Filter: "Urgent bugs", JQL: "project = KP AND priority in (Highest, High)", Share: "none"}))
+	assertContains(t, text, "Filter updated.", "shared with nobody", "JQL: project = KP AND priority in (Highest, High)")
+
+	text = resultText(t)(jiraRunFilterHandler(jiraRunFilterArgs{Filter: "Urgent bugs", MaxResults: 30}))
+	assertContains(t, text, "KP-5")
+
+	if _, err := jiraSaveFilterHandler(jiraSaveFilterArgs{Filter: "Urgent bugs"}); err == nil {
+		t.Error("updating a filter without changes succeeded, want an error")
+	}
+	if _, err := jiraSaveFilterHandler(jiraSaveFilterArgs{Name: "No query"}); err == nil {
+		t.Error("creating a filter without JQL succeeded, want an error")
+	}
+	if _, err := jiraSaveFilterHandler(jiraSaveFilterArgs{Name: "Bad share", JQL: "project = KP", Share: "everyone"}); err == nil {
+		t.Error("sharing a filter with an unknown audience succeeded, want an error")
+	}
+}
+
+func TestJiraListDashboardsHandler(t *testing.T) {
+	text := resultText(t)(jiraListDashboardsHandler(jiraListDashboardsArgs{Scope: "all"}))
+	assertContains(t, text, "- Kit Platform team (ID: 10300) owned by Alice Nguyen, favourite, shared with project KP", "- Releases (ID: 10301) owned by Bob Tran")
+
+	text = resultText(t)(jiraListDashboardsHandler(jiraListDashboardsArgs{Scope: "mine"}))
+	if strings.Contains(text, "Releases") {
+		t.Errorf("a dashboard owned by Bob Tran is listed as mine:\n%s", text)
+	}
+}
@@ -0,0 +1,473 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ctreminiom/go-atlassian/pkg/infra/models"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/nguyenvanduocit/dev-kit/services"
+	"github.com/nguyenvanduocit/dev-kit/util"
+)
+
+// maxFlowIssues limits how many issues a time in status report covers
+const maxFlowIssues = 200
+
+// maxHistoryValue limits how much of a changed value is shown in an issue history
+const maxHistoryValue = 200
+
+func registerJiraHistoryTools(s *server.MCPServer) {
+	jiraGetIssueHistoryTool := mcp.NewTool("jira_get_issue_history",
+		mcp.WithDescription("Get the change history of a Jira issue: every field change and status transition with who made it and when, oldest first"),
+		mcp.WithString("issue_key", mcp.Required(), mcp.Description("The unique identifier of the Jira issue (e.g., KP-2, PROJ-123)")),
+		mcp.WithString("fields", mcp.Description("Comma separated field names to keep, e.g. status,assignee (optional, all fields by default)")),
+		mcp.WithString("since", mcp.Pattern(`^\d{4}-\d{2}-\d{2}$`), mcp.Description("Only show changes made on or after this date, as YYYY-MM-DD (optional)")),
+	)
+	s.AddTool(jiraGetIssueHistoryTool, util.ErrorGuard(util.TypedHandler(jiraGetIssueHistoryTool, jiraGetIssueHistoryHandler)))
+
+	jiraTimeInStatusTool := mcp.NewTool("jira_time_in_status",
+		mcp.WithDescription("Compute flow metrics from Jira changelogs: time spent in each status, lead time (created to done) and cycle time (first in progress to done), for one issue or for every issue matching a JQL query with averages, medians and 85th percentiles"),
+		mcp.WithString("issue_key", mcp.Description("The Jira issue to measure (e.g., KP-2); either issue_key or jql is required")),
+		mcp.WithString("jql", mcp.Description("JQL query selecting the issues to measure, e.g. project = KP AND resolved >= -30d")),
+		mcp.WithNumber("max_issues", mcp.Min(1), mcp.Max(maxFlowIssues), mcp.DefaultNumber(50), mcp.Description("Maximum number of issues matching the JQL query to measure")),
+	)
+	s.AddTool(jiraTimeInStatusTool, util.ErrorGuard(util.TypedHandler(jiraTimeInStatusTool, jiraTimeInStatusHandler)))
+}
+
+type jiraGetIssueHistoryArgs struct {
+	IssueKey string `json:"issue_key"`
+	Fields   string `json:"fields"`
+	Since    string `json:"since"`
+}
+
+type jiraTimeInStatusArgs struct {
+	IssueKey  string `json:"issue_key"`
+	JQL       string `json:"jql"`
+	MaxIssues int    `json:"max_issues"`
+}
+
+func jiraGetIssueHistoryHandler(args jiraGetIssueHistoryArgs) (*mcp.CallToolResult, error) {
+	var since time.Time
+	if args.Since != "" {
+		var err error
+		if since, err = time.ParseInLocation("2006-01-02", args.Since, time.Local); err != nil {
+			return nil, fmt.Errorf("since must be a date as YYYY-MM-DD: %v", err)
+		}
+	}
+	fields := map[string]bool{}
+	for _, field := range splitList(args.Fields) {
+		fields[strings.ToLower(field)] = true
+	}
+
+	// long changelogs take several pages
+	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second*3)
+	defer cancel()
+
+	histories, err := jiraIssueChangelog(ctx, args.IssueKey)
+	if err != nil {
+		return nil, err
+	}
+
+	var sb strings.Builder
+	shown := 0
+	for _, history := range histories {
+		at, err := time.Parse(jiraChangelogLayout, history.Created)
+		if err != nil || at.Before(since) {
+			continue
+		}
+
+		var items []string
+		for _, item := range history.Items {
+			if len(fields) > 0 && !fields[strings.ToLower(item.Field)] && !fields[strings.ToLower(item.FieldID)] {
+				continue
+			}
+			items = append(items, fmt.Sprintf("- %s: %s → %s\n", item.Field, formatJiraHistoryValue(item.FromString, item.From), formatJiraHistoryValue(item.ToString, item.To)))
+		}
+		if len(items) == 0 {
+			continue
+		}
+
+		author := "Unknown"
+		if history.Author != nil && history.Author.DisplayName != "" {
+			author = history.Author.DisplayName
+		}
+		sb.WriteString(fmt.Sprintf("\n%s by %s:\n%s", at.Local().Format("2006-01-02 15:04"), author, strings.Join(items, "")))
+		shown++
+	}
+
+	if shown == 0 {
+		return mcp.NewToolResultText(fmt.Sprintf("No changes found for %s", args.IssueKey)), nil
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("History of %s (%d changes):\n%s", args.IssueKey, shown, sb.String())), nil
+}
+
+// formatJiraHistoryValue shows a changed value by its display string, falling back to its raw value
+func formatJiraHistoryValue(display, raw string) string {
+	value := strings.TrimSpace(display)
+	if value == "" {
+		value = strings.TrimSpace(raw)
+	}
+	if value == "" {
+		return "(none)"
+	}
+	value = strings.Join(strings.Fields(value), " ")
+	if len(value) > maxHistoryValue {
+		value = value[:maxHistoryValue] + "..."
+	}
+	return value
+}
+
+// jiraIssueChangelog reads the whole changelog of an issue, oldest first
+func jiraIssueChangelog(ctx context.Context, issueKey string) ([]*models.IssueChangelogHistoryScheme, error) {
+	var histories []*models.IssueChangelogHistoryScheme
+	for {
+		var page struct {
+			StartAt    int                                   `json:"startAt"`
+			MaxResults int                                   `json:"maxResults"`
+			Total      int                                   `json:"total"`
+			IsLast     bool                                  `json:"isLast"`
+			Values     []*models.IssueChangelogHistoryScheme `json:"values"`
+		}
+		endpoint := fmt.Sprintf("rest/api/2/issue/%s/changelog?startAt=%d&maxResults=100", url.PathEscape(issueKey), len(histories))
+		response, err := jiraRequest(ctx, http.MethodGet, endpoint, nil, &page)
+		if err != nil {
+			if response != nil && response.Code == http.StatusNotFound && len(histories) == 0 {
+				// Jira Data Center has no changelog resource, it returns the whole changelog with the issue
+				return jiraExpandedChangelog(ctx, issueKey)
+			}
+			if response != nil {
+				return nil, fmt.Errorf("failed to get changelog: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
+			}
+			return nil, fmt.Errorf("failed to get changelog: %v", err)
+		}
+
+		histories = append(histories, page.Values...)
+		if page.IsLast || len(page.Values) == 0 || len(histories) >= page.Total {
+			break
+		}
+	}
+
+	sortJiraHistories(histories)
+	return histories, nil
+}
+
+func jiraExpandedChangelog(ctx context.Context, issueKey string) ([]*models.IssueChangelogHistoryScheme, error) {
+	issue, response, err := services.JiraClient().Issue.Get(ctx, issueKey, []string{"summary"}, []string{"changelog"})
+	if err != nil {
+		if response != nil {
+			return nil, fmt.Errorf("failed to get issue: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
+		}
+		return nil, fmt.Errorf("failed to get issue: %v", err)
+	}
+	if issue.Changelog == nil {
+		return nil, nil
+	}
+
+	sortJiraHistories(issue.Changelog.Histories)
+	return issue.Changelog.Histories, nil
+}
+
+func sortJiraHistories(histories []*models.IssueChangelogHistoryScheme) {
+	sort.SliceStable(histories, func(i, j int) bool {
+		a, _ := time.Parse(jiraChangelogLayout, histories[i].Created)
+		b, _ := time.Parse(jiraChangelogLayout, histories[j].Created)
+		return a.Before(b)
+	})
+}
+
+// jiraCompleteChangelog replaces the changelog embedded in an issue when Jira cut it short
+func jiraCompleteChangelog(ctx context.Context, issue *models.IssueSchemeV2) error {
+	if issue.Changelog == nil || issue.Changelog.Total <= len(issue.Changelog.Histories) {
+		return nil
+	}
+	histories, err := jiraIssueChangelog(ctx, issue.Key)
+	if err != nil {
+		return err
+	}
+	issue.Changelog.Histories = histories
+	return nil
+}
+
+// jiraIssueFlow is how an issue moved through the statuses of its workflow
+type jiraIssueFlow struct {
+	Key     string
+	Summary string
+	Status  string
+	Done    bool
+	// Started is false for issues that never reached an in progress status
+	Started bool
+	// LeadTime and CycleTime run until now for issues that are not done
+	LeadTime  time.Duration
+	CycleTime time.Duration
+	// Statuses lists the statuses in the order they were first entered
+	Statuses []string
+	InStatus map[string]time.Duration
+}
+
+func jiraTimeInStatusHandler(args jiraTimeInStatusArgs) (*mcp.CallToolResult, error) {
+	if args.IssueKey == "" && args.JQL == "" {
+		return nil, fmt.Errorf("either issue_key or jql is required")
+	}
+
+	// issues whose changelogs were cut short in the search are read again
+	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second*8)
+	defer cancel()
+
+	fields := []string{"summary", "status", "created", "project"}
+	var issues []*models.IssueSchemeV2
+	total := 1
+	if args.IssueKey != "" {
+		issue, response, err := services.JiraClient().Issue.Get(ctx, args.IssueKey, fields, []string{"changelog"})
+		if err != nil {
+			if response != nil {
+				return nil, fmt.Errorf("failed to get issue: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
+			}
+			return nil, fmt.Errorf("failed to get issue: %v", err)
+		}
+		issues = append(issues, issue)
+	} else {
+		for len(issues) < args.MaxIssues {
+			result, response, err := services.JiraClient().Issue.Search.Get(ctx, args.JQL, fields, []string{"changelog"}, len(issues), args.MaxIssues-len(issues), "")
+			if err != nil {
+				if response != nil {
+					return nil, fmt.Errorf("failed to search issues: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
+				}
+				return nil, fmt.Errorf("failed to search issues: %v", err)
+			}
+			issues = append(issues, result.Issues...)
+			total = result.Total
+			if len(result.Issues) == 0 || len(issues) >= result.Total {
+				break
+			}
+		}
+		if len(issues) == 0 {
+			return mcp.NewToolResultText("No issues found matching the search criteria"), nil
+		}
+	}
+
+	now := time.Now()
+	categories := map[string]map[string]*models.ProjectStatusDetailsScheme{}
+	var flows []*jiraIssueFlow
+	for _, issue := range issues {
+		if err := jiraCompleteChangelog(ctx, issue); err != nil {
+			return nil, err
+		}
+		statuses, err := jiraProjectCategories(ctx, issue, categories)
+		if err != nil {
+			return nil, err
+		}
+		if flow := buildJiraIssueFlow(issue, statuses, now); flow != nil {
+			flows = append(flows, flow)
+		}
+	}
+
+	if args.IssueKey != "" {
+		if len(flows) == 0 {
+			return nil, fmt.Errorf("%s has no creation date to measure from", args.IssueKey)
+		}
+		return mcp.NewToolResultText(formatJiraIssueFlow(flows[0])), nil
+	}
+	return mcp.NewToolResultText(formatJiraFlowReport(args.JQL, total, flows)), nil
+}
+
+// buildJiraIssueFlow replays the status changes of an issue from its creation until now
+func buildJiraIssueFlow(issue *models.IssueSchemeV2, statuses map[string]*models.ProjectStatusDetailsScheme, now time.Time) *jiraIssueFlow {
+	if issue.Fields == nil {
+		return nil
+	}
+	created, err := time.Parse(jiraChangelogLayout, issue.Fields.Created)
+	if err != nil {
+		return nil
+	}
+
+	flow := &jiraIssueFlow{Key: issue.Key, Summary: issue.Fields.Summary, InStatus: map[string]time.Duration{}}
+	if issue.Fields.Status != nil {
+		flow.Status = issue.Fields.Status.Name
+	}
+
+	changes := jiraFieldChanges(issue, func(item *models.IssueChangelogHistoryItemScheme) bool { return item.Field == "status" })
+	status := flow.Status
+	if len(changes) > 0 {
+		status = changes[0].Item.FromString
+	}
+
+	var started, doneAt time.Time
+	enter := func(status string, at time.Time) {
+		if _, ok := flow.InStatus[status]; !ok {
+			flow.Statuses = append(flow.Statuses, status)
+			flow.InStatus[status] = 0
+		}
+		switch jiraStatusCategory(statuses[strings.ToLower(status)]) {
+		case "indeterminate":
+			if started.IsZero() {
+				started = at
+			}
+		case "done":
+			// lead time ends when the issue was last done, so reopened issues count their rework
+			doneAt = at
+			if started.IsZero() {
+				started = at
+			}
+		}
+	}
+
+	enter(status, created)
+	at := created
+	for _, change := range changes {
+		if change.At.Before(at) {
+			continue
+		}
+		flow.InStatus[status] += change.At.Sub(at)
+		status, at = change.Item.ToString, change.At
+		enter(status, at)
+	}
+	flow.InStatus[status] += now.Sub(at)
+
+	flow.Done = jiraStatusCategory(statuses[strings.ToLower(status)]) == "done"
+	end := now
+	if flow.Done {
+		end = doneAt
+	}
+	flow.LeadTime = end.Sub(created)
+	if !started.IsZero() {
+		flow.Started = true
+		flow.CycleTime = end.Sub(started)
+	}
+	return flow
+}
+
+func formatJiraIssueFlow(flow *jiraIssueFlow) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Time in status for %s: %s\n", flow.Key, flow.Summary))
+	sb.WriteString(fmt.Sprintf("Current status: %s\n", flow.Status))
+
+	ongoing := ""
+	if !flow.Done {
+		ongoing = " (ongoing)"
+	}
+	sb.WriteString(fmt.Sprintf("Lead time: %s%s\n", formatJiraDuration(flow.LeadTime), ongoing))
+	if flow.Started {
+		sb.WriteString(fmt.Sprintf("Cycle time: %s%s\n", formatJiraDuration(flow.CycleTime), ongoing))
+	} else {
+		sb.WriteString("Cycle time: not started\n")
+	}
+
+	sb.WriteString("\nTime per status:\n")
+	for _, status := range flow.Statuses {
+		sb.WriteString(fmt.Sprintf("- %s: %s\n", status, formatJiraDuration(flow.InStatus[status])))
+	}
+	return sb.String()
+}
+
+func formatJiraFlowReport(jql string, total int, flows []*jiraIssueFlow) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Time in status for %s\n", jql))
+	sb.WriteString(fmt.Sprintf("Issues: %d", len(flows)))
+	if total > len(flows) {
+		sb.WriteString(fmt.Sprintf(" of %d matching", total))
+	}
+
+	var leadTimes, cycleTimes []time.Duration
+	var statuses []string
+	inStatus := map[string]time.Duration{}
+	visits := map[string]int{}
+	for _, flow := range flows {
+		if flow.Done {
+			leadTimes = append(leadTimes, flow.LeadTime)
+			if flow.Started {
+				cycleTimes = append(cycleTimes, flow.CycleTime)
+			}
+		}
+		for _, status := range flow.Statuses {
+			if visits[status] == 0 {
+				statuses = append(statuses, status)
+			}
+			visits[status]++
+			inStatus[status] += flow.InStatus[status]
+		}
+	}
+	sb.WriteString(fmt.Sprintf(", %d done\n", len(leadTimes)))
+
+	if len(leadTimes) > 0 {
+		sb.WriteString("\nOf the done issues:\n")
+		sb.WriteString("- Lead time: " + formatJiraDurationStats(leadTimes) + "\n")
+		if len(cycleTimes) > 0 {
+			sb.WriteString("- Cycle time: " + formatJiraDurationStats(cycleTimes) + "\n")
+		}
+	}
+
+	sb.WriteString("\nAverage time per status:\n")
+	for _, status := range statuses {
+		sb.WriteString(fmt.Sprintf("- %s: %s (entered by %d)\n", status, formatJiraDuration(inStatus[status]/time.Duration(visits[status])), visits[status]))
+	}
+
+	sb.WriteString("\nIssues:\n")
+	for _, flow := range flows {
+		cycle := "not started"
+		if flow.Started {
+			cycle = formatJiraDuration(flow.CycleTime)
+		}
+		ongoing := ""
+		if !flow.Done {
+			ongoing = ", ongoing"
+		}
+		var times []string
+		for _, status := range flow.Statuses {
+			times = append(times, fmt.Sprintf("%s %s", status, formatJiraDuration(flow.InStatus[status])))
+		}
+		sb.WriteString(fmt.Sprintf("- %s: %s [%s] lead %s, cycle %s%s; %s\n", flow.Key, flow.Summary, flow.Status, formatJiraDuration(flow.LeadTime), cycle, ongoing, strings.Join(times, ", ")))
+	}
+	return sb.String()
+}
+
+// formatJiraDurationStats summarizes durations by their average, median and 85th percentile
+func formatJiraDurationStats(durations []time.Duration) string {
+	sorted := append([]time.Duration(nil), durations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var sum time.Duration
+	for _, duration := range sorted {
+		sum += duration
+	}
+	return fmt.Sprintf("average %s, median %s, 85th percentile %s",
+		formatJiraDuration(sum/time.Duration(len(sorted))), formatJiraDuration(jiraPercentile(sorted, 0.5)), formatJiraDuration(jiraPercentile(sorted, 0.85)))
+}
+
+// jiraPercentile returns the nearest-rank percentile of sorted durations
+func jiraPercentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	return sorted[rank]
+}
+
+// formatJiraDuration shows a duration in calendar days, hours and minutes, e.g. 3d 4h
+func formatJiraDuration(d time.Duration) string {
+	if d < time.Minute {
+		return "0m"
+	}
+	days := int(d / (24 * time.Hour))
+	hours := int(d % (24 * time.Hour) / time.Hour)
+	minutes := int(d % time.Hour / time.Minute)
+
+	var parts []string
+	if days > 0 {
+		parts = append(parts, fmt.Sprintf("%dd", days))
+	}
+	if hours > 0 {
+		parts = append(parts, fmt.Sprintf("%dh", hours))
+	}
+	// minutes only matter for short durations
+	if minutes > 0 && days == 0 {
+		parts = append(parts, fmt.Sprintf("%dm", minutes))
+	}
+	return strings.Join(parts, " ")
+}
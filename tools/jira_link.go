@@ -0,0 +1,564 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ctreminiom/go-atlassian/pkg/infra/models"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/nguyenvanduocit/dev-kit/services"
+	"github.com/nguyenvanduocit/dev-kit/util"
+)
+
+// maxTreeIssues limits how many issues an issue tree shows
+const maxTreeIssues = 300
+
+func registerJiraLinkTools(s *server.MCPServer) {
+	jiraLinkIssuesTool := mcp.NewTool("jira_link_issues",
+		mcp.WithDescription("Link two Jira issues, e.g. KP-1 blocks KP-2. The link type can be given by its name (Blocks) or by either of its descriptions (blocks, is blocked by), which decides the direction"),
+		mcp.WithString("issue_key", mcp.Required(), mcp.Description("The issue the link description applies to (e.g., KP-1 in KP-1 blocks KP-2)")),
+		mcp.WithString("link_type", mcp.Required(), mcp.Description("Link type name or description, e.g. Blocks, is blocked by, Relates, duplicates")),
+		mcp.WithString("target_key", mcp.Required(), mcp.Description("The other issue (e.g., KP-2 in KP-1 blocks KP-2)")),
+	)
+	s.AddTool(jiraLinkIssuesTool, util.ErrorGuard(util.TypedHandler(jiraLinkIssuesTool, jiraLinkIssuesHandler)))
+
+	jiraDeleteIssueLinkTool := mcp.NewTool("jira_delete_issue_link",
+		mcp.WithDescription("Remove a link between Jira issues, given by its ID from jira_get_issue or by the two issues it links"),
+		mcp.WithString("link_id", mcp.Description("ID of the link; either link_id or issue_key and target_key are required")),
+		mcp.WithString("issue_key", mcp.Description("One of the linked issues (e.g., KP-1)")),
+		mcp.WithString("target_key", mcp.Description("The other linked issue (e.g., KP-2)")),
+		mcp.WithString("link_type", mcp.Description("Only remove links of this type when the issues are linked more than once (optional)")),
+	)
+	s.AddTool(jiraDeleteIssueLinkTool, util.ErrorGuard(util.TypedHandler(jiraDeleteIssueLinkTool, jiraDeleteIssueLinkHandler)))
+
+	jiraCreateSubtaskTool := mcp.NewTool("jira_create_subtask",
+		mcp.WithDescription("Create a subtask under a Jira issue, in the parent's project"),
+		mcp.WithString("parent_key", mcp.Required(), mcp.Description("The issue the subtask belongs to (e.g., KP-12)")),
+		mcp.WithString("summary", mcp.Required(), mcp.Description("Brief title of the subtask")),
+		mcp.WithString("description", mcp.Description("Detailed explanation in Markdown (optional)")),
+		mcp.WithString("assignee", mcp.Description("Account ID of the assignee (optional)")),
+		mcp.WithString("issue_type", mcp.Description("Name of the subtask issue type (optional, defaults to the project's first subtask type)")),
+	)
+	s.AddTool(jiraCreateSubtaskTool, util.ErrorGuard(util.TypedHandler(jiraCreateSubtaskTool, jiraCreateSubtaskHandler)))
+
+	jiraSetParentTool := mcp.NewTool("jira_set_parent",
+		mcp.WithDescription("Move a Jira issue under an epic or another parent issue, or take it out of its epic"),
+		mcp.WithString("issue_key", mcp.Required(), mcp.Description("The issue to move (e.g., KP-2)")),
+		mcp.WithString("parent_key", mcp.Description("Key of the new epic or parent (e.g., KP-1); leave empty to clear the parent")),
+	)
+	s.AddTool(jiraSetParentTool, util.ErrorGuard(util.TypedHandler(jiraSetParentTool, jiraSetParentHandler)))
+
+	jiraGetIssueTreeTool := mcp.NewTool("jira_get_issue_tree",
+		mcp.WithDescription("Show the hierarchy under a Jira issue, such as epic → stories → subtasks, with the status of every issue and rollups of done issues and story points"),
+		mcp.WithString("issue_key", mcp.Required(), mcp.Description("The epic or issue at the top of the tree (e.g., KP-1)")),
+		mcp.WithNumber("depth", mcp.Min(1), mcp.Max(3), mcp.DefaultNumber(2), mcp.Description("Number of levels below the issue to show")),
+	)
+	s.AddTool(jiraGetIssueTreeTool, util.ErrorGuard(util.TypedHandler(jiraGetIssueTreeTool, jiraGetIssueTreeHandler)))
+}
+
+type jiraLinkIssuesArgs struct {
+	IssueKey  string `json:"issue_key"`
+	LinkType  string `json:"link_type"`
+	TargetKey string `json:"target_key"`
+}
+
+type jiraDeleteIssueLinkArgs struct {
+	LinkID    string `json:"link_id"`
+	IssueKey  string `json:"issue_key"`
+	TargetKey string `json:"target_key"`
+	LinkType  string `json:"link_type"`
+}
+
+type jiraCreateSubtaskArgs struct {
+	ParentKey   string `json:"parent_key"`
+	Summary     string `json:"summary"`
+	Description string `json:"description"`
+	Assignee    string `json:"assignee"`
+	IssueType   string `json:"issue_type"`
+}
+
+type jiraSetParentArgs struct {
+	IssueKey  string `json:"issue_key"`
+	ParentKey string `json:"parent_key"`
+}
+
+type jiraIssueTreeArgs struct {
+	IssueKey string `json:"issue_key"`
+	Depth    int    `json:"depth"`
+}
+
+func jiraLinkIssuesHandler(args jiraLinkIssuesArgs) (*mcp.CallToolResult, error) {
+	client := services.JiraClient()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second*2)
+	defer cancel()
+
+	linkType, inward, err := jiraFindLinkType(ctx, args.LinkType)
+	if err != nil {
+		return nil, err
+	}
+
+	// Jira applies the outward description to the inward issue of the request, so KP-1 blocks KP-2
+	// is sent with KP-1 as the inward issue
+	from, to := args.IssueKey, args.TargetKey
+	if inward {
+		from, to = to, from
+	}
+	payload := &models.LinkPayloadSchemeV2{
+		Type:         &models.LinkTypeScheme{Name: linkType.Name},
+		InwardIssue:  &models.LinkedIssueScheme{Key: from},
+		OutwardIssue: &models.LinkedIssueScheme{Key: to},
+	}
+	response, err := client.Issue.Link.Create(ctx, payload)
+	if err != nil {
+		if response != nil {
+			return nil, fmt.Errorf("failed to link issues: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
+		}
+		return nil, fmt.Errorf("failed to link issues: %v", err)
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("Linked: %s %s %s", from, linkType.Outward, to)), nil
+}
+
+// jiraFindLinkType looks a link type up by name or description, telling whether the inward description matched
+func jiraFindLinkType(ctx context.Context, nameOrDescription string) (*models.LinkTypeScheme, bool, error) {
+	result, response, err := services.JiraClient().Issue.Link.Type.Gets(ctx)
+	if err != nil {
+		if response != nil {
+			return nil, false, fmt.Errorf("failed to get link types: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
+		}
+		return nil, false, fmt.Errorf("failed to get link types: %v", err)
+	}
+
+	var names []string
+	for _, linkType := range result.IssueLinkTypes {
+		if strings.EqualFold(linkType.Name, nameOrDescription) || strings.EqualFold(linkType.Outward, nameOrDescription) || linkType.ID == nameOrDescription {
+			return linkType, false, nil
+		}
+		if strings.EqualFold(linkType.Inward, nameOrDescription) {
+			return linkType, true, nil
+		}
+		names = append(names, fmt.Sprintf("%s (%s / %s)", linkType.Name, linkType.Outward, linkType.Inward))
+	}
+	return nil, false, fmt.Errorf("link type %q not found, available link types: %s", nameOrDescription, strings.Join(names, ", "))
+}
+
+func jiraDeleteIssueLinkHandler(args jiraDeleteIssueLinkArgs) (*mcp.CallToolResult, error) {
+	if args.LinkID == "" && (args.IssueKey == "" || args.TargetKey == "") {
+		return nil, fmt.Errorf("either link_id or both issue_key and target_key are required")
+	}
+
+	client := services.JiraClient()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second*4)
+	defer cancel()
+
+	ids := []string{args.LinkID}
+	if args.LinkID == "" {
+		issue, response, err := client.Issue.Get(ctx, args.IssueKey, []string{"issuelinks"}, nil)
+		if err != nil {
+			if response != nil {
+				return nil, fmt.Errorf("failed to get issue: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
+			}
+			return nil, fmt.Errorf("failed to get issue: %v", err)
+		}
+
+		ids = nil
+		for _, link := range issue.Fields.IssueLinks {
+			other := link.OutwardIssue
+			if other == nil {
+				other = link.InwardIssue
+			}
+			if other == nil || !strings.EqualFold(other.Key, args.TargetKey) {
+				continue
+			}
+			if args.LinkType != "" && link.Type != nil && !strings.EqualFold(link.Type.Name, args.LinkType) &&
+				!strings.EqualFold(link.Type.Inward, args.LinkType) && !strings.EqualFold(link.Type.Outward, args.LinkType) {
+				continue
+			}
+			ids = append(ids, link.ID)
+		}
+		if len(ids) == 0 {
+			return nil, fmt.Errorf("%s is not linked to %s", args.IssueKey, args.TargetKey)
+		}
+	}
+
+	for _, id := range ids {
+		response, err := client.Issue.Link.Delete(ctx, id)
+		if err != nil {
+			if response != nil {
+				return nil, fmt.Errorf("failed to delete link %s: %s (endpoint: %s)", id, response.Bytes.String(), response.Endpoint)
+			}
+			return nil, fmt.Errorf("failed to delete link %s: %v", id, err)
+		}
+	}
+
+	if args.LinkID != "" {
+		return mcp.NewToolResultText(fmt.Sprintf("Link %s deleted successfully", args.LinkID)), nil
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("Deleted %d link(s) between %s and %s", len(ids), args.IssueKey, args.TargetKey)), nil
+}
+
+func jiraCreateSubtaskHandler(args jiraCreateSubtaskArgs) (*mcp.CallToolResult, error) {
+	client := services.JiraClient()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second*3)
+	defer cancel()
+
+	parent, response, err := client.Issue.Get(ctx, args.ParentKey, []string{"project"}, nil)
+	if err != nil {
+		if response != nil {
+			return nil, fmt.Errorf("failed to get parent issue: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
+		}
+		return nil, fmt.Errorf("failed to get parent issue: %v", err)
+	}
+	projectKey := parent.Fields.Project.Key
+
+	issueTypes, err := jiraCreateIssueTypes(ctx, projectKey)
+	if err != nil {
+		return nil, err
+	}
+	issueType := ""
+	var subtaskTypes []string
+	for _, candidate := range issueTypes {
+		if !candidate.Subtask {
+			continue
+		}
+		subtaskTypes = append(subtaskTypes, candidate.Name)
+		if issueType == "" && (args.IssueType == "" || strings.EqualFold(candidate.Name, args.IssueType)) {
+			issueType = candidate.Name
+		}
+	}
+	if issueType == "" {
+		if len(subtaskTypes) == 0 {
+			return nil, fmt.Errorf("project %s has no subtask issue types", projectKey)
+		}
+		return nil, fmt.Errorf("%q is not a subtask issue type of %s, available types: %s", args.IssueType, projectKey, strings.Join(subtaskTypes, ", "))
+	}
+
+	create := jiraCreateIssueArgs{
+		ProjectKey:  projectKey,
+		Summary:     args.Summary,
+		Description: args.Description,
+		IssueType:   issueType,
+		Assignee:    args.Assignee,
+		Parent:      parent.Key,
+	}
+	fields, err := create.fields(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	issue := new(models.IssueResponseScheme)
+	response, err = jiraRequest(ctx, http.MethodPost, "rest/api/2/issue", map[string]interface{}{"fields": fields}, issue)
+	if err != nil {
+		if response != nil {
+			return nil, fmt.Errorf("failed to create subtask: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
+		}
+		return nil, fmt.Errorf("failed to create subtask: %v", err)
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("Subtask created successfully!\nKey: %s\nParent: %s\nURL: %s", issue.Key, parent.Key, services.JiraBrowseURL(issue.Key))), nil
+}
+
+func jiraSetParentHandler(args jiraSetParentArgs) (*mcp.CallToolResult, error) {
+	client := services.JiraClient()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second*3)
+	defer cancel()
+
+	catalog, err := jiraFieldCatalog(ctx)
+	if err != nil {
+		return nil, err
+	}
+	epicLink := jiraEpicLinkField(catalog)
+
+	fieldIDs := []string{"parent", "issuetype"}
+	if epicLink != nil {
+		fieldIDs = append(fieldIDs, epicLink.ID)
+	}
+	issue, response, err := client.Issue.Get(ctx, args.IssueKey, fieldIDs, nil)
+	if err != nil {
+		if response != nil {
+			return nil, fmt.Errorf("failed to get issue: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
+		}
+		return nil, fmt.Errorf("failed to get issue: %v", err)
+	}
+	if issue.Fields.IssueType != nil && issue.Fields.IssueType.Subtask {
+		return nil, fmt.Errorf("%s is a subtask, whose parent can only be changed by moving it in Jira", args.IssueKey)
+	}
+
+	fields := map[string]interface{}{}
+	var message string
+	if args.ParentKey == "" {
+		epic := ""
+		if epicLink != nil {
+			values, _ := models.ParseStringCustomFields(response.Bytes, epicLink.ID)
+			epic = values[issue.Key]
+		}
+		switch {
+		case epic != "":
+			fields[epicLink.ID] = nil
+			message = fmt.Sprintf("%s removed from epic %s", args.IssueKey, epic)
+		case issue.Fields.Parent != nil:
+			fields["parent"] = nil
+			message = fmt.Sprintf("%s removed from %s", args.IssueKey, issue.Fields.Parent.Key)
+		default:
+			return mcp.NewToolResultText(fmt.Sprintf("%s has no parent", args.IssueKey)), nil
+		}
+	} else {
+		parent, response, err := client.Issue.Get(ctx, args.ParentKey, []string{"issuetype"}, nil)
+		if err != nil {
+			if response != nil {
+				return nil, fmt.Errorf("failed to get parent issue: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
+			}
+			return nil, fmt.Errorf("failed to get parent issue: %v", err)
+		}
+		if epicLink != nil && jiraIsEpic(parent) {
+			fields[epicLink.ID] = parent.Key
+		} else {
+			fields["parent"] = map[string]interface{}{"key": parent.Key}
+		}
+		message = fmt.Sprintf("%s moved under %s", args.IssueKey, parent.Key)
+	}
+
+	endpoint := fmt.Sprintf("rest/api/2/issue/%s", url.PathEscape(args.IssueKey))
+	response, err = jiraRequest(ctx, http.MethodPut, endpoint, map[string]interface{}{"fields": fields}, nil)
+	if err != nil {
+		if response != nil {
+			return nil, fmt.Errorf("failed to set parent: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
+		}
+		return nil, fmt.Errorf("failed to set parent: %v", err)
+	}
+
+	return mcp.NewToolResultText(message), nil
+}
+
+func jiraIsEpic(issue *models.IssueSchemeV2) bool {
+	if issue.Fields == nil || issue.Fields.IssueType == nil {
+		return false
+	}
+	return issue.Fields.IssueType.HierarchyLevel == 1 || strings.EqualFold(issue.Fields.IssueType.Name, "Epic")
+}
+
+// jiraTreeNode is an issue of an issue tree with the issues below it
+type jiraTreeNode struct {
+	Issue    *models.IssueSchemeV2
+	Points   float64
+	Children []*jiraTreeNode
+}
+
+// jiraTreeRollup counts the issues below a node by status category
+type jiraTreeRollup struct {
+	Issues     int
+	Done       int
+	InProgress int
+	Points     float64
+	DonePoints float64
+}
+
+func (node *jiraTreeNode) rollup() jiraTreeRollup {
+	var rollup jiraTreeRollup
+	for _, child := range node.Children {
+		category := jiraIssueStatusCategory(child.Issue)
+		rollup.Issues++
+		rollup.Points += child.Points
+		switch category {
+		case "done":
+			rollup.Done++
+			rollup.DonePoints += child.Points
+		case "indeterminate":
+			rollup.InProgress++
+		}
+
+		below := child.rollup()
+		rollup.Issues += below.Issues
+		rollup.Done += below.Done
+		rollup.InProgress += below.InProgress
+		rollup.Points += below.Points
+		rollup.DonePoints += below.DonePoints
+	}
+	return rollup
+}
+
+func jiraIssueStatusCategory(issue *models.IssueSchemeV2) string {
+	if issue.Fields == nil || issue.Fields.Status == nil || issue.Fields.Status.StatusCategory == nil {
+		return ""
+	}
+	return issue.Fields.Status.StatusCategory.Key
+}
+
+func jiraGetIssueTreeHandler(args jiraIssueTreeArgs) (*mcp.CallToolResult, error) {
+	client := services.JiraClient()
+
+	// one search per level, and the field catalog
+	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second*(2+time.Duration(args.Depth)))
+	defer cancel()
+
+	catalog, err := jiraFieldCatalog(ctx)
+	if err != nil {
+		return nil, err
+	}
+	epicLink, storyPoints := jiraEpicLinkField(catalog), jiraStoryPointsField(catalog)
+
+	fields := []string{"summary", "status", "issuetype", "assignee", "parent"}
+	if epicLink != nil {
+		fields = append(fields, epicLink.ID)
+	}
+	if storyPoints != nil {
+		fields = append(fields, storyPoints.ID)
+	}
+
+	issue, response, err := client.Issue.Get(ctx, args.IssueKey, fields, nil)
+	if err != nil {
+		if response != nil {
+			return nil, fmt.Errorf("failed to get issue: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
+		}
+		return nil, fmt.Errorf("failed to get issue: %v", err)
+	}
+	root := &jiraTreeNode{Issue: issue}
+	if storyPoints != nil {
+		values, _ := models.ParseFloatCustomFields(response.Bytes, storyPoints.ID)
+		root.Points = values[issue.Key]
+	}
+
+	level := []*jiraTreeNode{root}
+	count, truncated := 1, false
+	for depth := 0; depth < args.Depth && len(level) > 0 && !truncated; depth++ {
+		byKey := map[string]*jiraTreeNode{}
+		var keys, epics []string
+		for _, node := range level {
+			byKey[node.Issue.Key] = node
+			keys = append(keys, node.Issue.Key)
+			if jiraIsEpic(node.Issue) {
+				epics = append(epics, node.Issue.Key)
+			}
+		}
+
+		jql := fmt.Sprintf("parent in (%s)", strings.Join(keys, ", "))
+		if epicLink != nil && len(epics) > 0 {
+			jql += fmt.Sprintf(" OR cf[%s] in (%s)", strings.TrimPrefix(epicLink.ID, "customfield_"), strings.Join(epics, ", "))
+		}
+		jql += " ORDER BY rank"
+
+		var next []*jiraTreeNode
+		for {
+			result, response, err := client.Issue.Search.Get(ctx, jql, fields, nil, len(next), maxTreeIssues-count, "")
+			if err != nil {
+				if response != nil {
+					return nil, fmt.Errorf("failed to search issues: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
+				}
+				return nil, fmt.Errorf("failed to search issues: %v", err)
+			}
+
+			var points map[string]float64
+			if storyPoints != nil {
+				points, _ = models.ParseFloatCustomFields(response.Bytes, storyPoints.ID)
+			}
+			var epicKeys map[string]string
+			if epicLink != nil {
+				epicKeys, _ = models.ParseStringCustomFields(response.Bytes, epicLink.ID)
+			}
+
+			for _, child := range result.Issues {
+				parentKey := epicKeys[child.Key]
+				if child.Fields.Parent != nil && byKey[child.Fields.Parent.Key] != nil {
+					parentKey = child.Fields.Parent.Key
+				}
+				parent := byKey[parentKey]
+				if parent == nil {
+					continue
+				}
+				node := &jiraTreeNode{Issue: child, Points: points[child.Key]}
+				parent.Children = append(parent.Children, node)
+				next = append(next, node)
+			}
+
+			count += len(result.Issues)
+			if count >= maxTreeIssues && len(next) < result.Total {
+				truncated = true
+			}
+			if len(result.Issues) == 0 || len(next) >= result.Total || count >= maxTreeIssues {
+				break
+			}
+		}
+		level = next
+	}
+
+	var sb strings.Builder
+	formatJiraTreeNode(&sb, root, 0, storyPoints != nil)
+	if truncated {
+		sb.WriteString(fmt.Sprintf("\nOnly the first %d issues are shown\n", maxTreeIssues))
+	}
+	return mcp.NewToolResultText(sb.String()), nil
+}
+
+func formatJiraTreeNode(sb *strings.Builder, node *jiraTreeNode, depth int, points bool) {
+	issue := node.Issue
+	indent := strings.Repeat("  ", depth)
+
+	issueType, status, assignee := "", "", "Unassigned"
+	if issue.Fields.IssueType != nil {
+		issueType = issue.Fields.IssueType.Name
+	}
+	if issue.Fields.Status != nil {
+		status = issue.Fields.Status.Name
+	}
+	if issue.Fields.Assignee != nil {
+		assignee = issue.Fields.Assignee.DisplayName
+	}
+
+	prefix := indent + "- "
+	if depth == 0 {
+		prefix = ""
+	}
+	sb.WriteString(fmt.Sprintf("%s%s [%s] %s — %s, %s", prefix, issue.Key, issueType, issue.Fields.Summary, status, assignee))
+	if node.Points > 0 {
+		sb.WriteString(fmt.Sprintf(" (%s)", formatJiraEstimate(node.Points)))
+	}
+	sb.WriteString("\n")
+
+	if len(node.Children) > 0 {
+		rollup := node.rollup()
+		sb.WriteString(fmt.Sprintf("%s  Progress: %d of %d done (%d%%), %d in progress",
+			indent, rollup.Done, rollup.Issues, rollup.Done*100/rollup.Issues, rollup.InProgress))
+		if points && rollup.Points > 0 {
+			sb.WriteString(fmt.Sprintf("; %s of %s done", formatJiraEstimate(rollup.DonePoints), formatJiraEstimate(rollup.Points)))
+		}
+		sb.WriteString("\n")
+	}
+
+	for _, child := range node.Children {
+		formatJiraTreeNode(sb, child, depth+1, points)
+	}
+}
+
+// formatJiraIssueLink describes a link as seen from the issue holding it
+func formatJiraIssueLink(link *models.IssueLinkScheme) string {
+	if link.Type == nil {
+		return ""
+	}
+	description, other := link.Type.Outward, link.OutwardIssue
+	if other == nil {
+		description, other = link.Type.Inward, link.InwardIssue
+	}
+	if other == nil {
+		return ""
+	}
+
+	line := fmt.Sprintf("- %s %s", description, other.Key)
+	if other.Fields != nil {
+		if other.Fields.Summary != "" {
+			line += ": " + other.Fields.Summary
+		}
+		if other.Fields.Status != nil {
+			line += fmt.Sprintf(" (%s)", other.Fields.Status.Name)
+		}
+	}
+	return line + fmt.Sprintf(" [link ID: %s]\n", link.ID)
+}
@@ -0,0 +1,372 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ctreminiom/go-atlassian/pkg/infra/models"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/nguyenvanduocit/dev-kit/services"
+	"github.com/nguyenvanduocit/dev-kit/util"
+)
+
+// jiraAssigneeTypes maps the assignee_type argument to the default assignee types of Jira
+var jiraAssigneeTypes = map[string]string{
+	"project_default": "PROJECT_DEFAULT",
+	"component_lead":  "COMPONENT_LEAD",
+	"project_lead":    "PROJECT_LEAD",
+	"unassigned":      "UNASSIGNED",
+}
+
+func registerJiraProjectTools(s *server.MCPServer) {
+	jiraDescribeProjectTool := mcp.NewTool("jira_describe_project",
+		mcp.WithDescription("Describe a Jira project before creating or transitioning issues: its lead and default assignee, its issue types with their workflows and statuses, its issue type and workflow schemes, its components, and the priorities and resolutions that can be set"),
+		mcp.WithString("project_key", mcp.Required(), mcp.Description("Project identifier (e.g., KP, PROJ)")),
+	)
+
+	jiraListComponentsTool := mcp.NewTool("jira_list_components",
+		mcp.WithDescription("List the components of a Jira project with their IDs, leads and the default assignee of their issues"),
+		mcp.WithString("project_key", mcp.Required(), mcp.Description("Project identifier (e.g., KP, PROJ)")),
+		mcp.WithBoolean("issue_counts", mcp.DefaultBool(false), mcp.Description("Also count the issues of each component, one request per component")),
+	)
+
+	jiraCreateComponentTool := mcp.NewTool("jira_create_component",
+		mcp.WithDescription("Create a component in a Jira project, optionally with a lead and the default assignee of its issues"),
+		mcp.WithString("project_key", mcp.Required(), mcp.Description("Project identifier (e.g., KP, PROJ)")),
+		mcp.WithString("name", mcp.Required(), mcp.Description("Name of the component (e.g., Backend)")),
+		mcp.WithString("description", mcp.Description("Description of the component (optional)")),
+		mcp.WithString("lead", mcp.Description("Display name, email or account ID of the component lead (optional)")),
+		mcp.WithString("assignee_type", mcp.DefaultString("project_default"), mcp.Enum("project_default", "component_lead", "project_lead", "unassigned"), mcp.Description("Who new issues of the component are assigned to; component_lead needs a lead")),
+	)
+
+	s.AddTool(jiraDescribeProjectTool, util.ErrorGuard(util.TypedHandler(jiraDescribeProjectTool, jiraDescribeProjectHandler)))
+	s.AddTool(jiraListComponentsTool, util.ErrorGuard(util.TypedHandler(jiraListComponentsTool, jiraListComponentsHandler)))
+	s.AddTool(jiraCreateComponentTool, util.ErrorGuard(util.TypedHandler(jiraCreateComponentTool, jiraCreateComponentHandler)))
+}
+
+type jiraListComponentsArgs struct {
+	ProjectKey  string `json:"project_key"`
+	IssueCounts bool   `json:"issue_counts"`
+}
+
+type jiraCreateComponentArgs struct {
+	ProjectKey   string `json:"project_key"`
+	Name         string `json:"name"`
+	Description  string `json:"description"`
+	Lead         string `json:"lead"`
+	AssigneeType string `json:"assignee_type"`
+}
+
+// jiraNamedValue is a priority or a resolution
+type jiraNamedValue struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// jiraProjectSchemes are the issue type and workflow schemes of a project. Reading them needs
+// admin permissions on Cloud and Data Center has no such endpoints, so either may be missing.
+type jiraProjectSchemes struct {
+	IssueTypeScheme string
+	WorkflowScheme  string
+	// Workflows maps issue type IDs to the workflow they use, DefaultWorkflow applies to the others
+	Workflows       map[string]string
+	DefaultWorkflow string
+	Errors          []string
+}
+
+func jiraDescribeProjectHandler(args jiraProjectArgs) (*mcp.CallToolResult, error) {
+	client := services.JiraClient()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second*4)
+	defer cancel()
+
+	project, response, err := client.Project.Get(ctx, args.ProjectKey, nil)
+	if err != nil {
+		if response != nil {
+			return nil, fmt.Errorf("failed to get project: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
+		}
+		return nil, fmt.Errorf("failed to get project: %v", err)
+	}
+
+	issueTypes, response, err := client.Project.Statuses(ctx, args.ProjectKey)
+	if err != nil {
+		if response != nil {
+			return nil, fmt.Errorf("failed to get statuses: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
+		}
+		return nil, fmt.Errorf("failed to get statuses: %v", err)
+	}
+
+	components, err := jiraProjectComponents(ctx, args.ProjectKey)
+	if err != nil {
+		return nil, err
+	}
+
+	var priorities, resolutions []*jiraNamedValue
+	for _, list := range []struct {
+		name string
+		out  *[]*jiraNamedValue
+	}{
+		{"priority", &priorities},
+		{"resolution", &resolutions},
+	} {
+		response, err := jiraRequest(ctx, http.MethodGet, "rest/api/2/"+list.name, nil, list.out)
+		if err != nil {
+			if response != nil {
+				return nil, fmt.Errorf("failed to list %s values: %s (endpoint: %s)", list.name, response.Bytes.String(), response.Endpoint)
+			}
+			return nil, fmt.Errorf("failed to list %s values: %v", list.name, err)
+		}
+	}
+
+	schemes := jiraGetProjectSchemes(ctx, project.ID)
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Project %s: %s (ID: %s)\n", project.Key, project.Name, project.ID))
+	if project.ProjectTypeKey != "" {
+		sb.WriteString("Type: " + project.ProjectTypeKey + "\n")
+	}
+	if project.Lead != nil {
+		sb.WriteString("Lead: " + formatJiraUser(project.Lead.DisplayName, project.Lead.EmailAddress, jiraUserID(project.Lead), project.Lead.Active) + "\n")
+	}
+	if project.AssigneeType != "" {
+		sb.WriteString("Default assignee: " + formatJiraAssigneeType(project.AssigneeType) + "\n")
+	}
+	if project.Description != "" {
+		sb.WriteString("Description: " + project.Description + "\n")
+	}
+	if schemes.IssueTypeScheme != "" {
+		sb.WriteString("Issue type scheme: " + schemes.IssueTypeScheme + "\n")
+	}
+	if schemes.WorkflowScheme != "" {
+		sb.WriteString("Workflow scheme: " + schemes.WorkflowScheme + "\n")
+	}
+	for _, message := range schemes.Errors {
+		sb.WriteString("Not available: " + message + "\n")
+	}
+
+	sb.WriteString("\n" + formatJiraIssueTypeStatuses(issueTypes, schemes))
+
+	sb.WriteString(fmt.Sprintf("\nComponents (%d):\n", len(components)))
+	if len(components) == 0 {
+		sb.WriteString("None\n")
+	}
+	for _, component := range components {
+		sb.WriteString(formatJiraComponent(component))
+	}
+
+	for _, list := range []struct {
+		title  string
+		values []*jiraNamedValue
+	}{
+		{"Priorities", priorities},
+		{"Resolutions", resolutions},
+	} {
+		sb.WriteString(fmt.Sprintf("\n%s (%d):\n", list.title, len(list.values)))
+		for _, value := range list.values {
+			line := fmt.Sprintf("- %s (ID: %s)", value.Name, value.ID)
+			if value.Description != "" {
+				line += " — " + value.Description
+			}
+			sb.WriteString(line + "\n")
+		}
+	}
+
+	return mcp.NewToolResultText(sb.String()), nil
+}
+
+// formatJiraIssueTypeStatuses lists the issue types of a project with their workflows and statuses,
+// shared by jira_describe_project and jira_list_statuses
+func formatJiraIssueTypeStatuses(issueTypes []*models.ProjectStatusPageScheme, schemes *jiraProjectSchemes) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Issue types (%d):\n", len(issueTypes)))
+	for _, issueType := range issueTypes {
+		line := fmt.Sprintf("- %s (ID: %s)", issueType.Name, issueType.ID)
+		if issueType.Subtask {
+			line += ", subtask"
+		}
+		if workflow, ok := schemes.Workflows[issueType.ID]; ok {
+			line += ", workflow: " + workflow
+		} else if schemes.DefaultWorkflow != "" {
+			line += ", workflow: " + schemes.DefaultWorkflow
+		}
+		sb.WriteString(line + "\n")
+
+		statuses := make([]string, 0, len(issueType.Statuses))
+		for _, status := range issueType.Statuses {
+			statuses = append(statuses, fmt.Sprintf("%s (ID: %s, %s)", status.Name, status.ID, jiraStatusCategory(status)))
+		}
+		sb.WriteString("  Statuses: " + strings.Join(statuses, ", ") + "\n")
+	}
+	return sb.String()
+}
+
+func jiraListComponentsHandler(args jiraListComponentsArgs) (*mcp.CallToolResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second*4)
+	defer cancel()
+
+	components, err := jiraProjectComponents(ctx, args.ProjectKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(components) == 0 {
+		return mcp.NewToolResultText(fmt.Sprintf("No components found in %s.", args.ProjectKey)), nil
+	}
+
+	var sb strings.Builder
+	for _, component := range components {
+		line := formatJiraComponent(component)
+		if args.IssueCounts {
+			var count models.ComponentCountScheme
+			response, err := jiraRequest(ctx, http.MethodGet, fmt.Sprintf("rest/api/2/component/%s/relatedIssueCounts", component.ID), nil, &count)
+			if err != nil {
+				if response != nil {
+					return nil, fmt.Errorf("failed to count issues of component %s: %s (endpoint: %s)", component.Name, response.Bytes.String(), response.Endpoint)
+				}
+				return nil, fmt.Errorf("failed to count issues of component %s: %v", component.Name, err)
+			}
+			line = strings.TrimSuffix(line, "\n") + ", " + formatJiraIssueCount(count.IssueCount) + "\n"
+		}
+		sb.WriteString(line)
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("Components of %s (%d):\n\n%s", args.ProjectKey, len(components), sb.String())), nil
+}
+
+func jiraCreateComponentHandler(args jiraCreateComponentArgs) (*mcp.CallToolResult, error) {
+	assigneeType, ok := jiraAssigneeTypes[args.AssigneeType]
+	if !ok {
+		return nil, fmt.Errorf("unknown assignee_type %q", args.AssigneeType)
+	}
+	if assigneeType == "COMPONENT_LEAD" && args.Lead == "" {
+		return nil, fmt.Errorf("assignee_type component_lead needs a lead")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second*2)
+	defer cancel()
+
+	payload := map[string]interface{}{
+		"project":      args.ProjectKey,
+		"name":         args.Name,
+		"assigneeType": assigneeType,
+	}
+	if args.Description != "" {
+		payload["description"] = args.Description
+	}
+	if args.Lead != "" {
+		lead, err := jiraFindUser(ctx, args.Lead, "")
+		if err != nil {
+			return nil, err
+		}
+		// Data Center names the lead by username rather than account ID
+		if services.AtlassianDataCenter() {
+			payload["leadUserName"] = jiraUserID(lead)
+		} else {
+			payload["leadAccountId"] = jiraUserID(lead)
+		}
+	}
+
+	var component models.ComponentScheme
+	response, err := jiraRequest(ctx, http.MethodPost, "rest/api/2/component", payload, &component)
+	if err != nil {
+		if response != nil {
+			return nil, fmt.Errorf("failed to create component: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
+		}
+		return nil, fmt.Errorf("failed to create component: %v", err)
+	}
+
+	return mcp.NewToolResultText("Component created:\n" + formatJiraComponent(&component)), nil
+}
+
+func jiraProjectComponents(ctx context.Context, projectKey string) ([]*models.ComponentScheme, error) {
+	var components []*models.ComponentScheme
+	response, err := jiraRequest(ctx, http.MethodGet, fmt.Sprintf("rest/api/2/project/%s/components", projectKey), nil, &components)
+	if err != nil {
+		if response != nil {
+			return nil, fmt.Errorf("failed to list components: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
+		}
+		return nil, fmt.Errorf("failed to list components: %v", err)
+	}
+	return components, nil
+}
+
+// jiraGetProjectSchemes reads the schemes of a project, recording why a scheme could not be read
+// instead of failing, as the rest of a project description is still useful without them
+func jiraGetProjectSchemes(ctx context.Context, projectID string) *jiraProjectSchemes {
+	schemes := &jiraProjectSchemes{Workflows: map[string]string{}}
+
+	var issueTypeSchemes struct {
+		Values []struct {
+			IssueTypeScheme struct {
+				Name string `json:"name"`
+			} `json:"issueTypeScheme"`
+		} `json:"values"`
+	}
+	response, err := jiraRequest(ctx, http.MethodGet, "rest/api/2/issuetypescheme/project?projectId="+projectID, nil, &issueTypeSchemes)
+	switch {
+	case err != nil && response != nil:
+		schemes.Errors = append(schemes.Errors, fmt.Sprintf("issue type scheme, HTTP %d", response.Code))
+	case err != nil:
+		schemes.Errors = append(schemes.Errors, fmt.Sprintf("issue type scheme, %v", err))
+	case len(issueTypeSchemes.Values) > 0:
+		schemes.IssueTypeScheme = issueTypeSchemes.Values[0].IssueTypeScheme.Name
+	}
+
+	var workflowSchemes struct {
+		Values []struct {
+			WorkflowScheme struct {
+				ID                json.Number       `json:"id"`
+				Name              string            `json:"name"`
+				DefaultWorkflow   string            `json:"defaultWorkflow"`
+				IssueTypeMappings map[string]string `json:"issueTypeMappings"`
+			} `json:"workflowScheme"`
+		} `json:"values"`
+	}
+	response, err = jiraRequest(ctx, http.MethodGet, "rest/api/2/workflowscheme/project?projectId="+projectID, nil, &workflowSchemes)
+	switch {
+	case err != nil && response != nil:
+		schemes.Errors = append(schemes.Errors, fmt.Sprintf("workflow scheme, HTTP %d", response.Code))
+	case err != nil:
+		schemes.Errors = append(schemes.Errors, fmt.Sprintf("workflow scheme, %v", err))
+	case len(workflowSchemes.Values) > 0:
+		scheme := workflowSchemes.Values[0].WorkflowScheme
+		schemes.WorkflowScheme = scheme.Name + " (ID: " + scheme.ID.String() + ")"
+		schemes.DefaultWorkflow = scheme.DefaultWorkflow
+		for issueTypeID, workflow := range scheme.IssueTypeMappings {
+			schemes.Workflows[issueTypeID] = workflow
+		}
+	}
+
+	return schemes
+}
+
+// formatJiraAssigneeType turns an assignee type such as PROJECT_LEAD into "project lead"
+func formatJiraAssigneeType(assigneeType string) string {
+	return strings.ToLower(strings.ReplaceAll(assigneeType, "_", " "))
+}
+
+func formatJiraComponent(component *models.ComponentScheme) string {
+	line := fmt.Sprintf("- %s (ID: %s)", component.Name, component.ID)
+	if component.Lead != nil {
+		line += ", lead " + component.Lead.DisplayName
+	}
+
+	assignee := "unassigned"
+	if component.RealAssignee != nil {
+		assignee = component.RealAssignee.DisplayName
+	}
+	line += fmt.Sprintf(", default assignee %s: %s", formatJiraAssigneeType(component.AssigneeType), assignee)
+	// a component lead assignee without a lead falls back to the project default
+	if component.AssigneeType != "PROJECT_DEFAULT" && component.RealAssigneeType != "" && component.RealAssigneeType != component.AssigneeType {
+		line += " (falls back to " + formatJiraAssigneeType(component.RealAssigneeType) + ")"
+	}
+	if component.Description != "" {
+		line += " — " + component.Description
+	}
+	return line + "\n"
+}
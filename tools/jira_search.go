@@ -0,0 +1,424 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ctreminiom/go-atlassian/pkg/infra/models"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/nguyenvanduocit/dev-kit/services"
+)
+
+// maxGroupIssues bounds how many issues are read to count them by a field
+const maxGroupIssues = 1000
+
+// jiraSearchDefaultFields are the fields every search result shows
+var jiraSearchDefaultFields = []string{"summary", "status", "created", "updated", "assignee", "priority", "resolutiondate"}
+
+var jiraSearchExpands = map[string]string{"changelog": "changelog", "renderedfields": "renderedFields"}
+
+var (
+	jiraOrderByKeyword    = regexp.MustCompile(`(?i)^order\s+by\b`)
+	jiraUnknownFieldError = regexp.MustCompile(`Field '([^']+)' does not exist`)
+	jiraUnknownValueError = regexp.MustCompile(`The value '([^']+)' does not exist for the field '([^']+)'`)
+	jiraOperatorError     = regexp.MustCompile(`The operator '([^']+)' is not supported by the '([^']+)' field`)
+)
+
+// jiraSearchPage is a page of search results with the fields kept as raw JSON, so that custom fields survive
+type jiraSearchPage struct {
+	Total  int `json:"total"`
+	Issues []struct {
+		Key            string                       `json:"key"`
+		Fields         map[string]interface{}       `json:"fields"`
+		RenderedFields map[string]interface{}       `json:"renderedFields"`
+		Changelog      *models.IssueChangelogScheme `json:"changelog"`
+	} `json:"issues"`
+}
+
+func jiraSearchHandler(args jiraSearchArgs) (*mcp.CallToolResult, error) {
+	result, err := jiraSearch(args)
+	if err != nil {
+		return nil, err
+	}
+	return mcp.NewToolResultText(result), nil
+}
+
+// jiraSearch runs a search and formats its results, shared by the search and filter tools
+func jiraSearch(args jiraSearchArgs) (string, error) {
+	// validation, the field list, the results and the grouped counts each take requests of their own
+	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second*6)
+	defer cancel()
+
+	jql := args.JQL
+	if args.OrderBy != "" {
+		jql = stripJiraOrderBy(jql) + " ORDER BY " + args.OrderBy
+	}
+
+	var expand []string
+	for _, name := range splitList(args.Expand) {
+		value, ok := jiraSearchExpands[strings.ToLower(name)]
+		if !ok {
+			return "", fmt.Errorf("unknown expand %q, use changelog or renderedFields", name)
+		}
+		expand = append(expand, value)
+	}
+
+	if err := jiraValidateJQL(ctx, jql); err != nil {
+		return "", err
+	}
+
+	var catalog []*models.IssueFieldScheme
+	if args.Fields != "" || args.GroupBy != "" {
+		var err error
+		if catalog, err = jiraFieldCatalog(ctx); err != nil {
+			return "", err
+		}
+	}
+
+	fields := append([]string{}, jiraSearchDefaultFields...)
+	var extra []*models.IssueFieldScheme
+	for _, name := range splitList(args.Fields) {
+		field := findJiraField(catalog, name)
+		if field == nil {
+			return "", fmt.Errorf("unknown field %q, use jira_get_create_meta to list the fields of a project", name)
+		}
+		// the default fields are always shown, asking for them again would print them twice
+		if slices.Contains(jiraSearchDefaultFields, field.ID) {
+			continue
+		}
+		extra = append(extra, field)
+		fields = append(fields, field.ID)
+	}
+
+	var sb strings.Builder
+	if args.GroupBy != "" {
+		field := findJiraField(catalog, args.GroupBy)
+		if field == nil {
+			return "", fmt.Errorf("unknown field %q to group by, use jira_get_create_meta to list the fields of a project", args.GroupBy)
+		}
+		counts, err := jiraGroupIssues(ctx, jql, field)
+		if err != nil {
+			return "", err
+		}
+		sb.WriteString(counts)
+		if args.MaxResults == 0 {
+			return sb.String(), nil
+		}
+		sb.WriteString("\n")
+	}
+
+	page, err := jiraSearchIssues(ctx, jql, fields, expand, args.StartAt, args.MaxResults)
+	if err != nil {
+		return "", err
+	}
+
+	if len(page.Issues) == 0 {
+		sb.WriteString("No issues found matching the search criteria.")
+		return sb.String(), nil
+	}
+
+	for _, issue := range page.Issues {
+		sb.WriteString(fmt.Sprintf("Key: %s\n", issue.Key))
+
+		if summary := jiraFieldText(issue.Fields["summary"]); summary != "" {
+			sb.WriteString(fmt.Sprintf("Summary: %s\n", summary))
+		}
+		if status := jiraFieldText(issue.Fields["status"]); status != "" {
+			sb.WriteString(fmt.Sprintf("Status: %s\n", status))
+		}
+		if created := jiraFieldText(issue.Fields["created"]); created != "" {
+			sb.WriteString(fmt.Sprintf("Created: %s\n", created))
+		}
+		if updated := jiraFieldText(issue.Fields["updated"]); updated != "" {
+			sb.WriteString(fmt.Sprintf("Updated: %s\n", updated))
+		}
+		if assignee := jiraFieldText(issue.Fields["assignee"]); assignee != "" {
+			sb.WriteString(fmt.Sprintf("Assignee: %s\n", assignee))
+		} else {
+			sb.WriteString("Assignee: Unassigned\n")
+		}
+		if priority := jiraFieldText(issue.Fields["priority"]); priority != "" {
+			sb.WriteString(fmt.Sprintf("Priority: %s\n", priority))
+		} else {
+			sb.WriteString("Priority: Unset\n")
+		}
+		if resolved := jiraFieldText(issue.Fields["resolutiondate"]); resolved != "" {
+			sb.WriteString(fmt.Sprintf("Resolution date: %s\n", resolved))
+		}
+
+		for _, field := range extra {
+			value := jiraFieldText(issue.Fields[field.ID])
+			if value == "" {
+				value = "(none)"
+			}
+			sb.WriteString(fmt.Sprintf("%s: %s\n", field.Name, value))
+		}
+
+		rendered := []string{"description"}
+		for _, field := range extra {
+			rendered = append(rendered, field.ID)
+		}
+		for _, name := range rendered {
+			if text, ok := issue.RenderedFields[name].(string); ok && text != "" {
+				sb.WriteString(fmt.Sprintf("Rendered %s:\n%s\n", name, text))
+			}
+		}
+
+		if issue.Changelog != nil {
+			sortJiraHistories(issue.Changelog.Histories)
+			sb.WriteString(fmt.Sprintf("Changelog (%d changes):\n", len(issue.Changelog.Histories)))
+			for _, history := range issue.Changelog.Histories {
+				author := "Unknown"
+				if history.Author != nil && history.Author.DisplayName != "" {
+					author = history.Author.DisplayName
+				}
+				for _, item := range history.Items {
+					sb.WriteString(fmt.Sprintf("- %s by %s: %s %s → %s\n", history.Created, author, item.Field, formatJiraHistoryValue(item.FromString, item.From), formatJiraHistoryValue(item.ToString, item.To)))
+				}
+			}
+		}
+
+		sb.WriteString("\n")
+	}
+
+	if shown := args.StartAt + len(page.Issues); shown < page.Total {
+		sb.WriteString(fmt.Sprintf("Showing issues %d to %d of %d, use start_at=%d for the next page.\n", args.StartAt+1, shown, page.Total, shown))
+	}
+
+	return sb.String(), nil
+}
+
+// stripJiraOrderBy removes the ORDER BY clause of a query, ignoring the words inside quoted values
+func stripJiraOrderBy(jql string) string {
+	var quote byte
+	for i := 0; i < len(jql); i++ {
+		c := jql[i]
+		switch {
+		case quote != 0 && c == '\\':
+			i++
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case (i == 0 || !isJQLWordByte(jql[i-1])) && jiraOrderByKeyword.MatchString(jql[i:]):
+			return strings.TrimRight(jql[:i], " \t\r\n")
+		}
+	}
+	return jql
+}
+
+func isJQLWordByte(c byte) bool {
+	return c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// jiraSearchIssues reads one page of search results, explaining JQL errors the validation did not catch
+func jiraSearchIssues(ctx context.Context, jql string, fields, expand []string, startAt, maxResults int) (*jiraSearchPage, error) {
+	params := url.Values{}
+	params.Set("jql", jql)
+	params.Set("startAt", strconv.Itoa(startAt))
+	params.Set("maxResults", strconv.Itoa(maxResults))
+	params.Set("fields", strings.Join(fields, ","))
+	if len(expand) > 0 {
+		params.Set("expand", strings.Join(expand, ","))
+	}
+
+	page := new(jiraSearchPage)
+	response, err := jiraRequest(ctx, http.MethodGet, "rest/api/2/search?"+params.Encode(), nil, page)
+	if err != nil {
+		if response != nil {
+			if response.Code == http.StatusBadRequest {
+				var body struct {
+					ErrorMessages []string `json:"errorMessages"`
+				}
+				if json.Unmarshal(response.Bytes.Bytes(), &body) == nil && len(body.ErrorMessages) > 0 {
+					return nil, jiraExplainJQLErrors(ctx, jql, body.ErrorMessages)
+				}
+			}
+			return nil, fmt.Errorf("failed to search issues: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
+		}
+		return nil, fmt.Errorf("failed to search issues: %v", err)
+	}
+	return page, nil
+}
+
+// jiraValidateJQL checks a query with the JQL parser. Jira Data Center has no parser endpoint,
+// so when it is missing the query is left for the search to check.
+func jiraValidateJQL(ctx context.Context, jql string) error {
+	parsed, response, err := services.JiraClient().JQL.Parse(ctx, "strict", []string{jql})
+	if err != nil {
+		if response != nil && (response.Code == http.StatusNotFound || response.Code == http.StatusMethodNotAllowed) {
+			return nil
+		}
+		if response != nil {
+			return fmt.Errorf("failed to validate JQL: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
+		}
+		return fmt.Errorf("failed to validate JQL: %v", err)
+	}
+
+	for _, query := range parsed.Queries {
+		if len(query.Errors) > 0 {
+			return jiraExplainJQLErrors(ctx, jql, query.Errors)
+		}
+	}
+	return nil
+}
+
+// jiraExplainJQLErrors turns the errors Jira reports for a query into an error with hints on fixing it
+func jiraExplainJQLErrors(ctx context.Context, jql string, errors []string) error {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("invalid JQL: %s\n", jql))
+
+	var catalog []*models.IssueFieldScheme
+	for _, message := range errors {
+		sb.WriteString(fmt.Sprintf("- %s\n", message))
+
+		switch {
+		case jiraUnknownFieldError.MatchString(message):
+			name := jiraUnknownFieldError.FindStringSubmatch(message)[1]
+			if catalog == nil {
+				catalog, _ = jiraFieldCatalog(ctx)
+			}
+			if suggestions := jiraSuggestFields(catalog, name); len(suggestions) > 0 {
+				sb.WriteString(fmt.Sprintf("  Did you mean %s? Field names with spaces must be quoted.\n", strings.Join(suggestions, " or ")))
+			} else {
+				sb.WriteString("  Field names with spaces must be quoted, custom fields can also be given as cf[ID].\n")
+			}
+		case jiraUnknownValueError.MatchString(message):
+			match := jiraUnknownValueError.FindStringSubmatch(message)
+			sb.WriteString(fmt.Sprintf("  Check the spelling of %q. Values of %s can be looked up with jira_get_create_meta, and users with jira_search_users.\n", match[1], match[2]))
+		case jiraOperatorError.MatchString(message):
+			match := jiraOperatorError.FindStringSubmatch(message)
+			sb.WriteString(fmt.Sprintf("  Use =, != or IN with %s; ~ only works on text fields such as summary.\n", match[2]))
+		case strings.Contains(message, "reserved"):
+			sb.WriteString("  Quote reserved words when they are used as values.\n")
+		default:
+			sb.WriteString("  Quote values that contain spaces or special characters (e.g., status = \"In Progress\"), and list several values with IN (a, b).\n")
+		}
+	}
+	return fmt.Errorf("%s", strings.TrimSuffix(sb.String(), "\n"))
+}
+
+// jiraSuggestFields finds fields whose name resembles an unknown field, in the form JQL refers to them
+func jiraSuggestFields(catalog []*models.IssueFieldScheme, name string) []string {
+	name = strings.Trim(strings.ToLower(name), `"`)
+	var suggestions []string
+	for _, field := range catalog {
+		fieldName := strings.ToLower(field.Name)
+		similar := strings.Contains(fieldName, name) || strings.Contains(name, fieldName)
+		for _, word := range strings.Fields(name) {
+			if word = strings.TrimSuffix(word, "s"); len(word) >= 3 && strings.Contains(fieldName, word) {
+				similar = true
+			}
+		}
+		if !similar {
+			continue
+		}
+		suggestion := fmt.Sprintf("%q", field.Name)
+		if len(field.ClauseNames) > 0 {
+			suggestion = fmt.Sprintf("%q (%s)", field.Name, strings.Join(field.ClauseNames, ", "))
+		}
+		suggestions = append(suggestions, suggestion)
+		if len(suggestions) == 3 {
+			break
+		}
+	}
+	return suggestions
+}
+
+// jiraGroupIssues counts the issues matching a query by the values of a field
+func jiraGroupIssues(ctx context.Context, jql string, field *models.IssueFieldScheme) (string, error) {
+	counts := map[string]int{}
+	read, total := 0, 0
+	for read < maxGroupIssues {
+		page, err := jiraSearchIssues(ctx, jql, []string{field.ID}, nil, read, 100)
+		if err != nil {
+			return "", err
+		}
+		total = page.Total
+		for _, issue := range page.Issues {
+			values := jiraFieldTexts(issue.Fields[field.ID])
+			if len(values) == 0 {
+				values = []string{"(none)"}
+			}
+			for _, value := range values {
+				counts[value]++
+			}
+		}
+		read += len(page.Issues)
+		if len(page.Issues) == 0 || read >= page.Total {
+			break
+		}
+	}
+
+	values := make([]string, 0, len(counts))
+	for value := range counts {
+		values = append(values, value)
+	}
+	sort.Slice(values, func(i, j int) bool {
+		if counts[values[i]] != counts[values[j]] {
+			return counts[values[i]] > counts[values[j]]
+		}
+		return values[i] < values[j]
+	})
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Counts by %s (%d issues):\n", field.Name, total))
+	if read < total {
+		sb.WriteString(fmt.Sprintf("Only the first %d issues were counted.\n", read))
+	}
+	for _, value := range values {
+		sb.WriteString(fmt.Sprintf("- %s: %d\n", value, counts[value]))
+	}
+	return sb.String(), nil
+}
+
+// jiraFieldText shows a field value of any type as text
+func jiraFieldText(value interface{}) string {
+	return strings.Join(jiraFieldTexts(value), ", ")
+}
+
+// jiraFieldTexts shows each item of a field value as text, so that multi-value fields can be counted per item
+func jiraFieldTexts(value interface{}) []string {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case string:
+		if v == "" {
+			return nil
+		}
+		return []string{v}
+	case float64:
+		return []string{strconv.FormatFloat(v, 'f', -1, 64)}
+	case bool:
+		return []string{strconv.FormatBool(v)}
+	case []interface{}:
+		var texts []string
+		for _, item := range v {
+			texts = append(texts, jiraFieldTexts(item)...)
+		}
+		return texts
+	case map[string]interface{}:
+		for _, key := range []string{"displayName", "name", "value", "key"} {
+			if text, ok := v[key].(string); ok && text != "" {
+				if child, ok := v["child"].(map[string]interface{}); ok {
+					text += " > " + jiraFieldText(child)
+				}
+				return []string{text}
+			}
+		}
+		encoded, _ := json.Marshal(v)
+		return []string{string(encoded)}
+	}
+	return []string{fmt.Sprint(value)}
+}
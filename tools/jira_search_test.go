@@ -0,0 +1,24 @@
+package tools
+
+import "testing"
+
+func TestStripJiraOrderBy(t *testing.T) {
+	tests := []struct {
+		jql  string
+		want string
+	}{
+		{`project = KP ORDER BY created DESC`, `project = KP`},
+		{`project = KP order  by rank`, `project = KP`},
+		{`ORDER BY created`, ``},
+		{`project = KP`, `project = KP`},
+		{`summary ~ "order by date" ORDER BY key`, `summary ~ "order by date"`},
+		{`summary ~ 'sort, then order by' AND status = Open`, `summary ~ 'sort, then order by' AND status = Open`},
+		{`summary ~ "say \"order by\"" order by key`, `summary ~ "say \"order by\""`},
+		{`labels = reorder_by_hand`, `labels = reorder_by_hand`},
+	}
+	for _, test := range tests {
+		if got := stripJiraOrderBy(test.jql); got != test.want {
+			t.Errorf("stripJiraOrderBy(%q) = %q, want %q", test.jql, got, test.want)
+		}
+	}
+}
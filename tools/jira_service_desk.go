@@ -0,0 +1,759 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ctreminiom/go-atlassian/pkg/infra/models"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/nguyenvanduocit/dev-kit/services"
+	"github.com/nguyenvanduocit/dev-kit/util"
+	"github.com/nguyenvanduocit/dev-kit/util/markup"
+)
+
+func registerJiraServiceDeskTools(s *server.MCPServer) {
+	jiraListServiceDesksTool := mcp.NewTool("jira_list_service_desks",
+		mcp.WithDescription("List the Jira Service Management service desks you can see, with their IDs and project keys"),
+	)
+
+	jiraListRequestTypesTool := mcp.NewTool("jira_list_request_types",
+		mcp.WithDescription("List the request types customers can raise in a Jira Service Management service desk"),
+		mcp.WithString("service_desk", mcp.Required(), mcp.Description("ID of the service desk or key of its project (e.g., 1, SUP)")),
+		mcp.WithString("query", mcp.Description("Only list request types whose name contains this text (optional)")),
+	)
+
+	jiraGetRequestTypeFieldsTool := mcp.NewTool("jira_get_request_type_fields",
+		mcp.WithDescription("List the fields of a request type's form, which are required and which values they accept, before creating a customer request"),
+		mcp.WithString("service_desk", mcp.Required(), mcp.Description("ID of the service desk or key of its project (e.g., 1, SUP)")),
+		mcp.WithString("request_type", mcp.Required(), mcp.Description("Name or ID of the request type (e.g., Get IT help)")),
+	)
+
+	jiraCreateCustomerRequestTool := mcp.NewTool("jira_create_customer_request",
+		mcp.WithDescription("Raise a customer request in a Jira Service Management service desk, as the customer portal does. The fields are checked against the request type's form first; use jira_get_request_type_fields to see them"),
+		mcp.WithString("service_desk", mcp.Required(), mcp.Description("ID of the service desk or key of its project (e.g., 1, SUP)")),
+		mcp.WithString("request_type", mcp.Required(), mcp.Description("Name or ID of the request type (e.g., Get IT help)")),
+		mcp.WithString("summary", mcp.Required(), mcp.Description("Summary of the request")),
+		mcp.WithString("description", mcp.Description("Description of the request in Markdown (optional unless the request type requires it)")),
+		mcp.WithString("fields", mcp.Description("JSON object of other fields of the form by name or ID, e.g. {\"Urgency\": \"High\"}. Options and users can be given by name; objects are sent as is (optional)")),
+		mcp.WithString("raise_on_behalf_of", mcp.Description("Account ID, or username on Data Center, of the customer the request is raised for (optional, defaults to you)")),
+	)
+
+	jiraListQueuesTool := mcp.NewTool("jira_list_queues",
+		mcp.WithDescription("List the queues of a Jira Service Management service desk with their IDs, JQL and number of requests"),
+		mcp.WithString("service_desk", mcp.Required(), mcp.Description("ID of the service desk or key of its project (e.g., 1, SUP)")),
+	)
+
+	jiraListQueueRequestsTool := mcp.NewTool("jira_list_queue_requests",
+		mcp.WithDescription("List the requests in a Jira Service Management queue with their status and assignee"),
+		mcp.WithString("service_desk", mcp.Required(), mcp.Description("ID of the service desk or key of its project (e.g., 1, SUP)")),
+		mcp.WithString("queue", mcp.Required(), mcp.Description("Name or ID of the queue (e.g., Unassigned)")),
+		mcp.WithNumber("max_results", mcp.DefaultNumber(30), mcp.Min(1), mcp.Max(50), mcp.Description("Maximum number of requests to return")),
+		mcp.WithNumber("start_at", mcp.DefaultNumber(0), mcp.Min(0), mcp.Description("Index of the first request to return, for paging")),
+	)
+
+	jiraAddRequestCommentTool := mcp.NewTool("jira_add_request_comment",
+		mcp.WithDescription("Comment on a Jira Service Management request, either as an internal note only agents see or as a public reply the customer is notified of"),
+		mcp.WithString("issue_key", mcp.Required(), mcp.Description("Key of the request (e.g., SUP-12)")),
+		mcp.WithString("body", mcp.Required(), mcp.Description("Comment text in Markdown")),
+		mcp.WithBoolean("public", mcp.DefaultBool(false), mcp.Description("Reply to the customer instead of adding an internal note")),
+	)
+
+	jiraGetRequestSLATool := mcp.NewTool("jira_get_request_sla",
+		mcp.WithDescription("Show the SLAs of a Jira Service Management request: whether each was met or breached, or how much time is left and when it is due"),
+		mcp.WithString("issue_key", mcp.Required(), mcp.Description("Key of the request (e.g., SUP-12)")),
+	)
+
+	s.AddTool(jiraListServiceDesksTool, util.ErrorGuard(util.TypedHandler(jiraListServiceDesksTool, jiraListServiceDesksHandler)))
+	s.AddTool(jiraListRequestTypesTool, util.ErrorGuard(util.TypedHandler(jiraListRequestTypesTool, jiraListRequestTypesHandler)))
+	s.AddTool(jiraGetRequestTypeFieldsTool, util.ErrorGuard(util.TypedHandler(jiraGetRequestTypeFieldsTool, jiraGetRequestTypeFieldsHandler)))
+	s.AddTool(jiraCreateCustomerRequestTool, util.ErrorGuard(util.TypedHandler(jiraCreateCustomerRequestTool, jiraCreateCustomerRequestHandler)))
+	s.AddTool(jiraListQueuesTool, util.ErrorGuard(util.TypedHandler(jiraListQueuesTool, jiraListQueuesHandler)))
+	s.AddTool(jiraListQueueRequestsTool, util.ErrorGuard(util.TypedHandler(jiraListQueueRequestsTool, jiraListQueueRequestsHandler)))
+	s.AddTool(jiraAddRequestCommentTool, util.ErrorGuard(util.TypedHandler(jiraAddRequestCommentTool, jiraAddRequestCommentHandler)))
+	s.AddTool(jiraGetRequestSLATool, util.ErrorGuard(util.TypedHandler(jiraGetRequestSLATool, jiraGetRequestSLAHandler)))
+}
+
+type jiraListServiceDesksArgs struct{}
+
+type jiraListRequestTypesArgs struct {
+	ServiceDesk string `json:"service_desk"`
+	Query       string `json:"query"`
+}
+
+type jiraRequestTypeArgs struct {
+	ServiceDesk string `json:"service_desk"`
+	RequestType string `json:"request_type"`
+}
+
+type jiraCreateCustomerRequestArgs struct {
+	ServiceDesk     string `json:"service_desk"`
+	RequestType     string `json:"request_type"`
+	Summary         string `json:"summary"`
+	Description     string `json:"description"`
+	Fields          string `json:"fields"`
+	RaiseOnBehalfOf string `json:"raise_on_behalf_of"`
+}
+
+type jiraServiceDeskArgs struct {
+	ServiceDesk string `json:"service_desk"`
+}
+
+type jiraListQueueRequestsArgs struct {
+	ServiceDesk string `json:"service_desk"`
+	Queue       string `json:"queue"`
+	MaxResults  int    `json:"max_results"`
+	StartAt     int    `json:"start_at"`
+}
+
+type jiraAddRequestCommentArgs struct {
+	IssueKey string `json:"issue_key"`
+	Body     string `json:"body"`
+	Public   bool   `json:"public"`
+}
+
+// jiraRequestSLA holds the cycle times of an SLA, which the go-atlassian SLA model leaves out
+type jiraRequestSLA struct {
+	ID              string              `json:"id"`
+	Name            string              `json:"name"`
+	CompletedCycles []*jiraRequestCycle `json:"completedCycles"`
+	OngoingCycle    *jiraRequestCycle   `json:"ongoingCycle"`
+}
+
+type jiraRequestCycle struct {
+	StartTime           *jiraServiceDeskDate     `json:"startTime"`
+	StopTime            *jiraServiceDeskDate     `json:"stopTime"`
+	BreachTime          *jiraServiceDeskDate     `json:"breachTime"`
+	Breached            bool                     `json:"breached"`
+	Paused              bool                     `json:"paused"`
+	WithinCalendarHours bool                     `json:"withinCalendarHours"`
+	GoalDuration        *jiraServiceDeskDuration `json:"goalDuration"`
+	ElapsedTime         *jiraServiceDeskDuration `json:"elapsedTime"`
+	RemainingTime       *jiraServiceDeskDuration `json:"remainingTime"`
+}
+
+type jiraServiceDeskDate struct {
+	ISO8601     string `json:"iso8601"`
+	Friendly    string `json:"friendly"`
+	EpochMillis int64  `json:"epochMillis"`
+}
+
+type jiraServiceDeskDuration struct {
+	Millis   int64  `json:"millis"`
+	Friendly string `json:"friendly"`
+}
+
+// serviceDeskRequest sends a request to a Service Management REST endpoint that go-atlassian
+// does not model fully. The response body is decoded into out when it is not nil.
+func serviceDeskRequest(ctx context.Context, method, endpoint string, payload, out interface{}) (*models.ResponseScheme, error) {
+	client := services.ServiceManagementClient()
+
+	request, err := client.NewRequest(ctx, method, endpoint, "", payload)
+	if err != nil {
+		return nil, err
+	}
+
+	return client.Call(request, out)
+}
+
+func jiraListServiceDesksHandler(args jiraListServiceDesksArgs) (*mcp.CallToolResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second)
+	defer cancel()
+
+	desks, err := jiraServiceDesks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(desks) == 0 {
+		return mcp.NewToolResultText("No service desks found."), nil
+	}
+
+	var sb strings.Builder
+	for _, desk := range desks {
+		sb.WriteString(fmt.Sprintf("- %s: %s (ID: %s)\n", desk.ProjectKey, desk.ProjectName, desk.ID))
+	}
+	return mcp.NewToolResultText(sb.String()), nil
+}
+
+func jiraListRequestTypesHandler(args jiraListRequestTypesArgs) (*mcp.CallToolResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second*2)
+	defer cancel()
+
+	desk, err := jiraFindServiceDesk(ctx, args.ServiceDesk)
+	if err != nil {
+		return nil, err
+	}
+	types, err := jiraRequestTypes(ctx, desk)
+	if err != nil {
+		return nil, err
+	}
+
+	var sb strings.Builder
+	for _, requestType := range types {
+		if args.Query != "" && !strings.Contains(strings.ToLower(requestType.Name), strings.ToLower(args.Query)) {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("- %s (ID: %s)", requestType.Name, requestType.ID))
+		if requestType.Description != "" {
+			sb.WriteString(": " + requestType.Description)
+		}
+		sb.WriteString("\n")
+	}
+	if sb.Len() == 0 {
+		return mcp.NewToolResultText(fmt.Sprintf("No request types found in service desk %s.", desk.ProjectKey)), nil
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("Request types of service desk %s (ID: %s):\n%s", desk.ProjectKey, desk.ID, sb.String())), nil
+}
+
+func jiraGetRequestTypeFieldsHandler(args jiraRequestTypeArgs) (*mcp.CallToolResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second*3)
+	defer cancel()
+
+	desk, requestType, form, err := jiraRequestTypeForm(ctx, args.ServiceDesk, args.RequestType)
+	if err != nil {
+		return nil, err
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Fields of request type %s (ID: %s) in service desk %s:\n", requestType.Name, requestType.ID, desk.ProjectKey))
+	if requestType.HelpText != "" {
+		sb.WriteString(fmt.Sprintf("Help text: %s\n", requestType.HelpText))
+	}
+	for _, field := range form.RequestTypeFields {
+		sb.WriteString(formatJiraRequestTypeField(field))
+	}
+	if form.CanRaiseOnBehalfOf {
+		sb.WriteString("Requests can be raised on behalf of customers.\n")
+	}
+
+	return mcp.NewToolResultText(sb.String()), nil
+}
+
+func jiraCreateCustomerRequestHandler(args jiraCreateCustomerRequestArgs) (*mcp.CallToolResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second*3)
+	defer cancel()
+
+	desk, requestType, form, err := jiraRequestTypeForm(ctx, args.ServiceDesk, args.RequestType)
+	if err != nil {
+		return nil, err
+	}
+
+	given, err := parseJiraFieldsArgument("fields", args.Fields)
+	if err != nil {
+		return nil, err
+	}
+	given["summary"] = args.Summary
+	if args.Description != "" {
+		given["description"] = markup.MarkdownToWiki(args.Description)
+	}
+
+	values, err := jiraRequestFieldValues(form, given)
+	if err != nil {
+		return nil, fmt.Errorf("request type %s: %v", requestType.Name, err)
+	}
+
+	payload := &models.CreateCustomerRequestPayloadScheme{
+		ServiceDeskID:      desk.ID,
+		RequestTypeID:      requestType.ID,
+		RequestFieldValues: values,
+		RaiseOnBehalfOf:    args.RaiseOnBehalfOf,
+	}
+	request, response, err := services.ServiceManagementClient().Request.Create(ctx, payload)
+	if err != nil {
+		if response != nil {
+			return nil, fmt.Errorf("failed to create request: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
+		}
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Request %s created in service desk %s as %s.\n", request.IssueKey, desk.ProjectKey, requestType.Name))
+	if request.Reporter != nil {
+		sb.WriteString(fmt.Sprintf("Reporter: %s\n", request.Reporter.DisplayName))
+	}
+	if request.CurrentStatus != nil {
+		sb.WriteString(fmt.Sprintf("Status: %s\n", request.CurrentStatus.Status))
+	}
+	if request.Links != nil {
+		sb.WriteString(fmt.Sprintf("Portal: %s\nAgent view: %s\n", request.Links.Web, request.Links.Agent))
+	}
+	return mcp.NewToolResultText(sb.String()), nil
+}
+
+func jiraListQueuesHandler(args jiraServiceDeskArgs) (*mcp.CallToolResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second*2)
+	defer cancel()
+
+	desk, err := jiraFindServiceDesk(ctx, args.ServiceDesk)
+	if err != nil {
+		return nil, err
+	}
+	queues, err := jiraQueues(ctx, desk)
+	if err != nil {
+		return nil, err
+	}
+	if len(queues) == 0 {
+		return mcp.NewToolResultText(fmt.Sprintf("No queues found in service desk %s.", desk.ProjectKey)), nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Queues of service desk %s (ID: %s):\n", desk.ProjectKey, desk.ID))
+	for _, queue := range queues {
+		requests := fmt.Sprintf("%d requests", queue.IssueCount)
+		if queue.IssueCount == 1 {
+			requests = "1 request"
+		}
+		sb.WriteString(fmt.Sprintf("- %s (ID: %s): %s\n  JQL: %s\n", queue.Name, queue.ID, requests, queue.Jql))
+	}
+	return mcp.NewToolResultText(sb.String()), nil
+}
+
+func jiraListQueueRequestsHandler(args jiraListQueueRequestsArgs) (*mcp.CallToolResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second*3)
+	defer cancel()
+
+	desk, err := jiraFindServiceDesk(ctx, args.ServiceDesk)
+	if err != nil {
+		return nil, err
+	}
+	queue, err := jiraFindQueue(ctx, desk, args.Queue)
+	if err != nil {
+		return nil, err
+	}
+
+	deskID, _ := strconv.Atoi(desk.ID)
+	queueID, _ := strconv.Atoi(queue.ID)
+	page, response, err := services.ServiceManagementClient().ServiceDesk.Queue.Issues(ctx, deskID, queueID, args.StartAt, args.MaxResults)
+	if err != nil {
+		if response != nil {
+			return nil, fmt.Errorf("failed to list queue requests: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
+		}
+		return nil, fmt.Errorf("failed to list queue requests: %v", err)
+	}
+
+	if len(page.Values) == 0 {
+		return mcp.NewToolResultText(fmt.Sprintf("Queue %s of service desk %s is empty.", queue.Name, desk.ProjectKey)), nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Requests %d-%d in queue %s of service desk %s:\n", args.StartAt+1, args.StartAt+len(page.Values), queue.Name, desk.ProjectKey))
+	for _, issue := range page.Values {
+		sb.WriteString(formatJiraBoardIssue(nil, issue, nil))
+	}
+	if !page.IsLastPage {
+		sb.WriteString(fmt.Sprintf("More requests are queued, use start_at=%d for the next page.\n", args.StartAt+len(page.Values)))
+	}
+	return mcp.NewToolResultText(sb.String()), nil
+}
+
+func jiraAddRequestCommentHandler(args jiraAddRequestCommentArgs) (*mcp.CallToolResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second)
+	defer cancel()
+
+	comment, response, err := services.ServiceManagementClient().Request.Comment.Create(ctx, args.IssueKey, markup.MarkdownToWiki(args.Body), args.Public)
+	if err != nil {
+		if response != nil {
+			return nil, fmt.Errorf("failed to add request comment: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
+		}
+		return nil, fmt.Errorf("failed to add request comment: %v", err)
+	}
+
+	if args.Public {
+		return mcp.NewToolResultText(fmt.Sprintf("Public reply %s added to %s, the customer is notified.", comment.ID, args.IssueKey)), nil
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("Internal note %s added to %s, only agents can see it.", comment.ID, args.IssueKey)), nil
+}
+
+func jiraGetRequestSLAHandler(args jiraIssueKeyArgs) (*mcp.CallToolResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second)
+	defer cancel()
+
+	var page struct {
+		Values []*jiraRequestSLA `json:"values"`
+	}
+	response, err := serviceDeskRequest(ctx, http.MethodGet, fmt.Sprintf("rest/servicedeskapi/request/%s/sla", args.IssueKey), nil, &page)
+	if err != nil {
+		if response != nil {
+			return nil, fmt.Errorf("failed to get request SLAs: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
+		}
+		return nil, fmt.Errorf("failed to get request SLAs: %v", err)
+	}
+
+	if len(page.Values) == 0 {
+		return mcp.NewToolResultText(fmt.Sprintf("No SLAs apply to %s.", args.IssueKey)), nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("SLAs of %s:\n", args.IssueKey))
+	for _, sla := range page.Values {
+		sb.WriteString(formatJiraRequestSLA(sla))
+	}
+	return mcp.NewToolResultText(sb.String()), nil
+}
+
+// formatJiraRequestSLA shows the ongoing cycle of an SLA, or the last completed one
+func formatJiraRequestSLA(sla *jiraRequestSLA) string {
+	cycle := sla.OngoingCycle
+	if cycle == nil && len(sla.CompletedCycles) > 0 {
+		cycle = sla.CompletedCycles[len(sla.CompletedCycles)-1]
+	}
+	if cycle == nil {
+		return fmt.Sprintf("- %s: not started\n", sla.Name)
+	}
+
+	goal := ""
+	if cycle.GoalDuration != nil {
+		goal = " (goal " + cycle.GoalDuration.Friendly + ")"
+	}
+
+	var state string
+	switch {
+	case sla.OngoingCycle == nil && cycle.Breached:
+		state = "breached, took " + cycle.ElapsedTime.friendly()
+	case sla.OngoingCycle == nil:
+		state = "met, took " + cycle.ElapsedTime.friendly()
+	case cycle.Breached:
+		state = fmt.Sprintf("breached, overdue by %s since %s", strings.TrimPrefix(cycle.RemainingTime.friendly(), "-"), cycle.BreachTime.friendly())
+	default:
+		state = fmt.Sprintf("running, %s left, due %s", cycle.RemainingTime.friendly(), cycle.BreachTime.friendly())
+	}
+	if cycle.Paused {
+		state += ", paused"
+	}
+
+	line := fmt.Sprintf("- %s: %s%s", sla.Name, state, goal)
+	if cycle.StopTime != nil {
+		line += ", stopped " + cycle.StopTime.friendly()
+	}
+	return line + "\n"
+}
+
+func (d *jiraServiceDeskDuration) friendly() string {
+	if d == nil {
+		return "unknown"
+	}
+	return d.Friendly
+}
+
+func (d *jiraServiceDeskDate) friendly() string {
+	if d == nil {
+		return "unknown"
+	}
+	if d.ISO8601 != "" {
+		return d.ISO8601
+	}
+	return d.Friendly
+}
+
+func formatJiraRequestTypeField(field *models.RequestTypeFieldScheme) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("- %s (%s): %s", field.Name, field.FieldID, jiraFieldType(jiraRequestFieldSchema(field))))
+	if field.Required {
+		sb.WriteString(", required")
+	}
+
+	if len(field.ValidValues) > 0 {
+		var values []string
+		for i, value := range field.ValidValues {
+			if i == maxAllowedValues {
+				values = append(values, fmt.Sprintf("... and %d more", len(field.ValidValues)-maxAllowedValues))
+				break
+			}
+			values = append(values, value.Label)
+		}
+		sb.WriteString("; allowed: " + strings.Join(values, ", "))
+	}
+	if field.Description != "" {
+		sb.WriteString("; " + field.Description)
+	}
+
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+// jiraRequestFieldSchema converts the schema of a form field to the one jiraFieldValue takes
+func jiraRequestFieldSchema(field *models.RequestTypeFieldScheme) *models.IssueFieldSchemaScheme {
+	if field.JiraSchema == nil {
+		return nil
+	}
+	return &models.IssueFieldSchemaScheme{
+		Type:     field.JiraSchema.Type,
+		Items:    field.JiraSchema.Items,
+		System:   field.JiraSchema.System,
+		Custom:   field.JiraSchema.Custom,
+		CustomID: field.JiraSchema.CustomID,
+	}
+}
+
+// jiraRequestFieldValues matches the given values, keyed by field name or ID, to the fields of a
+// request type's form and converts them, failing on unknown fields, invalid options and missing
+// required fields before Jira does
+func jiraRequestFieldValues(form *models.RequestTypeFieldsScheme, given map[string]interface{}) (map[string]interface{}, error) {
+	values := map[string]interface{}{}
+	for key, value := range given {
+		var field *models.RequestTypeFieldScheme
+		for _, candidate := range form.RequestTypeFields {
+			if candidate.FieldID == key || strings.EqualFold(candidate.Name, key) {
+				field = candidate
+				break
+			}
+		}
+		if field == nil {
+			var names []string
+			for _, candidate := range form.RequestTypeFields {
+				names = append(names, candidate.Name)
+			}
+			return nil, fmt.Errorf("%q is not on the form, its fields are: %s", key, strings.Join(names, ", "))
+		}
+
+		if err := jiraCheckValidValues(field, value); err != nil {
+			return nil, err
+		}
+		converted, err := jiraFieldValue(jiraRequestFieldSchema(field), value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", field.Name, err)
+		}
+		values[field.FieldID] = converted
+	}
+
+	var missing []string
+	for _, field := range form.RequestTypeFields {
+		if _, ok := values[field.FieldID]; field.Required && !ok {
+			missing = append(missing, strings.TrimSuffix(formatJiraRequestTypeField(field), "\n"))
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("missing required fields:\n%s", strings.Join(missing, "\n"))
+	}
+	return values, nil
+}
+
+// jiraCheckValidValues checks option names given as text against the values a field accepts
+func jiraCheckValidValues(field *models.RequestTypeFieldScheme, value interface{}) error {
+	schema := field.JiraSchema
+	if len(field.ValidValues) == 0 || schema == nil {
+		return nil
+	}
+	if !strings.HasPrefix(schema.Type, "option") && !(schema.Type == "array" && schema.Items == "option") {
+		return nil
+	}
+
+	var names []string
+	switch v := value.(type) {
+	case string:
+		names = splitList(v)
+		if schema.Type != "array" {
+			names = []string{strings.TrimSpace(v)}
+		}
+	case []interface{}:
+		for _, item := range v {
+			if name, ok := item.(string); ok {
+				names = append(names, name)
+			}
+		}
+	}
+
+	var labels []string
+	for _, valid := range field.ValidValues {
+		labels = append(labels, valid.Label)
+	}
+	for _, name := range names {
+		// options with children are given as parent > child
+		parent, _, _ := strings.Cut(name, ">")
+		found := false
+		for _, label := range labels {
+			found = found || label == strings.TrimSpace(parent)
+		}
+		if !found {
+			return fmt.Errorf("%s does not accept %q, allowed: %s", field.Name, name, strings.Join(labels, ", "))
+		}
+	}
+	return nil
+}
+
+// jiraServiceDesks lists the service desks, explaining when Jira Service Management is not available
+func jiraServiceDesks(ctx context.Context) ([]*models.ServiceDeskScheme, error) {
+	var desks []*models.ServiceDeskScheme
+	for start := 0; ; {
+		page, response, err := services.ServiceManagementClient().ServiceDesk.Gets(ctx, start, 50)
+		if err != nil {
+			if response != nil && response.Code == http.StatusNotFound {
+				return nil, fmt.Errorf("Jira Service Management is not available on this Jira site (endpoint: %s)", response.Endpoint)
+			}
+			if response != nil {
+				return nil, fmt.Errorf("failed to list service desks: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
+			}
+			return nil, fmt.Errorf("failed to list service desks: %v", err)
+		}
+
+		desks = append(desks, page.Values...)
+		if page.IsLastPage || len(page.Values) == 0 {
+			return desks, nil
+		}
+		start += len(page.Values)
+	}
+}
+
+// jiraFindServiceDesk finds a service desk by ID, project key or project name
+func jiraFindServiceDesk(ctx context.Context, idOrKey string) (*models.ServiceDeskScheme, error) {
+	desks, err := jiraServiceDesks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	idOrKey = strings.TrimSpace(idOrKey)
+	var keys []string
+	for _, desk := range desks {
+		if desk.ID == idOrKey || strings.EqualFold(desk.ProjectKey, idOrKey) || strings.EqualFold(desk.ProjectName, idOrKey) {
+			return desk, nil
+		}
+		keys = append(keys, desk.ProjectKey)
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no service desk %q found, and you cannot see any service desk", idOrKey)
+	}
+	return nil, fmt.Errorf("no service desk %q found, use one of: %s", idOrKey, strings.Join(keys, ", "))
+}
+
+func jiraRequestTypes(ctx context.Context, desk *models.ServiceDeskScheme) ([]*models.RequestTypeScheme, error) {
+	deskID, err := strconv.Atoi(desk.ID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid service desk ID %q", desk.ID)
+	}
+
+	var types []*models.RequestTypeScheme
+	for start := 0; ; {
+		page, response, err := services.ServiceManagementClient().Request.Type.Gets(ctx, deskID, 0, start, 50)
+		if err != nil {
+			if response != nil {
+				return nil, fmt.Errorf("failed to list request types: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
+			}
+			return nil, fmt.Errorf("failed to list request types: %v", err)
+		}
+
+		types = append(types, page.Values...)
+		if page.IsLastPage || len(page.Values) == 0 {
+			return types, nil
+		}
+		start += len(page.Values)
+	}
+}
+
+// jiraFindRequestType finds a request type by ID, exact name or a name that only it contains
+func jiraFindRequestType(ctx context.Context, desk *models.ServiceDeskScheme, nameOrID string) (*models.RequestTypeScheme, error) {
+	types, err := jiraRequestTypes(ctx, desk)
+	if err != nil {
+		return nil, err
+	}
+
+	nameOrID = strings.TrimSpace(nameOrID)
+	var exact, partial []*models.RequestTypeScheme
+	for _, requestType := range types {
+		switch {
+		case requestType.ID == nameOrID, strings.EqualFold(requestType.Name, nameOrID):
+			exact = append(exact, requestType)
+		case strings.Contains(strings.ToLower(requestType.Name), strings.ToLower(nameOrID)):
+			partial = append(partial, requestType)
+		}
+	}
+	if len(exact) == 0 {
+		exact = partial
+	}
+
+	switch len(exact) {
+	case 1:
+		return exact[0], nil
+	case 0:
+		return nil, fmt.Errorf("no request type %q found in service desk %s, use jira_list_request_types to find it", nameOrID, desk.ProjectKey)
+	}
+	var names []string
+	for _, requestType := range exact {
+		names = append(names, fmt.Sprintf("- %s (ID: %s)", requestType.Name, requestType.ID))
+	}
+	return nil, fmt.Errorf("several request types match %q, pass the ID of one of them:\n%s", nameOrID, strings.Join(names, "\n"))
+}
+
+// jiraRequestTypeForm finds a service desk and request type and reads the fields of its form
+func jiraRequestTypeForm(ctx context.Context, serviceDesk, nameOrID string) (*models.ServiceDeskScheme, *models.RequestTypeScheme, *models.RequestTypeFieldsScheme, error) {
+	desk, err := jiraFindServiceDesk(ctx, serviceDesk)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	requestType, err := jiraFindRequestType(ctx, desk, nameOrID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	deskID, _ := strconv.Atoi(desk.ID)
+	typeID, _ := strconv.Atoi(requestType.ID)
+	form, response, err := services.ServiceManagementClient().Request.Type.Fields(ctx, deskID, typeID)
+	if err != nil {
+		if response != nil {
+			return nil, nil, nil, fmt.Errorf("failed to get request type fields: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
+		}
+		return nil, nil, nil, fmt.Errorf("failed to get request type fields: %v", err)
+	}
+	return desk, requestType, form, nil
+}
+
+func jiraQueues(ctx context.Context, desk *models.ServiceDeskScheme) ([]*models.ServiceDeskQueueScheme, error) {
+	deskID, err := strconv.Atoi(desk.ID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid service desk ID %q", desk.ID)
+	}
+
+	var queues []*models.ServiceDeskQueueScheme
+	for start := 0; ; {
+		page, response, err := services.ServiceManagementClient().ServiceDesk.Queue.Gets(ctx, deskID, true, start, 50)
+		if err != nil {
+			if response != nil {
+				return nil, fmt.Errorf("failed to list queues: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
+			}
+			return nil, fmt.Errorf("failed to list queues: %v", err)
+		}
+
+		queues = append(queues, page.Values...)
+		if page.IsLastPage || len(page.Values) == 0 {
+			return queues, nil
+		}
+		start += len(page.Values)
+	}
+}
+
+// jiraFindQueue finds a queue by ID, exact name or a name that only it contains
+func jiraFindQueue(ctx context.Context, desk *models.ServiceDeskScheme, nameOrID string) (*models.ServiceDeskQueueScheme, error) {
+	queues, err := jiraQueues(ctx, desk)
+	if err != nil {
+		return nil, err
+	}
+
+	nameOrID = strings.TrimSpace(nameOrID)
+	var exact, partial []*models.ServiceDeskQueueScheme
+	for _, queue := range queues {
+		switch {
+		case queue.ID == nameOrID, strings.EqualFold(queue.Name, nameOrID):
+			exact = append(exact, queue)
+		case strings.Contains(strings.ToLower(queue.Name), strings.ToLower(nameOrID)):
+			partial = append(partial, queue)
+		}
+	}
+	if len(exact) == 0 {
+		exact = partial
+	}
+
+	switch len(exact) {
+	case 1:
+		return exact[0], nil
+	case 0:
+		return nil, fmt.Errorf("no queue %q found in service desk %s, use jira_list_queues to find it", nameOrID, desk.ProjectKey)
+	}
+	var names []string
+	for _, queue := range exact {
+		names = append(names, fmt.Sprintf("- %s (ID: %s)", queue.Name, queue.ID))
+	}
+	return nil, fmt.Errorf("several queues match %q, pass the ID of one of them:\n%s", nameOrID, strings.Join(names, "\n"))
+}
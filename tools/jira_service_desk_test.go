@@ -0,0 +1,88 @@
+package tools
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestJiraServiceDeskHandlers(t *testing.T) {
+	text := resultText(t)(jiraListServiceDesksHandler(jiraListServiceDesksArgs{}))
+	assertContains(t, text, "- SUP: Support (ID: 1)")
+
+	text = resultText(t)(jiraListRequestTypesHandler(jiraListRequestTypesArgs{ServiceDesk: "SUP", Query: "access"}))
+	assertContains(t, text, "Request types of service desk SUP (ID: 1):", "- Request access (ID: 11): Ask for access")
+	if strings.Contains(text, "Get IT help") {
+		t.Errorf("request types not matching the query are listed:\n%s", text)
+	}
+
+	text = resultText(t)(jiraGetRequestTypeFieldsHandler(jiraRequestTypeArgs{ServiceDesk: "1", RequestType: "Report an incident"}))
+	assertContains(t, text, "Fields of request type Report an incident (ID: 12) in service desk SUP:", "- Urgency (customfield_10040): ", "required; allowed: Low, Medium, High, Critical")
+}
+
+func TestJiraCreateCustomerRequestHandler(t *testing.T) {
+	text := resultText(t)(jiraCreateCustomerRequestHandler(jiraCreateCustomerRequestArgs{
+		ServiceDesk: "SUP",
+		RequestType: "Report an incident",
+		Summary:     "Login page times out",
+		Description: "Since the **morning** deploy",
+		Fields:      `{"Urgency": "High", "Affected environments": "Production, Staging"}`,
+	}))
+	key := regexp.MustCompile(`SUP-\d+`).FindString(text)
+	if key == "" {
+		t.Fatalf("no request key in the create output:\n%s", text)
+	}
+	assertContains(t, text, "Request "+key+" created in service desk SUP as Report an incident.", "Reporter: Alice Nguyen")
+
+	text = resultText(t)(jiraIssueHandler(jiraIssueKeyArgs{IssueKey: key}))
+	assertContains(t, text, "Login page times out", "Since the **morning** deploy")
+
+	tests := []struct {
+		name   string
+		fields string
+		want   string
+	}{
+		{"missing required field", ``, "missing required fields:\n- Urgency (customfield_10040)"},
+		{"option not on the field", `{"Urgency": "Extreme"}`, `Urgency does not accept "Extreme", allowed: Low, Medium, High, Critical`},
+		{"field not on the form", `{"Urgency": "Low", "Team": "Platform"}`, `"Team" is not on the form`},
+	}
+	for _, test := range tests {
+		_, err := jiraCreateCustomerRequestHandler(jiraCreateCustomerRequestArgs{ServiceDesk: "SUP", RequestType: "12", Summary: "Rejected", Fields: test.fields})
+		if err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("%s: got %v, want an error containing %q", test.name, err, test.want)
+		}
+	}
+}
+
+func TestJiraQueueHandlers(t *testing.T) {
+	text := resultText(t)(jiraListQueuesHandler(jiraServiceDeskArgs{ServiceDesk: "SUP"}))
+	assertContains(t, text, "Queues of service desk SUP (ID: 1):", "- Unassigned (ID: 3): ", "JQL: project = SUP AND assignee is EMPTY AND status != Done")
+
+	text = resultText(t)(jiraListQueueRequestsHandler(jiraListQueueRequestsArgs{ServiceDesk: "SUP", Queue: "Unassigned", MaxResults: 30}))
+	assertContains(t, text, "in queue Unassigned of service desk SUP:", "SUP-3")
+	for _, key := range []string{"SUP-1", "SUP-2"} {
+		if strings.Contains(text, key) {
+			t.Errorf("assigned request %s is in the Unassigned queue:\n%s", key, text)
+		}
+	}
+
+	// nothing in SUP is assigned to Alice, the current user
+	text = resultText(t)(jiraListQueueRequestsHandler(jiraListQueueRequestsArgs{ServiceDesk: "SUP", Queue: "Assigned to me", MaxResults: 30}))
+	assertContains(t, text, "Queue Assigned to me of service desk SUP is empty.")
+
+	if _, err := jiraListQueueRequestsHandler(jiraListQueueRequestsArgs{ServiceDesk: "SUP", Queue: "Escalations", MaxResults: 30}); err == nil {
+		t.Error("listing an unknown queue succeeded, want an error")
+	}
+}
+
+func TestJiraRequestCommentAndSLAHandlers(t *testing.T) {
+	text := resultText(t)(jiraAddRequestCommentHandler(jiraAddRequestCommentArgs{IssueKey: "SUP-3", Body: "Checking the repository settings"}))
+	assertContains(t, text, "Internal note ", " added to SUP-3, only agents can see it.")
+
+	text = resultText(t)(jiraAddRequestCommentHandler(jiraAddRequestCommentArgs{IssueKey: "SUP-3", Body: "You have access now", Public: true}))
+	assertContains(t, text, "Public reply ", " added to SUP-3, the customer is notified.")
+
+	// SUP-1 was answered an hour and a half after it was raised and is done
+	text = resultText(t)(jiraGetRequestSLAHandler(jiraIssueKeyArgs{IssueKey: "SUP-1"}))
+	assertContains(t, text, "SLAs of SUP-1:", "- Time to first response: met, took ", "(goal 4h", "- Time to resolution: ")
+}
@@ -0,0 +1,597 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ctreminiom/go-atlassian/pkg/infra/models"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/nguyenvanduocit/dev-kit/services"
+	"github.com/nguyenvanduocit/dev-kit/util"
+)
+
+// maxMoveIssues is how many issues the agile API moves or ranks per request
+const maxMoveIssues = 50
+
+// jiraSprintDateLayout is the date format the agile API expects
+const jiraSprintDateLayout = "2006-01-02T15:04:05.000Z07:00"
+
+func registerJiraSprintTools(s *server.MCPServer) {
+	jiraCreateSprintTool := mcp.NewTool("jira_create_sprint",
+		mcp.WithDescription("Create a future sprint on a Jira board"),
+		mcp.WithString("board_id", mcp.Required(), mcp.Description("Numeric ID of the Jira board the sprint belongs to")),
+		mcp.WithString("name", mcp.Required(), mcp.Description("Name of the sprint (e.g., KP Sprint 12)")),
+		mcp.WithString("goal", mcp.Description("Goal of the sprint (optional)")),
+		mcp.WithString("start_date", mcp.Description("Planned start as YYYY-MM-DD or an RFC 3339 timestamp (optional)")),
+		mcp.WithString("end_date", mcp.Description("Planned end as YYYY-MM-DD or an RFC 3339 timestamp (optional)")),
+	)
+	s.AddTool(jiraCreateSprintTool, util.ErrorGuard(util.TypedHandler(jiraCreateSprintTool, jiraCreateSprintHandler)))
+
+	jiraUpdateSprintTool := mcp.NewTool("jira_update_sprint",
+		mcp.WithDescription("Rename a Jira sprint or change its goal or dates. Only the given values are changed"),
+		mcp.WithString("sprint_id", mcp.Required(), mcp.Description("Numeric ID of the sprint")),
+		mcp.WithString("name", mcp.Description("New name of the sprint (optional)")),
+		mcp.WithString("goal", mcp.Description("New goal of the sprint (optional)")),
+		mcp.WithString("start_date", mcp.Description("New start as YYYY-MM-DD or an RFC 3339 timestamp (optional)")),
+		mcp.WithString("end_date", mcp.Description("New end as YYYY-MM-DD or an RFC 3339 timestamp (optional)")),
+	)
+	s.AddTool(jiraUpdateSprintTool, util.ErrorGuard(util.TypedHandler(jiraUpdateSprintTool, jiraUpdateSprintHandler)))
+
+	jiraStartSprintTool := mcp.NewTool("jira_start_sprint",
+		mcp.WithDescription("Start a future Jira sprint. A board can only have one active sprint"),
+		mcp.WithString("sprint_id", mcp.Required(), mcp.Description("Numeric ID of the sprint")),
+		mcp.WithString("start_date", mcp.Description("Start as YYYY-MM-DD or an RFC 3339 timestamp (optional, defaults to the planned start or now)")),
+		mcp.WithString("end_date", mcp.Description("End as YYYY-MM-DD or an RFC 3339 timestamp (optional, defaults to the planned end or duration_days after the start)")),
+		mcp.WithNumber("duration_days", mcp.Min(1), mcp.DefaultNumber(14), mcp.Description("Length of the sprint in days when it has no end date")),
+		mcp.WithString("goal", mcp.Description("Goal of the sprint (optional)")),
+	)
+	s.AddTool(jiraStartSprintTool, util.ErrorGuard(util.TypedHandler(jiraStartSprintTool, jiraStartSprintHandler)))
+
+	jiraCompleteSprintTool := mcp.NewTool("jira_complete_sprint",
+		mcp.WithDescription("Complete an active Jira sprint. Issues that are not done are moved to the next future sprint, the backlog or a given sprint first"),
+		mcp.WithString("sprint_id", mcp.Required(), mcp.Description("Numeric ID of the active sprint")),
+		mcp.WithString("move_incomplete_to", mcp.DefaultString("next"), mcp.Description("Where incomplete issues go: next (the next future sprint of the board, or the backlog when there is none), backlog, or the numeric ID of a sprint")),
+	)
+	s.AddTool(jiraCompleteSprintTool, util.ErrorGuard(util.TypedHandler(jiraCompleteSprintTool, jiraCompleteSprintHandler)))
+
+	jiraMoveToSprintTool := mcp.NewTool("jira_move_issues_to_sprint",
+		mcp.WithDescription("Move Jira issues into an active or future sprint, optionally ranking them before or after another issue"),
+		mcp.WithString("sprint_id", mcp.Required(), mcp.Description("Numeric ID of the sprint")),
+		mcp.WithString("issue_keys", mcp.Required(), mcp.Description("Comma separated issue keys (e.g., KP-2,KP-3)")),
+		mcp.WithString("rank_before", mcp.Description("Key of the issue to rank the moved issues before (optional)")),
+		mcp.WithString("rank_after", mcp.Description("Key of the issue to rank the moved issues after (optional)")),
+	)
+	s.AddTool(jiraMoveToSprintTool, util.ErrorGuard(util.TypedHandler(jiraMoveToSprintTool, jiraMoveToSprintHandler)))
+
+	jiraMoveToBacklogTool := mcp.NewTool("jira_move_issues_to_backlog",
+		mcp.WithDescription("Move Jira issues out of their sprints and back to the backlog"),
+		mcp.WithString("issue_keys", mcp.Required(), mcp.Description("Comma separated issue keys (e.g., KP-2,KP-3)")),
+	)
+	s.AddTool(jiraMoveToBacklogTool, util.ErrorGuard(util.TypedHandler(jiraMoveToBacklogTool, jiraMoveToBacklogHandler)))
+
+	jiraRankIssuesTool := mcp.NewTool("jira_rank_issues",
+		mcp.WithDescription("Rank Jira issues before or after another issue, keeping their given order"),
+		mcp.WithString("issue_keys", mcp.Required(), mcp.Description("Comma separated issue keys in the order they should be ranked (e.g., KP-4,KP-3)")),
+		mcp.WithString("rank_before", mcp.Description("Key of the issue to rank them before")),
+		mcp.WithString("rank_after", mcp.Description("Key of the issue to rank them after")),
+	)
+	s.AddTool(jiraRankIssuesTool, util.ErrorGuard(util.TypedHandler(jiraRankIssuesTool, jiraRankIssuesHandler)))
+}
+
+type jiraCreateSprintArgs struct {
+	BoardID   int    `json:"board_id"`
+	Name      string `json:"name"`
+	Goal      string `json:"goal"`
+	StartDate string `json:"start_date"`
+	EndDate   string `json:"end_date"`
+}
+
+type jiraUpdateSprintArgs struct {
+	SprintID  int    `json:"sprint_id"`
+	Name      string `json:"name"`
+	Goal      string `json:"goal"`
+	StartDate string `json:"start_date"`
+	EndDate   string `json:"end_date"`
+}
+
+type jiraStartSprintArgs struct {
+	SprintID     int     `json:"sprint_id"`
+	StartDate    string  `json:"start_date"`
+	EndDate      string  `json:"end_date"`
+	DurationDays float64 `json:"duration_days"`
+	Goal         string  `json:"goal"`
+}
+
+type jiraCompleteSprintArgs struct {
+	SprintID         int    `json:"sprint_id"`
+	MoveIncompleteTo string `json:"move_incomplete_to"`
+}
+
+type jiraMoveToSprintArgs struct {
+	SprintID   int    `json:"sprint_id"`
+	IssueKeys  string `json:"issue_keys"`
+	RankBefore string `json:"rank_before"`
+	RankAfter  string `json:"rank_after"`
+}
+
+type jiraMoveToBacklogArgs struct {
+	IssueKeys string `json:"issue_keys"`
+}
+
+type jiraRankIssuesArgs struct {
+	IssueKeys  string `json:"issue_keys"`
+	RankBefore string `json:"rank_before"`
+	RankAfter  string `json:"rank_after"`
+}
+
+// agileRequest calls an endpoint of the Jira Software REST API, for the calls the agile client lacks
+func agileRequest(ctx context.Context, method, endpoint string, payload, out interface{}) (*models.ResponseScheme, error) {
+	client := services.AgileClient()
+
+	request, err := client.NewRequest(ctx, method, endpoint, "", payload)
+	if err != nil {
+		return nil, err
+	}
+
+	return client.Call(request, out)
+}
+
+// parseJiraSprintDate accepts a date as YYYY-MM-DD, taken as midnight UTC, or an RFC 3339 timestamp
+func parseJiraSprintDate(name, value string) (time.Time, error) {
+	if date, err := time.Parse("2006-01-02", value); err == nil {
+		return date, nil
+	}
+	date, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s must be YYYY-MM-DD or an RFC 3339 timestamp, got %q", name, value)
+	}
+	return date, nil
+}
+
+// jiraSprintDates formats the given start and end dates for the agile API, leaving empty ones empty
+func jiraSprintDates(startDate, endDate string) (string, string, error) {
+	var start, end time.Time
+	var err error
+	if startDate != "" {
+		if start, err = parseJiraSprintDate("start_date", startDate); err != nil {
+			return "", "", err
+		}
+	}
+	if endDate != "" {
+		if end, err = parseJiraSprintDate("end_date", endDate); err != nil {
+			return "", "", err
+		}
+	}
+	if !start.IsZero() && !end.IsZero() && !end.After(start) {
+		return "", "", fmt.Errorf("end_date must be after start_date")
+	}
+	return formatJiraSprintDate(start), formatJiraSprintDate(end), nil
+}
+
+func formatJiraSprintDate(date time.Time) string {
+	if date.IsZero() {
+		return ""
+	}
+	return date.Format(jiraSprintDateLayout)
+}
+
+func formatJiraSprint(sprint *models.SprintScheme) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("ID: %d\nName: %s\nState: %s\n", sprint.ID, sprint.Name, sprint.State))
+	if sprint.Goal != "" {
+		sb.WriteString(fmt.Sprintf("Goal: %s\n", sprint.Goal))
+	}
+	if !sprint.StartDate.IsZero() {
+		sb.WriteString(fmt.Sprintf("StartDate: %s\n", sprint.StartDate.Format(time.RFC3339)))
+	}
+	if !sprint.EndDate.IsZero() {
+		sb.WriteString(fmt.Sprintf("EndDate: %s\n", sprint.EndDate.Format(time.RFC3339)))
+	}
+	if !sprint.CompleteDate.IsZero() {
+		sb.WriteString(fmt.Sprintf("CompleteDate: %s\n", sprint.CompleteDate.Format(time.RFC3339)))
+	}
+	return sb.String()
+}
+
+func jiraGetSprint(ctx context.Context, sprintID int) (*models.SprintScheme, error) {
+	sprint, response, err := services.AgileClient().Sprint.Get(ctx, sprintID)
+	if err != nil {
+		if response != nil {
+			return nil, fmt.Errorf("failed to get sprint: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
+		}
+		return nil, fmt.Errorf("failed to get sprint: %v", err)
+	}
+	return sprint, nil
+}
+
+// jiraUpdateSprint changes the given values of a sprint, leaving the others as they are
+func jiraUpdateSprint(ctx context.Context, sprintID int, payload *models.SprintPayloadScheme) (*models.SprintScheme, error) {
+	// Sprint.Start and Sprint.Close send capitalized states, which Jira Data Center rejects
+	sprint, response, err := services.AgileClient().Sprint.Path(ctx, sprintID, payload)
+	if err != nil {
+		if response != nil {
+			return nil, fmt.Errorf("failed to update sprint: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
+		}
+		return nil, fmt.Errorf("failed to update sprint: %v", err)
+	}
+	return sprint, nil
+}
+
+func jiraCreateSprintHandler(args jiraCreateSprintArgs) (*mcp.CallToolResult, error) {
+	startDate, endDate, err := jiraSprintDates(args.StartDate, args.EndDate)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second)
+	defer cancel()
+
+	sprint, response, err := services.AgileClient().Sprint.Create(ctx, &models.SprintPayloadScheme{
+		Name:          args.Name,
+		Goal:          args.Goal,
+		StartDate:     startDate,
+		EndDate:       endDate,
+		OriginBoardID: args.BoardID,
+	})
+	if err != nil {
+		if response != nil {
+			return nil, fmt.Errorf("failed to create sprint: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
+		}
+		return nil, fmt.Errorf("failed to create sprint: %v", err)
+	}
+
+	return mcp.NewToolResultText("Sprint created successfully!\n" + formatJiraSprint(sprint)), nil
+}
+
+func jiraUpdateSprintHandler(args jiraUpdateSprintArgs) (*mcp.CallToolResult, error) {
+	if args.Name == "" && args.Goal == "" && args.StartDate == "" && args.EndDate == "" {
+		return nil, fmt.Errorf("at least one of name, goal, start_date and end_date is required")
+	}
+
+	startDate, endDate, err := jiraSprintDates(args.StartDate, args.EndDate)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second)
+	defer cancel()
+
+	sprint, err := jiraUpdateSprint(ctx, args.SprintID, &models.SprintPayloadScheme{
+		Name:      args.Name,
+		Goal:      args.Goal,
+		StartDate: startDate,
+		EndDate:   endDate,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return mcp.NewToolResultText("Sprint updated successfully!\n" + formatJiraSprint(sprint)), nil
+}
+
+func jiraStartSprintHandler(args jiraStartSprintArgs) (*mcp.CallToolResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second)
+	defer cancel()
+
+	sprint, err := jiraGetSprint(ctx, args.SprintID)
+	if err != nil {
+		return nil, err
+	}
+	if sprint.State != "future" {
+		return nil, fmt.Errorf("sprint %s is %s, only future sprints can be started", sprint.Name, sprint.State)
+	}
+
+	start := sprint.StartDate
+	if args.StartDate != "" {
+		if start, err = parseJiraSprintDate("start_date", args.StartDate); err != nil {
+			return nil, err
+		}
+	} else if start.IsZero() {
+		start = time.Now().UTC()
+	}
+
+	end := sprint.EndDate
+	if args.EndDate != "" {
+		if end, err = parseJiraSprintDate("end_date", args.EndDate); err != nil {
+			return nil, err
+		}
+	} else if end.IsZero() || !end.After(start) {
+		days := args.DurationDays
+		if days <= 0 {
+			days = 14
+		}
+		end = start.AddDate(0, 0, int(days))
+	}
+	if !end.After(start) {
+		return nil, fmt.Errorf("end_date must be after the start of the sprint (%s)", start.Format(time.RFC3339))
+	}
+
+	sprint, err = jiraUpdateSprint(ctx, args.SprintID, &models.SprintPayloadScheme{
+		State:     "active",
+		Goal:      args.Goal,
+		StartDate: formatJiraSprintDate(start),
+		EndDate:   formatJiraSprintDate(end),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return mcp.NewToolResultText("Sprint started successfully!\n" + formatJiraSprint(sprint)), nil
+}
+
+func jiraCompleteSprintHandler(args jiraCompleteSprintArgs) (*mcp.CallToolResult, error) {
+	// the incomplete issues are listed and moved in batches before the sprint is closed
+	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second*4)
+	defer cancel()
+
+	sprint, err := jiraGetSprint(ctx, args.SprintID)
+	if err != nil {
+		return nil, err
+	}
+	if sprint.State != "active" {
+		return nil, fmt.Errorf("sprint %s is %s, only active sprints can be completed", sprint.Name, sprint.State)
+	}
+
+	target, err := jiraIncompleteTarget(ctx, sprint, args.MoveIncompleteTo)
+	if err != nil {
+		return nil, err
+	}
+
+	incomplete, err := jiraIncompleteSprintIssues(ctx, sprint.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	destination := "the backlog"
+	if target != nil {
+		destination = fmt.Sprintf("sprint %s (ID: %d)", target.Name, target.ID)
+	}
+	for start := 0; start < len(incomplete); start += maxMoveIssues {
+		batch := incomplete[start:min(start+maxMoveIssues, len(incomplete))]
+		if target != nil {
+			err = jiraMoveToSprint(ctx, target.ID, &models.SprintMovePayloadScheme{Issues: batch})
+		} else {
+			err = jiraMoveToBacklog(ctx, batch)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%v (%d incomplete issues were already moved to %s, the sprint is still active)", err, start, destination)
+		}
+	}
+
+	sprint, err = jiraUpdateSprint(ctx, sprint.ID, &models.SprintPayloadScheme{State: "closed"})
+	if err != nil {
+		return nil, err
+	}
+
+	var sb strings.Builder
+	sb.WriteString("Sprint completed successfully!\n")
+	sb.WriteString(formatJiraSprint(sprint))
+	if len(incomplete) == 0 {
+		sb.WriteString("\nAll issues were done.\n")
+	} else {
+		sb.WriteString(fmt.Sprintf("\n%d incomplete issues moved to %s: %s\n", len(incomplete), destination, strings.Join(incomplete, ", ")))
+	}
+
+	return mcp.NewToolResultText(sb.String()), nil
+}
+
+// jiraIncompleteTarget resolves where the incomplete issues of a sprint go, nil meaning the backlog
+func jiraIncompleteTarget(ctx context.Context, sprint *models.SprintScheme, moveTo string) (*models.SprintScheme, error) {
+	switch strings.ToLower(strings.TrimSpace(moveTo)) {
+	case "backlog":
+		return nil, nil
+	case "", "next":
+		sprints, err := jiraBoardSprints(ctx, sprint.OriginBoardID, []string{"future"})
+		if err != nil {
+			return nil, err
+		}
+		for _, next := range sprints {
+			if next.ID != sprint.ID {
+				return &models.SprintScheme{ID: next.ID, Name: next.Name, State: next.State}, nil
+			}
+		}
+		return nil, nil
+	}
+
+	id, err := strconv.Atoi(strings.TrimSpace(moveTo))
+	if err != nil {
+		return nil, fmt.Errorf("move_incomplete_to must be next, backlog or a sprint ID, got %q", moveTo)
+	}
+	if id == sprint.ID {
+		return nil, fmt.Errorf("incomplete issues cannot be moved to the sprint being completed")
+	}
+	target, err := jiraGetSprint(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if target.State == "closed" {
+		return nil, fmt.Errorf("sprint %s is closed, incomplete issues can only be moved to an active or future sprint", target.Name)
+	}
+	return target, nil
+}
+
+// jiraIncompleteSprintIssues lists the keys of the issues of a sprint whose status is not in the done category
+func jiraIncompleteSprintIssues(ctx context.Context, sprintID int) ([]string, error) {
+	var keys []string
+	for startAt := 0; ; {
+		// Sprint.Issues drops the fields of the issues, so the page is read directly
+		var page models.BoardIssuePageScheme
+		endpoint := fmt.Sprintf("rest/agile/1.0/sprint/%d/issue?fields=status&startAt=%d&maxResults=100", sprintID, startAt)
+		response, err := agileRequest(ctx, http.MethodGet, endpoint, nil, &page)
+		if err != nil {
+			if response != nil {
+				return nil, fmt.Errorf("failed to get sprint issues: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
+			}
+			return nil, fmt.Errorf("failed to get sprint issues: %v", err)
+		}
+
+		for _, issue := range page.Issues {
+			if issue.Fields != nil && issue.Fields.Status != nil && issue.Fields.Status.StatusCategory != nil && issue.Fields.Status.StatusCategory.Key == "done" {
+				continue
+			}
+			keys = append(keys, issue.Key)
+		}
+
+		startAt += len(page.Issues)
+		if len(page.Issues) == 0 || startAt >= page.Total {
+			return keys, nil
+		}
+	}
+}
+
+// jiraBoardSprints lists every sprint of a board in the given states
+func jiraBoardSprints(ctx context.Context, boardID int, states []string) ([]*models.BoardSprintScheme, error) {
+	var sprints []*models.BoardSprintScheme
+	for startAt := 0; ; {
+		page, response, err := services.AgileClient().Board.Sprints(ctx, boardID, startAt, 50, states)
+		if err != nil {
+			if response != nil {
+				return nil, fmt.Errorf("failed to get sprints: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
+			}
+			return nil, fmt.Errorf("failed to get sprints: %v", err)
+		}
+
+		sprints = append(sprints, page.Values...)
+		startAt += len(page.Values)
+		if page.IsLast || len(page.Values) == 0 {
+			return sprints, nil
+		}
+	}
+}
+
+func jiraMoveToSprint(ctx context.Context, sprintID int, payload *models.SprintMovePayloadScheme) error {
+	response, err := services.AgileClient().Sprint.Move(ctx, sprintID, payload)
+	if err != nil {
+		if response != nil {
+			return fmt.Errorf("failed to move issues to sprint: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
+		}
+		return fmt.Errorf("failed to move issues to sprint: %v", err)
+	}
+	return nil
+}
+
+func jiraMoveToBacklog(ctx context.Context, issueKeys []string) error {
+	response, err := services.AgileClient().Backlog.Move(ctx, issueKeys)
+	if err != nil {
+		if response != nil {
+			return fmt.Errorf("failed to move issues to backlog: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
+		}
+		return fmt.Errorf("failed to move issues to backlog: %v", err)
+	}
+	return nil
+}
+
+// jiraIssueKeys splits the issue_keys argument, checking the agile API's limit per request
+func jiraIssueKeys(issueKeys string) ([]string, error) {
+	keys := splitList(issueKeys)
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("issue_keys must list at least one issue key")
+	}
+	if len(keys) > maxMoveIssues {
+		return nil, fmt.Errorf("at most %d issues can be moved at once, got %d", maxMoveIssues, len(keys))
+	}
+	return keys, nil
+}
+
+func jiraMoveToSprintHandler(args jiraMoveToSprintArgs) (*mcp.CallToolResult, error) {
+	keys, err := jiraIssueKeys(args.IssueKeys)
+	if err != nil {
+		return nil, err
+	}
+	if args.RankBefore != "" && args.RankAfter != "" {
+		return nil, fmt.Errorf("only one of rank_before and rank_after can be given")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second)
+	defer cancel()
+
+	err = jiraMoveToSprint(ctx, args.SprintID, &models.SprintMovePayloadScheme{
+		Issues:          keys,
+		RankBeforeIssue: args.RankBefore,
+		RankAfterIssue:  args.RankAfter,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("Moved %s to sprint %d", strings.Join(keys, ", "), args.SprintID)), nil
+}
+
+func jiraMoveToBacklogHandler(args jiraMoveToBacklogArgs) (*mcp.CallToolResult, error) {
+	keys, err := jiraIssueKeys(args.IssueKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second)
+	defer cancel()
+
+	if err := jiraMoveToBacklog(ctx, keys); err != nil {
+		return nil, err
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("Moved %s to the backlog", strings.Join(keys, ", "))), nil
+}
+
+func jiraRankIssuesHandler(args jiraRankIssuesArgs) (*mcp.CallToolResult, error) {
+	keys, err := jiraIssueKeys(args.IssueKeys)
+	if err != nil {
+		return nil, err
+	}
+	if (args.RankBefore == "") == (args.RankAfter == "") {
+		return nil, fmt.Errorf("exactly one of rank_before and rank_after is required")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second)
+	defer cancel()
+
+	// the agile client has no rank call
+	payload := &models.SprintMovePayloadScheme{
+		Issues:          keys,
+		RankBeforeIssue: args.RankBefore,
+		RankAfterIssue:  args.RankAfter,
+	}
+	var result struct {
+		Entries []struct {
+			IssueKey string   `json:"issueKey"`
+			Status   int      `json:"status"`
+			Errors   []string `json:"errors"`
+		} `json:"entries"`
+	}
+	response, err := agileRequest(ctx, http.MethodPut, "rest/agile/1.0/issue/rank", payload, nil)
+	if err != nil {
+		if response != nil {
+			return nil, fmt.Errorf("failed to rank issues: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
+		}
+		return nil, fmt.Errorf("failed to rank issues: %v", err)
+	}
+
+	// a full success has no body, a partial one lists the issues that could not be ranked
+	if response.Bytes.Len() > 0 {
+		if err := json.Unmarshal(response.Bytes.Bytes(), &result); err != nil {
+			return nil, fmt.Errorf("failed to read rank result: %v", err)
+		}
+	}
+
+	var failed []string
+	for _, entry := range result.Entries {
+		if len(entry.Errors) > 0 {
+			failed = append(failed, fmt.Sprintf("%s: %s", entry.IssueKey, strings.Join(entry.Errors, "; ")))
+		}
+	}
+	if len(failed) > 0 {
+		return nil, fmt.Errorf("some issues could not be ranked:\n%s", strings.Join(failed, "\n"))
+	}
+
+	reference, position := args.RankBefore, "before"
+	if reference == "" {
+		reference, position = args.RankAfter, "after"
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("Ranked %s %s %s", strings.Join(keys, ", "), position, reference)), nil
+}
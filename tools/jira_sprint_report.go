@@ -0,0 +1,607 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ctreminiom/go-atlassian/pkg/infra/models"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/nguyenvanduocit/dev-kit/services"
+	"github.com/nguyenvanduocit/dev-kit/util"
+)
+
+// maxReportCandidates limits how many recently updated issues are checked for having left a sprint
+const maxReportCandidates = 200
+
+// maxVelocitySprints limits how many sprints a velocity report covers
+const maxVelocitySprints = 12
+
+// jiraChangelogLayout is the timestamp format of changelog entries and issue dates
+const jiraChangelogLayout = "2006-01-02T15:04:05.000-0700"
+
+func registerJiraSprintReportTools(s *server.MCPServer) {
+	jiraSprintReportTool := mcp.NewTool("jira_sprint_report",
+		mcp.WithDescription("Report how a Jira sprint went: committed vs completed issues and story points, scope added or removed after the start, issues carried over and a breakdown per assignee. Built from the issue changelogs"),
+		mcp.WithString("sprint_id", mcp.Required(), mcp.Description("Numeric ID of an active or closed sprint, see jira_list_sprints")),
+	)
+	s.AddTool(jiraSprintReportTool, util.ErrorGuard(util.TypedHandler(jiraSprintReportTool, jiraSprintReportHandler)))
+
+	jiraVelocityReportTool := mcp.NewTool("jira_velocity_report",
+		mcp.WithDescription("Show the velocity trend of a Jira board: committed and completed story points of its last closed sprints, their average and whether velocity is rising or falling"),
+		mcp.WithString("board_id", mcp.Required(), mcp.Description("Numeric ID of the Jira board, see jira_list_boards")),
+		mcp.WithNumber("sprint_count", mcp.Min(2), mcp.Max(maxVelocitySprints), mcp.DefaultNumber(6), mcp.Description("Number of most recently closed sprints to include")),
+	)
+	s.AddTool(jiraVelocityReportTool, util.ErrorGuard(util.TypedHandler(jiraVelocityReportTool, jiraVelocityReportHandler)))
+}
+
+type jiraSprintReportArgs struct {
+	SprintID int `json:"sprint_id"`
+}
+
+type jiraVelocityReportArgs struct {
+	BoardID     int `json:"board_id"`
+	SprintCount int `json:"sprint_count"`
+}
+
+// jiraSprintOutcome is what happened to one issue during a sprint
+type jiraSprintOutcome struct {
+	Key      string
+	Summary  string
+	Assignee string
+	// Committed issues were in the sprint when it started, added ones joined later
+	Committed bool
+	Added     bool
+	Removed   bool
+	Completed bool
+	// StartPoints is the estimate at the start of the sprint, EndPoints at its end
+	StartPoints float64
+	EndPoints   float64
+	// MovedTo names the sprint an incomplete issue went to when the sprint was completed
+	MovedTo string
+}
+
+type jiraSprintReport struct {
+	Sprint   *models.SprintScheme
+	Start    time.Time
+	End      time.Time
+	Points   string // name of the estimation field, empty when the board counts issues
+	Outcomes []*jiraSprintOutcome
+}
+
+// jiraSprintTotals sums the outcomes of a sprint, in issues and in points
+type jiraSprintTotals struct {
+	Committed, Added, Removed, Completed, Incomplete                               int
+	CommittedPoints, AddedPoints, RemovedPoints, CompletedPoints, IncompletePoints float64
+}
+
+func (r *jiraSprintReport) totals(outcomes []*jiraSprintOutcome) jiraSprintTotals {
+	var t jiraSprintTotals
+	for _, o := range outcomes {
+		if o.Committed {
+			t.Committed++
+			t.CommittedPoints += o.StartPoints
+		}
+		if o.Added {
+			t.Added++
+			t.AddedPoints += o.EndPoints
+		}
+		switch {
+		case o.Removed:
+			t.Removed++
+			t.RemovedPoints += o.EndPoints
+		case o.Completed:
+			t.Completed++
+			t.CompletedPoints += o.EndPoints
+		default:
+			t.Incomplete++
+			t.IncompletePoints += o.EndPoints
+		}
+	}
+	return t
+}
+
+func jiraSprintReportHandler(args jiraSprintReportArgs) (*mcp.CallToolResult, error) {
+	// the sprint, its board, its issues, recently updated issues and the project statuses are read
+	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second*5)
+	defer cancel()
+
+	sprint, err := jiraGetSprint(ctx, args.SprintID)
+	if err != nil {
+		return nil, err
+	}
+	layout, err := jiraGetBoardLayout(ctx, sprint.OriginBoardID)
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := buildJiraSprintReport(ctx, sprint, layout, map[string]map[string]*models.ProjectStatusDetailsScheme{})
+	if err != nil {
+		return nil, err
+	}
+
+	return mcp.NewToolResultText(formatJiraSprintReport(report)), nil
+}
+
+func jiraVelocityReportHandler(args jiraVelocityReportArgs) (*mcp.CallToolResult, error) {
+	count := args.SprintCount
+	if count <= 0 {
+		count = 6
+	}
+
+	// every sprint needs its issues and the recently updated issues of the board's project
+	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second*time.Duration(3+2*count))
+	defer cancel()
+
+	layout, err := jiraGetBoardLayout(ctx, args.BoardID)
+	if err != nil {
+		return nil, err
+	}
+	closed, err := jiraBoardSprints(ctx, args.BoardID, []string{"closed"})
+	if err != nil {
+		return nil, err
+	}
+	if len(closed) == 0 {
+		return mcp.NewToolResultText("The board has no closed sprints yet."), nil
+	}
+
+	sort.SliceStable(closed, func(i, j int) bool {
+		return jiraSprintEnd(closed[i].CompleteDate, closed[i].EndDate).Before(jiraSprintEnd(closed[j].CompleteDate, closed[j].EndDate))
+	})
+	if len(closed) > count {
+		closed = closed[len(closed)-count:]
+	}
+
+	categories := map[string]map[string]*models.ProjectStatusDetailsScheme{}
+	var reports []*jiraSprintReport
+	for _, sprint := range closed {
+		report, err := buildJiraSprintReport(ctx, (*models.SprintScheme)(sprint), layout, categories)
+		if err != nil {
+			return nil, fmt.Errorf("sprint %s: %v", sprint.Name, err)
+		}
+		reports = append(reports, report)
+	}
+
+	return mcp.NewToolResultText(formatJiraVelocityReport(args.BoardID, layout, reports)), nil
+}
+
+func jiraSprintEnd(completeDate, endDate time.Time) time.Time {
+	if !completeDate.IsZero() {
+		return completeDate
+	}
+	return endDate
+}
+
+// buildJiraSprintReport replays the changelogs of the issues that were in the sprint at any time.
+// categories caches the status categories of projects across sprints.
+func buildJiraSprintReport(ctx context.Context, sprint *models.SprintScheme, layout *jiraBoardLayout, categories map[string]map[string]*models.ProjectStatusDetailsScheme) (*jiraSprintReport, error) {
+	if sprint.State == "future" || sprint.StartDate.IsZero() {
+		return nil, fmt.Errorf("sprint %s has not started yet", sprint.Name)
+	}
+
+	report := &jiraSprintReport{Sprint: sprint, Start: sprint.StartDate, End: time.Now()}
+	if sprint.State == "closed" {
+		// incomplete issues are moved on when the sprint is completed, so its end is taken just before
+		report.End = jiraSprintEnd(sprint.CompleteDate, sprint.EndDate).Add(-time.Minute)
+	}
+	if layout.EstimationField != "" {
+		report.Points = layout.EstimationName
+	}
+
+	fields := []string{"summary", "status", "assignee", "created", "project"}
+	if layout.EstimationField != "" {
+		fields = append(fields, layout.EstimationField)
+	}
+
+	// the issues in the sprint now, and those that left it, which only their changelogs tell
+	issues, estimates, _, err := jiraBoardIssues(layout, maxBoardIssues, func(opts *models.IssueOptionScheme, startAt, maxResults int) (*models.BoardIssuePageScheme, *models.ResponseScheme, error) {
+		opts.Fields, opts.Expand = fields, []string{"changelog"}
+		return services.AgileClient().Board.IssuesBySprint(ctx, sprint.OriginBoardID, sprint.ID, opts, startAt, maxResults)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sprint issues: %v", err)
+	}
+	listed := map[string]bool{}
+	for _, issue := range issues {
+		listed[issue.Key] = true
+	}
+
+	if layout.ProjectKey != "" {
+		jql := fmt.Sprintf("project = %q AND updated >= %q ORDER BY updated DESC", layout.ProjectKey, sprint.StartDate.UTC().Format("2006-01-02 15:04"))
+		result, response, err := services.JiraClient().Issue.Search.Get(ctx, jql, fields, []string{"changelog"}, 0, maxReportCandidates, "")
+		if err != nil {
+			if response != nil {
+				return nil, fmt.Errorf("failed to search issues: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
+			}
+			return nil, fmt.Errorf("failed to search issues: %v", err)
+		}
+		if layout.EstimationField != "" {
+			values, _ := models.ParseFloatCustomFields(response.Bytes, layout.EstimationField)
+			for key, value := range values {
+				estimates[key] = value
+			}
+		}
+		for _, issue := range result.Issues {
+			if !listed[issue.Key] && len(jiraSprintChanges(issue, sprint)) > 0 {
+				issues = append(issues, issue)
+			}
+		}
+	}
+
+	for _, issue := range issues {
+		statuses, err := jiraProjectCategories(ctx, issue, categories)
+		if err != nil {
+			return nil, err
+		}
+		if outcome := jiraIssueSprintOutcome(report, issue, listed[issue.Key], estimates, layout, statuses); outcome != nil {
+			report.Outcomes = append(report.Outcomes, outcome)
+		}
+	}
+
+	return report, nil
+}
+
+// jiraProjectCategories returns the statuses of the issue's project, reading them once per project
+func jiraProjectCategories(ctx context.Context, issue *models.IssueSchemeV2, cache map[string]map[string]*models.ProjectStatusDetailsScheme) (map[string]*models.ProjectStatusDetailsScheme, error) {
+	if issue.Fields == nil || issue.Fields.Project == nil {
+		return nil, nil
+	}
+	key := issue.Fields.Project.Key
+	if statuses, ok := cache[key]; ok {
+		return statuses, nil
+	}
+	statuses, err := jiraStatusCategories(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	cache[key] = statuses
+	return statuses, nil
+}
+
+// jiraFieldChange is a changelog item with the time it was made
+type jiraFieldChange struct {
+	At   time.Time
+	Item *models.IssueChangelogHistoryItemScheme
+}
+
+// jiraFieldChanges returns the changes of the matching fields, oldest first
+func jiraFieldChanges(issue *models.IssueSchemeV2, match func(*models.IssueChangelogHistoryItemScheme) bool) []jiraFieldChange {
+	if issue.Changelog == nil {
+		return nil
+	}
+
+	var changes []jiraFieldChange
+	for _, history := range issue.Changelog.Histories {
+		at, err := time.Parse(jiraChangelogLayout, history.Created)
+		if err != nil {
+			continue
+		}
+		for _, item := range history.Items {
+			if match(item) {
+				changes = append(changes, jiraFieldChange{At: at, Item: item})
+			}
+		}
+	}
+	sort.SliceStable(changes, func(i, j int) bool { return changes[i].At.Before(changes[j].At) })
+	return changes
+}
+
+// jiraChangeAfter returns the first change made after t, whose from value is the value the field had at t
+func jiraChangeAfter(changes []jiraFieldChange, t time.Time) *models.IssueChangelogHistoryItemScheme {
+	for _, change := range changes {
+		if change.At.After(t) {
+			return change.Item
+		}
+	}
+	return nil
+}
+
+// jiraSprintChanges returns the changes of the Sprint field that involve the sprint
+func jiraSprintChanges(issue *models.IssueSchemeV2, sprint *models.SprintScheme) []jiraFieldChange {
+	return jiraFieldChanges(issue, func(item *models.IssueChangelogHistoryItemScheme) bool {
+		if item.Field != "Sprint" {
+			return false
+		}
+		return jiraSprintValueHas(item.From, item.FromString, sprint) || jiraSprintValueHas(item.To, item.ToString, sprint)
+	})
+}
+
+// jiraSprintValueHas tells whether a Sprint field value, given as comma separated IDs and names, holds the sprint
+func jiraSprintValueHas(ids, names string, sprint *models.SprintScheme) bool {
+	if strings.TrimSpace(ids) != "" {
+		for _, id := range strings.Split(ids, ",") {
+			if strings.TrimSpace(id) == strconv.Itoa(sprint.ID) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, name := range strings.Split(names, ",") {
+		if strings.TrimSpace(name) == sprint.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// jiraIssueSprintOutcome works out what happened to an issue during the sprint, nil when it was never in it
+func jiraIssueSprintOutcome(report *jiraSprintReport, issue *models.IssueSchemeV2, listed bool, estimates map[string]float64, layout *jiraBoardLayout, statuses map[string]*models.ProjectStatusDetailsScheme) *jiraSprintOutcome {
+	if issue.Fields == nil {
+		return nil
+	}
+	sprint := report.Sprint
+	created, _ := time.Parse(jiraChangelogLayout, issue.Fields.Created)
+	sprintChanges := jiraSprintChanges(issue, sprint)
+
+	memberAt := func(t time.Time) bool {
+		if !created.IsZero() && created.After(t) {
+			return false
+		}
+		if item := jiraChangeAfter(sprintChanges, t); item != nil {
+			return jiraSprintValueHas(item.From, item.FromString, sprint)
+		}
+		if len(sprintChanges) > 0 {
+			last := sprintChanges[len(sprintChanges)-1].Item
+			return jiraSprintValueHas(last.To, last.ToString, sprint)
+		}
+		return listed
+	}
+
+	addedDuring := false
+	for _, change := range sprintChanges {
+		if change.At.After(report.Start) && !change.At.After(report.End) && jiraSprintValueHas(change.Item.To, change.Item.ToString, sprint) {
+			addedDuring = true
+		}
+	}
+	// an issue created in the sprint has no Sprint change
+	if listed && len(sprintChanges) == 0 && created.After(report.Start) {
+		addedDuring = true
+	}
+
+	outcome := &jiraSprintOutcome{Key: issue.Key, Summary: issue.Fields.Summary, Assignee: "Unassigned"}
+	if issue.Fields.Assignee != nil {
+		outcome.Assignee = issue.Fields.Assignee.DisplayName
+	}
+	outcome.Committed = memberAt(report.Start)
+	outcome.Added = !outcome.Committed && addedDuring
+	if !outcome.Committed && !outcome.Added {
+		return nil
+	}
+	inAtEnd := memberAt(report.End)
+	outcome.Removed = !inAtEnd
+
+	status := ""
+	if issue.Fields.Status != nil {
+		status = issue.Fields.Status.Name
+	}
+	statusChanges := jiraFieldChanges(issue, func(item *models.IssueChangelogHistoryItemScheme) bool { return item.Field == "status" })
+	if item := jiraChangeAfter(statusChanges, report.End); item != nil {
+		status = item.FromString
+	}
+	outcome.Completed = inAtEnd && jiraStatusCategory(statuses[strings.ToLower(status)]) == "done"
+
+	if layout.EstimationField != "" {
+		estimateChanges := jiraFieldChanges(issue, func(item *models.IssueChangelogHistoryItemScheme) bool {
+			return item.FieldID == layout.EstimationField || (item.FieldID == "" && strings.EqualFold(item.Field, layout.EstimationName))
+		})
+		estimateAt := func(t time.Time) float64 {
+			if item := jiraChangeAfter(estimateChanges, t); item != nil {
+				value, _ := strconv.ParseFloat(strings.TrimSpace(item.FromString), 64)
+				return value
+			}
+			return estimates[issue.Key]
+		}
+		outcome.StartPoints = estimateAt(report.Start)
+		outcome.EndPoints = estimateAt(report.End)
+	}
+
+	// incomplete issues of a completed sprint go to the sprint added by the change made at completion
+	if inAtEnd && !outcome.Completed && sprint.State == "closed" {
+		for _, change := range sprintChanges {
+			if change.At.After(report.End) {
+				outcome.MovedTo = jiraAddedSprint(change.Item.FromString, change.Item.ToString)
+				break
+			}
+		}
+	}
+
+	return outcome
+}
+
+// jiraAddedSprint returns the sprint names in to that are not in from
+func jiraAddedSprint(from, to string) string {
+	before := map[string]bool{}
+	for _, name := range strings.Split(from, ",") {
+		before[strings.TrimSpace(name)] = true
+	}
+	var added []string
+	for _, name := range strings.Split(to, ",") {
+		if name = strings.TrimSpace(name); name != "" && !before[name] {
+			added = append(added, name)
+		}
+	}
+	if len(added) == 0 {
+		return "the backlog"
+	}
+	return strings.Join(added, ", ")
+}
+
+// formatJiraAmount formats a number of issues, with their points when the board estimates in points
+func formatJiraAmount(report *jiraSprintReport, issues int, points float64) string {
+	amount := fmt.Sprintf("%d issues", issues)
+	if issues == 1 {
+		amount = "1 issue"
+	}
+	if report.Points != "" {
+		amount += ", " + formatJiraEstimate(points)
+	}
+	return amount
+}
+
+func formatJiraSprintReport(report *jiraSprintReport) string {
+	sprint := report.Sprint
+	t := report.totals(report.Outcomes)
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Sprint report: %s (ID: %d, %s)\n", sprint.Name, sprint.ID, sprint.State))
+	if sprint.Goal != "" {
+		sb.WriteString(fmt.Sprintf("Goal: %s\n", sprint.Goal))
+	}
+	end := "now"
+	if sprint.State == "closed" {
+		end = jiraSprintEnd(sprint.CompleteDate, sprint.EndDate).Format("2006-01-02")
+	}
+	sb.WriteString(fmt.Sprintf("Period: %s to %s\n", report.Start.Format("2006-01-02"), end))
+	if report.Points != "" {
+		sb.WriteString(fmt.Sprintf("Estimation: %s\n", report.Points))
+	}
+
+	sb.WriteString("\nSummary:\n")
+	sb.WriteString(fmt.Sprintf("- Committed at start: %s\n", formatJiraAmount(report, t.Committed, t.CommittedPoints)))
+	sb.WriteString(fmt.Sprintf("- Added after start: %s\n", formatJiraAmount(report, t.Added, t.AddedPoints)))
+	sb.WriteString(fmt.Sprintf("- Removed: %s\n", formatJiraAmount(report, t.Removed, t.RemovedPoints)))
+	sb.WriteString(fmt.Sprintf("- Completed: %s\n", formatJiraAmount(report, t.Completed, t.CompletedPoints)))
+	label := "Not completed"
+	if sprint.State == "closed" {
+		label = "Carried over"
+	}
+	sb.WriteString(fmt.Sprintf("- %s: %s\n", label, formatJiraAmount(report, t.Incomplete, t.IncompletePoints)))
+	if report.Points != "" && t.CommittedPoints > 0 {
+		sb.WriteString(fmt.Sprintf("- Completed vs committed: %.0f%%\n", 100*t.CompletedPoints/t.CommittedPoints))
+	} else if t.Committed > 0 {
+		sb.WriteString(fmt.Sprintf("- Completed vs committed: %.0f%%\n", 100*float64(t.Completed)/float64(t.Committed)))
+	}
+
+	sections := []struct {
+		title string
+		match func(*jiraSprintOutcome) bool
+	}{
+		{"Completed", func(o *jiraSprintOutcome) bool { return o.Completed }},
+		{label, func(o *jiraSprintOutcome) bool { return !o.Completed && !o.Removed }},
+		{"Added after start", func(o *jiraSprintOutcome) bool { return o.Added }},
+		{"Removed", func(o *jiraSprintOutcome) bool { return o.Removed }},
+	}
+	for _, section := range sections {
+		var lines []string
+		for _, o := range report.Outcomes {
+			if !section.match(o) {
+				continue
+			}
+			details := []string{o.Assignee}
+			if report.Points != "" {
+				if o.Committed && o.StartPoints != o.EndPoints {
+					details = append(details, fmt.Sprintf("%s, re-estimated from %s", formatJiraEstimate(o.EndPoints), strconv.FormatFloat(o.StartPoints, 'f', -1, 64)))
+				} else {
+					details = append(details, formatJiraEstimate(o.EndPoints))
+				}
+			}
+			if o.MovedTo != "" && section.title == label {
+				details = append(details, "moved to "+o.MovedTo)
+			}
+			lines = append(lines, fmt.Sprintf("- %s %s (%s)\n", o.Key, o.Summary, strings.Join(details, ", ")))
+		}
+		if len(lines) > 0 {
+			sb.WriteString(fmt.Sprintf("\n%s:\n%s", section.title, strings.Join(lines, "")))
+		}
+	}
+
+	byAssignee := map[string][]*jiraSprintOutcome{}
+	for _, o := range report.Outcomes {
+		byAssignee[o.Assignee] = append(byAssignee[o.Assignee], o)
+	}
+	assignees := make([]string, 0, len(byAssignee))
+	for assignee := range byAssignee {
+		assignees = append(assignees, assignee)
+	}
+	sort.Strings(assignees)
+
+	sb.WriteString("\nBy assignee:\n")
+	for _, assignee := range assignees {
+		at := report.totals(byAssignee[assignee])
+		sb.WriteString(fmt.Sprintf("- %s: committed %s; added %d; completed %s; %s %d\n", assignee,
+			formatJiraAmount(report, at.Committed, at.CommittedPoints), at.Added,
+			formatJiraAmount(report, at.Completed, at.CompletedPoints), strings.ToLower(label), at.Incomplete))
+	}
+
+	return sb.String()
+}
+
+func formatJiraVelocityReport(boardID int, layout *jiraBoardLayout, reports []*jiraSprintReport) string {
+	unit := "issues"
+	if layout.EstimationField != "" {
+		unit = "points"
+	}
+	// velocity counts what was completed, in points when the board estimates in points
+	value := func(issues int, points float64) float64 {
+		if layout.EstimationField != "" {
+			return points
+		}
+		return float64(issues)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Velocity of board %d over the last %d closed sprints, in %s\n\n", boardID, len(reports), unit))
+	sb.WriteString("| Sprint | Completed on | Committed | Added | Removed | Completed | Completed vs committed |\n")
+	sb.WriteString("|---|---|---|---|---|---|---|\n")
+
+	var completed []float64
+	for _, report := range reports {
+		t := report.totals(report.Outcomes)
+		committed := value(t.Committed, t.CommittedPoints)
+		done := value(t.Completed, t.CompletedPoints)
+		completed = append(completed, done)
+
+		ratio := "-"
+		if committed > 0 {
+			ratio = fmt.Sprintf("%.0f%%", 100*done/committed)
+		}
+		sb.WriteString(fmt.Sprintf("| %s | %s | %s | %s | %s | %s | %s |\n", report.Sprint.Name,
+			jiraSprintEnd(report.Sprint.CompleteDate, report.Sprint.EndDate).Format("2006-01-02"),
+			strconv.FormatFloat(committed, 'f', -1, 64), strconv.FormatFloat(value(t.Added, t.AddedPoints), 'f', -1, 64),
+			strconv.FormatFloat(value(t.Removed, t.RemovedPoints), 'f', -1, 64), strconv.FormatFloat(done, 'f', -1, 64), ratio))
+	}
+
+	var sum float64
+	for _, v := range completed {
+		sum += v
+	}
+	sb.WriteString(fmt.Sprintf("\nAverage velocity: %.1f %s per sprint\n", sum/float64(len(completed)), unit))
+	if len(completed) >= 3 {
+		recent := (completed[len(completed)-1] + completed[len(completed)-2] + completed[len(completed)-3]) / 3
+		sb.WriteString(fmt.Sprintf("Average of the last 3 sprints: %.1f %s\n", recent, unit))
+	}
+	if len(completed) >= 2 {
+		slope := jiraTrendSlope(completed)
+		trend := "steady"
+		if mean := sum / float64(len(completed)); mean > 0 && slope > 0.05*mean {
+			trend = "rising"
+		} else if mean > 0 && slope < -0.05*mean {
+			trend = "falling"
+		}
+		sb.WriteString(fmt.Sprintf("Trend: %s (%+.1f %s per sprint)\n", trend, slope, unit))
+	}
+
+	return sb.String()
+}
+
+// jiraTrendSlope fits a line through the values by least squares and returns its slope
+func jiraTrendSlope(values []float64) float64 {
+	n := float64(len(values))
+	var sumX, sumY, sumXY, sumXX float64
+	for i, y := range values {
+		x := float64(i)
+		sumX += x
+		sumY += y
+		sumXY += x * y
+		sumXX += x * x
+	}
+	denominator := n*sumXX - sumX*sumX
+	if denominator == 0 {
+		return 0
+	}
+	return (n*sumXY - sumX*sumY) / denominator
+}
@@ -0,0 +1,320 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ctreminiom/go-atlassian/pkg/infra/models"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/nguyenvanduocit/dev-kit/services"
+	"github.com/nguyenvanduocit/dev-kit/util/markup"
+)
+
+// maxTransitionHops limits how many transitions are made to reach a status
+const maxTransitionHops = 6
+
+type jiraTransitionIssueArgs struct {
+	IssueKey     string `json:"issue_key"`
+	Status       string `json:"status"`
+	TransitionID string `json:"transition_id"`
+	Comment      string `json:"comment"`
+	Resolution   string `json:"resolution"`
+	Fields       string `json:"fields"`
+}
+
+// jiraTransition is an available transition with the fields of its screen
+type jiraTransition struct {
+	ID     string                          `json:"id"`
+	Name   string                          `json:"name"`
+	To     *models.StatusScheme            `json:"to"`
+	Fields map[string]*jiraCreateMetaField `json:"fields"`
+}
+
+// statusCategoryRank orders status categories along the usual flow of an issue
+var statusCategoryRank = map[string]int{"new": 0, "indeterminate": 1, "done": 2}
+
+func jiraTransitionIssueHandler(args jiraTransitionIssueArgs) (*mcp.CallToolResult, error) {
+	if args.Status == "" && args.TransitionID == "" {
+		return nil, fmt.Errorf("either status or transition_id is required")
+	}
+
+	values, err := parseJiraFieldsArgument("fields", args.Fields)
+	if err != nil {
+		return nil, err
+	}
+	if args.Resolution != "" {
+		values["resolution"] = args.Resolution
+	}
+
+	// reaching a status may take several transitions, each needing two requests
+	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second*maxTransitionHops)
+	defer cancel()
+
+	if args.Status == "" {
+		transitions, err := jiraIssueTransitions(ctx, args.IssueKey)
+		if err != nil {
+			return nil, err
+		}
+		for _, transition := range transitions {
+			if transition.ID == args.TransitionID {
+				if err := jiraDoTransition(ctx, args.IssueKey, transition, values, args.Comment); err != nil {
+					return nil, err
+				}
+				return mcp.NewToolResultText("Issue transition completed successfully"), nil
+			}
+		}
+		return nil, fmt.Errorf("transition %s is not available for %s, available transitions: %s", args.TransitionID, args.IssueKey, describeJiraTransitions(transitions))
+	}
+
+	client := services.JiraClient()
+	issue, response, err := client.Issue.Get(ctx, args.IssueKey, []string{"status", "project"}, nil)
+	if err != nil {
+		if response != nil {
+			return nil, fmt.Errorf("failed to get issue: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
+		}
+		return nil, fmt.Errorf("failed to get issue: %v", err)
+	}
+
+	from := issue.Fields.Status.Name
+	if strings.EqualFold(from, args.Status) {
+		return mcp.NewToolResultText(fmt.Sprintf("%s is already in status %s", args.IssueKey, from)), nil
+	}
+
+	categories, err := jiraStatusCategories(ctx, issue.Fields.Project.Key)
+	if err != nil {
+		return nil, err
+	}
+	target, ok := categories[strings.ToLower(args.Status)]
+	if !ok {
+		var names []string
+		for _, status := range categories {
+			names = append(names, status.Name)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("status %q does not exist in %s, available statuses: %s", args.Status, issue.Fields.Project.Key, strings.Join(names, ", "))
+	}
+
+	path, err := jiraMoveToStatus(ctx, args.IssueKey, from, target, categories, values, args.Comment)
+	if err != nil {
+		return nil, err
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("%s moved from %s to %s via %s", args.IssueKey, from, target.Name, strings.Join(path, " → "))), nil
+}
+
+// jiraMoveToStatus makes transitions until the issue reaches target, picking at every step the one
+// that leads closest to it. values and comment go with the last transition. It returns the
+// transitions made, which on failure are part of the error as the issue was left in between.
+func jiraMoveToStatus(ctx context.Context, issueKey, from string, target *models.ProjectStatusDetailsScheme, categories map[string]*models.ProjectStatusDetailsScheme, values map[string]interface{}, comment string) ([]string, error) {
+	visited := map[string]bool{strings.ToLower(from): true}
+	var path []string
+	for len(path) < maxTransitionHops {
+		transitions, err := jiraIssueTransitions(ctx, issueKey)
+		if err != nil {
+			return path, jiraPartialTransitionError(path, err)
+		}
+
+		next := nextJiraTransition(transitions, target, visited, categories)
+		if next == nil {
+			return path, jiraPartialTransitionError(path, fmt.Errorf("no transition leads towards %s, available transitions: %s", target.Name, describeJiraTransitions(transitions)))
+		}
+
+		final := strings.EqualFold(next.To.Name, target.Name)
+		stepValues, stepComment := values, comment
+		if !final {
+			// the comment and fields are meant for the last transition; earlier ones only get the fields their screens need
+			stepValues, stepComment = jiraScreenValues(next, values), ""
+		}
+		if err := jiraDoTransition(ctx, issueKey, next, stepValues, stepComment); err != nil {
+			return path, jiraPartialTransitionError(path, err)
+		}
+
+		path = append(path, fmt.Sprintf("%s (to %s)", next.Name, next.To.Name))
+		visited[strings.ToLower(next.To.Name)] = true
+		if final {
+			return path, nil
+		}
+	}
+
+	return path, jiraPartialTransitionError(path, fmt.Errorf("%s was not reached within %d transitions", target.Name, maxTransitionHops))
+}
+
+// jiraPartialTransitionError reports the transitions made before err, as the issue was left in between
+func jiraPartialTransitionError(path []string, err error) error {
+	if len(path) == 0 {
+		return err
+	}
+	return fmt.Errorf("%v (the issue was already moved via %s)", err, strings.Join(path, " → "))
+}
+
+// nextJiraTransition returns the transition to the target status, or else the one to an unvisited
+// status whose category is closest to the target's
+func nextJiraTransition(transitions []*jiraTransition, target *models.ProjectStatusDetailsScheme, visited map[string]bool, categories map[string]*models.ProjectStatusDetailsScheme) *jiraTransition {
+	var best *jiraTransition
+	bestDistance := 0
+	for _, transition := range transitions {
+		if transition.To == nil {
+			continue
+		}
+		if strings.EqualFold(transition.To.Name, target.Name) {
+			return transition
+		}
+		if visited[strings.ToLower(transition.To.Name)] {
+			continue
+		}
+
+		distance := statusCategoryRank[jiraStatusCategory(target)] - statusCategoryRank[jiraStatusCategory(categories[strings.ToLower(transition.To.Name)])]
+		if distance < 0 {
+			distance = -distance
+		}
+		if best == nil || distance < bestDistance {
+			best, bestDistance = transition, distance
+		}
+	}
+	return best
+}
+
+func jiraStatusCategory(status *models.ProjectStatusDetailsScheme) string {
+	if status == nil || status.StatusCategory == nil {
+		return "indeterminate"
+	}
+	return status.StatusCategory.Key
+}
+
+// jiraStatusCategories maps the lower-cased names of the statuses used in a project to the statuses
+func jiraStatusCategories(ctx context.Context, projectKey string) (map[string]*models.ProjectStatusDetailsScheme, error) {
+	client := services.JiraClient()
+
+	issueTypes, response, err := client.Project.Statuses(ctx, projectKey)
+	if err != nil {
+		if response != nil {
+			return nil, fmt.Errorf("failed to get statuses: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
+		}
+		return nil, fmt.Errorf("failed to get statuses: %v", err)
+	}
+
+	statuses := map[string]*models.ProjectStatusDetailsScheme{}
+	for _, issueType := range issueTypes {
+		for _, status := range issueType.Statuses {
+			statuses[strings.ToLower(status.Name)] = status
+		}
+	}
+	return statuses, nil
+}
+
+// jiraIssueTransitions lists the transitions available to an issue, including their screen fields
+func jiraIssueTransitions(ctx context.Context, issueKey string) ([]*jiraTransition, error) {
+	var page struct {
+		Transitions []*jiraTransition `json:"transitions"`
+	}
+	endpoint := fmt.Sprintf("rest/api/2/issue/%s/transitions?expand=transitions.fields", issueKey)
+	response, err := jiraRequest(ctx, http.MethodGet, endpoint, nil, &page)
+	if err != nil {
+		if response != nil {
+			return nil, fmt.Errorf("failed to get transitions: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
+		}
+		return nil, fmt.Errorf("failed to get transitions: %v", err)
+	}
+
+	for _, transition := range page.Transitions {
+		for id, field := range transition.Fields {
+			field.FieldID = id
+		}
+	}
+	return page.Transitions, nil
+}
+
+func describeJiraTransitions(transitions []*jiraTransition) string {
+	if len(transitions) == 0 {
+		return "none"
+	}
+	var names []string
+	for _, transition := range transitions {
+		to := ""
+		if transition.To != nil {
+			to = " to " + transition.To.Name
+		}
+		names = append(names, fmt.Sprintf("%s (ID: %s%s)", transition.Name, transition.ID, to))
+	}
+	return strings.Join(names, ", ")
+}
+
+// jiraScreenField finds a field of the transition screen by ID or name
+func jiraScreenField(transition *jiraTransition, nameOrID string) *jiraCreateMetaField {
+	if field, ok := transition.Fields[nameOrID]; ok {
+		return field
+	}
+	for _, field := range transition.Fields {
+		if strings.EqualFold(field.Name, nameOrID) {
+			return field
+		}
+	}
+	return nil
+}
+
+// jiraScreenValues keeps the values of fields that are on the transition screen
+func jiraScreenValues(transition *jiraTransition, values map[string]interface{}) map[string]interface{} {
+	kept := map[string]interface{}{}
+	for name, value := range values {
+		if jiraScreenField(transition, name) != nil {
+			kept[name] = value
+		}
+	}
+	return kept
+}
+
+// jiraDoTransition makes a transition, checking the required fields of its screen first
+func jiraDoTransition(ctx context.Context, issueKey string, transition *jiraTransition, values map[string]interface{}, comment string) error {
+	fields := map[string]interface{}{}
+	for name, value := range values {
+		field := jiraScreenField(transition, name)
+		if field == nil {
+			return fmt.Errorf("field %q is not on the screen of transition %s", name, transition.Name)
+		}
+		converted, err := jiraFieldValue(field.Schema, value)
+		if err != nil {
+			return fmt.Errorf("invalid value for field %s: %v", field.Name, err)
+		}
+		fields[field.FieldID] = converted
+	}
+
+	var missing []string
+	for id, field := range transition.Fields {
+		if _, ok := fields[id]; field.Required && !field.HasDefaultValue && !ok {
+			missing = append(missing, strings.TrimSuffix(formatJiraCreateMetaField(field), "\n"))
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("transition %s requires these fields, pass them with resolution or fields:\n%s", transition.Name, strings.Join(missing, "\n"))
+	}
+
+	payload := map[string]interface{}{
+		"transition": map[string]interface{}{"id": transition.ID},
+	}
+	if len(fields) > 0 {
+		payload["fields"] = fields
+	}
+	if comment != "" {
+		payload["update"] = map[string]interface{}{
+			"comment": []interface{}{
+				map[string]interface{}{"add": map[string]interface{}{"body": markup.MarkdownToWiki(comment)}},
+			},
+		}
+	}
+
+	// Issue.Move drops the transition ID as soon as options are given, so the transition is posted directly
+	endpoint := fmt.Sprintf("rest/api/2/issue/%s/transitions", issueKey)
+	response, err := jiraRequest(ctx, http.MethodPost, endpoint, payload, nil)
+	if err != nil {
+		if response != nil {
+			return fmt.Errorf("transition failed: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
+		}
+		return fmt.Errorf("transition failed: %v", err)
+	}
+
+	return nil
+}
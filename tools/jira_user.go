@@ -0,0 +1,355 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ctreminiom/go-atlassian/pkg/infra/models"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/nguyenvanduocit/dev-kit/services"
+	"github.com/nguyenvanduocit/dev-kit/util"
+)
+
+// maxUserCandidates bounds the search behind resolving a name to a single user
+const maxUserCandidates = 20
+
+func registerJiraUserTools(s *server.MCPServer) {
+	jiraSearchUsersTool := mcp.NewTool("jira_search_users",
+		mcp.WithDescription("Find Jira users by display name or email and return their account IDs. With project_key or issue_key, only users who can be assigned issues there are returned"),
+		mcp.WithString("query", mcp.Required(), mcp.Description("Part of the user's display name or email (e.g., minh, minh@example.com)")),
+		mcp.WithString("project_key", mcp.Description("Only return users who can be assigned issues in this project (optional, e.g., KP)")),
+		mcp.WithString("issue_key", mcp.Description("Only return users who can be assigned this issue (optional, e.g., KP-2)")),
+		mcp.WithNumber("max_results", mcp.DefaultNumber(20), mcp.Min(1), mcp.Max(100), mcp.Description("Maximum number of users to return")),
+	)
+
+	jiraAssignIssueTool := mcp.NewTool("jira_assign_issue",
+		mcp.WithDescription("Assign a Jira issue to a user given by display name, email or account ID, to yourself with 'me', to the project's default assignee with 'default', or unassign it with 'unassigned'"),
+		mcp.WithString("issue_key", mcp.Required(), mcp.Description("The issue to assign (e.g., KP-2)")),
+		mcp.WithString("assignee", mcp.Required(), mcp.Description("Display name, email or account ID of the new assignee, or one of me, default, unassigned")),
+	)
+
+	jiraListWatchersTool := mcp.NewTool("jira_list_watchers",
+		mcp.WithDescription("List the users watching a Jira issue"),
+		mcp.WithString("issue_key", mcp.Required(), mcp.Description("The unique identifier of the Jira issue (e.g., KP-2, PROJ-123)")),
+	)
+
+	jiraAddWatcherTool := mcp.NewTool("jira_add_watcher",
+		mcp.WithDescription("Add a user as a watcher of a Jira issue so they are notified of its changes"),
+		mcp.WithString("issue_key", mcp.Required(), mcp.Description("The issue to watch (e.g., KP-2)")),
+		mcp.WithString("user", mcp.DefaultString("me"), mcp.Description("Display name, email or account ID of the watcher, or me for yourself")),
+	)
+
+	jiraRemoveWatcherTool := mcp.NewTool("jira_remove_watcher",
+		mcp.WithDescription("Stop a user from watching a Jira issue"),
+		mcp.WithString("issue_key", mcp.Required(), mcp.Description("The watched issue (e.g., KP-2)")),
+		mcp.WithString("user", mcp.DefaultString("me"), mcp.Description("Display name, email or account ID of the watcher, or me for yourself")),
+	)
+
+	s.AddTool(jiraSearchUsersTool, util.ErrorGuard(util.TypedHandler(jiraSearchUsersTool, jiraSearchUsersHandler)))
+	s.AddTool(jiraAssignIssueTool, util.ErrorGuard(util.TypedHandler(jiraAssignIssueTool, jiraAssignIssueHandler)))
+	s.AddTool(jiraListWatchersTool, util.ErrorGuard(util.TypedHandler(jiraListWatchersTool, jiraListWatchersHandler)))
+	s.AddTool(jiraAddWatcherTool, util.ErrorGuard(util.TypedHandler(jiraAddWatcherTool, jiraAddWatcherHandler)))
+	s.AddTool(jiraRemoveWatcherTool, util.ErrorGuard(util.TypedHandler(jiraRemoveWatcherTool, jiraRemoveWatcherHandler)))
+}
+
+type jiraSearchUsersArgs struct {
+	Query      string `json:"query"`
+	ProjectKey string `json:"project_key"`
+	IssueKey   string `json:"issue_key"`
+	MaxResults int    `json:"max_results"`
+}
+
+type jiraAssignIssueArgs struct {
+	IssueKey string `json:"issue_key"`
+	Assignee string `json:"assignee"`
+}
+
+type jiraWatcherArgs struct {
+	IssueKey string `json:"issue_key"`
+	User     string `json:"user"`
+}
+
+func jiraSearchUsersHandler(args jiraSearchUsersArgs) (*mcp.CallToolResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second)
+	defer cancel()
+
+	users, response, err := jiraSearchUsers(ctx, args.Query, args.ProjectKey, args.IssueKey, args.MaxResults)
+	if err != nil {
+		if response != nil {
+			return nil, fmt.Errorf("failed to search users: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
+		}
+		return nil, fmt.Errorf("failed to search users: %v", err)
+	}
+
+	scope := ""
+	if args.IssueKey != "" {
+		scope = fmt.Sprintf(" who can be assigned %s", args.IssueKey)
+	} else if args.ProjectKey != "" {
+		scope = fmt.Sprintf(" who can be assigned issues in %s", args.ProjectKey)
+	}
+
+	if len(users) == 0 {
+		return mcp.NewToolResultText(fmt.Sprintf("No users matching %q%s.", args.Query, scope)), nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Users matching %q%s:\n\n", args.Query, scope))
+	for _, user := range users {
+		sb.WriteString(fmt.Sprintf("- %s\n", formatJiraUser(user.DisplayName, user.EmailAddress, jiraUserID(user), user.Active)))
+	}
+
+	return mcp.NewToolResultText(sb.String()), nil
+}
+
+func jiraAssignIssueHandler(args jiraAssignIssueArgs) (*mcp.CallToolResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second*2)
+	defer cancel()
+
+	// A null account unassigns the issue and -1 hands it to the project's default assignee
+	var accountID interface{}
+	result := fmt.Sprintf("%s is now unassigned.", args.IssueKey)
+	switch strings.ToLower(strings.TrimSpace(args.Assignee)) {
+	case "unassigned", "none":
+	case "default":
+		accountID = "-1"
+		result = fmt.Sprintf("%s is now assigned to the project's default assignee.", args.IssueKey)
+	default:
+		user, err := jiraFindUser(ctx, args.Assignee, args.IssueKey)
+		if err != nil {
+			return nil, err
+		}
+		accountID = jiraUserID(user)
+		result = fmt.Sprintf("%s is now assigned to %s.", args.IssueKey, formatJiraUser(user.DisplayName, user.EmailAddress, jiraUserID(user), user.Active))
+	}
+
+	endpoint := fmt.Sprintf("rest/api/2/issue/%s/assignee", args.IssueKey)
+	response, err := jiraRequest(ctx, http.MethodPut, endpoint, jiraUserRef(accountID), nil)
+	if err != nil {
+		if response != nil {
+			return nil, fmt.Errorf("failed to assign issue: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
+		}
+		return nil, fmt.Errorf("failed to assign issue: %v", err)
+	}
+
+	return mcp.NewToolResultText(result), nil
+}
+
+func jiraListWatchersHandler(args jiraIssueKeyArgs) (*mcp.CallToolResult, error) {
+	client := services.JiraClient()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second)
+	defer cancel()
+
+	watchers, response, err := client.Issue.Watcher.Gets(ctx, args.IssueKey)
+	if err != nil {
+		if response != nil {
+			return nil, fmt.Errorf("failed to list watchers: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
+		}
+		return nil, fmt.Errorf("failed to list watchers: %v", err)
+	}
+
+	if len(watchers.Watchers) == 0 {
+		return mcp.NewToolResultText(fmt.Sprintf("Nobody is watching %s.", args.IssueKey)), nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Watchers of %s (%d):\n\n", args.IssueKey, watchers.WatchCount))
+	for _, user := range watchers.Watchers {
+		sb.WriteString(fmt.Sprintf("- %s\n", formatJiraUser(user.DisplayName, user.EmailAddress, jiraUserID(&models.UserScheme{AccountID: user.AccountID, Name: user.Name}), user.Active)))
+	}
+	if watchers.IsWatching {
+		sb.WriteString("\nYou are watching this issue.\n")
+	}
+
+	return mcp.NewToolResultText(sb.String()), nil
+}
+
+func jiraAddWatcherHandler(args jiraWatcherArgs) (*mcp.CallToolResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second*2)
+	defer cancel()
+
+	user, err := jiraFindUser(ctx, args.User, "")
+	if err != nil {
+		return nil, err
+	}
+
+	// The go-atlassian watcher service can only add the current user, Jira takes any account ID as a bare JSON string
+	endpoint := fmt.Sprintf("rest/api/2/issue/%s/watchers", args.IssueKey)
+	response, err := jiraRequest(ctx, http.MethodPost, endpoint, jiraUserID(user), nil)
+	if err != nil {
+		if response != nil {
+			return nil, fmt.Errorf("failed to add watcher: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
+		}
+		return nil, fmt.Errorf("failed to add watcher: %v", err)
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("%s is now watching %s.", formatJiraUser(user.DisplayName, user.EmailAddress, jiraUserID(user), user.Active), args.IssueKey)), nil
+}
+
+func jiraRemoveWatcherHandler(args jiraWatcherArgs) (*mcp.CallToolResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second*2)
+	defer cancel()
+
+	user, err := jiraFindUser(ctx, args.User, "")
+	if err != nil {
+		return nil, err
+	}
+
+	// Data Center names the watcher by username rather than account ID
+	param := "accountId"
+	if services.AtlassianDataCenter() {
+		param = "username"
+	}
+	endpoint := fmt.Sprintf("rest/api/2/issue/%s/watchers?%s=%s", args.IssueKey, param, url.QueryEscape(jiraUserID(user)))
+	response, err := jiraRequest(ctx, http.MethodDelete, endpoint, nil, nil)
+	if err != nil {
+		if response != nil {
+			return nil, fmt.Errorf("failed to remove watcher: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
+		}
+		return nil, fmt.Errorf("failed to remove watcher: %v", err)
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("%s no longer watches %s.", formatJiraUser(user.DisplayName, user.EmailAddress, jiraUserID(user), user.Active), args.IssueKey)), nil
+}
+
+// jiraSearchUsers searches all users, or only those who can be assigned issues in a project or an issue
+func jiraSearchUsers(ctx context.Context, query, projectKey, issueKey string, maxResults int) ([]*models.UserScheme, *models.ResponseScheme, error) {
+	dataCenter := services.AtlassianDataCenter()
+	if projectKey == "" && issueKey == "" && !dataCenter {
+		return services.JiraClient().User.Search.Do(ctx, "", query, 0, maxResults)
+	}
+
+	// Data Center has no query parameter, its username parameter also matches names and emails
+	params := url.Values{}
+	if dataCenter {
+		params.Add("username", query)
+	} else {
+		params.Add("query", query)
+	}
+	params.Add("maxResults", strconv.Itoa(maxResults))
+
+	endpoint := "rest/api/2/user/search?"
+	if issueKey != "" {
+		params.Add("issueKey", issueKey)
+		endpoint = "rest/api/2/user/assignable/search?"
+	} else if projectKey != "" {
+		params.Add("project", projectKey)
+		endpoint = "rest/api/2/user/assignable/search?"
+	}
+
+	var users []*models.UserScheme
+	response, err := jiraRequest(ctx, http.MethodGet, endpoint+params.Encode(), nil, &users)
+	return users, response, err
+}
+
+// jiraGetUser loads a user by the ID jiraUserID returns
+func jiraGetUser(ctx context.Context, id string) (*models.UserScheme, error) {
+	if !services.AtlassianDataCenter() {
+		user, _, err := services.JiraClient().User.Get(ctx, id, nil)
+		return user, err
+	}
+
+	user := &models.UserScheme{}
+	_, err := jiraRequest(ctx, http.MethodGet, "rest/api/2/user?username="+url.QueryEscape(id), nil, user)
+	return user, err
+}
+
+// jiraUserID returns what identifies a user in requests: the account ID on Cloud, the username on Data Center
+func jiraUserID(user *models.UserScheme) string {
+	if services.AtlassianDataCenter() {
+		return user.Name
+	}
+	return user.AccountID
+}
+
+// jiraUserRef references a user in issue fields and assignments by the ID jiraUserID returns; a nil ID clears the user
+func jiraUserRef(id interface{}) map[string]interface{} {
+	if services.AtlassianDataCenter() {
+		return map[string]interface{}{"name": id}
+	}
+	return map[string]interface{}{"accountId": id}
+}
+
+// jiraFindUser resolves a display name, email, account ID or "me" to a single user. With an
+// issue key, only users who can be assigned that issue are considered.
+func jiraFindUser(ctx context.Context, query, issueKey string) (*models.UserScheme, error) {
+	client := services.JiraClient()
+
+	query = strings.TrimSpace(query)
+	switch strings.ToLower(query) {
+	case "":
+		return nil, fmt.Errorf("a user is required")
+	case "me", "myself", "currentuser()":
+		user, response, err := client.MySelf.Details(ctx, nil)
+		if err != nil {
+			if response != nil {
+				return nil, fmt.Errorf("failed to get current user: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
+			}
+			return nil, fmt.Errorf("failed to get current user: %v", err)
+		}
+		return user, nil
+	}
+
+	candidates, response, err := jiraSearchUsers(ctx, query, "", issueKey, maxUserCandidates)
+	if err != nil {
+		if response != nil {
+			return nil, fmt.Errorf("failed to search users: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
+		}
+		return nil, fmt.Errorf("failed to search users: %v", err)
+	}
+
+	var exact []*models.UserScheme
+	for _, user := range candidates {
+		if jiraUserID(user) == query || strings.EqualFold(user.EmailAddress, query) || strings.EqualFold(user.DisplayName, query) {
+			exact = append(exact, user)
+		}
+	}
+	if len(exact) == 1 {
+		return exact[0], nil
+	}
+	if len(exact) == 0 && len(candidates) == 1 {
+		return candidates[0], nil
+	}
+
+	if len(candidates) == 0 {
+		// Searches match names and emails only, so the query may be an account ID
+		if user, err := jiraGetUser(ctx, query); err == nil && jiraUserID(user) != "" {
+			return user, nil
+		}
+		if issueKey != "" {
+			return nil, fmt.Errorf("no user matching %q can be assigned %s", query, issueKey)
+		}
+		return nil, fmt.Errorf("no user matches %q", query)
+	}
+
+	if len(exact) > 1 {
+		candidates = exact
+	}
+	names := make([]string, 0, len(candidates))
+	for _, user := range candidates {
+		names = append(names, formatJiraUser(user.DisplayName, user.EmailAddress, jiraUserID(user), user.Active))
+	}
+	return nil, fmt.Errorf("%q matches several users, give an email or account ID instead: %s", query, strings.Join(names, "; "))
+}
+
+func formatJiraUser(displayName, email, accountID string, active bool) string {
+	text := displayName
+	if email != "" {
+		text += " <" + email + ">"
+	}
+	if services.AtlassianDataCenter() {
+		text += " (username: " + accountID + ")"
+	} else {
+		text += " (account ID: " + accountID + ")"
+	}
+	if !active {
+		text += " [inactive]"
+	}
+	return text
+}
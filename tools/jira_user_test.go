@@ -0,0 +1,71 @@
+package tools
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestJiraSearchUsersHandler(t *testing.T) {
+	text := resultText(t)(jiraSearchUsersHandler(jiraSearchUsersArgs{Query: "minh", MaxResults: 20}))
+	assertContains(t, text, "Minh Pham <minh@example.com> (account ID: 5c2e4bd1a7e3f10d2a8b91c6)")
+
+	// Minh is a user but not one of the people who can be assigned issues in KP
+	text = resultText(t)(jiraSearchUsersHandler(jiraSearchUsersArgs{Query: "minh", ProjectKey: "KP", MaxResults: 20}))
+	assertContains(t, text, `No users matching "minh" who can be assigned issues in KP.`)
+
+	text = resultText(t)(jiraSearchUsersHandler(jiraSearchUsersArgs{Query: "example.com", IssueKey: "SUP-3", MaxResults: 20}))
+	assertContains(t, text, "who can be assigned SUP-3", "Bob Tran", "Carol Le")
+	for _, name := range []string{"Alice Nguyen", "Minh Pham"} {
+		if strings.Contains(text, name) {
+			t.Errorf("%s cannot be assigned SUP-3 but is listed:\n%s", name, text)
+		}
+	}
+}
+
+func TestJiraAssignIssueHandler(t *testing.T) {
+	text := resultText(t)(jiraCreateIssueHandler(jiraCreateIssueArgs{ProjectKey: "KP", Summary: "Assignment target", IssueType: "Task"}))
+	key := regexp.MustCompile(`KP-\d+`).FindString(text)
+
+	text = resultText(t)(jiraAssignIssueHandler(jiraAssignIssueArgs{IssueKey: key, Assignee: "bob@example.com"}))
+	assertContains(t, text, key+" is now assigned to Bob Tran")
+	text = resultText(t)(jiraIssueHandler(jiraIssueKeyArgs{IssueKey: key}))
+	assertContains(t, text, "Bob Tran")
+
+	text = resultText(t)(jiraAssignIssueHandler(jiraAssignIssueArgs{IssueKey: key, Assignee: "me"}))
+	assertContains(t, text, key+" is now assigned to Alice Nguyen")
+
+	text = resultText(t)(jiraAssignIssueHandler(jiraAssignIssueArgs{IssueKey: key, Assignee: "unassigned"}))
+	assertContains(t, text, key+" is now unassigned.")
+
+	// Minh exists but is not assignable in KP
+	_, err := jiraAssignIssueHandler(jiraAssignIssueArgs{IssueKey: key, Assignee: "minh"})
+	if err == nil || !strings.Contains(err.Error(), "be assigned") {
+		t.Errorf("got %v, want an error saying minh cannot be assigned %s", err, key)
+	}
+
+	// bob and carol share the example.com domain, so the name is ambiguous
+	_, err = jiraAssignIssueHandler(jiraAssignIssueArgs{IssueKey: key, Assignee: "example.com"})
+	if err == nil || !strings.Contains(err.Error(), "matches several users") {
+		t.Errorf("got %v, want an error about several matching users", err)
+	}
+}
+
+func TestJiraWatcherHandlers(t *testing.T) {
+	text := resultText(t)(jiraCreateIssueHandler(jiraCreateIssueArgs{ProjectKey: "KP", Summary: "Watcher target", IssueType: "Task"}))
+	key := regexp.MustCompile(`KP-\d+`).FindString(text)
+
+	text = resultText(t)(jiraAddWatcherHandler(jiraWatcherArgs{IssueKey: key, User: "Carol Le"}))
+	assertContains(t, text, "Carol Le <carol@example.com>", "is now watching "+key)
+
+	text = resultText(t)(jiraListWatchersHandler(jiraIssueKeyArgs{IssueKey: key}))
+	assertContains(t, text, "Carol Le")
+
+	text = resultText(t)(jiraRemoveWatcherHandler(jiraWatcherArgs{IssueKey: key, User: "carol@example.com"}))
+	assertContains(t, text, "no longer watches "+key)
+
+	text = resultText(t)(jiraListWatchersHandler(jiraIssueKeyArgs{IssueKey: key}))
+	if strings.Contains(text, "Carol Le") {
+		t.Errorf("Carol Le still watches %s:\n%s", key, text)
+	}
+}
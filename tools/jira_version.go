@@ -0,0 +1,569 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ctreminiom/go-atlassian/pkg/infra/models"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/nguyenvanduocit/dev-kit/services"
+	"github.com/nguyenvanduocit/dev-kit/util"
+)
+
+const maxVersionIssues = 500
+
+// jiraBlockerPriorities mark an unresolved issue as a release blocker on their own
+var jiraBlockerPriorities = map[string]bool{"blocker": true, "highest": true}
+
+// jiraReleaseNoteTypes orders the sections of release notes; other types follow alphabetically
+var jiraReleaseNoteTypes = []string{"Epic", "New Feature", "Story", "Improvement", "Task", "Bug"}
+
+func registerJiraVersionTools(s *server.MCPServer) {
+	jiraListVersionsTool := mcp.NewTool("jira_list_versions",
+		mcp.WithDescription("List the versions of a Jira project with their IDs, release state, start and release dates"),
+		mcp.WithString("project_key", mcp.Required(), mcp.Description("Project identifier (e.g., KP, PROJ)")),
+		mcp.WithString("status", mcp.DefaultString("active"), mcp.Enum("active", "unreleased", "released", "archived", "all"), mcp.Description("Which versions to list: active for all but archived ones")),
+	)
+
+	jiraCreateVersionTool := mcp.NewTool("jira_create_version",
+		mcp.WithDescription("Create a version in a Jira project to plan a release"),
+		mcp.WithString("project_key", mcp.Required(), mcp.Description("Project identifier (e.g., KP, PROJ)")),
+		mcp.WithString("name", mcp.Required(), mcp.Description("Name of the version (e.g., 1.2.0)")),
+		mcp.WithString("description", mcp.Description("Description of the version (optional)")),
+		mcp.WithString("start_date", mcp.Description("Start date as YYYY-MM-DD (optional)")),
+		mcp.WithString("release_date", mcp.Description("Planned release date as YYYY-MM-DD (optional)")),
+	)
+
+	jiraReleaseVersionTool := mcp.NewTool("jira_release_version",
+		mcp.WithDescription("Mark a Jira version as released, optionally moving its unresolved issues to another version"),
+		mcp.WithString("project_key", mcp.Required(), mcp.Description("Project identifier (e.g., KP, PROJ)")),
+		mcp.WithString("version", mcp.Required(), mcp.Description("Name or ID of the version to release")),
+		mcp.WithString("release_date", mcp.Description("Release date as YYYY-MM-DD (optional, defaults to today)")),
+		mcp.WithString("move_unresolved_to", mcp.Description("Name or ID of the version that takes over unresolved issues (optional)")),
+	)
+
+	jiraArchiveVersionTool := mcp.NewTool("jira_archive_version",
+		mcp.WithDescription("Archive a Jira version so it no longer shows in version pickers, or restore an archived one"),
+		mcp.WithString("project_key", mcp.Required(), mcp.Description("Project identifier (e.g., KP, PROJ)")),
+		mcp.WithString("version", mcp.Required(), mcp.Description("Name or ID of the version")),
+		mcp.WithBoolean("unarchive", mcp.DefaultBool(false), mcp.Description("Restore the version instead of archiving it")),
+	)
+
+	jiraVersionStatusTool := mcp.NewTool("jira_version_status",
+		mcp.WithDescription("Summarize the progress of a Jira fix version: issues done, in progress and to do, and the unresolved blockers, i.e. issues at Blocker or Highest priority and issues blocked by unresolved issues"),
+		mcp.WithString("project_key", mcp.Required(), mcp.Description("Project identifier (e.g., KP, PROJ)")),
+		mcp.WithString("version", mcp.Required(), mcp.Description("Name or ID of the version")),
+	)
+
+	jiraReleaseNotesTool := mcp.NewTool("jira_release_notes",
+		mcp.WithDescription("Render Markdown release notes for a Jira fix version, listing its completed issues grouped by issue type"),
+		mcp.WithString("project_key", mcp.Required(), mcp.Description("Project identifier (e.g., KP, PROJ)")),
+		mcp.WithString("version", mcp.Required(), mcp.Description("Name or ID of the version")),
+		mcp.WithBoolean("include_unresolved", mcp.DefaultBool(false), mcp.Description("Also list the issues that are not done yet in a separate section")),
+		mcp.WithBoolean("include_subtasks", mcp.DefaultBool(false), mcp.Description("Include subtasks, which are left out by default")),
+	)
+
+	s.AddTool(jiraListVersionsTool, util.ErrorGuard(util.TypedHandler(jiraListVersionsTool, jiraListVersionsHandler)))
+	s.AddTool(jiraCreateVersionTool, util.ErrorGuard(util.TypedHandler(jiraCreateVersionTool, jiraCreateVersionHandler)))
+	s.AddTool(jiraReleaseVersionTool, util.ErrorGuard(util.TypedHandler(jiraReleaseVersionTool, jiraReleaseVersionHandler)))
+	s.AddTool(jiraArchiveVersionTool, util.ErrorGuard(util.TypedHandler(jiraArchiveVersionTool, jiraArchiveVersionHandler)))
+	s.AddTool(jiraVersionStatusTool, util.ErrorGuard(util.TypedHandler(jiraVersionStatusTool, jiraVersionStatusHandler)))
+	s.AddTool(jiraReleaseNotesTool, util.ErrorGuard(util.TypedHandler(jiraReleaseNotesTool, jiraReleaseNotesHandler)))
+}
+
+// jiraVersion adds the start date, which the go-atlassian version model leaves out
+type jiraVersion struct {
+	models.VersionScheme
+	StartDate string `json:"startDate,omitempty"`
+}
+
+type jiraListVersionsArgs struct {
+	ProjectKey string `json:"project_key"`
+	Status     string `json:"status"`
+}
+
+type jiraCreateVersionArgs struct {
+	ProjectKey  string `json:"project_key"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	StartDate   string `json:"start_date"`
+	ReleaseDate string `json:"release_date"`
+}
+
+type jiraVersionArgs struct {
+	ProjectKey string `json:"project_key"`
+	Version    string `json:"version"`
+}
+
+type jiraReleaseVersionArgs struct {
+	jiraVersionArgs
+	ReleaseDate      string `json:"release_date"`
+	MoveUnresolvedTo string `json:"move_unresolved_to"`
+}
+
+type jiraArchiveVersionArgs struct {
+	jiraVersionArgs
+	Unarchive bool `json:"unarchive"`
+}
+
+type jiraReleaseNotesArgs struct {
+	jiraVersionArgs
+	IncludeUnresolved bool `json:"include_unresolved"`
+	IncludeSubtasks   bool `json:"include_subtasks"`
+}
+
+func jiraListVersionsHandler(args jiraListVersionsArgs) (*mcp.CallToolResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second)
+	defer cancel()
+
+	versions, err := jiraProjectVersions(ctx, args.ProjectKey)
+	if err != nil {
+		return nil, err
+	}
+
+	var sb strings.Builder
+	count := 0
+	for _, version := range versions {
+		switch args.Status {
+		case "active":
+			if version.Archived {
+				continue
+			}
+		case "unreleased":
+			if version.Released || version.Archived {
+				continue
+			}
+		case "released":
+			if !version.Released || version.Archived {
+				continue
+			}
+		case "archived":
+			if !version.Archived {
+				continue
+			}
+		}
+		sb.WriteString(formatJiraVersion(version))
+		count++
+	}
+
+	if count == 0 {
+		return mcp.NewToolResultText(fmt.Sprintf("No %s versions found in %s.", args.Status, args.ProjectKey)), nil
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("Versions of %s (%d):\n\n%s", args.ProjectKey, count, sb.String())), nil
+}
+
+func jiraCreateVersionHandler(args jiraCreateVersionArgs) (*mcp.CallToolResult, error) {
+	for name, date := range map[string]string{"start_date": args.StartDate, "release_date": args.ReleaseDate} {
+		if _, err := time.Parse("2006-01-02", date); date != "" && err != nil {
+			return nil, fmt.Errorf("%s must be YYYY-MM-DD, got %q", name, date)
+		}
+	}
+
+	client := services.JiraClient()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second*2)
+	defer cancel()
+
+	project, response, err := client.Project.Get(ctx, args.ProjectKey, nil)
+	if err != nil {
+		if response != nil {
+			return nil, fmt.Errorf("failed to get project: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
+		}
+		return nil, fmt.Errorf("failed to get project: %v", err)
+	}
+	projectID, err := strconv.Atoi(project.ID)
+	if err != nil {
+		return nil, fmt.Errorf("unexpected project ID %q", project.ID)
+	}
+
+	payload := &models.VersionPayloadScheme{
+		Name:        args.Name,
+		Description: args.Description,
+		ProjectID:   projectID,
+		StartDate:   args.StartDate,
+		ReleaseDate: args.ReleaseDate,
+	}
+	var version jiraVersion
+	response, err = jiraRequest(ctx, http.MethodPost, "rest/api/2/version", payload, &version)
+	if err != nil {
+		if response != nil {
+			return nil, fmt.Errorf("failed to create version: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
+		}
+		return nil, fmt.Errorf("failed to create version: %v", err)
+	}
+
+	return mcp.NewToolResultText("Version created:\n" + formatJiraVersion(&version)), nil
+}
+
+func jiraReleaseVersionHandler(args jiraReleaseVersionArgs) (*mcp.CallToolResult, error) {
+	releaseDate := args.ReleaseDate
+	if releaseDate == "" {
+		releaseDate = time.Now().Format("2006-01-02")
+	} else if _, err := time.Parse("2006-01-02", releaseDate); err != nil {
+		return nil, fmt.Errorf("release_date must be YYYY-MM-DD, got %q", releaseDate)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second*2)
+	defer cancel()
+
+	versions, err := jiraProjectVersions(ctx, args.ProjectKey)
+	if err != nil {
+		return nil, err
+	}
+	version, err := jiraFindVersion(versions, args.ProjectKey, args.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	// The payload model drops false booleans, so updates are sent as maps
+	payload := map[string]interface{}{"released": true, "releaseDate": releaseDate}
+	moved := ""
+	if args.MoveUnresolvedTo != "" {
+		target, err := jiraFindVersion(versions, args.ProjectKey, args.MoveUnresolvedTo)
+		if err != nil {
+			return nil, err
+		}
+		if target.ID == version.ID {
+			return nil, fmt.Errorf("unresolved issues cannot be moved to the version being released")
+		}
+		payload["moveUnfixedIssuesTo"] = target.Self
+		moved = fmt.Sprintf("\nUnresolved issues were moved to %s.", target.Name)
+	}
+
+	updated, err := jiraUpdateVersion(ctx, version.ID, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	return mcp.NewToolResultText("Version released:\n" + formatJiraVersion(updated) + moved), nil
+}
+
+func jiraArchiveVersionHandler(args jiraArchiveVersionArgs) (*mcp.CallToolResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second*2)
+	defer cancel()
+
+	versions, err := jiraProjectVersions(ctx, args.ProjectKey)
+	if err != nil {
+		return nil, err
+	}
+	version, err := jiraFindVersion(versions, args.ProjectKey, args.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := jiraUpdateVersion(ctx, version.ID, map[string]interface{}{"archived": !args.Unarchive})
+	if err != nil {
+		return nil, err
+	}
+
+	action := "archived"
+	if args.Unarchive {
+		action = "restored"
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("Version %s:\n%s", action, formatJiraVersion(updated))), nil
+}
+
+func jiraVersionStatusHandler(args jiraVersionArgs) (*mcp.CallToolResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second*4)
+	defer cancel()
+
+	versions, err := jiraProjectVersions(ctx, args.ProjectKey)
+	if err != nil {
+		return nil, err
+	}
+	version, err := jiraFindVersion(versions, args.ProjectKey, args.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := []string{"summary", "status", "issuetype", "assignee", "priority", "issuelinks"}
+	issues, total, err := jiraVersionIssues(ctx, args.ProjectKey, version.ID, fields)
+	if err != nil {
+		return nil, err
+	}
+
+	var done, inProgress, toDo, blockers []string
+	for _, issue := range issues {
+		line := formatJiraVersionIssue(issue)
+		switch jiraIssueStatusCategory(issue) {
+		case "done":
+			done = append(done, line)
+			continue
+		case "indeterminate":
+			inProgress = append(inProgress, line)
+		default:
+			toDo = append(toDo, line)
+		}
+
+		var reasons []string
+		if issue.Fields.Priority != nil && jiraBlockerPriorities[strings.ToLower(issue.Fields.Priority.Name)] {
+			reasons = append(reasons, "priority "+issue.Fields.Priority.Name)
+		}
+		for _, blocker := range jiraUnresolvedBlockers(issue) {
+			reasons = append(reasons, "blocked by "+blocker)
+		}
+		if len(reasons) > 0 {
+			blockers = append(blockers, fmt.Sprintf("%s: %s", issue.Key, strings.Join(reasons, ", ")))
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString("Version " + strings.TrimPrefix(formatJiraVersion(version), "- ") + "\n")
+	if len(issues) == 0 {
+		sb.WriteString("No issues have this fix version.\n")
+		return mcp.NewToolResultText(sb.String()), nil
+	}
+
+	sb.WriteString(fmt.Sprintf("Issues: %d (%d done, %d in progress, %d to do), %.0f%% done\n", len(issues), len(done), len(inProgress), len(toDo), 100*float64(len(done))/float64(len(issues))))
+	if total > len(issues) {
+		sb.WriteString(fmt.Sprintf("Only the first %d of %d issues were read.\n", len(issues), total))
+	}
+
+	for _, section := range []struct {
+		title string
+		lines []string
+	}{
+		{"Unresolved blockers", blockers},
+		{"In progress", inProgress},
+		{"To do", toDo},
+		{"Done", done},
+	} {
+		if len(section.lines) == 0 {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("\n%s (%d):\n", section.title, len(section.lines)))
+		for _, line := range section.lines {
+			sb.WriteString("- " + line + "\n")
+		}
+	}
+
+	return mcp.NewToolResultText(sb.String()), nil
+}
+
+func jiraReleaseNotesHandler(args jiraReleaseNotesArgs) (*mcp.CallToolResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second*4)
+	defer cancel()
+
+	versions, err := jiraProjectVersions(ctx, args.ProjectKey)
+	if err != nil {
+		return nil, err
+	}
+	version, err := jiraFindVersion(versions, args.ProjectKey, args.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	issues, total, err := jiraVersionIssues(ctx, args.ProjectKey, version.ID, []string{"summary", "status", "issuetype"})
+	if err != nil {
+		return nil, err
+	}
+
+	byType := map[string][]string{}
+	var unresolved []string
+	for _, issue := range issues {
+		issueType := "Other"
+		if issue.Fields.IssueType != nil {
+			if issue.Fields.IssueType.Subtask && !args.IncludeSubtasks {
+				continue
+			}
+			issueType = issue.Fields.IssueType.Name
+		}
+
+		line := fmt.Sprintf("- %s: %s", issue.Key, issue.Fields.Summary)
+		if jiraIssueStatusCategory(issue) != "done" {
+			if args.IncludeUnresolved {
+				unresolved = append(unresolved, fmt.Sprintf("%s (%s, %s)", line, issueType, issue.Fields.Status.Name))
+			}
+			continue
+		}
+		byType[issueType] = append(byType[issueType], line)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("# %s %s release notes\n\n", args.ProjectKey, version.Name))
+	switch {
+	case version.Released && version.ReleaseDate != "":
+		sb.WriteString(fmt.Sprintf("Released on %s.\n\n", version.ReleaseDate))
+	case version.ReleaseDate != "":
+		sb.WriteString(fmt.Sprintf("Planned for %s, not released yet.\n\n", version.ReleaseDate))
+	case !version.Released:
+		sb.WriteString("Not released yet.\n\n")
+	}
+	if version.Description != "" {
+		sb.WriteString(version.Description + "\n\n")
+	}
+
+	if len(byType) == 0 {
+		sb.WriteString("No completed issues.\n")
+	}
+	for _, issueType := range jiraReleaseNoteOrder(byType) {
+		sb.WriteString(fmt.Sprintf("## %s\n\n%s\n\n", issueType, strings.Join(byType[issueType], "\n")))
+	}
+
+	if len(unresolved) > 0 {
+		sb.WriteString(fmt.Sprintf("## Not done yet\n\n%s\n\n", strings.Join(unresolved, "\n")))
+	}
+	if total > len(issues) {
+		sb.WriteString(fmt.Sprintf("_Only the first %d of %d issues are included._\n", len(issues), total))
+	}
+
+	return mcp.NewToolResultText(strings.TrimRight(sb.String(), "\n") + "\n"), nil
+}
+
+// jiraReleaseNoteOrder sorts issue types by jiraReleaseNoteTypes, then alphabetically
+func jiraReleaseNoteOrder(byType map[string][]string) []string {
+	rank := func(issueType string) int {
+		for i, known := range jiraReleaseNoteTypes {
+			if strings.EqualFold(known, issueType) {
+				return i
+			}
+		}
+		return len(jiraReleaseNoteTypes)
+	}
+
+	types := make([]string, 0, len(byType))
+	for issueType := range byType {
+		types = append(types, issueType)
+	}
+	sort.Slice(types, func(i, j int) bool {
+		if rank(types[i]) != rank(types[j]) {
+			return rank(types[i]) < rank(types[j])
+		}
+		return types[i] < types[j]
+	})
+	return types
+}
+
+// jiraUnresolvedBlockers returns the issues that block an issue through a Blocks link and are not done yet
+func jiraUnresolvedBlockers(issue *models.IssueSchemeV2) []string {
+	var blockers []string
+	for _, link := range issue.Fields.IssueLinks {
+		if link.Type == nil || link.InwardIssue == nil || !strings.Contains(strings.ToLower(link.Type.Inward), "blocked by") {
+			continue
+		}
+		blocker := link.InwardIssue
+		if blocker.Fields == nil || blocker.Fields.Status == nil {
+			blockers = append(blockers, blocker.Key)
+			continue
+		}
+		if blocker.Fields.Status.StatusCategory != nil && blocker.Fields.Status.StatusCategory.Key == "done" {
+			continue
+		}
+		blockers = append(blockers, fmt.Sprintf("%s (%s)", blocker.Key, blocker.Fields.Status.Name))
+	}
+	return blockers
+}
+
+func jiraProjectVersions(ctx context.Context, projectKey string) ([]*jiraVersion, error) {
+	var versions []*jiraVersion
+	response, err := jiraRequest(ctx, http.MethodGet, fmt.Sprintf("rest/api/2/project/%s/versions", projectKey), nil, &versions)
+	if err != nil {
+		if response != nil {
+			return nil, fmt.Errorf("failed to list versions: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
+		}
+		return nil, fmt.Errorf("failed to list versions: %v", err)
+	}
+	return versions, nil
+}
+
+// jiraFindVersion picks a version by ID or name, ignoring case when no name matches exactly
+func jiraFindVersion(versions []*jiraVersion, projectKey, nameOrID string) (*jiraVersion, error) {
+	for _, version := range versions {
+		if version.ID == nameOrID || version.Name == nameOrID {
+			return version, nil
+		}
+	}
+	for _, version := range versions {
+		if strings.EqualFold(version.Name, nameOrID) {
+			return version, nil
+		}
+	}
+
+	names := make([]string, 0, len(versions))
+	for _, version := range versions {
+		if !version.Archived {
+			names = append(names, version.Name)
+		}
+	}
+	return nil, fmt.Errorf("version %q not found in %s, available versions: %s", nameOrID, projectKey, strings.Join(names, ", "))
+}
+
+func jiraUpdateVersion(ctx context.Context, versionID string, payload map[string]interface{}) (*jiraVersion, error) {
+	var version jiraVersion
+	response, err := jiraRequest(ctx, http.MethodPut, "rest/api/2/version/"+versionID, payload, &version)
+	if err != nil {
+		if response != nil {
+			return nil, fmt.Errorf("failed to update version: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
+		}
+		return nil, fmt.Errorf("failed to update version: %v", err)
+	}
+	return &version, nil
+}
+
+// jiraVersionIssues returns up to maxVersionIssues issues with the given fix version, and their total
+func jiraVersionIssues(ctx context.Context, projectKey, versionID string, fields []string) ([]*models.IssueSchemeV2, int, error) {
+	jql := fmt.Sprintf("project = %q AND fixVersion = %s ORDER BY issuetype, key", projectKey, versionID)
+
+	var issues []*models.IssueSchemeV2
+	total := 0
+	for len(issues) < maxVersionIssues {
+		result, response, err := services.JiraClient().Issue.Search.Get(ctx, jql, fields, nil, len(issues), maxVersionIssues-len(issues), "")
+		if err != nil {
+			if response != nil {
+				return nil, 0, fmt.Errorf("failed to search issues: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
+			}
+			return nil, 0, fmt.Errorf("failed to search issues: %v", err)
+		}
+		issues = append(issues, result.Issues...)
+		total = result.Total
+		if len(result.Issues) == 0 || len(issues) >= result.Total {
+			break
+		}
+	}
+	return issues, total, nil
+}
+
+func formatJiraVersion(version *jiraVersion) string {
+	state := "unreleased"
+	switch {
+	case version.Archived:
+		state = "archived"
+	case version.Released:
+		state = "released"
+	case version.Overdue:
+		state = "unreleased, overdue"
+	}
+
+	line := fmt.Sprintf("- %s (ID: %s): %s", version.Name, version.ID, state)
+	if version.StartDate != "" {
+		line += ", starts " + version.StartDate
+	}
+	if version.ReleaseDate != "" {
+		line += ", release date " + version.ReleaseDate
+	}
+	if version.Description != "" {
+		line += " — " + version.Description
+	}
+	return line + "\n"
+}
+
+func formatJiraVersionIssue(issue *models.IssueSchemeV2) string {
+	issueType, status, assignee := "", "", "Unassigned"
+	if issue.Fields.IssueType != nil {
+		issueType = issue.Fields.IssueType.Name
+	}
+	if issue.Fields.Status != nil {
+		status = issue.Fields.Status.Name
+	}
+	if issue.Fields.Assignee != nil {
+		assignee = issue.Fields.Assignee.DisplayName
+	}
+	return fmt.Sprintf("%s [%s] %s — %s, %s", issue.Key, issueType, issue.Fields.Summary, status, assignee)
+}
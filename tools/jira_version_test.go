@@ -0,0 +1,76 @@
+package tools
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestJiraListVersionsHandler(t *testing.T) {
+	text := resultText(t)(jiraListVersionsHandler(jiraListVersionsArgs{ProjectKey: "KP", Status: "released"}))
+	assertContains(t, text, "Versions of KP (1):", "- 1.0 (ID: 10101): released, starts 2026-08-27, release date 2026-09-15 — Record and replay")
+
+	text = resultText(t)(jiraListVersionsHandler(jiraListVersionsArgs{ProjectKey: "KP", Status: "archived"}))
+	assertContains(t, text, "- 0.9 (ID: 10100): archived")
+	if strings.Contains(text, "1.0") {
+		t.Errorf("released version 1.0 is listed as archived:\n%s", text)
+	}
+}
+
+func TestJiraVersionReleaseHandlers(t *testing.T) {
+	text := resultText(t)(jiraCreateVersionHandler(jiraCreateVersionArgs{ProjectKey: "KP", Name: "3.0", Description: "Next major", StartDate: "2026-11-02", ReleaseDate: "2026-12-14"}))
+	assertContains(t, text, "Version created:", "- 3.0 (ID: ", "starts 2026-11-02, release date 2026-12-14 — Next major")
+	resultText(t)(jiraCreateVersionHandler(jiraCreateVersionArgs{ProjectKey: "KP", Name: "3.1"}))
+
+	if _, err := jiraCreateVersionHandler(jiraCreateVersionArgs{ProjectKey: "KP", Name: "3.2", ReleaseDate: "14/12/2026"}); err == nil {
+		t.Error("creating a version with a malformed release date succeeded, want an error")
+	}
+
+	text = resultText(t)(jiraCreateIssueHandler(jiraCreateIssueArgs{ProjectKey: "KP", Summary: "Ship 3.0", IssueType: "Task", FixVersions: "3.0"}))
+	key := regexp.MustCompile(`KP-\d+`).FindString(text)
+
+	text = resultText(t)(jiraVersionStatusHandler(jiraVersionArgs{ProjectKey: "KP", Version: "3.0"}))
+	assertContains(t, text, "Issues: 1 (0 done, 0 in progress, 1 to do), 0% done", key+" [Task] Ship 3.0 — To Do")
+
+	text = resultText(t)(jiraReleaseVersionHandler(jiraReleaseVersionArgs{jiraVersionArgs: jiraVersionArgs{ProjectKey: "KP", Version: "3.0"}, ReleaseDate: "2026-12-10", MoveUnresolvedTo: "3.1"}))
+	assertContains(t, text, "Version released:", "- 3.0 (ID: ", "released", "release date 2026-12-10", "Unresolved issues were moved to 3.1.")
+
+	text = resultText(t)(jiraVersionStatusHandler(jiraVersionArgs{ProjectKey: "KP", Version: "3.0"}))
+	assertContains(t, text, "No issues have this fix version.")
+	text = resultText(t)(jiraVersionStatusHandler(jiraVersionArgs{ProjectKey: "KP", Version: "3.1"}))
+	assertContains(t, text, key)
+
+	text = resultText(t)(jiraArchiveVersionHandler(jiraArchiveVersionArgs{jiraVersionArgs: jiraVersionArgs{ProjectKey: "KP", Version: "3.0"}}))
+	assertContains(t, text, "Version archived:", "- 3.0 (ID: ")
+	text = resultText(t)(jiraArchiveVersionHandler(jiraArchiveVersionArgs{jiraVersionArgs: jiraVersionArgs{ProjectKey: "KP", Version: "3.0"}, Unarchive: true}))
+	assertContains(t, text, "Version restored:")
+
+	_, err := jiraReleaseVersionHandler(jiraReleaseVersionArgs{jiraVersionArgs: jiraVersionArgs{ProjectKey: "KP", Version: "3.1"}, MoveUnresolvedTo: "3.1"})
+	if err == nil {
+		t.Error("moving unresolved issues to the version being released succeeded, want an error")
+	}
+	_, err = jiraVersionStatusHandler(jiraVersionArgs{ProjectKey: "KP", Version: "9.9"})
+	if err == nil || !strings.Contains(err.Error(), "available versions: 1.0, 1.1") {
+		t.Errorf("got %v, want an error listing the available versions", err)
+	}
+}
+
+func TestJiraVersionStatusHandler(t *testing.T) {
+	text := resultText(t)(jiraVersionStatusHandler(jiraVersionArgs{ProjectKey: "KP", Version: "1.0"}))
+	assertContains(t, text, "Version 1.0 (ID: 10101): released", "Issues: 1 (1 done, 0 in progress, 0 to do), 100% done", "Done (1):\n- KP-2 [Story]")
+
+	// KP-5 is at Highest priority, which makes it a blocker of 1.1
+	text = resultText(t)(jiraVersionStatusHandler(jiraVersionArgs{ProjectKey: "KP", Version: "1.1"}))
+	assertContains(t, text, "Unresolved blockers", "- KP-5: priority Highest")
+}
+
+func TestJiraReleaseNotesHandler(t *testing.T) {
+	text := resultText(t)(jiraReleaseNotesHandler(jiraReleaseNotesArgs{jiraVersionArgs: jiraVersionArgs{ProjectKey: "KP", Version: "1.0"}}))
+	assertContains(t, text, "# KP 1.0 release notes", "Released on 2026-09-15.", "## Story\n\n- KP-2: Record and replay upstream traffic")
+
+	text = resultText(t)(jiraReleaseNotesHandler(jiraReleaseNotesArgs{jiraVersionArgs: jiraVersionArgs{ProjectKey: "KP", Version: "1.1"}, IncludeUnresolved: true}))
+	assertContains(t, text, "Planned for 2026-10-30, not released yet.", "## Not done yet", "- KP-5: Search ignores maxResults (Bug, To Do)")
+	if strings.Contains(text, "KP-4") {
+		t.Errorf("subtask KP-4 is in the release notes without include_subtasks:\n%s", text)
+	}
+}
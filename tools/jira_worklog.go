@@ -0,0 +1,419 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ctreminiom/go-atlassian/pkg/infra/models"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/nguyenvanduocit/dev-kit/services"
+	"github.com/nguyenvanduocit/dev-kit/util"
+	"github.com/nguyenvanduocit/dev-kit/util/markup"
+)
+
+// maxWorklogIssues limits how many issues a worklog report reads the worklogs of
+const maxWorklogIssues = 500
+
+// jiraTimeSpentPattern matches one amount of a time spent such as 1h, 30 mins or 1.5 hours
+var jiraTimeSpentPattern = regexp.MustCompile(`(?i)(\d+(?:\.\d+)?)\s*(weeks?|w|days?|d|hours?|hrs?|h|minutes?|mins?|m)`)
+
+// jiraTimeSpentSeparators matches what may separate the amounts of a time spent
+var jiraTimeSpentSeparators = regexp.MustCompile(`(?i)[\s,]+|\band\b`)
+
+// jiraOrderByPattern matches the ORDER BY clause that ends a JQL query
+var jiraOrderByPattern = regexp.MustCompile(`(?is)\s+order\s+by\s.*$`)
+
+func registerJiraWorklogTools(s *server.MCPServer) {
+	jiraAddWorklogTool := mcp.NewTool("jira_add_worklog",
+		mcp.WithDescription("Log time spent on a Jira issue"),
+		mcp.WithString("issue_key", mcp.Required(), mcp.Description("The issue to log time on (e.g., KP-2)")),
+		mcp.WithString("time_spent", mcp.Required(), mcp.Description("Time spent, e.g. 1h 30m, 45m, 2d or 1.5h. Days and weeks are working days and weeks as configured in Jira")),
+		mcp.WithString("started", mcp.Description("When the work started, as YYYY-MM-DD HH:MM in local time, YYYY-MM-DD for 09:00 that day, or an RFC 3339 timestamp (optional, defaults to now)")),
+		mcp.WithString("comment", mcp.Description("Description of the work in Markdown (optional)")),
+		mcp.WithString("remaining_estimate", mcp.Description("Set the remaining estimate to this value, e.g. 2h, instead of reducing it by the time spent (optional)")),
+	)
+	s.AddTool(jiraAddWorklogTool, util.ErrorGuard(util.TypedHandler(jiraAddWorklogTool, jiraAddWorklogHandler)))
+
+	jiraListWorklogsTool := mcp.NewTool("jira_list_worklogs",
+		mcp.WithDescription("List the time logged on a Jira issue with authors, start times, durations and comments"),
+		mcp.WithString("issue_key", mcp.Required(), mcp.Description("The unique identifier of the Jira issue (e.g., KP-2, PROJ-123)")),
+	)
+	s.AddTool(jiraListWorklogsTool, util.ErrorGuard(util.TypedHandler(jiraListWorklogsTool, jiraListWorklogsHandler)))
+
+	jiraWorklogReportTool := mcp.NewTool("jira_worklog_report",
+		mcp.WithDescription("Summarize the time logged on Jira issues over a date range: hours per user, per day, per issue and each user's days, like a timesheet"),
+		mcp.WithString("from", mcp.Required(), mcp.Pattern(`^\d{4}-\d{2}-\d{2}$`), mcp.Description("First day of the range, as YYYY-MM-DD")),
+		mcp.WithString("to", mcp.Pattern(`^\d{4}-\d{2}-\d{2}$`), mcp.Description("Last day of the range, as YYYY-MM-DD (optional, defaults to today)")),
+		mcp.WithString("jql", mcp.Description("JQL query narrowing the issues, e.g. project = KP (optional, all issues with time logged in the range by default)")),
+		mcp.WithString("author", mcp.Description("Only count time logged by this user, given as display name, email or account ID (optional)")),
+		mcp.WithNumber("max_issues", mcp.Min(1), mcp.Max(maxWorklogIssues), mcp.DefaultNumber(100), mcp.Description("Maximum number of issues to read the worklogs of")),
+	)
+	s.AddTool(jiraWorklogReportTool, util.ErrorGuard(util.TypedHandler(jiraWorklogReportTool, jiraWorklogReportHandler)))
+}
+
+type jiraAddWorklogArgs struct {
+	IssueKey          string `json:"issue_key"`
+	TimeSpent         string `json:"time_spent"`
+	Started           string `json:"started"`
+	Comment           string `json:"comment"`
+	RemainingEstimate string `json:"remaining_estimate"`
+}
+
+type jiraWorklogReportArgs struct {
+	From      string `json:"from"`
+	To        string `json:"to"`
+	JQL       string `json:"jql"`
+	Author    string `json:"author"`
+	MaxIssues int    `json:"max_issues"`
+}
+
+func jiraAddWorklogHandler(args jiraAddWorklogArgs) (*mcp.CallToolResult, error) {
+	timeSpent, err := parseJiraTimeSpent("time_spent", args.TimeSpent)
+	if err != nil {
+		return nil, err
+	}
+	started := time.Now()
+	if args.Started != "" {
+		if started, err = parseJiraWorklogStarted(args.Started); err != nil {
+			return nil, err
+		}
+	}
+
+	// the rich text payload of the client sends the comment as an object, which API v2 rejects
+	payload := map[string]interface{}{
+		"timeSpent": timeSpent,
+		"started":   started.Format(jiraChangelogLayout),
+	}
+	if args.Comment != "" {
+		payload["comment"] = markup.MarkdownToWiki(args.Comment)
+	}
+
+	endpoint := fmt.Sprintf("rest/api/2/issue/%s/worklog", url.PathEscape(args.IssueKey))
+	if args.RemainingEstimate != "" {
+		estimate, err := parseJiraTimeSpent("remaining_estimate", args.RemainingEstimate)
+		if err != nil {
+			return nil, err
+		}
+		endpoint += "?" + url.Values{"adjustEstimate": {"new"}, "newEstimate": {estimate}}.Encode()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second)
+	defer cancel()
+
+	worklog := new(models.IssueWorklogRichTextScheme)
+	response, err := jiraRequest(ctx, http.MethodPost, endpoint, payload, worklog)
+	if err != nil {
+		if response != nil {
+			return nil, fmt.Errorf("failed to add worklog: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
+		}
+		return nil, fmt.Errorf("failed to add worklog: %v", err)
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("Logged %s on %s\n%s", timeSpent, args.IssueKey, formatJiraWorklog(worklog))), nil
+}
+
+func jiraListWorklogsHandler(args jiraIssueKeyArgs) (*mcp.CallToolResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second)
+	defer cancel()
+
+	worklogs, err := jiraIssueWorklogs(ctx, args.IssueKey, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	if len(worklogs) == 0 {
+		return mcp.NewToolResultText(fmt.Sprintf("No time logged on %s.", args.IssueKey)), nil
+	}
+
+	total := 0
+	var sb strings.Builder
+	for _, worklog := range worklogs {
+		total += worklog.TimeSpentSeconds
+		sb.WriteString(formatJiraWorklog(worklog))
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("%s on %s, %s in total:\n%s", formatJiraWorklogCount(len(worklogs)), args.IssueKey, formatJiraWorkTime(total), sb.String())), nil
+}
+
+func formatJiraWorklog(worklog *models.IssueWorklogRichTextScheme) string {
+	author := "Unknown"
+	if worklog.Author != nil {
+		author = worklog.Author.DisplayName
+	}
+	started := worklog.Started
+	if at, err := time.Parse(jiraChangelogLayout, worklog.Started); err == nil {
+		started = at.Local().Format("2006-01-02 15:04")
+	}
+
+	line := fmt.Sprintf("- %s by %s: %s (ID: %s)", started, author, worklog.TimeSpent, worklog.ID)
+	if comment := strings.Join(strings.Fields(worklog.Comment), " "); comment != "" {
+		line += " — " + comment
+	}
+	return line + "\n"
+}
+
+// jiraIssueWorklogs reads the worklogs of an issue started at or after since, oldest first
+func jiraIssueWorklogs(ctx context.Context, issueKey string, since time.Time) ([]*models.IssueWorklogRichTextScheme, error) {
+	after := 0
+	if !since.IsZero() {
+		after = int(since.UnixMilli())
+	}
+
+	var worklogs []*models.IssueWorklogRichTextScheme
+	for {
+		page, response, err := services.JiraClient().Issue.Worklog.Issue(ctx, issueKey, len(worklogs), 1000, after, nil)
+		if err != nil {
+			if response != nil {
+				return nil, fmt.Errorf("failed to get worklogs of %s: %s (endpoint: %s)", issueKey, response.Bytes.String(), response.Endpoint)
+			}
+			return nil, fmt.Errorf("failed to get worklogs of %s: %v", issueKey, err)
+		}
+		worklogs = append(worklogs, page.Worklogs...)
+		if len(page.Worklogs) == 0 || len(worklogs) >= page.Total {
+			break
+		}
+	}
+
+	sort.SliceStable(worklogs, func(i, j int) bool {
+		a, _ := time.Parse(jiraChangelogLayout, worklogs[i].Started)
+		b, _ := time.Parse(jiraChangelogLayout, worklogs[j].Started)
+		return a.Before(b)
+	})
+	return worklogs, nil
+}
+
+// jiraWorklogTotals adds up logged seconds by a key, remembering the order keys were first seen in
+type jiraWorklogTotals struct {
+	keys    []string
+	seconds map[string]int
+}
+
+func (t *jiraWorklogTotals) add(key string, seconds int) {
+	if t.seconds == nil {
+		t.seconds = map[string]int{}
+	}
+	if _, ok := t.seconds[key]; !ok {
+		t.keys = append(t.keys, key)
+	}
+	t.seconds[key] += seconds
+}
+
+func jiraWorklogReportHandler(args jiraWorklogReportArgs) (*mcp.CallToolResult, error) {
+	from, err := time.ParseInLocation("2006-01-02", args.From, time.Local)
+	if err != nil {
+		return nil, fmt.Errorf("from must be a date as YYYY-MM-DD: %v", err)
+	}
+	to := time.Now()
+	if args.To != "" {
+		if to, err = time.ParseInLocation("2006-01-02", args.To, time.Local); err != nil {
+			return nil, fmt.Errorf("to must be a date as YYYY-MM-DD: %v", err)
+		}
+	}
+	to = time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.Local)
+	if to.Before(from) {
+		return nil, fmt.Errorf("to (%s) is before from (%s)", to.Format("2006-01-02"), args.From)
+	}
+	until := to.AddDate(0, 0, 1)
+
+	jql := fmt.Sprintf("worklogDate >= %q AND worklogDate <= %q", from.Format("2006-01-02"), to.Format("2006-01-02"))
+	if query := strings.TrimSpace(jiraOrderByPattern.ReplaceAllString(args.JQL, "")); query != "" {
+		jql = fmt.Sprintf("(%s) AND %s", query, jql)
+	}
+
+	// every issue takes its own request for its worklogs
+	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second*10)
+	defer cancel()
+
+	var issues []*models.IssueSchemeV2
+	total := 0
+	for len(issues) < args.MaxIssues {
+		result, response, err := services.JiraClient().Issue.Search.Get(ctx, jql+" ORDER BY key", []string{"summary"}, nil, len(issues), args.MaxIssues-len(issues), "")
+		if err != nil {
+			if response != nil {
+				return nil, fmt.Errorf("failed to search issues: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
+			}
+			return nil, fmt.Errorf("failed to search issues: %v", err)
+		}
+		issues = append(issues, result.Issues...)
+		total = result.Total
+		if len(result.Issues) == 0 || len(issues) >= result.Total {
+			break
+		}
+	}
+
+	var byUser, byDay, byIssue jiraWorklogTotals
+	userDays := map[string]*jiraWorklogTotals{}
+	summaries := map[string]string{}
+	count := 0
+	for _, issue := range issues {
+		worklogs, err := jiraIssueWorklogs(ctx, issue.Key, from)
+		if err != nil {
+			return nil, err
+		}
+		for _, worklog := range worklogs {
+			started, err := time.Parse(jiraChangelogLayout, worklog.Started)
+			if err != nil || started.Before(from) || !started.Before(until) {
+				continue
+			}
+			user := "Unknown"
+			if worklog.Author != nil {
+				if args.Author != "" && !jiraUserMatches(worklog.Author, args.Author) {
+					continue
+				}
+				user = worklog.Author.DisplayName
+			} else if args.Author != "" {
+				continue
+			}
+
+			day := started.Local().Format("2006-01-02 Mon")
+			byUser.add(user, worklog.TimeSpentSeconds)
+			byDay.add(day, worklog.TimeSpentSeconds)
+			byIssue.add(issue.Key, worklog.TimeSpentSeconds)
+			if userDays[user] == nil {
+				userDays[user] = &jiraWorklogTotals{}
+			}
+			userDays[user].add(day, worklog.TimeSpentSeconds)
+			if issue.Fields != nil {
+				summaries[issue.Key] = issue.Fields.Summary
+			}
+			count++
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Time logged from %s to %s", from.Format("2006-01-02"), to.Format("2006-01-02")))
+	if args.JQL != "" {
+		sb.WriteString(" on " + args.JQL)
+	}
+	if args.Author != "" {
+		sb.WriteString(" by " + args.Author)
+	}
+	sb.WriteString("\n")
+	if total > len(issues) {
+		sb.WriteString(fmt.Sprintf("Only the first %d of %d matching issues were read, raise max_issues or narrow the query\n", len(issues), total))
+	}
+	if count == 0 {
+		sb.WriteString("No time logged.\n")
+		return mcp.NewToolResultText(sb.String()), nil
+	}
+
+	sum := 0
+	for _, seconds := range byUser.seconds {
+		sum += seconds
+	}
+	sb.WriteString(fmt.Sprintf("Total: %s in %s on %s\n", formatJiraWorkTime(sum), formatJiraWorklogCount(count), formatJiraIssueCount(len(byIssue.keys))))
+
+	sb.WriteString("\nBy user:\n")
+	sort.SliceStable(byUser.keys, func(i, j int) bool { return byUser.seconds[byUser.keys[i]] > byUser.seconds[byUser.keys[j]] })
+	for _, user := range byUser.keys {
+		sb.WriteString(fmt.Sprintf("- %s: %s\n", user, formatJiraWorkTime(byUser.seconds[user])))
+	}
+
+	sb.WriteString("\nBy day:\n")
+	sort.Strings(byDay.keys)
+	for _, day := range byDay.keys {
+		sb.WriteString(fmt.Sprintf("- %s: %s\n", day, formatJiraWorkTime(byDay.seconds[day])))
+	}
+
+	sb.WriteString("\nBy issue:\n")
+	sort.SliceStable(byIssue.keys, func(i, j int) bool { return byIssue.seconds[byIssue.keys[i]] > byIssue.seconds[byIssue.keys[j]] })
+	for _, key := range byIssue.keys {
+		sb.WriteString(fmt.Sprintf("- %s %s: %s\n", key, summaries[key], formatJiraWorkTime(byIssue.seconds[key])))
+	}
+
+	sb.WriteString("\nTimesheet:\n")
+	for _, user := range byUser.keys {
+		days := userDays[user]
+		sort.Strings(days.keys)
+		var entries []string
+		for _, day := range days.keys {
+			entries = append(entries, fmt.Sprintf("%s %s", day, formatJiraWorkTime(days.seconds[day])))
+		}
+		sb.WriteString(fmt.Sprintf("- %s: %s\n", user, strings.Join(entries, ", ")))
+	}
+
+	return mcp.NewToolResultText(sb.String()), nil
+}
+
+func jiraUserMatches(user *models.UserDetailScheme, nameOrID string) bool {
+	return user.AccountID == nameOrID || user.Name == nameOrID ||
+		strings.EqualFold(user.EmailAddress, nameOrID) || strings.EqualFold(user.DisplayName, nameOrID)
+}
+
+// parseJiraTimeSpent turns a duration such as "1h 30m", "90 mins" or "1.5h" into the notation Jira expects
+func parseJiraTimeSpent(name, value string) (string, error) {
+	rest := jiraTimeSpentSeparators.ReplaceAllString(jiraTimeSpentPattern.ReplaceAllString(value, " "), "")
+	matches := jiraTimeSpentPattern.FindAllStringSubmatch(value, -1)
+	if len(matches) == 0 || rest != "" {
+		return "", fmt.Errorf("%s must be a duration such as 1h 30m, 45m or 2d, got %q", name, value)
+	}
+
+	var parts []string
+	for _, match := range matches {
+		amount, _ := strconv.ParseFloat(match[1], 64)
+		unit := strings.ToLower(match[2])[0:1]
+		whole := amount == float64(int(amount))
+		switch {
+		case whole:
+			parts = append(parts, fmt.Sprintf("%d%s", int(amount), unit))
+		case unit == "h":
+			parts = append(parts, fmt.Sprintf("%dh", int(amount)), fmt.Sprintf("%dm", int((amount-float64(int(amount)))*60+0.5)))
+		default:
+			return "", fmt.Errorf("%s may only use fractions of hours, got %q", name, value)
+		}
+	}
+
+	var kept []string
+	for _, part := range parts {
+		if part != "0h" && part != "0m" {
+			kept = append(kept, part)
+		}
+	}
+	if len(kept) == 0 {
+		return "", fmt.Errorf("%s must be more than zero, got %q", name, value)
+	}
+	return strings.Join(kept, " "), nil
+}
+
+// parseJiraWorklogStarted accepts YYYY-MM-DD HH:MM in local time, YYYY-MM-DD for 09:00 that day, or an RFC 3339 timestamp
+func parseJiraWorklogStarted(value string) (time.Time, error) {
+	for _, layout := range []string{"2006-01-02 15:04", "2006-01-02T15:04"} {
+		if started, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return started, nil
+		}
+	}
+	if day, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return day.Add(9 * time.Hour), nil
+	}
+	started, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("started must be YYYY-MM-DD HH:MM, YYYY-MM-DD or an RFC 3339 timestamp, got %q", value)
+	}
+	return started, nil
+}
+
+// formatJiraWorkTime shows logged time in hours and minutes, as days depend on the working hours of the instance
+func formatJiraWorkTime(seconds int) string {
+	hours, minutes := seconds/3600, seconds%3600/60
+	switch {
+	case hours == 0:
+		return fmt.Sprintf("%dm", minutes)
+	case minutes == 0:
+		return fmt.Sprintf("%dh", hours)
+	}
+	return fmt.Sprintf("%dh %dm", hours, minutes)
+}
+
+func formatJiraWorklogCount(count int) string {
+	if count == 1 {
+		return "1 worklog"
+	}
+	return fmt.Sprintf("%d worklogs", count)
+}
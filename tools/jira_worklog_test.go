@@ -0,0 +1,78 @@
+package tools
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestParseJiraTimeSpent(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"1h 30m", "1h 30m"},
+		{"90 mins", "90m"},
+		{"1.5h", "1h 30m"},
+		{"2 days and 3 hours", "2d 3h"},
+		{"1w, 2d", "1w 2d"},
+		{"0.25 hours", "15m"},
+	}
+	for _, test := range tests {
+		got, err := parseJiraTimeSpent("time_spent", test.value)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", test.value, err)
+		} else if got != test.want {
+			t.Errorf("%q: got %q, want %q", test.value, got, test.want)
+		}
+	}
+
+	for _, value := range []string{"", "soon", "1h later", "1.5d", "0h"} {
+		if got, err := parseJiraTimeSpent("time_spent", value); err == nil {
+			t.Errorf("%q: got %q, want an error", value, got)
+		}
+	}
+}
+
+func TestJiraWorklogHandlers(t *testing.T) {
+	text := resultText(t)(jiraCreateIssueHandler(jiraCreateIssueArgs{ProjectKey: "KP", Summary: "Worklog target", IssueType: "Task"}))
+	key := regexp.MustCompile(`KP-\d+`).FindString(text)
+
+	text = resultText(t)(jiraListWorklogsHandler(jiraIssueKeyArgs{IssueKey: key}))
+	assertContains(t, text, "No time logged on "+key)
+
+	text = resultText(t)(jiraAddWorklogHandler(jiraAddWorklogArgs{IssueKey: key, TimeSpent: "1.5 hours", Started: "2026-06-01 10:00", Comment: "Pairing"}))
+	assertContains(t, text, "Logged 1h 30m on "+key, "2026-06-01 10:00 by Alice Nguyen", "Pairing")
+
+	text = resultText(t)(jiraListWorklogsHandler(jiraIssueKeyArgs{IssueKey: key}))
+	assertContains(t, text, "1 worklog on "+key+", 1h 30m in total")
+
+	resultText(t)(jiraAddWorklogHandler(jiraAddWorklogArgs{IssueKey: key, TimeSpent: "45m", Started: "2026-06-02"}))
+	text = resultText(t)(jiraListWorklogsHandler(jiraIssueKeyArgs{IssueKey: key}))
+	assertContains(t, text, "2 worklogs on "+key+", 2h 15m in total")
+
+	if _, err := jiraAddWorklogHandler(jiraAddWorklogArgs{IssueKey: key, TimeSpent: "a while"}); err == nil {
+		t.Error("logging an invalid time spent succeeded, want an error")
+	}
+}
+
+func TestJiraWorklogReportHandler(t *testing.T) {
+	// the fixture logs time on KP-2 and KP-3 between 2026-09-03 and 2026-09-10
+	text := resultText(t)(jiraWorklogReportHandler(jiraWorklogReportArgs{From: "2026-09-03", To: "2026-09-10", JQL: "project = KP", MaxIssues: 100}))
+	assertContains(t, text,
+		"Total: 17h 30m in 5 worklogs on 2 issues",
+		"- Bob Tran: 10h 30m\n- Alice Nguyen: 7h\n",
+		"- 2026-09-04 Fri: 9h 30m",
+		"- KP-2 Record and replay upstream traffic: 11h 45m\n- KP-3 Fake backends for local development: 5h 45m\n",
+		"- Bob Tran: 2026-09-03 Thu 4h, 2026-09-04 Fri 6h 30m",
+	)
+
+	text = resultText(t)(jiraWorklogReportHandler(jiraWorklogReportArgs{From: "2026-09-03", To: "2026-09-10", JQL: "project = KP", Author: "bob@example.com", MaxIssues: 100}))
+	assertContains(t, text, "by bob@example.com", "Total: 10h 30m in 2 worklogs on 1 issue\n")
+
+	text = resultText(t)(jiraWorklogReportHandler(jiraWorklogReportArgs{From: "2026-09-05", To: "2026-09-09", JQL: "project = KP", MaxIssues: 100}))
+	assertContains(t, text, "No time logged.")
+
+	if _, err := jiraWorklogReportHandler(jiraWorklogReportArgs{From: "2026-09-10", To: "2026-09-03", MaxIssues: 100}); err == nil {
+		t.Error("a report ending before it starts succeeded, want an error")
+	}
+}
@@ -0,0 +1,201 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/nguyenvanduocit/dev-kit/services"
+	"github.com/nguyenvanduocit/dev-kit/util"
+)
+
+// downstreamClient is the part of an MCP client the proxy needs, whatever the transport
+type downstreamClient interface {
+	Initialize(ctx context.Context, request mcp.InitializeRequest) (*mcp.InitializeResult, error)
+	ListTools(ctx context.Context, request mcp.ListToolsRequest) (*mcp.ListToolsResult, error)
+	CallTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error)
+	ListPrompts(ctx context.Context, request mcp.ListPromptsRequest) (*mcp.ListPromptsResult, error)
+	GetPrompt(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error)
+	ListResources(ctx context.Context, request mcp.ListResourcesRequest) (*mcp.ListResourcesResult, error)
+	ListResourceTemplates(ctx context.Context, request mcp.ListResourceTemplatesRequest) (*mcp.ListResourceTemplatesResult, error)
+	ReadResource(ctx context.Context, request mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error)
+	Close() error
+}
+
+func (ext Extension) connect() (downstreamClient, error) {
+	switch ext.Transport {
+	case "sse":
+		sseClient, err := client.NewSSEMCPClient(os.ExpandEnv(ext.URL))
+		if err != nil {
+			return nil, err
+		}
+		// The event stream lives as long as the client, so it must not be tied to a request context
+		if err := sseClient.Start(context.Background()); err != nil {
+			return nil, err
+		}
+		return sseClient, nil
+	case "http":
+		headers := make(map[string]string, len(ext.Headers))
+		for key, value := range ext.Headers {
+			headers[key] = os.ExpandEnv(value)
+		}
+		return services.NewMCPHTTPClient(os.ExpandEnv(ext.URL), headers), nil
+	default:
+		return client.NewStdioMCPClient(ext.Command, ext.environ(), ext.Args...)
+	}
+}
+
+// resourceURI namespaces a resource URI of the extension by prefixing its scheme, so
+// file:///notes.md of the extension "docs" becomes docs+file:///notes.md
+func (ext Extension) resourceURI(uri string) string {
+	return ext.Name + "+" + uri
+}
+
+// registerMCP connects to the MCP server of the extension and re-exports its tools, prompts and resources
+func (ext Extension) registerMCP(s *server.MCPServer) error {
+	downstream, err := ext.connect()
+	if err != nil {
+		return fmt.Errorf("failed to connect to extension %s: %v", ext.Name, err)
+	}
+
+	if err := ext.mirror(s, downstream); err != nil {
+		downstream.Close()
+		return fmt.Errorf("extension %s: %v", ext.Name, err)
+	}
+
+	return nil
+}
+
+func (ext Extension) mirror(s *server.MCPServer, downstream downstreamClient) error {
+	ctx, cancel := context.WithTimeout(context.Background(), extensionTimeout)
+	defer cancel()
+
+	initRequest := mcp.InitializeRequest{}
+	initRequest.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	initRequest.Params.ClientInfo = mcp.Implementation{Name: "dev-kit", Version: "1.0.0"}
+	initResult, err := downstream.Initialize(ctx, initRequest)
+	if err != nil {
+		return fmt.Errorf("failed to initialize: %v", err)
+	}
+
+	var tools []mcp.Tool
+	toolsRequest := mcp.ListToolsRequest{}
+	for {
+		result, err := downstream.ListTools(ctx, toolsRequest)
+		if err != nil {
+			return fmt.Errorf("failed to list tools: %v", err)
+		}
+		tools = append(tools, result.Tools...)
+		if result.NextCursor == "" {
+			break
+		}
+		toolsRequest.Params.Cursor = result.NextCursor
+	}
+
+	var prompts []mcp.Prompt
+	if initResult.Capabilities.Prompts != nil {
+		promptsRequest := mcp.ListPromptsRequest{}
+		for {
+			result, err := downstream.ListPrompts(ctx, promptsRequest)
+			if err != nil {
+				return fmt.Errorf("failed to list prompts: %v", err)
+			}
+			prompts = append(prompts, result.Prompts...)
+			if result.NextCursor == "" {
+				break
+			}
+			promptsRequest.Params.Cursor = result.NextCursor
+		}
+	}
+
+	var resources []mcp.Resource
+	var templates []mcp.ResourceTemplate
+	if initResult.Capabilities.Resources != nil {
+		resourcesRequest := mcp.ListResourcesRequest{}
+		for {
+			result, err := downstream.ListResources(ctx, resourcesRequest)
+			if err != nil {
+				return fmt.Errorf("failed to list resources: %v", err)
+			}
+			resources = append(resources, result.Resources...)
+			if result.NextCursor == "" {
+				break
+			}
+			resourcesRequest.Params.Cursor = result.NextCursor
+		}
+
+		// Resource templates are optional, servers without them answer with an error
+		templatesRequest := mcp.ListResourceTemplatesRequest{}
+		for {
+			result, err := downstream.ListResourceTemplates(ctx, templatesRequest)
+			if err != nil {
+				break
+			}
+			templates = append(templates, result.ResourceTemplates...)
+			if result.NextCursor == "" {
+				break
+			}
+			templatesRequest.Params.Cursor = result.NextCursor
+		}
+	}
+
+	for _, tool := range tools {
+		name := tool.Name
+		exposed := tool
+		exposed.Name = ext.toolName(name)
+		s.AddTool(exposed, util.ErrorGuard(func(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+			ctx, cancel := context.WithTimeout(context.Background(), extensionTimeout)
+			defer cancel()
+
+			request := mcp.CallToolRequest{}
+			request.Params.Name = name
+			request.Params.Arguments = arguments
+			return downstream.CallTool(ctx, request)
+		}))
+	}
+
+	for _, prompt := range prompts {
+		name := prompt.Name
+		exposed := prompt
+		exposed.Name = ext.toolName(name)
+		s.AddPrompt(exposed, func(arguments map[string]string) (*mcp.GetPromptResult, error) {
+			ctx, cancel := context.WithTimeout(context.Background(), extensionTimeout)
+			defer cancel()
+
+			request := mcp.GetPromptRequest{}
+			request.Params.Name = name
+			request.Params.Arguments = arguments
+			return downstream.GetPrompt(ctx, request)
+		})
+	}
+
+	readResource := func(request mcp.ReadResourceRequest) ([]interface{}, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), extensionTimeout)
+		defer cancel()
+
+		request.Params.URI = strings.TrimPrefix(request.Params.URI, ext.resourceURI(""))
+		result, err := downstream.ReadResource(ctx, request)
+		if err != nil {
+			return nil, err
+		}
+		return result.Contents, nil
+	}
+
+	for _, resource := range resources {
+		resource.URI = ext.resourceURI(resource.URI)
+		resource.Name = ext.toolName(resource.Name)
+		s.AddResource(resource, readResource)
+	}
+
+	for _, template := range templates {
+		template.URITemplate = ext.resourceURI(template.URITemplate)
+		template.Name = ext.toolName(template.Name)
+		s.AddResourceTemplate(template, readResource)
+	}
+
+	return nil
+}
@@ -0,0 +1,114 @@
+package tools
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/nguyenvanduocit/dev-kit/services"
+)
+
+// Access tells whether a tool group only reads from the services it talks to or may also change them
+type Access string
+
+const (
+	AccessRead  Access = "read"
+	AccessWrite Access = "write"
+)
+
+// ToolGroup describes a set of tools that are enabled or disabled together through ENABLE_TOOLS
+type ToolGroup struct {
+	Name        string
+	Description string
+	// RequiredEnv lists the environment variables the group needs to talk to its service.
+	// They are not checked when the server runs against cassettes or the fake backend.
+	RequiredEnv []string
+	// RequiredEnvFunc, when set, lists the required variables instead of RequiredEnv, for services
+	// whose configuration decides which credentials are needed
+	RequiredEnvFunc func() []string
+	// Offline is true when the group's service can be served by the replay or fake backends
+	Offline  bool
+	Access   Access
+	Register func(s *server.MCPServer) error
+}
+
+// Requires returns the environment variables the group needs with the current configuration
+func (g ToolGroup) Requires() []string {
+	if g.RequiredEnvFunc != nil {
+		return g.RequiredEnvFunc()
+	}
+	return g.RequiredEnv
+}
+
+// MissingEnv returns the required environment variables of the group that are not set
+func (g ToolGroup) MissingEnv() []string {
+	if g.Offline && services.IsOffline() {
+		return nil
+	}
+
+	var missing []string
+	for _, name := range g.Requires() {
+		if os.Getenv(name) == "" {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
+
+var (
+	groupsMu sync.Mutex
+	groups   = map[string]ToolGroup{}
+)
+
+// RegisterGroup adds a tool group to the registry. It panics if a group with the same name
+// is already registered, which for built-in groups is a programming error.
+func RegisterGroup(group ToolGroup) {
+	if err := addGroup(group); err != nil {
+		panic(err)
+	}
+}
+
+func addGroup(group ToolGroup) error {
+	groupsMu.Lock()
+	defer groupsMu.Unlock()
+
+	if group.Name == "" || group.Register == nil {
+		return fmt.Errorf("tool group needs a name and a register function")
+	}
+	if strings.ContainsAny(group.Name, ", ") {
+		return fmt.Errorf("tool group name %q must not contain commas or spaces", group.Name)
+	}
+	if _, exists := groups[group.Name]; exists {
+		return fmt.Errorf("tool group %q is already registered", group.Name)
+	}
+	if group.Access == "" {
+		group.Access = AccessWrite
+	}
+
+	groups[group.Name] = group
+	return nil
+}
+
+// Groups returns every registered tool group sorted by name
+func Groups() []ToolGroup {
+	groupsMu.Lock()
+	defer groupsMu.Unlock()
+
+	list := make([]ToolGroup, 0, len(groups))
+	for _, group := range groups {
+		list = append(list, group)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// infallible adapts a register function that cannot fail to ToolGroup.Register
+func infallible(register func(s *server.MCPServer)) func(s *server.MCPServer) error {
+	return func(s *server.MCPServer) error {
+		register(s)
+		return nil
+	}
+}
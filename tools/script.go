@@ -16,6 +16,15 @@ import (
 	"github.com/nguyenvanduocit/dev-kit/util"
 )
 
+func init() {
+	RegisterGroup(ToolGroup{
+		Name:        "script",
+		Description: "Run command line scripts on the local machine",
+		Access:      AccessWrite,
+		Register:    infallible(RegisterScriptTool),
+	})
+}
+
 // RegisterScriptTool registers the script execution tool with the MCP server
 func RegisterScriptTool(s *server.MCPServer) {
 	currentUser, err := user.Current()
@@ -30,31 +39,17 @@ func RegisterScriptTool(s *server.MCPServer) {
 		mcp.WithString("working_dir", mcp.DefaultString(currentUser.HomeDir), mcp.Description("Execution directory path (default: user home). Validated to prevent unauthorized access to system locations")),
 	)
 
-	s.AddTool(tool, util.ErrorGuard(scriptExecuteHandler))
+	s.AddTool(tool, util.ErrorGuard(util.TypedHandler(tool, scriptExecuteHandler)))
 }
 
-func scriptExecuteHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
-	// Get script content
-	contentElement, ok := arguments["content"]
-	if !ok {
-		return mcp.NewToolResultError("content must be provided"), nil
-	}
-	content, ok := contentElement.(string)
-	if !ok {
-		return mcp.NewToolResultError("content must be a string"), nil
-	}
-
-	// Get interpreter
-	interpreter := "/bin/sh"
-	if interpreterElement, ok := arguments["interpreter"]; ok {
-		interpreter = interpreterElement.(string)
-	}
+type scriptExecuteArgs struct {
+	Content     string `json:"content"`
+	Interpreter string `json:"interpreter"`
+	WorkingDir  string `json:"working_dir"`
+}
 
-	// Get working directory
-	workingDir := ""
-	if workingDirElement, ok := arguments["working_dir"]; ok {
-		workingDir = workingDirElement.(string)
-	}
+func scriptExecuteHandler(args scriptExecuteArgs) (*mcp.CallToolResult, error) {
+	content, interpreter, workingDir := args.Content, args.Interpreter, args.WorkingDir
 
 	// Create temporary script file
 	tmpFile, err := os.CreateTemp("", "script-*.sh")
@@ -0,0 +1,321 @@
+package util
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// ArgumentsError lists every problem found while decoding tool arguments
+type ArgumentsError []string
+
+func (e ArgumentsError) Error() string {
+	return "invalid arguments:\n- " + strings.Join(e, "\n- ")
+}
+
+// TypedHandler wraps a handler that takes its arguments as a struct. The arguments are
+// validated against the tool's input schema and decoded with DecodeArguments before the
+// handler is called.
+func TypedHandler[T any](tool mcp.Tool, handler func(args T) (*mcp.CallToolResult, error)) server.ToolHandlerFunc {
+	return func(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+		var args T
+		if err := DecodeArguments(tool.InputSchema, arguments, &args); err != nil {
+			return nil, err
+		}
+		return handler(args)
+	}
+}
+
+// DecodeArguments validates arguments against schema and stores them in the struct pointed
+// to by out. Struct fields are matched by their `json` tag. Schema defaults are applied to
+// missing arguments, and integer fields accept numeric strings. Required strings must not
+// be blank and arguments the schema does not declare are rejected. All missing, unknown and
+// invalid fields are reported at once as an ArgumentsError.
+func DecodeArguments(schema mcp.ToolInputSchema, arguments map[string]interface{}, out interface{}) error {
+	target := reflect.ValueOf(out)
+	if target.Kind() != reflect.Pointer || target.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("DecodeArguments expects a pointer to a struct, got %T", out)
+	}
+
+	values := make(map[string]interface{}, len(arguments))
+	for name, value := range arguments {
+		if value != nil {
+			values[name] = value
+		}
+	}
+
+	var problems []string
+	invalid := make(map[string]bool)
+	for _, name := range schema.Required {
+		value, ok := values[name]
+		if s, isString := value.(string); !ok || (isString && strings.TrimSpace(s) == "") {
+			problems = append(problems, fmt.Sprintf("%s: is required", name))
+			invalid[name] = true
+		}
+	}
+
+	for name := range values {
+		if _, known := schema.Properties[name]; known {
+			continue
+		}
+		if len(schema.Properties) == 0 {
+			problems = append(problems, fmt.Sprintf("%s: unknown argument, the tool takes none", name))
+		} else {
+			problems = append(problems, fmt.Sprintf("%s: unknown argument, expected one of %s", name, strings.Join(propertyNames(schema), ", ")))
+		}
+	}
+
+	for name, raw := range schema.Properties {
+		property, _ := raw.(map[string]interface{})
+		value, ok := values[name]
+		if !ok {
+			if def, hasDefault := property["default"]; hasDefault {
+				values[name] = def
+			}
+			continue
+		}
+		if invalid[name] {
+			continue
+		}
+		if problem := validateProperty(property, value); problem != "" {
+			problems = append(problems, fmt.Sprintf("%s: %s", name, problem))
+			invalid[name] = true
+		}
+	}
+
+	problems = append(problems, decodeFields(target.Elem(), values, invalid)...)
+
+	if len(problems) == 0 {
+		return nil
+	}
+
+	sort.Strings(problems)
+	return ArgumentsError(problems)
+}
+
+// propertyNames lists the arguments a schema accepts, sorted
+func propertyNames(schema mcp.ToolInputSchema) []string {
+	names := make([]string, 0, len(schema.Properties))
+	for name := range schema.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// decodeFields assigns values to the tagged fields of v, descending into embedded structs
+func decodeFields(v reflect.Value, values map[string]interface{}, invalid map[string]bool) []string {
+	var problems []string
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			problems = append(problems, decodeFields(v.Field(i), values, invalid)...)
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" || name == "-" || !field.IsExported() {
+			continue
+		}
+		value, ok := values[name]
+		if !ok || invalid[name] {
+			continue
+		}
+		// an empty string leaves optional non-string fields unset
+		if value == "" && field.Type.Kind() != reflect.String {
+			continue
+		}
+		if err := assign(v.Field(i), value); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", name, err))
+		}
+	}
+	return problems
+}
+
+func validateProperty(property map[string]interface{}, value interface{}) string {
+	switch property["type"] {
+	case "string":
+		s, ok := value.(string)
+		if !ok {
+			return fmt.Sprintf("expected a string, got %s", describe(value))
+		}
+		if enum, ok := property["enum"].([]string); ok && s != "" && !contains(enum, s) {
+			return fmt.Sprintf("must be one of %s, got %q", strings.Join(enum, ", "), s)
+		}
+		if min, ok := toFloat(property["minLength"]); ok && float64(len(s)) < min {
+			return fmt.Sprintf("must be at least %v characters long", min)
+		}
+		if max, ok := toFloat(property["maxLength"]); ok && float64(len(s)) > max {
+			return fmt.Sprintf("must be at most %v characters long", max)
+		}
+		if pattern, ok := property["pattern"].(string); ok && s != "" {
+			if matched, err := regexp.MatchString(pattern, s); err == nil && !matched {
+				return fmt.Sprintf("must match pattern %s, got %q", pattern, s)
+			}
+		}
+	case "number":
+		n, ok := toFloat(value)
+		if !ok {
+			return fmt.Sprintf("expected a number, got %s", describe(value))
+		}
+		if min, ok := toFloat(property["minimum"]); ok && n < min {
+			return fmt.Sprintf("must be at least %v, got %v", min, n)
+		}
+		if max, ok := toFloat(property["maximum"]); ok && n > max {
+			return fmt.Sprintf("must be at most %v, got %v", max, n)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return fmt.Sprintf("expected a boolean, got %s", describe(value))
+		}
+	case "array":
+		if _, ok := value.([]interface{}); !ok {
+			return fmt.Sprintf("expected an array, got %s", describe(value))
+		}
+	case "object":
+		if _, ok := value.(map[string]interface{}); !ok {
+			return fmt.Sprintf("expected an object, got %s", describe(value))
+		}
+	}
+	return ""
+}
+
+// assign stores value in field, converting between the JSON representation and the Go type
+func assign(field reflect.Value, value interface{}) error {
+	if field.Kind() == reflect.Pointer {
+		ptr := reflect.New(field.Type().Elem())
+		if err := assign(ptr.Elem(), value); err != nil {
+			return err
+		}
+		field.Set(ptr)
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		s, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("expected a string, got %s", describe(value))
+		}
+		field.SetString(s)
+	case reflect.Bool:
+		switch v := value.(type) {
+		case bool:
+			field.SetBool(v)
+		case string:
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return fmt.Errorf("expected a boolean, got %q", v)
+			}
+			field.SetBool(b)
+		default:
+			return fmt.Errorf("expected a boolean, got %s", describe(value))
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := toInt(value)
+		if err != nil {
+			return err
+		}
+		if field.OverflowInt(n) {
+			return fmt.Errorf("%d is out of range", n)
+		}
+		field.SetInt(n)
+	case reflect.Float32, reflect.Float64:
+		n, ok := toFloat(value)
+		if !ok {
+			s, isString := value.(string)
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+			if !isString || err != nil {
+				return fmt.Errorf("expected a number, got %s", describe(value))
+			}
+			n = parsed
+		}
+		field.SetFloat(n)
+	case reflect.Slice:
+		items, ok := value.([]interface{})
+		if !ok {
+			return fmt.Errorf("expected an array, got %s", describe(value))
+		}
+		slice := reflect.MakeSlice(field.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := assign(slice.Index(i), item); err != nil {
+				return fmt.Errorf("item %d: %v", i, err)
+			}
+		}
+		field.Set(slice)
+	case reflect.Map, reflect.Interface:
+		v := reflect.ValueOf(value)
+		if !v.Type().AssignableTo(field.Type()) {
+			return fmt.Errorf("expected %s, got %s", field.Type(), describe(value))
+		}
+		field.Set(v)
+	default:
+		return fmt.Errorf("unsupported field type %s", field.Type())
+	}
+	return nil
+}
+
+func toInt(value interface{}) (int64, error) {
+	if s, ok := value.(string); ok {
+		n, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("expected an integer, got %q", s)
+		}
+		return n, nil
+	}
+	f, ok := toFloat(value)
+	if !ok {
+		return 0, fmt.Errorf("expected an integer, got %s", describe(value))
+	}
+	if f != math.Trunc(f) {
+		return 0, fmt.Errorf("expected an integer, got %v", f)
+	}
+	return int64(f), nil
+}
+
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	}
+	return 0, false
+}
+
+func describe(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return fmt.Sprintf("string %q", v)
+	case bool:
+		return fmt.Sprintf("boolean %v", v)
+	case float64, float32, int, int64, int32:
+		return fmt.Sprintf("number %v", v)
+	case []interface{}:
+		return "an array"
+	case map[string]interface{}:
+		return "an object"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
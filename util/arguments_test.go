@@ -0,0 +1,193 @@
+package util
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+type testArgs struct {
+	Key     string                 `json:"key"`
+	Count   int                    `json:"count"`
+	Ratio   float64                `json:"ratio"`
+	Points  *float64               `json:"points"`
+	Enabled bool                   `json:"enabled"`
+	State   string                 `json:"state"`
+	Date    string                 `json:"date"`
+	Tags    []string               `json:"tags"`
+	Extra   map[string]interface{} `json:"extra"`
+	Limit   int                    `json:"limit"`
+	Verbose bool                   `json:"verbose"`
+}
+
+var testSchema = mcp.NewTool("test",
+	mcp.WithString("key", mcp.Required()),
+	mcp.WithNumber("count", mcp.DefaultNumber(30), mcp.Min(0), mcp.Max(100)),
+	mcp.WithNumber("ratio"),
+	mcp.WithNumber("points"),
+	mcp.WithBoolean("enabled", mcp.DefaultBool(true)),
+	mcp.WithString("state", mcp.DefaultString("open"), mcp.Enum("open", "closed")),
+	mcp.WithString("date", mcp.Pattern(`^\d{4}-\d{2}-\d{2}$`)),
+	mcp.WithArray("tags"),
+	mcp.WithObject("extra"),
+	mcp.WithString("limit"),
+	mcp.WithString("verbose"),
+).InputSchema
+
+func float(f float64) *float64 { return &f }
+
+func TestDecodeArguments(t *testing.T) {
+	tests := []struct {
+		name      string
+		arguments map[string]interface{}
+		want      testArgs
+	}{
+		{
+			name:      "defaults fill missing arguments",
+			arguments: map[string]interface{}{"key": "KP-1"},
+			want:      testArgs{Key: "KP-1", Count: 30, Enabled: true, State: "open"},
+		},
+		{
+			name: "values of every kind",
+			arguments: map[string]interface{}{
+				"key": "KP-1", "count": float64(5), "ratio": 0.5, "points": float64(3), "enabled": false,
+				"state": "closed", "date": "2026-10-18", "tags": []interface{}{"a", "b"}, "extra": map[string]interface{}{"x": "y"},
+			},
+			want: testArgs{
+				Key: "KP-1", Count: 5, Ratio: 0.5, Points: float(3), State: "closed", Date: "2026-10-18",
+				Tags: []string{"a", "b"}, Extra: map[string]interface{}{"x": "y"},
+			},
+		},
+		{
+			name:      "string arguments decoded into numeric and boolean fields",
+			arguments: map[string]interface{}{"key": "KP-1", "limit": " 7 ", "verbose": "true"},
+			want:      testArgs{Key: "KP-1", Count: 30, Enabled: true, State: "open", Limit: 7, Verbose: true},
+		},
+		{
+			name:      "null and empty optional values are left unset",
+			arguments: map[string]interface{}{"key": "KP-1", "limit": "", "verbose": "", "points": nil, "date": ""},
+			want:      testArgs{Key: "KP-1", Count: 30, Enabled: true, State: "open"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var got testArgs
+			if err := DecodeArguments(testSchema, test.arguments, &got); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestDecodeArgumentsErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		arguments map[string]interface{}
+		want      ArgumentsError
+	}{
+		{
+			name:      "missing required argument",
+			arguments: map[string]interface{}{},
+			want:      ArgumentsError{"key: is required"},
+		},
+		{
+			name:      "blank required argument",
+			arguments: map[string]interface{}{"key": "  \t"},
+			want:      ArgumentsError{"key: is required"},
+		},
+		{
+			name:      "unknown argument",
+			arguments: map[string]interface{}{"key": "KP-1", "issue_key": "KP-1"},
+			want:      ArgumentsError{"issue_key: unknown argument, expected one of count, date, enabled, extra, key, limit, points, ratio, state, tags, verbose"},
+		},
+		{
+			name: "every invalid field is reported",
+			arguments: map[string]interface{}{
+				"count": float64(101), "ratio": true, "enabled": "maybe", "state": "merged",
+				"date": "18/10/2026", "tags": "a,b", "extra": "x",
+			},
+			want: ArgumentsError{
+				"count: must be at most 100, got 101",
+				"date: must match pattern ^\\d{4}-\\d{2}-\\d{2}$, got \"18/10/2026\"",
+				"enabled: expected a boolean, got string \"maybe\"",
+				"extra: expected an object, got string \"x\"",
+				"key: is required",
+				"ratio: expected a number, got boolean true",
+				"state: must be one of open, closed, got \"merged\"",
+				"tags: expected an array, got string \"a,b\"",
+			},
+		},
+		{
+			name:      "number given as a string",
+			arguments: map[string]interface{}{"key": "KP-1", "ratio": "1.5"},
+			want:      ArgumentsError{"ratio: expected a number, got string \"1.5\""},
+		},
+		{
+			name:      "string that is not an integer",
+			arguments: map[string]interface{}{"key": "KP-1", "limit": "ten"},
+			want:      ArgumentsError{"limit: expected an integer, got \"ten\""},
+		},
+		{
+			name:      "fractional integer",
+			arguments: map[string]interface{}{"key": "KP-1", "count": 2.5},
+			want:      ArgumentsError{"count: expected an integer, got 2.5"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var got testArgs
+			err := DecodeArguments(testSchema, test.arguments, &got)
+			var problems ArgumentsError
+			if !errors.As(err, &problems) {
+				t.Fatalf("got error %v, want an ArgumentsError", err)
+			}
+			if !reflect.DeepEqual(problems, test.want) {
+				t.Errorf("got %q, want %q", problems, test.want)
+			}
+		})
+	}
+}
+
+func TestDecodeArgumentsNoArguments(t *testing.T) {
+	schema := mcp.NewTool("noop").InputSchema
+
+	var args struct{}
+	if err := DecodeArguments(schema, map[string]interface{}{}, &args); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	err := DecodeArguments(schema, map[string]interface{}{"verbose": true}, &args)
+	if want := (ArgumentsError{"verbose: unknown argument, the tool takes none"}); !reflect.DeepEqual(err, want) {
+		t.Errorf("got %v, want %v", err, want)
+	}
+}
+
+func TestDecodeArgumentsNeedsStructPointer(t *testing.T) {
+	var args testArgs
+	if err := DecodeArguments(testSchema, map[string]interface{}{"key": "KP-1"}, args); err == nil {
+		t.Error("decoding into a struct value succeeded, want an error")
+	}
+}
+
+func TestDecodeArgumentsEmbeddedStructs(t *testing.T) {
+	type base struct {
+		Key string `json:"key"`
+	}
+	var args struct {
+		base
+		Count int `json:"count"`
+	}
+	if err := DecodeArguments(testSchema, map[string]interface{}{"key": "KP-1", "count": float64(3)}, &args); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if args.Key != "KP-1" || args.Count != 3 {
+		t.Errorf("got %+v, want the key and count decoded", args)
+	}
+}
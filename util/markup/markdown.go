@@ -0,0 +1,623 @@
+package markup
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	mdFence     = regexp.MustCompile("^\\s{0,3}(`{3,}|~{3,})\\s*([^`\\s]*)")
+	mdHeading   = regexp.MustCompile(`^\s{0,3}(#{1,6})(?:\s+(.*?))?(?:\s+#+)?\s*$`)
+	mdRule      = regexp.MustCompile(`^\s{0,3}(?:(?:-\s*){3,}|(?:\*\s*){3,}|(?:_\s*){3,})$`)
+	mdListItem  = regexp.MustCompile(`^(\s*)([-*+]|\d{1,9}[.)])(\s+|$)(.*)$`)
+	mdTableSep  = regexp.MustCompile(`^\s*\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)*\|?\s*$`)
+	mdAlert     = regexp.MustCompile(`^\[!(NOTE|TIP|IMPORTANT|WARNING|CAUTION)\]\s*(.*)$`)
+	mdQuoteMark = regexp.MustCompile(`^\s{0,3}> ?`)
+)
+
+// alertPanels maps GitHub alert names used in Markdown to panel types
+var alertPanels = map[string]string{
+	"NOTE":      PanelInfo,
+	"TIP":       PanelSuccess,
+	"IMPORTANT": PanelNote,
+	"WARNING":   PanelWarning,
+	"CAUTION":   PanelError,
+}
+
+// ParseMarkdown parses CommonMark-style Markdown with GitHub tables and alerts
+func ParseMarkdown(source string) *Node {
+	lines := strings.Split(strings.ReplaceAll(source, "\r\n", "\n"), "\n")
+	return &Node{Kind: Document, Children: parseMarkdownBlocks(lines)}
+}
+
+func parseMarkdownBlocks(lines []string) []*Node {
+	var blocks []*Node
+	for i := 0; i < len(lines); {
+		line := expandTabs(lines[i])
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case trimmed == "":
+			i++
+
+		case mdFence.MatchString(line):
+			m := mdFence.FindStringSubmatch(line)
+			fence := m[1]
+			var code []string
+			for i++; i < len(lines); i++ {
+				if strings.HasPrefix(strings.TrimSpace(lines[i]), fence) {
+					i++
+					break
+				}
+				code = append(code, lines[i])
+			}
+			blocks = append(blocks, &Node{Kind: CodeBlock, Language: m[2], Text: strings.Join(code, "\n")})
+
+		case mdHeading.MatchString(line):
+			m := mdHeading.FindStringSubmatch(line)
+			blocks = append(blocks, &Node{Kind: Heading, Level: len(m[1]), Children: parseMarkdownInline(m[2])})
+			i++
+
+		case mdRule.MatchString(line):
+			blocks = append(blocks, &Node{Kind: Rule})
+			i++
+
+		case strings.HasPrefix(trimmed, "|") && i+1 < len(lines) && mdTableSep.MatchString(lines[i+1]):
+			var table *Node
+			table, i = parseMarkdownTable(lines, i)
+			blocks = append(blocks, table)
+
+		case mdQuoteMark.MatchString(line):
+			var inner []string
+			for ; i < len(lines) && mdQuoteMark.MatchString(lines[i]); i++ {
+				inner = append(inner, mdQuoteMark.ReplaceAllString(lines[i], ""))
+			}
+			blocks = append(blocks, parseMarkdownQuote(inner))
+
+		case mdListItem.MatchString(line):
+			var list *Node
+			list, i = parseMarkdownList(lines, i)
+			blocks = append(blocks, list)
+
+		default:
+			var paragraph []string
+			for ; i < len(lines) && strings.TrimSpace(lines[i]) != ""; i++ {
+				if len(paragraph) > 0 && startsMarkdownBlock(lines, i) {
+					break
+				}
+				paragraph = append(paragraph, strings.TrimLeft(lines[i], " \t"))
+			}
+			blocks = append(blocks, &Node{Kind: Paragraph, Children: parseMarkdownLines(paragraph)})
+		}
+	}
+	return blocks
+}
+
+// startsMarkdownBlock tells whether lines[i] interrupts a paragraph
+func startsMarkdownBlock(lines []string, i int) bool {
+	line := expandTabs(lines[i])
+	return mdFence.MatchString(line) || mdHeading.MatchString(line) || mdRule.MatchString(line) ||
+		mdQuoteMark.MatchString(line) || mdListItem.MatchString(line) ||
+		(strings.HasPrefix(strings.TrimSpace(line), "|") && i+1 < len(lines) && mdTableSep.MatchString(lines[i+1]))
+}
+
+// parseMarkdownLines parses the lines of a paragraph, keeping line breaks
+func parseMarkdownLines(lines []string) []*Node {
+	var nodes []*Node
+	for i, line := range lines {
+		hard := strings.HasSuffix(line, "  ") || strings.HasSuffix(line, "\\")
+		line = strings.TrimRight(line, " ")
+		if i < len(lines)-1 {
+			line = strings.TrimSuffix(line, "\\")
+		}
+		nodes = append(nodes, parseMarkdownInline(line)...)
+		if i < len(lines)-1 {
+			if hard {
+				nodes = append(nodes, &Node{Kind: HardBreak})
+			} else {
+				nodes = append(nodes, &Node{Kind: SoftBreak})
+			}
+		}
+	}
+	return nodes
+}
+
+func parseMarkdownQuote(lines []string) *Node {
+	if len(lines) > 0 {
+		if m := mdAlert.FindStringSubmatch(strings.TrimSpace(lines[0])); m != nil {
+			return &Node{Kind: Panel, PanelType: alertPanels[m[1]], Title: m[2], Children: parseMarkdownBlocks(lines[1:])}
+		}
+	}
+	return &Node{Kind: Quote, Children: parseMarkdownBlocks(lines)}
+}
+
+func parseMarkdownTable(lines []string, i int) (*Node, int) {
+	table := &Node{Kind: Table}
+	header := table.add(&Node{Kind: TableRow})
+	for _, cell := range splitTableRow(lines[i]) {
+		header.add(&Node{Kind: TableCell, Header: true, Children: parseMarkdownInline(cell)})
+	}
+
+	for i += 2; i < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i]), "|"); i++ {
+		row := table.add(&Node{Kind: TableRow})
+		for _, cell := range splitTableRow(lines[i]) {
+			row.add(&Node{Kind: TableCell, Children: parseMarkdownInline(cell)})
+		}
+	}
+	return table, i
+}
+
+// splitTableRow splits a Markdown table row on pipes that are not escaped or inside code
+func splitTableRow(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+	if strings.HasSuffix(line, "|") && !strings.HasSuffix(line, "\\|") {
+		line = line[:len(line)-1]
+	}
+
+	var cells []string
+	var cell strings.Builder
+	inCode := false
+	for i := 0; i < len(line); i++ {
+		switch {
+		case line[i] == '\\' && i+1 < len(line) && line[i+1] == '|':
+			cell.WriteByte('|')
+			i++
+		case line[i] == '`':
+			inCode = !inCode
+			cell.WriteByte('`')
+		case line[i] == '|' && !inCode:
+			cells = append(cells, strings.TrimSpace(cell.String()))
+			cell.Reset()
+		default:
+			cell.WriteByte(line[i])
+		}
+	}
+	return append(cells, strings.TrimSpace(cell.String()))
+}
+
+func parseMarkdownList(lines []string, i int) (*Node, int) {
+	first := mdListItem.FindStringSubmatch(expandTabs(lines[i]))
+	baseIndent := len(first[1])
+	ordered := !strings.ContainsAny(first[2], "-*+")
+	list := &Node{Kind: List, Ordered: ordered}
+
+	for i < len(lines) {
+		m := mdListItem.FindStringSubmatch(expandTabs(lines[i]))
+		if m == nil || len(m[1]) < baseIndent || len(m[1]) > baseIndent+1 || ordered == strings.ContainsAny(m[2], "-*+") {
+			break
+		}
+
+		offset := len(m[1]) + len(m[2]) + len(m[3])
+		if len(m[3]) > 4 || m[4] == "" {
+			offset = len(m[1]) + len(m[2]) + 1
+		}
+
+		content := []string{m[4]}
+		for i++; i < len(lines); i++ {
+			line := expandTabs(lines[i])
+			if strings.TrimSpace(line) == "" {
+				next := i + 1
+				for next < len(lines) && strings.TrimSpace(lines[next]) == "" {
+					next++
+				}
+				if next < len(lines) && indentOf(expandTabs(lines[next])) >= offset {
+					content = append(content, "")
+					continue
+				}
+				break
+			}
+
+			indent := indentOf(line)
+			switch {
+			case indent > baseIndent:
+				content = append(content, line[min(indent, offset):])
+			case !startsMarkdownBlock(lines, i) && strings.TrimSpace(content[len(content)-1]) != "":
+				// a lazy continuation of the item's paragraph
+				content = append(content, strings.TrimSpace(line))
+			default:
+				goto done
+			}
+		}
+	done:
+		list.add(&Node{Kind: ListItem, Children: parseMarkdownBlocks(content)})
+
+		// blank lines between items of a loose list
+		next := i
+		for next < len(lines) && strings.TrimSpace(lines[next]) == "" {
+			next++
+		}
+		if next < len(lines) && next > i {
+			if m := mdListItem.FindStringSubmatch(expandTabs(lines[next])); m != nil && len(m[1]) == baseIndent {
+				i = next
+			}
+		}
+	}
+	return list, i
+}
+
+func indentOf(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+func expandTabs(line string) string {
+	indent := 0
+	for _, c := range line {
+		switch c {
+		case ' ':
+			indent++
+		case '\t':
+			indent += 4 - indent%4
+		default:
+			return strings.Repeat(" ", indent) + strings.TrimLeft(line, " \t")
+		}
+	}
+	return line
+}
+
+// parseMarkdownInline parses emphasis, code spans, links, images and mentions in a line
+func parseMarkdownInline(s string) []*Node {
+	var nodes []*Node
+	var text strings.Builder
+	flush := func() {
+		if text.Len() > 0 {
+			nodes = append(nodes, &Node{Kind: Text, Text: text.String()})
+			text.Reset()
+		}
+	}
+	emit := func(node *Node) {
+		flush()
+		nodes = append(nodes, node)
+	}
+
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s) && isPunct(s[i+1]):
+			text.WriteByte(s[i+1])
+			i += 2
+			continue
+
+		case c == '`':
+			run := runLength(s, i, '`')
+			if end := strings.Index(s[i+run:], strings.Repeat("`", run)); end >= 0 {
+				code := s[i+run : i+run+end]
+				if len(code) > 1 && code[0] == ' ' && code[len(code)-1] == ' ' {
+					code = code[1 : len(code)-1]
+				}
+				emit(&Node{Kind: Code, Text: code})
+				i += run + end + run
+				continue
+			}
+			text.WriteString(s[i : i+run])
+			i += run
+			continue
+
+		case c == '!' && strings.HasPrefix(s[i+1:], "["):
+			if label, url, n := parseMarkdownLink(s[i+1:]); n > 0 {
+				emit(&Node{Kind: Image, URL: url, Text: label})
+				i += 1 + n
+				continue
+			}
+
+		case c == '[' && strings.HasPrefix(s[i:], "[~"):
+			if end := strings.IndexByte(s[i:], ']'); end > 2 {
+				emit(&Node{Kind: Mention, Text: s[i+2 : i+end]})
+				i += end + 1
+				continue
+			}
+
+		case c == '[':
+			if label, url, n := parseMarkdownLink(s[i:]); n > 0 {
+				emit(&Node{Kind: Link, URL: url, Children: parseMarkdownInline(label)})
+				i += n
+				continue
+			}
+
+		case c == '<' && strings.HasPrefix(s[i:], "<ins>"):
+			if end := strings.Index(s[i+5:], "</ins>"); end > 0 {
+				emit(&Node{Kind: Underline, Children: parseMarkdownInline(s[i+5 : i+5+end])})
+				i += 5 + end + 6
+				continue
+			}
+
+		case c == '<':
+			if end := strings.IndexByte(s[i:], '>'); end > 0 && strings.Contains(s[i:i+end], "://") && !strings.ContainsAny(s[i+1:i+end], " <") {
+				url := s[i+1 : i+end]
+				emit(&Node{Kind: Link, URL: url, Children: []*Node{{Kind: Text, Text: url}}})
+				i += end + 1
+				continue
+			}
+
+		case c == '~' && strings.HasPrefix(s[i:], "~~"):
+			if end := findCloser(s, i+2, "~~", false); end > i+2 {
+				emit(&Node{Kind: Strike, Children: parseMarkdownInline(s[i+2 : end])})
+				i = end + 2
+				continue
+			}
+
+		case c == '*' || c == '_':
+			run := runLength(s, i, c)
+			if run > 3 {
+				break
+			}
+			opens := i+run < len(s) && s[i+run] != ' '
+			if c == '_' && i > 0 && isAlnum(s[i-1]) {
+				opens = false
+			}
+			delim := strings.Repeat(string(c), run)
+			if end := findCloser(s, i+run, delim, c == '_'); opens && end > i+run {
+				inner := parseMarkdownInline(s[i+run : end])
+				var node *Node
+				switch run {
+				case 1:
+					node = &Node{Kind: Emphasis, Children: inner}
+				case 2:
+					node = &Node{Kind: Strong, Children: inner}
+				default:
+					node = &Node{Kind: Strong, Children: []*Node{{Kind: Emphasis, Children: inner}}}
+				}
+				emit(node)
+				i = end + run
+				continue
+			}
+			text.WriteString(delim)
+			i += run
+			continue
+		}
+
+		text.WriteByte(c)
+		i++
+	}
+	flush()
+	return nodes
+}
+
+// parseMarkdownLink parses [label](url "title") at the start of s and returns its length
+func parseMarkdownLink(s string) (label, url string, n int) {
+	depth := 0
+	closeLabel := -1
+	for i := 0; i < len(s) && closeLabel < 0; i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				closeLabel = i
+			}
+		}
+	}
+	if closeLabel < 0 || closeLabel+1 >= len(s) || s[closeLabel+1] != '(' {
+		return "", "", 0
+	}
+
+	depth = 0
+	for i := closeLabel + 1; i < len(s); i++ {
+		switch s[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				target := strings.TrimSpace(s[closeLabel+2 : i])
+				if space := strings.IndexAny(target, " \t"); space >= 0 {
+					target = target[:space]
+				}
+				target = strings.TrimSuffix(strings.TrimPrefix(target, "<"), ">")
+				return s[1:closeLabel], target, i + 1
+			}
+		}
+	}
+	return "", "", 0
+}
+
+// findCloser finds the closing delimiter for an opener ending at from, skipping code spans
+func findCloser(s string, from int, delim string, wordBoundary bool) int {
+	for j := from; j < len(s); j++ {
+		switch {
+		case s[j] == '\\':
+			j++
+		case s[j] == '`':
+			run := runLength(s, j, '`')
+			if end := strings.Index(s[j+run:], strings.Repeat("`", run)); end >= 0 {
+				j += run + end + run - 1
+			}
+		case strings.HasPrefix(s[j:], delim):
+			run := runLength(s, j, delim[0])
+			if run == len(delim) && j > from && s[j-1] != ' ' &&
+				(!wordBoundary || j+run >= len(s) || !isAlnum(s[j+run])) {
+				return j
+			}
+			j += run - 1
+		}
+	}
+	return -1
+}
+
+func runLength(s string, i int, c byte) int {
+	n := 0
+	for i+n < len(s) && s[i+n] == c {
+		n++
+	}
+	return n
+}
+
+func isAlnum(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+func isPunct(c byte) bool {
+	return strings.IndexByte("!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~", c) >= 0
+}
+
+// RenderMarkdown renders a document tree as Markdown
+func RenderMarkdown(doc *Node) string {
+	return renderMarkdownBlocks(doc.Children)
+}
+
+func renderMarkdownBlocks(blocks []*Node) string {
+	var parts []string
+	for _, block := range blocks {
+		parts = append(parts, renderMarkdownBlock(block))
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+func renderMarkdownBlock(n *Node) string {
+	switch n.Kind {
+	case Heading:
+		return strings.Repeat("#", max(1, min(n.Level, 6))) + " " + renderMarkdownInline(n.Children)
+	case CodeBlock:
+		fence := "```"
+		for strings.Contains(n.Text, fence) {
+			fence += "`"
+		}
+		return fence + n.Language + "\n" + n.Text + "\n" + fence
+	case Rule:
+		return "---"
+	case Quote:
+		return prefixLines(renderMarkdownBlocks(n.Children), "> ", ">")
+	case Panel:
+		alert := "NOTE"
+		for name, panelType := range alertPanels {
+			if panelType == n.PanelType {
+				alert = name
+			}
+		}
+		header := "[!" + alert + "]"
+		if n.Title != "" {
+			header += " " + n.Title
+		}
+		body := renderMarkdownBlocks(n.Children)
+		if body != "" {
+			header += "\n" + body
+		}
+		return prefixLines(header, "> ", ">")
+	case List:
+		var items []string
+		for i, item := range n.Children {
+			marker := "- "
+			if n.Ordered {
+				marker = fmt.Sprintf("%d. ", i+1)
+			}
+			var content strings.Builder
+			for j, block := range item.Children {
+				if j > 0 {
+					if block.Kind == List {
+						content.WriteString("\n")
+					} else {
+						content.WriteString("\n\n")
+					}
+				}
+				content.WriteString(renderMarkdownBlock(block))
+			}
+			items = append(items, marker+prefixLines(content.String(), strings.Repeat(" ", len(marker)), "")[len(marker):])
+		}
+		return strings.Join(items, "\n")
+	case Table:
+		var rows []string
+		for i, row := range n.Children {
+			var cells []string
+			for _, cell := range row.Children {
+				text := renderMarkdownInline(cell.Children)
+				text = strings.ReplaceAll(text, "|", "\\|")
+				text = strings.ReplaceAll(text, "\n", " ")
+				cells = append(cells, text)
+			}
+			rows = append(rows, "| "+strings.Join(cells, " | ")+" |")
+			if i == 0 {
+				rows = append(rows, "|"+strings.Repeat(" --- |", len(cells)))
+			}
+		}
+		return strings.Join(rows, "\n")
+	case Paragraph:
+		return renderMarkdownInline(n.Children)
+	}
+	return renderMarkdownInline([]*Node{n})
+}
+
+func renderMarkdownInline(nodes []*Node) string {
+	var sb strings.Builder
+	for _, n := range nodes {
+		switch n.Kind {
+		case Text:
+			sb.WriteString(escapeMarkdown(n.Text))
+		case Strong:
+			sb.WriteString("**" + renderMarkdownInline(n.Children) + "**")
+		case Emphasis:
+			sb.WriteString("_" + renderMarkdownInline(n.Children) + "_")
+		case Strike:
+			sb.WriteString("~~" + renderMarkdownInline(n.Children) + "~~")
+		case Underline:
+			// Markdown has no underline, GitHub and most renderers accept the HTML tag
+			sb.WriteString("<ins>" + renderMarkdownInline(n.Children) + "</ins>")
+		case Code:
+			fence := "`"
+			for strings.Contains(n.Text, fence) {
+				fence += "`"
+			}
+			if strings.HasPrefix(n.Text, "`") || strings.HasSuffix(n.Text, "`") {
+				sb.WriteString(fence + " " + n.Text + " " + fence)
+			} else {
+				sb.WriteString(fence + n.Text + fence)
+			}
+		case Link:
+			label := renderMarkdownInline(n.Children)
+			if label == "" || label == n.URL {
+				sb.WriteString("<" + n.URL + ">")
+			} else {
+				sb.WriteString("[" + label + "](" + n.URL + ")")
+			}
+		case Image:
+			sb.WriteString("![" + n.Text + "](" + n.URL + ")")
+		case Mention:
+			sb.WriteString("[~" + n.Text + "]")
+		case SoftBreak:
+			sb.WriteString("\n")
+		case HardBreak:
+			sb.WriteString("\\\n")
+		default:
+			if isBlock(n.Kind) {
+				sb.WriteString(renderMarkdownBlock(n))
+			} else {
+				sb.WriteString(renderMarkdownInline(n.Children))
+			}
+		}
+	}
+	return sb.String()
+}
+
+// escapeMarkdown escapes the characters that would start emphasis, code or a link
+func escapeMarkdown(text string) string {
+	var sb strings.Builder
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case c == '\\' || c == '*' || c == '`' || c == '[':
+			sb.WriteByte('\\')
+		case c == '_' && (i == 0 || i == len(text)-1 || !isAlnum(text[i-1]) || !isAlnum(text[i+1])):
+			sb.WriteByte('\\')
+		case c == '~' && i+1 < len(text) && text[i+1] == '~':
+			sb.WriteByte('\\')
+		}
+		sb.WriteByte(c)
+	}
+	return sb.String()
+}
+
+// prefixLines prefixes every line of text, using emptyPrefix for empty lines
+func prefixLines(text, prefix, emptyPrefix string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if line == "" {
+			lines[i] = emptyPrefix
+		} else {
+			lines[i] = prefix + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
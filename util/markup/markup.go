@@ -0,0 +1,11 @@
+package markup
+
+// MarkdownToWiki converts Markdown into Jira wiki markup, the format of the Jira REST API v2
+func MarkdownToWiki(markdown string) string {
+	return RenderWiki(ParseMarkdown(markdown))
+}
+
+// WikiToMarkdown converts Jira wiki markup into Markdown
+func WikiToMarkdown(wiki string) string {
+	return RenderMarkdown(ParseWiki(wiki))
+}
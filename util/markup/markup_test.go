@@ -0,0 +1,92 @@
+package markup
+
+import "testing"
+
+// Each case is converted in both directions. The Markdown is written the way RenderMarkdown
+// emits it, so converting back must reproduce it exactly.
+var wikiTests = []struct {
+	name     string
+	markdown string
+	wiki     string
+}{
+	{
+		name:     "headings",
+		markdown: "# Title\n\n## Sub _title_\n\n###### Smallest",
+		wiki:     "h1. Title\n\nh2. Sub _title_\n\nh6. Smallest",
+	},
+	{
+		name:     "inline formatting",
+		markdown: "**bold**, _emphasis_, ~~strike~~ and `code`",
+		wiki:     "*bold*, _emphasis_, -strike- and {{code}}",
+	},
+	{
+		name:     "underline",
+		markdown: "<ins>underlined</ins> and <ins>**bold underline**</ins>",
+		wiki:     "+underlined+ and +*bold underline*+",
+	},
+	{
+		name:     "inline code with braces",
+		markdown: "Use `map[string]struct{}` or `}}` and `{code}`",
+		wiki:     "Use {{map[string]struct\\{\\}}} or {{\\}\\}}} and {{\\{code\\}}}",
+	},
+	{
+		name:     "inline code with braces inside an effect",
+		markdown: "**see `x}*` here**",
+		wiki:     "*see {{x\\}*}} here*",
+	},
+	{
+		name:     "nested lists",
+		markdown: "- one\n  - nested **bold**\n    1. deep\n- two\n\n1. first\n2. second",
+		wiki:     "* one\n** nested *bold*\n**# deep\n* two\n\n# first\n# second",
+	},
+	{
+		name:     "code fences",
+		markdown: "```go\nfunc main() {\n\tfmt.Println(\"*not bold*\")\n}\n```\n\n```\nplain\n```",
+		wiki:     "{code:go}\nfunc main() {\n\tfmt.Println(\"*not bold*\")\n}\n{code}\n\n{noformat}\nplain\n{noformat}",
+	},
+	{
+		name:     "tables",
+		markdown: "| Name | Status |\n| --- | --- |\n| KP-1 | **Done** |\n| KP-2 | `open` |",
+		wiki:     "||Name||Status||\n|KP-1|*Done*|\n|KP-2|{{open}}|",
+	},
+	{
+		name:     "links",
+		markdown: "See [the docs](https://example.com/docs) or <https://example.com>",
+		wiki:     "See [the docs|https://example.com/docs] or [https://example.com]",
+	},
+	{
+		name:     "mentions",
+		markdown: "Ping [~jdoe] and [~accountid:5b10ac8d82e05b22cc7d4ef5]",
+		wiki:     "Ping [~jdoe] and [~accountid:5b10ac8d82e05b22cc7d4ef5]",
+	},
+	{
+		name:     "panels",
+		markdown: "> [!WARNING] Careful\n> Do not run this twice\n\n> [!NOTE]\n> Informational\n\n> quoted",
+		wiki:     "{warning:title=Careful}\nDo not run this twice\n{warning}\n\n{info}\nInformational\n{info}\n\n{quote}\nquoted\n{quote}",
+	},
+	{
+		name:     "rule",
+		markdown: "above\n\n---\n\nbelow",
+		wiki:     "above\n\n----\n\nbelow",
+	},
+}
+
+func TestMarkdownToWiki(t *testing.T) {
+	for _, test := range wikiTests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := MarkdownToWiki(test.markdown); got != test.wiki {
+				t.Errorf("got\n%s\nwant\n%s", got, test.wiki)
+			}
+		})
+	}
+}
+
+func TestWikiToMarkdown(t *testing.T) {
+	for _, test := range wikiTests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := WikiToMarkdown(test.wiki); got != test.markdown {
+				t.Errorf("got\n%s\nwant\n%s", got, test.markdown)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+// Package markup converts rich text between Markdown and Jira wiki markup, the format of the
+// Jira REST API v2. Both formats are parsed into the same small document tree and rendered
+// from it, so further formats can be added alongside them.
+package markup
+
+// Kind identifies the type of a Node
+type Kind int
+
+const (
+	// Block nodes
+	Document Kind = iota
+	Paragraph
+	Heading
+	List
+	ListItem
+	CodeBlock
+	Quote
+	Panel
+	Rule
+	Table
+	TableRow
+	TableCell
+
+	// Inline nodes
+	Text
+	Strong
+	Emphasis
+	Strike
+	Underline
+	Code
+	Link
+	Image
+	Mention
+	SoftBreak
+	HardBreak
+)
+
+// Panel types, named after the panel types of Atlassian Cloud
+const (
+	PanelInfo    = "info"
+	PanelNote    = "note"
+	PanelWarning = "warning"
+	PanelSuccess = "success"
+	PanelError   = "error"
+)
+
+// Node is an element of a document tree
+type Node struct {
+	Kind     Kind
+	Children []*Node
+
+	// Text is the content of Text and Code nodes, the source of CodeBlock nodes and the
+	// account ID or username of Mention nodes
+	Text string
+	// Level is the level of Heading nodes
+	Level int
+	// Ordered is set on numbered List nodes
+	Ordered bool
+	// Header is set on TableCell nodes of a header row
+	Header bool
+	// Language is the language of CodeBlock nodes
+	Language string
+	// URL is the target of Link and Image nodes
+	URL string
+	// PanelType is one of the Panel constants, Title the optional panel title
+	PanelType string
+	Title     string
+}
+
+func (n *Node) add(child *Node) *Node {
+	n.Children = append(n.Children, child)
+	return child
+}
+
+func isBlock(kind Kind) bool {
+	return kind < Text
+}
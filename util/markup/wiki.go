@@ -0,0 +1,522 @@
+package markup
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	wikiHeading  = regexp.MustCompile(`^\s*h([1-6])\.\s*(.*)$`)
+	wikiBlockTag = regexp.MustCompile(`^\s*\{(code|noformat|quote|panel|info|note|warning|tip)(?::([^}]*))?\}(.*)$`)
+	wikiQuote    = regexp.MustCompile(`^\s*bq\.\s+(.*)$`)
+	wikiRule     = regexp.MustCompile(`^\s*-{4,}\s*$`)
+	wikiListItem = regexp.MustCompile(`^\s*([*#]+|-)\s+(.*)$`)
+	wikiColor    = regexp.MustCompile(`\{color(?::[^}]*)?\}`)
+)
+
+// wikiPanels maps Jira wiki panel macros to panel types
+var wikiPanels = map[string]string{
+	"panel":   PanelInfo,
+	"info":    PanelInfo,
+	"note":    PanelNote,
+	"warning": PanelWarning,
+	"tip":     PanelSuccess,
+}
+
+// ParseWiki parses Jira wiki markup
+func ParseWiki(source string) *Node {
+	lines := strings.Split(strings.ReplaceAll(source, "\r\n", "\n"), "\n")
+	return &Node{Kind: Document, Children: parseWikiBlocks(lines)}
+}
+
+func parseWikiBlocks(lines []string) []*Node {
+	var blocks []*Node
+	var lists []*Node
+
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		if !wikiListItem.MatchString(line) || wikiRule.MatchString(line) {
+			lists = nil
+		}
+
+		switch {
+		case strings.TrimSpace(line) == "":
+			i++
+
+		case wikiBlockTag.MatchString(line):
+			m := wikiBlockTag.FindStringSubmatch(line)
+			var body []string
+			body, i = wikiMacroBody(lines, i, m[1], m[3])
+			params := wikiMacroParams(m[2])
+
+			switch m[1] {
+			case "code", "noformat":
+				language := params["language"]
+				if language == "" && m[1] == "code" {
+					language = params[""]
+				}
+				blocks = append(blocks, &Node{Kind: CodeBlock, Language: language, Text: strings.Join(body, "\n")})
+			case "quote":
+				blocks = append(blocks, &Node{Kind: Quote, Children: parseWikiBlocks(body)})
+			default:
+				blocks = append(blocks, &Node{Kind: Panel, PanelType: wikiPanels[m[1]], Title: params["title"], Children: parseWikiBlocks(body)})
+			}
+
+		case wikiHeading.MatchString(line):
+			m := wikiHeading.FindStringSubmatch(line)
+			level := int(m[1][0] - '0')
+			blocks = append(blocks, &Node{Kind: Heading, Level: level, Children: parseWikiInline(m[2])})
+			i++
+
+		case wikiQuote.MatchString(line):
+			m := wikiQuote.FindStringSubmatch(line)
+			blocks = append(blocks, &Node{Kind: Quote, Children: []*Node{{Kind: Paragraph, Children: parseWikiInline(m[1])}}})
+			i++
+
+		case wikiRule.MatchString(line):
+			blocks = append(blocks, &Node{Kind: Rule})
+			i++
+
+		case strings.HasPrefix(strings.TrimSpace(line), "|"):
+			table := &Node{Kind: Table}
+			for ; i < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i]), "|"); i++ {
+				table.add(parseWikiTableRow(strings.TrimSpace(lines[i])))
+			}
+			blocks = append(blocks, table)
+
+		case wikiListItem.MatchString(line):
+			m := wikiListItem.FindStringSubmatch(line)
+			markers := strings.ReplaceAll(m[1], "-", "*")
+			// keep the open lists whose type matches the markers, then open the missing ones
+			keep := 0
+			for keep < len(lists) && keep < len(markers) && lists[keep].Ordered == (markers[keep] == '#') {
+				keep++
+			}
+			lists = lists[:keep]
+			for len(lists) < len(markers) {
+				list := &Node{Kind: List, Ordered: markers[len(lists)] == '#'}
+				if len(lists) == 0 {
+					blocks = append(blocks, list)
+				} else {
+					parent := lists[len(lists)-1]
+					if len(parent.Children) == 0 {
+						parent.add(&Node{Kind: ListItem})
+					}
+					item := parent.Children[len(parent.Children)-1]
+					item.add(list)
+				}
+				lists = append(lists, list)
+			}
+			lists[len(lists)-1].add(&Node{Kind: ListItem, Children: []*Node{{Kind: Paragraph, Children: parseWikiInline(m[2])}}})
+			i++
+
+		default:
+			var paragraph []*Node
+			for ; i < len(lines) && strings.TrimSpace(lines[i]) != ""; i++ {
+				if len(paragraph) > 0 {
+					if startsWikiBlock(lines[i]) {
+						break
+					}
+					paragraph = append(paragraph, &Node{Kind: SoftBreak})
+				}
+				paragraph = append(paragraph, parseWikiInline(strings.TrimSpace(lines[i]))...)
+			}
+			blocks = append(blocks, &Node{Kind: Paragraph, Children: paragraph})
+		}
+	}
+	return blocks
+}
+
+func startsWikiBlock(line string) bool {
+	return wikiBlockTag.MatchString(line) || wikiHeading.MatchString(line) || wikiQuote.MatchString(line) ||
+		wikiRule.MatchString(line) || wikiListItem.MatchString(line) || strings.HasPrefix(strings.TrimSpace(line), "|")
+}
+
+// wikiMacroBody collects the lines of a {macro}...{macro} block starting at lines[i], whose
+// opening tag is followed by rest
+func wikiMacroBody(lines []string, i int, macro, rest string) ([]string, int) {
+	closing := "{" + macro + "}"
+	if end := strings.Index(rest, closing); end >= 0 {
+		return []string{rest[:end]}, i + 1
+	}
+
+	var body []string
+	if strings.TrimSpace(rest) != "" {
+		body = append(body, rest)
+	}
+	for i++; i < len(lines); i++ {
+		if end := strings.Index(lines[i], closing); end >= 0 {
+			if before := lines[i][:end]; strings.TrimSpace(before) != "" {
+				body = append(body, before)
+			}
+			return body, i + 1
+		}
+		body = append(body, lines[i])
+	}
+	return body, i
+}
+
+// wikiMacroParams parses "java" or "title=Foo|borderStyle=solid" into a map, with a bare value under ""
+func wikiMacroParams(params string) map[string]string {
+	values := map[string]string{}
+	for _, param := range strings.Split(params, "|") {
+		if key, value, ok := strings.Cut(param, "="); ok {
+			values[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		} else if param != "" {
+			values[""] = strings.TrimSpace(param)
+		}
+	}
+	return values
+}
+
+// parseWikiTableRow parses "||a||b||" header rows and "|a|b|" rows, ignoring pipes inside links
+func parseWikiTableRow(line string) *Node {
+	row := &Node{Kind: TableRow}
+	var cell strings.Builder
+	header := false
+	started := false
+	depth := 0
+
+	finish := func() {
+		if started {
+			row.add(&Node{Kind: TableCell, Header: header, Children: parseWikiInline(strings.TrimSpace(cell.String()))})
+		}
+		cell.Reset()
+	}
+
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case c == '\\' && i+1 < len(line):
+			cell.WriteString(line[i : i+2])
+			i++
+		case c == '[' || c == '{':
+			depth++
+			cell.WriteByte(c)
+		case (c == ']' || c == '}') && depth > 0:
+			depth--
+			cell.WriteByte(c)
+		case c == '|' && depth == 0:
+			finish()
+			header = strings.HasPrefix(line[i:], "||")
+			if header {
+				i++
+			}
+			started = true
+		default:
+			cell.WriteByte(c)
+		}
+	}
+	if strings.TrimSpace(cell.String()) != "" {
+		finish()
+	}
+	return row
+}
+
+// wikiEffects maps wiki inline effect delimiters to node kinds
+var wikiEffects = map[string]Kind{
+	"*":  Strong,
+	"_":  Emphasis,
+	"-":  Strike,
+	"+":  Underline,
+	"??": Emphasis,
+}
+
+// parseWikiInline parses effects, monospace, links, mentions and images in a line
+func parseWikiInline(s string) []*Node {
+	s = wikiColor.ReplaceAllString(s, "")
+
+	var nodes []*Node
+	var text strings.Builder
+	flush := func() {
+		if text.Len() > 0 {
+			nodes = append(nodes, &Node{Kind: Text, Text: text.String()})
+			text.Reset()
+		}
+	}
+	emit := func(node ...*Node) {
+		flush()
+		nodes = append(nodes, node...)
+	}
+
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case strings.HasPrefix(s[i:], `\\`):
+			emit(&Node{Kind: HardBreak})
+			i += 2
+			continue
+
+		case c == '\\' && i+1 < len(s):
+			text.WriteByte(s[i+1])
+			i += 2
+			continue
+
+		case strings.HasPrefix(s[i:], "{{"):
+			if end := wikiMonospaceEnd(s, i+2); end >= 0 {
+				emit(&Node{Kind: Code, Text: wikiMonospaceUnescaper.Replace(s[i+2 : end])})
+				i = end + 2
+				continue
+			}
+
+		case c == '[':
+			end := strings.IndexByte(s[i:], ']')
+			if end < 0 {
+				break
+			}
+			inner := s[i+1 : i+end]
+			i += end + 1
+			switch {
+			case strings.HasPrefix(inner, "~"):
+				emit(&Node{Kind: Mention, Text: inner[1:]})
+			case strings.Contains(inner, "|"):
+				label, url, _ := strings.Cut(inner, "|")
+				emit(&Node{Kind: Link, URL: strings.TrimSpace(url), Children: parseWikiInline(label)})
+			default:
+				emit(&Node{Kind: Link, URL: strings.TrimSpace(inner), Children: []*Node{{Kind: Text, Text: strings.TrimSpace(inner)}}})
+			}
+			continue
+
+		case c == '!':
+			if end := strings.IndexByte(s[i+1:], '!'); end > 0 && !strings.ContainsAny(s[i+1:i+1+end], " \t") {
+				target, params, _ := strings.Cut(s[i+1:i+1+end], "|")
+				emit(&Node{Kind: Image, URL: target, Text: wikiMacroParams(params)["alt"]})
+				i += end + 2
+				continue
+			}
+		}
+
+		if delim, kind, ok := wikiEffectAt(s, i); ok {
+			if end := findWikiCloser(s, i+len(delim), delim); end > i+len(delim) {
+				emit(&Node{Kind: kind, Children: parseWikiInline(s[i+len(delim) : end])})
+				i = end + len(delim)
+				continue
+			}
+		}
+
+		text.WriteByte(c)
+		i++
+	}
+	flush()
+	return nodes
+}
+
+// wikiEffectAt tells whether an effect opens at s[i]: it must not follow a word character
+// or be followed by a space
+func wikiEffectAt(s string, i int) (string, Kind, bool) {
+	for delim, kind := range wikiEffects {
+		if !strings.HasPrefix(s[i:], delim) {
+			continue
+		}
+		if i > 0 && isAlnum(s[i-1]) {
+			return "", 0, false
+		}
+		if next := i + len(delim); next >= len(s) || s[next] == ' ' {
+			return "", 0, false
+		}
+		return delim, kind, true
+	}
+	return "", 0, false
+}
+
+func findWikiCloser(s string, from int, delim string) int {
+	for j := from; j < len(s); j++ {
+		switch {
+		case s[j] == '\\':
+			j++
+		case strings.HasPrefix(s[j:], "{{"):
+			if end := wikiMonospaceEnd(s, j+2); end >= 0 {
+				j = end + 1
+			}
+		case strings.HasPrefix(s[j:], delim) && s[j-1] != ' ':
+			if next := j + len(delim); next >= len(s) || !isAlnum(s[next]) {
+				return j
+			}
+		}
+	}
+	return -1
+}
+
+// RenderWiki renders a document tree as Jira wiki markup
+func RenderWiki(doc *Node) string {
+	return renderWikiBlocks(doc.Children)
+}
+
+func renderWikiBlocks(blocks []*Node) string {
+	var parts []string
+	for _, block := range blocks {
+		parts = append(parts, renderWikiBlock(block))
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+func renderWikiBlock(n *Node) string {
+	switch n.Kind {
+	case Heading:
+		return "h" + string(rune('0'+max(1, min(n.Level, 6)))) + ". " + renderWikiInline(n.Children)
+	case CodeBlock:
+		if n.Language == "" {
+			return "{noformat}\n" + n.Text + "\n{noformat}"
+		}
+		return "{code:" + n.Language + "}\n" + n.Text + "\n{code}"
+	case Rule:
+		return "----"
+	case Quote:
+		return "{quote}\n" + renderWikiBlocks(n.Children) + "\n{quote}"
+	case Panel:
+		macro := "info"
+		switch n.PanelType {
+		case PanelNote:
+			macro = "note"
+		case PanelWarning, PanelError:
+			macro = "warning"
+		case PanelSuccess:
+			macro = "tip"
+		}
+		open := "{" + macro
+		if n.Title != "" {
+			open += ":title=" + n.Title
+		}
+		return open + "}\n" + renderWikiBlocks(n.Children) + "\n{" + macro + "}"
+	case List:
+		return renderWikiList(n, "")
+	case Table:
+		var rows []string
+		for _, row := range n.Children {
+			var sb strings.Builder
+			for _, cell := range row.Children {
+				delim := "|"
+				if cell.Header {
+					delim = "||"
+				}
+				text := renderWikiInline(cell.Children)
+				text = strings.ReplaceAll(text, "|", "\\|")
+				text = strings.ReplaceAll(text, "\n", " \\\\ ")
+				if text == "" {
+					text = " "
+				}
+				sb.WriteString(delim + text)
+			}
+			if len(row.Children) > 0 && row.Children[len(row.Children)-1].Header {
+				sb.WriteString("||")
+			} else {
+				sb.WriteString("|")
+			}
+			rows = append(rows, sb.String())
+		}
+		return strings.Join(rows, "\n")
+	case Paragraph:
+		return renderWikiInline(n.Children)
+	}
+	return renderWikiInline([]*Node{n})
+}
+
+// renderWikiList renders a list whose items are prefixed with the markers of the enclosing lists
+func renderWikiList(list *Node, prefix string) string {
+	marker := prefix + "*"
+	if list.Ordered {
+		marker = prefix + "#"
+	}
+
+	var lines []string
+	for _, item := range list.Children {
+		text := ""
+		for _, block := range item.Children {
+			switch {
+			case block.Kind == List:
+				if text != "" || len(lines) == 0 {
+					lines = append(lines, marker+" "+text)
+					text = ""
+				}
+				lines = append(lines, renderWikiList(block, marker))
+			case block.Kind == Paragraph && text == "":
+				text = strings.ReplaceAll(renderWikiInline(block.Children), "\n", " \\\\ ")
+			default:
+				text += " \\\\ " + strings.ReplaceAll(renderWikiBlock(block), "\n", " \\\\ ")
+			}
+		}
+		if text != "" || len(item.Children) == 0 {
+			lines = append(lines, marker+" "+text)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func renderWikiInline(nodes []*Node) string {
+	var sb strings.Builder
+	for _, n := range nodes {
+		switch n.Kind {
+		case Text:
+			sb.WriteString(escapeWiki(n.Text))
+		case Strong:
+			sb.WriteString("*" + renderWikiInline(n.Children) + "*")
+		case Emphasis:
+			sb.WriteString("_" + renderWikiInline(n.Children) + "_")
+		case Strike:
+			sb.WriteString("-" + renderWikiInline(n.Children) + "-")
+		case Underline:
+			sb.WriteString("+" + renderWikiInline(n.Children) + "+")
+		case Code:
+			// braces inside would end the monospace early or start a macro, so they are escaped
+			sb.WriteString("{{" + wikiMonospaceEscaper.Replace(n.Text) + "}}")
+		case Link:
+			label := renderWikiInline(n.Children)
+			if label == "" || label == n.URL {
+				sb.WriteString("[" + n.URL + "]")
+			} else {
+				sb.WriteString("[" + label + "|" + n.URL + "]")
+			}
+		case Image:
+			if n.Text != "" {
+				sb.WriteString("!" + n.URL + "|alt=" + n.Text + "!")
+			} else {
+				sb.WriteString("!" + n.URL + "!")
+			}
+		case Mention:
+			sb.WriteString("[~" + n.Text + "]")
+		case SoftBreak, HardBreak:
+			sb.WriteString("\n")
+		default:
+			if isBlock(n.Kind) {
+				sb.WriteString(renderWikiBlock(n))
+			} else {
+				sb.WriteString(renderWikiInline(n.Children))
+			}
+		}
+	}
+	return sb.String()
+}
+
+var (
+	wikiMonospaceEscaper   = strings.NewReplacer("{", `\{`, "}", `\}`)
+	wikiMonospaceUnescaper = strings.NewReplacer(`\{`, "{", `\}`, "}")
+)
+
+// wikiMonospaceEnd returns the index of the "}}" closing a monospace span whose content starts at
+// from, skipping escaped braces, or -1 when the span is not closed
+func wikiMonospaceEnd(s string, from int) int {
+	for j := from; j+1 < len(s); j++ {
+		switch {
+		case s[j] == '\\' && (s[j+1] == '{' || s[j+1] == '}'):
+			j++
+		case s[j] == '}' && s[j+1] == '}':
+			return j
+		}
+	}
+	return -1
+}
+
+// escapeWiki escapes the characters that would start a macro, a link or an effect
+func escapeWiki(text string) string {
+	var sb strings.Builder
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		if c == '{' || c == '[' {
+			sb.WriteByte('\\')
+		} else if _, _, ok := wikiEffectAt(text, i); ok && c != '?' {
+			sb.WriteByte('\\')
+		}
+		sb.WriteByte(c)
+	}
+	return sb.String()
+}